@@ -0,0 +1,86 @@
+// Command opnsense-sim serves the simulated OPNsense alias API for local development, so the firewall
+// features can be used without a router. Point features.firewall_management.router_endpoint at it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"homelab-dashboard/internal/services/firewall/opnsensesim"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// aliasFlags collects repeated -alias uuid=name:type[:ip,ip...] definitions.
+type aliasFlags []string
+
+func (a *aliasFlags) String() string {
+	return strings.Join(*a, " ")
+}
+
+func (a *aliasFlags) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+// defaultAliases match the example aliases in config.docker.yaml.template.
+var defaultAliases = []string{
+	"c0daef37-718c-40e4-bb2b-ba5aab418d0d=Database:host",
+	"7f93ff45-6c60-4a21-9767-3fc246f4d335=VPNUsers:host",
+}
+
+func main() {
+	listen := flag.String("listen", ":8443", "address to listen on")
+	apiKey := flag.String("api-key", "your-api-key", "API key clients must authenticate with")
+	apiSecret := flag.String("api-secret", "your-api-secret", "API secret clients must authenticate with")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	var aliases aliasFlags
+	flag.Var(&aliases, "alias", "alias to serve as uuid=name:type[:ip,ip...] (repeatable, defaults to the example config aliases)")
+	flag.Parse()
+
+	sim := opnsensesim.NewServer(*apiKey, *apiSecret)
+	sim.SetLatency(*latency)
+
+	if len(aliases) == 0 {
+		aliases = defaultAliases
+	}
+
+	for _, definition := range aliases {
+		uuid, name, aliasType, content, err := parseAlias(definition)
+		if err != nil {
+			log.Fatalf("invalid -alias %q: %v", definition, err)
+		}
+		sim.AddAlias(uuid, name, aliasType, content...)
+		log.Printf("serving alias %s (%s, %s)", name, aliasType, uuid)
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           sim,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("OPNsense simulator listening on %s", *listen)
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("server failed: %v", err)
+	}
+}
+
+func parseAlias(definition string) (uuid, name, aliasType string, content []string, err error) {
+	uuid, rest, ok := strings.Cut(definition, "=")
+	if !ok || uuid == "" {
+		return "", "", "", nil, fmt.Errorf("expected uuid=name:type")
+	}
+
+	parts := strings.SplitN(rest, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", nil, fmt.Errorf("expected uuid=name:type")
+	}
+
+	if len(parts) == 3 && parts[2] != "" {
+		content = strings.Split(parts[2], ",")
+	}
+
+	return uuid, parts[0], parts[1], content, nil
+}
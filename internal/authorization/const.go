@@ -26,6 +26,11 @@ const (
 	ScopeFirewallReadAll   = "firewall:read:all"
 	ScopeFirewallRevokeAll = "firewall:revoke:all"
 	ScopeFirewallBlacklist = "firewall:blacklist"
+	ScopeFirewallAliases   = "firewall:aliases"
+)
+
+const (
+	ScopeStatusWrite = "status:write" // post incidents, maintenance and their updates to the status page
 )
 
 // GetAllValidScopes returns all valid authorization scopes defined in the system
@@ -47,5 +52,7 @@ func GetAllValidScopes() []string {
 		ScopeFirewallReadAll,
 		ScopeFirewallRevokeAll,
 		ScopeFirewallBlacklist,
+		ScopeFirewallAliases,
+		ScopeStatusWrite,
 	}
 }
@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"homelab-dashboard/internal/authorization"
 	"log/slog"
+	"math"
 	"net"
+	"net/mail"
 	"os"
+	"regexp"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
 )
 
@@ -46,6 +51,7 @@ var (
 	EnvDataPrometheusURL        = "DASHBOARD_DATA_PROMETHEUS_URL"
 	EnvDataBasicAuthUsername    = "DASHBOARD_DATA_BASIC_AUTH_USERNAME"
 	EnvDataBasicAuthPassword    = "DASHBOARD_DATA_BASIC_AUTH_PASSWORD"
+	EnvDataAlertsSMTPPassword   = "DASHBOARD_DATA_ALERTS_SMTP_PASSWORD"
 	EnvRedisPassword            = "DASHBOARD_REDIS_PASSWORD"
 	EnvRedisUsername            = "DASHBOARD_REDIS_USERNAME"
 	EnvRedisSentinelUsername    = "DASHBOARD_REDIS_SENTINEL_USERNAME"
@@ -97,6 +103,13 @@ func applyEnvironmentOverrides(config *Config) {
 		config.Data.BasicAuth.Password = password
 	}
 
+	if smtpPassword := os.Getenv(EnvDataAlertsSMTPPassword); smtpPassword != "" && config.Data.Alerts != nil {
+		if config.Data.Alerts.SMTP == nil {
+			config.Data.Alerts.SMTP = &DataAlertSMTP{}
+		}
+		config.Data.Alerts.SMTP.Password = smtpPassword
+	}
+
 	if redisPassword := os.Getenv(EnvRedisPassword); redisPassword != "" {
 		if config.Redis == nil {
 			config.Redis = &RedisConfig{}
@@ -410,8 +423,17 @@ func (c *Config) validateSessionConfig() error {
 }
 
 func (c *Config) validateDataConfig() (err error) {
-	if c.Data.PrometheusURL == "" {
-		return fmt.Errorf("data.prometheus_url is required")
+	usesSource := func(source string) bool {
+		for _, query := range c.Data.Queries {
+			if !query.Disabled && (query.Source == source || (query.Source == "" && source == DataSourcePrometheus)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if c.Data.PrometheusURL == "" && len(c.Data.Backends) == 0 && (len(c.Data.Queries) == 0 || usesSource(DataSourcePrometheus)) {
+		return fmt.Errorf("data.prometheus_url or data.backends is required")
 	}
 
 	if c.Data.BasicAuth != nil {
@@ -423,18 +445,618 @@ func (c *Config) validateDataConfig() (err error) {
 		}
 	}
 
+	if err := c.validateDataBackends(); err != nil {
+		return err
+	}
+
+	if c.Data.Loki != nil {
+		if !isHTTPURL(c.Data.Loki.URL) {
+			return fmt.Errorf("data.loki.url must be an http or https URL")
+		}
+
+		if c.Data.Loki.BasicAuth != nil && (c.Data.Loki.BasicAuth.Username == "" || c.Data.Loki.BasicAuth.Password == "") {
+			return fmt.Errorf("data.loki.basic_auth requires both username and password")
+		}
+	} else if usesSource(DataSourceLoki) {
+		return fmt.Errorf("data.loki is required by queries with source %s", DataSourceLoki)
+	}
+
+	if c.Data.Kubernetes == nil && usesSource(DataSourceKubernetes) {
+		return fmt.Errorf("data.kubernetes is required by queries with source %s", DataSourceKubernetes)
+	}
+
 	if len(c.Data.Queries) > 0 {
 		if err = c.validateDataQueriesConfig(); err != nil {
 			return err
 		}
 	}
 
-	if c.Data.FallbackFetchInterval.Seconds() < 0 {
+	if c.Data.FallbackFetchInterval.Seconds() <= 0 {
 		c.Data.FallbackFetchInterval = defaultDataConfig.FallbackFetchInterval
-	} else if c.Data.FallbackFetchInterval.Seconds() < 30 && c.Data.FallbackFetchInterval.Seconds() > 0 {
+	} else if c.Data.FallbackFetchInterval.Seconds() < 30 {
 		return fmt.Errorf("data.fallback_fetch_interval cannot be less than 30 seconds")
 	}
 
+	if err := c.validateDataVariables(); err != nil {
+		return err
+	}
+
+	if err := c.validateDataRangeQueries(); err != nil {
+		return err
+	}
+
+	if err := c.validateDataSchedulerConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateDataCircuitBreaker(); err != nil {
+		return err
+	}
+
+	if err := c.validateDataProbes(); err != nil {
+		return err
+	}
+
+	if err := c.validateDataHistory(); err != nil {
+		return err
+	}
+
+	return c.validateDataAlerts()
+}
+
+// validateDataBackends turns prometheus_url and basic_auth into a backend named "default", placed first so it is
+// the one queries without a backend use, and checks every backend.
+func (c *Config) validateDataBackends() error {
+	if c.Data.PrometheusURL != "" {
+		legacy := DataPrometheusBackend{
+			Name:      DefaultDataBackendName,
+			URL:       c.Data.PrometheusURL,
+			BasicAuth: c.Data.BasicAuth,
+		}
+		c.Data.Backends = append([]DataPrometheusBackend{legacy}, c.Data.Backends...)
+		c.Data.PrometheusURL = ""
+		c.Data.BasicAuth = nil
+	}
+
+	names := make(map[string]bool, len(c.Data.Backends))
+	for i := range c.Data.Backends {
+		backend := &c.Data.Backends[i]
+
+		if !backendNamePattern.MatchString(backend.Name) {
+			return fmt.Errorf("data.backends[%d].name must contain only letters, digits, dashes and underscores", i)
+		}
+		if names[backend.Name] {
+			return fmt.Errorf("data.backends[%d].name %q is defined more than once", i, backend.Name)
+		}
+		names[backend.Name] = true
+
+		if !isHTTPURL(backend.URL) {
+			return fmt.Errorf("data.backends[%d].url must be an http or https URL", i)
+		}
+
+		if backend.BasicAuth != nil {
+			if backend.BasicAuth.Username == "" || backend.BasicAuth.Password == "" {
+				return fmt.Errorf("data.backends[%d].basic_auth requires both username and password", i)
+			}
+			if backend.BearerTokenFile != "" {
+				return fmt.Errorf("data.backends[%d] cannot set both basic_auth and bearer_token_file", i)
+			}
+		}
+
+		if backend.TLS != nil && (backend.TLS.CertFile == "") != (backend.TLS.KeyFile == "") {
+			return fmt.Errorf("data.backends[%d].tls requires both cert_file and key_file for client certificates", i)
+		}
+
+		for name := range backend.Headers {
+			if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, "X-Scope-OrgID") {
+				return fmt.Errorf("data.backends[%d].headers cannot set %s, use basic_auth, bearer_token_file or tenant_id", i, name)
+			}
+		}
+
+		if backend.Timeout == 0 {
+			backend.Timeout = DefaultDataBackendTimeout
+		} else if backend.Timeout < time.Second {
+			return fmt.Errorf("data.backends[%d].timeout cannot be less than 1s", i)
+		}
+	}
+
+	return nil
+}
+
+// resolveDataBackend returns the backend a query or variable uses: the named one, or the first when none is named.
+func (c *Config) resolveDataBackend(name string) (string, error) {
+	if len(c.Data.Backends) == 0 {
+		return "", fmt.Errorf("requires data.prometheus_url or data.backends")
+	}
+	if name == "" {
+		return c.Data.Backends[0].Name, nil
+	}
+	for _, backend := range c.Data.Backends {
+		if backend.Name == name {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("backend %q is not defined in data.backends", name)
+}
+
+func (c *Config) validateDataVariables() error {
+	names := make(map[string]bool, len(c.Data.Variables))
+
+	for i := range c.Data.Variables {
+		variable := &c.Data.Variables[i]
+
+		if !variableNamePattern.MatchString(variable.Name) {
+			return fmt.Errorf("data.variables[%d].name must contain only letters, digits and underscores", i)
+		}
+		if names[variable.Name] {
+			return fmt.Errorf("data.variables[%d].name %q is defined more than once", i, variable.Name)
+		}
+		names[variable.Name] = true
+
+		switch {
+		case len(variable.Values) > 0 && variable.LabelValues != nil:
+			return fmt.Errorf("data.variables[%d] cannot set both values and label_values", i)
+
+		case len(variable.Values) > 0:
+			if variable.Default == "" {
+				variable.Default = variable.Values[0]
+			} else if !slices.Contains(variable.Values, variable.Default) {
+				return fmt.Errorf("data.variables[%d].default must be one of its values", i)
+			}
+			for _, value := range variable.Precompute {
+				if !slices.Contains(variable.Values, value) {
+					return fmt.Errorf("data.variables[%d].precompute value %q is not one of its values", i, value)
+				}
+			}
+
+		case variable.LabelValues != nil:
+			backend, err := c.resolveDataBackend(variable.LabelValues.Backend)
+			if err != nil {
+				return fmt.Errorf("data.variables[%d].label_values %w", i, err)
+			}
+			variable.LabelValues.Backend = backend
+			if !labelNamePattern.MatchString(variable.LabelValues.Label) {
+				return fmt.Errorf("data.variables[%d].label_values.label must be a valid label name", i)
+			}
+			if variable.Default == "" {
+				return fmt.Errorf("data.variables[%d].default is required with label_values", i)
+			}
+			if variable.LabelValues.RefreshInterval == 0 {
+				variable.LabelValues.RefreshInterval = 5 * time.Minute
+			} else if variable.LabelValues.RefreshInterval < 30*time.Second {
+				return fmt.Errorf("data.variables[%d].label_values.refresh_interval cannot be less than 30s", i)
+			}
+
+		default:
+			return fmt.Errorf("data.variables[%d] requires either values or label_values", i)
+		}
+
+		if !slices.Contains(variable.Precompute, variable.Default) {
+			variable.Precompute = append(variable.Precompute, variable.Default)
+		}
+	}
+
+	if c.Data.OnDemand == nil {
+		defaults := *DefaultDataOnDemandConfig
+		c.Data.OnDemand = &defaults
+	}
+
+	if c.Data.OnDemand.RequestsPerWindow == 0 {
+		c.Data.OnDemand.RequestsPerWindow = DefaultDataOnDemandConfig.RequestsPerWindow
+	} else if c.Data.OnDemand.RequestsPerWindow < 0 {
+		return fmt.Errorf("data.on_demand.requests_per_window cannot be negative")
+	}
+
+	if c.Data.OnDemand.Window == 0 {
+		c.Data.OnDemand.Window = DefaultDataOnDemandConfig.Window
+	} else if c.Data.OnDemand.Window < time.Second {
+		return fmt.Errorf("data.on_demand.window cannot be less than 1s")
+	}
+
+	return nil
+}
+
+func (c *Config) validateDataRangeQueries() error {
+	if c.Data.RangeQueries == nil {
+		defaults := *DefaultDataRangeQueries
+		c.Data.RangeQueries = &defaults
+	}
+
+	limits := c.Data.RangeQueries
+
+	if limits.MaxRange == 0 {
+		limits.MaxRange = DefaultDataRangeQueries.MaxRange
+	} else if limits.MaxRange < time.Minute {
+		return fmt.Errorf("data.range_queries.max_range cannot be less than 1m")
+	}
+
+	if limits.MaxPoints == 0 {
+		limits.MaxPoints = DefaultDataRangeQueries.MaxPoints
+	} else if limits.MaxPoints < 2 || limits.MaxPoints > 11000 {
+		return fmt.Errorf("data.range_queries.max_points must be between 2 and 11000")
+	}
+
+	if limits.CacheTTL == 0 {
+		limits.CacheTTL = DefaultDataRangeQueries.CacheTTL
+	} else if limits.CacheTTL < 0 {
+		return fmt.Errorf("data.range_queries.cache_ttl cannot be negative")
+	}
+
+	if limits.RequestsPerWindow == 0 {
+		limits.RequestsPerWindow = DefaultDataRangeQueries.RequestsPerWindow
+	} else if limits.RequestsPerWindow < 0 {
+		return fmt.Errorf("data.range_queries.requests_per_window cannot be negative")
+	}
+
+	if limits.Window == 0 {
+		limits.Window = DefaultDataRangeQueries.Window
+	} else if limits.Window < time.Second {
+		return fmt.Errorf("data.range_queries.window cannot be less than 1s")
+	}
+
+	for i, query := range c.Data.Queries {
+		if query.MaxRange < 0 {
+			return fmt.Errorf("data.queries[%d].max_range cannot be negative", i)
+		}
+		if query.MaxPoints != 0 && (query.MaxPoints < 2 || query.MaxPoints > 11000) {
+			return fmt.Errorf("data.queries[%d].max_points must be between 2 and 11000", i)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateDataHistory() error {
+	if c.Data.History == nil {
+		c.Data.History = &DataHistory{}
+	}
+
+	history := c.Data.History
+
+	if history.Retention == nil {
+		defaults := *DefaultDataHistory.Retention
+		history.Retention = &defaults
+	}
+	if err := fillDataHistoryRetention("data.history.retention", history.Retention, *DefaultDataHistory.Retention); err != nil {
+		return err
+	}
+
+	if history.PruneInterval == 0 {
+		history.PruneInterval = DefaultDataHistory.PruneInterval
+	} else if history.PruneInterval < time.Minute {
+		return fmt.Errorf("data.history.prune_interval cannot be less than 1m")
+	}
+
+	for i := range c.Data.Queries {
+		query := &c.Data.Queries[i]
+		if query.History == nil || !query.History.Enabled {
+			continue
+		}
+
+		if !history.Enabled {
+			return fmt.Errorf("data.queries[%d].history requires data.history.enabled", i)
+		}
+		if query.Type == "range" {
+			return fmt.Errorf("data.queries[%d].history is only supported for instant queries", i)
+		}
+
+		if query.History.Retention == nil {
+			query.History.Retention = &DataHistoryRetention{}
+		}
+		if err := fillDataHistoryRetention(fmt.Sprintf("data.queries[%d].history.retention", i), query.History.Retention, *history.Retention); err != nil {
+			return err
+		}
+	}
+
+	for i := range c.Data.Probes {
+		probe := &c.Data.Probes[i]
+		if probe.History == nil || !probe.History.Enabled {
+			continue
+		}
+
+		if !history.Enabled {
+			return fmt.Errorf("data.probes[%d].history requires data.history.enabled", i)
+		}
+
+		if probe.History.Retention == nil {
+			probe.History.Retention = &DataHistoryRetention{}
+		}
+		if err := fillDataHistoryRetention(fmt.Sprintf("data.probes[%d].history.retention", i), probe.History.Retention, *history.Retention); err != nil {
+			return err
+		}
+	}
+
+	if history.Enabled && (c.Storage == nil || !c.Storage.Enabled) {
+		return fmt.Errorf("data.history requires storage to be enabled")
+	}
+
+	return nil
+}
+
+func (c *Config) validateDataProbes() error {
+	names := make(map[string]bool, len(c.Data.Probes))
+
+	for i := range c.Data.Probes {
+		probe := &c.Data.Probes[i]
+
+		if probe.Name == "" {
+			return fmt.Errorf("data.probes[%d].name is required", i)
+		}
+		if strings.Contains(probe.Name, "?") {
+			return fmt.Errorf("data.probes[%d].name cannot contain '?'", i)
+		}
+		if names[probe.Name] {
+			return fmt.Errorf("data.probes[%d].name %q is defined more than once", i, probe.Name)
+		}
+		names[probe.Name] = true
+
+		// Probe results are cached under the probe's name, next to the results of queries
+		if slices.ContainsFunc(c.Data.Queries, func(query PrometheusQuery) bool { return query.Name == probe.Name }) {
+			return fmt.Errorf("data.probes[%d].name %q is already the name of a query", i, probe.Name)
+		}
+
+		if probe.Disabled {
+			continue
+		}
+
+		if probe.Interval == 0 {
+			probe.Interval = DefaultDataProbe.Interval
+		} else if probe.Interval < 10*time.Second {
+			return fmt.Errorf("data.probes[%d].interval cannot be less than 10s", i)
+		}
+
+		if probe.Timeout == 0 {
+			probe.Timeout = min(DefaultDataProbe.Timeout, probe.Interval)
+		} else if probe.Timeout < 0 || probe.Timeout > probe.Interval {
+			return fmt.Errorf("data.probes[%d].timeout must be positive and no longer than its interval", i)
+		}
+
+		if probe.CertExpiryDays < 0 {
+			return fmt.Errorf("data.probes[%d].cert_expiry_days cannot be negative", i)
+		}
+
+		if err := validateDataProbeCheck(probe); err != nil {
+			return fmt.Errorf("data.probes[%d] %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateDataProbeCheck checks a probe's target and that it only sets the options of its type.
+func validateDataProbeCheck(probe *DataProbe) error {
+	if probe.Type != DataProbeHTTP && (len(probe.ExpectedStatus) > 0 || probe.BodyRegex != "") {
+		return fmt.Errorf("expected_status and body_regex are only supported by %s probes", DataProbeHTTP)
+	}
+	if probe.Type != DataProbeTCP && probe.TLS {
+		return fmt.Errorf("tls is only supported by %s probes", DataProbeTCP)
+	}
+	if probe.Type != DataProbeHTTP && probe.Type != DataProbeTCP && probe.CertExpiryDays > 0 {
+		return fmt.Errorf("cert_expiry_days is only supported by %s and %s probes", DataProbeHTTP, DataProbeTCP)
+	}
+	if probe.Type != DataProbeHTTP && probe.Type != DataProbeTCP && probe.CAFile != "" {
+		return fmt.Errorf("ca_file is only supported by %s and %s probes", DataProbeHTTP, DataProbeTCP)
+	}
+	if probe.Type != DataProbeDNS && (probe.RecordType != "" || probe.Resolver != "") {
+		return fmt.Errorf("record_type and resolver are only supported by %s probes", DataProbeDNS)
+	}
+
+	switch probe.Type {
+	case DataProbeHTTP:
+		if !isHTTPURL(probe.Target) {
+			return fmt.Errorf("target must be an http or https URL")
+		}
+		for _, status := range probe.ExpectedStatus {
+			if status < 100 || status > 599 {
+				return fmt.Errorf("expected_status %d is not an HTTP status code", status)
+			}
+		}
+		if _, err := regexp.Compile(probe.BodyRegex); err != nil {
+			return fmt.Errorf("body_regex is invalid: %w", err)
+		}
+	case DataProbeTCP:
+		if _, port, err := net.SplitHostPort(probe.Target); err != nil || port == "" {
+			return fmt.Errorf("target must be a host:port")
+		}
+		if probe.CertExpiryDays > 0 && !probe.TLS {
+			return fmt.Errorf("cert_expiry_days requires tls")
+		}
+		if probe.CAFile != "" && !probe.TLS {
+			return fmt.Errorf("ca_file requires tls")
+		}
+	case DataProbeDNS:
+		if probe.Target == "" {
+			return fmt.Errorf("target is required")
+		}
+		if probe.RecordType == "" {
+			probe.RecordType = DefaultDataProbe.RecordType
+		} else if probe.RecordType = strings.ToUpper(probe.RecordType); !slices.Contains(DataProbeRecordTypes, probe.RecordType) {
+			return fmt.Errorf("record_type must be one of %s", strings.Join(DataProbeRecordTypes, ", "))
+		}
+		if probe.Resolver != "" {
+			if _, _, err := net.SplitHostPort(probe.Resolver); err != nil {
+				return fmt.Errorf("resolver must be a host:port")
+			}
+		}
+	case DataProbeICMP:
+		if probe.Target == "" {
+			return fmt.Errorf("target is required")
+		}
+	default:
+		return fmt.Errorf("type must be %s, %s, %s or %s", DataProbeHTTP, DataProbeTCP, DataProbeDNS, DataProbeICMP)
+	}
+
+	return nil
+}
+
+// fillDataHistoryRetention sets the rollups retention leaves out to the given defaults. Each rollup must be kept
+// for at least one of its buckets.
+func fillDataHistoryRetention(path string, retention *DataHistoryRetention, defaults DataHistoryRetention) error {
+	rollups := []struct {
+		name     string
+		value    *time.Duration
+		fallback time.Duration
+		minimum  time.Duration
+	}{
+		{"5m", &retention.FiveMinutes, defaults.FiveMinutes, 5 * time.Minute},
+		{"1h", &retention.Hourly, defaults.Hourly, time.Hour},
+		{"1d", &retention.Daily, defaults.Daily, 24 * time.Hour},
+	}
+
+	for _, rollup := range rollups {
+		if *rollup.value == 0 {
+			*rollup.value = rollup.fallback
+		} else if *rollup.value < rollup.minimum {
+			return fmt.Errorf("%s.%s cannot be less than %s", path, rollup.name, rollup.minimum)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateDataAlerts() error {
+	alerts := c.Data.Alerts
+	if alerts == nil || len(alerts.Rules) == 0 {
+		return nil
+	}
+
+	if c.Storage == nil || !c.Storage.Enabled {
+		return fmt.Errorf("data.alerts requires storage to be enabled")
+	}
+
+	if alerts.EvaluationInterval == 0 {
+		alerts.EvaluationInterval = DefaultDataAlerts.EvaluationInterval
+	} else if alerts.EvaluationInterval < 5*time.Second {
+		return fmt.Errorf("data.alerts.evaluation_interval cannot be less than 5s")
+	}
+
+	receivers := make(map[string]bool, len(alerts.Receivers))
+	for i, receiver := range alerts.Receivers {
+		if receiver.Name == "" {
+			return fmt.Errorf("data.alerts.receivers[%d].name is required", i)
+		}
+		if receivers[receiver.Name] {
+			return fmt.Errorf("data.alerts.receivers[%d].name %q is defined more than once", i, receiver.Name)
+		}
+		receivers[receiver.Name] = true
+
+		switch receiver.Type {
+		case DataAlertReceiverWebhook:
+			if !isHTTPURL(receiver.URL) {
+				return fmt.Errorf("data.alerts.receivers[%d].url must be an http or https URL", i)
+			}
+		case DataAlertReceiverEmail:
+			if len(receiver.To) == 0 {
+				return fmt.Errorf("data.alerts.receivers[%d].to requires at least one address", i)
+			}
+			for _, address := range receiver.To {
+				if _, err := mail.ParseAddress(address); err != nil {
+					return fmt.Errorf("data.alerts.receivers[%d].to contains an invalid address %q", i, address)
+				}
+			}
+			if alerts.SMTP == nil || alerts.SMTP.Host == "" || alerts.SMTP.From == "" {
+				return fmt.Errorf("data.alerts.smtp host and from are required by email receivers")
+			}
+			if alerts.SMTP.Port == 0 {
+				alerts.SMTP.Port = 587
+			}
+		default:
+			return fmt.Errorf("data.alerts.receivers[%d].type must be %s or %s", i, DataAlertReceiverWebhook, DataAlertReceiverEmail)
+		}
+	}
+
+	rules := make(map[string]bool, len(alerts.Rules))
+	for i := range alerts.Rules {
+		rule := &alerts.Rules[i]
+
+		if !variableNamePattern.MatchString(rule.Name) {
+			return fmt.Errorf("data.alerts.rules[%d].name must contain only letters, digits and underscores", i)
+		}
+		if rules[rule.Name] {
+			return fmt.Errorf("data.alerts.rules[%d].name %q is defined more than once", i, rule.Name)
+		}
+		rules[rule.Name] = true
+
+		if !slices.ContainsFunc(c.Data.Queries, func(query PrometheusQuery) bool { return query.Name == rule.Query }) {
+			return fmt.Errorf("data.alerts.rules[%d].query %q is not a configured query", i, rule.Query)
+		}
+
+		if !alertConditionPattern.MatchString(rule.Condition) {
+			return fmt.Errorf("data.alerts.rules[%d].condition must compare with a number, e.g. \"> 90\"", i)
+		}
+
+		if rule.For < 0 {
+			return fmt.Errorf("data.alerts.rules[%d].for cannot be negative", i)
+		}
+
+		switch rule.Severity {
+		case "":
+			rule.Severity = DataAlertSeverityWarning
+		case DataAlertSeverityInfo, DataAlertSeverityWarning, DataAlertSeverityCritical:
+		default:
+			return fmt.Errorf("data.alerts.rules[%d].severity must be %s, %s or %s", i, DataAlertSeverityInfo, DataAlertSeverityWarning, DataAlertSeverityCritical)
+		}
+
+		for _, name := range rule.Receivers {
+			if !receivers[name] {
+				return fmt.Errorf("data.alerts.rules[%d].receivers references unknown receiver %q", i, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateDataCircuitBreaker() error {
+	if c.Data.CircuitBreaker == nil {
+		defaults := *DefaultDataCircuitBreaker
+		c.Data.CircuitBreaker = &defaults
+	}
+
+	breaker := c.Data.CircuitBreaker
+
+	if breaker.FailureThreshold == 0 {
+		breaker.FailureThreshold = DefaultDataCircuitBreaker.FailureThreshold
+	} else if breaker.FailureThreshold < 0 {
+		return fmt.Errorf("data.circuit_breaker.failure_threshold cannot be negative")
+	}
+
+	if breaker.InitialBackoff == 0 {
+		breaker.InitialBackoff = DefaultDataCircuitBreaker.InitialBackoff
+	}
+	if breaker.MaxBackoff == 0 {
+		breaker.MaxBackoff = max(DefaultDataCircuitBreaker.MaxBackoff, breaker.InitialBackoff)
+	}
+
+	if breaker.InitialBackoff < time.Second {
+		return fmt.Errorf("data.circuit_breaker.initial_backoff cannot be less than 1s")
+	}
+
+	if breaker.MaxBackoff < breaker.InitialBackoff {
+		return fmt.Errorf("data.circuit_breaker.max_backoff cannot be less than data.circuit_breaker.initial_backoff")
+	}
+
+	return nil
+}
+
+func (c *Config) validateDataSchedulerConfig() error {
+	if c.Data.Scheduler == nil {
+		defaults := *DefaultDataSchedulerConfig
+		c.Data.Scheduler = &defaults
+	}
+
+	scheduler := c.Data.Scheduler
+
+	if scheduler.Workers == 0 {
+		scheduler.Workers = DefaultDataSchedulerConfig.Workers
+	} else if scheduler.Workers < 0 || scheduler.Workers > 64 {
+		return fmt.Errorf("data.scheduler.workers must be between 1 and 64")
+	}
+
+	if scheduler.MaxJitter < 0 {
+		return fmt.Errorf("data.scheduler.max_jitter cannot be negative")
+	}
+
 	return nil
 }
 
@@ -455,13 +1077,58 @@ func (c *Config) validateDataQueriesConfig() (err error) {
 			return fmt.Errorf("data.queries[%d].query is required", i)
 		}
 
+		if strings.Contains(query.Name, "?") {
+			return fmt.Errorf("data.queries[%d].name cannot contain '?'", i)
+		}
+
 		if query.TTL.Seconds() == 0 {
 			queries[i].TTL = 30 * time.Second
 		} else if query.TTL.Seconds() < 30 {
 			return fmt.Errorf("data.queries[%d].ttl cannot be less than 30s", i)
 		}
 
+		if query.Source == "" {
+			queries[i].Source = DataSourcePrometheus
+		}
+
+		if queries[i].Source == DataSourcePrometheus {
+			backend, err := c.resolveDataBackend(query.Backend)
+			if err != nil {
+				return fmt.Errorf("data.queries[%d] %w", i, err)
+			}
+			queries[i].Backend = backend
+		} else if query.Backend != "" {
+			return fmt.Errorf("data.queries[%d].backend is only supported by %s queries", i, DataSourcePrometheus)
+		}
+
+		switch queries[i].Source {
+		case DataSourcePrometheus, DataSourceLoki:
+		case DataSourceHTTPJSON:
+			if !isHTTPURL(query.URL) {
+				return fmt.Errorf("data.queries[%d].url must be an http or https URL for %s queries", i, DataSourceHTTPJSON)
+			}
+			if !strings.HasPrefix(query.Query, "$") {
+				return fmt.Errorf("data.queries[%d].query must be a JSONPath expression starting with $", i)
+			}
+		case DataSourceKubernetes:
+			switch query.Query {
+			case DataKubernetesNodes, DataKubernetesPods, DataKubernetesPersistentVolumeClaims:
+			default:
+				return fmt.Errorf("data.queries[%d].query must be one of %s, %s or %s for %s queries", i,
+					DataKubernetesNodes, DataKubernetesPods, DataKubernetesPersistentVolumeClaims, DataSourceKubernetes)
+			}
+			if query.Namespace != "" && !isDNSLabel(query.Namespace) {
+				return fmt.Errorf("data.queries[%d].namespace must be a valid Kubernetes namespace", i)
+			}
+		default:
+			return fmt.Errorf("data.queries[%d].source %q is not supported", i, query.Source)
+		}
+
 		if query.Type == "range" {
+			if queries[i].Source != DataSourcePrometheus && queries[i].Source != DataSourceLoki {
+				return fmt.Errorf("data.queries[%d].type range is only supported by %s and %s queries", i, DataSourcePrometheus, DataSourceLoki)
+			}
+
 			if query.Range == "" {
 				return fmt.Errorf("data.queries[%d].range is required for range queries", i)
 			}
@@ -472,6 +1139,60 @@ func (c *Config) validateDataQueriesConfig() (err error) {
 		} else if query.Type != "" {
 			return fmt.Errorf("invalid query type: %s", query.Type)
 		}
+
+		for j, step := range query.Transform {
+			if err := validateDataTransform(step); err != nil {
+				return fmt.Errorf("data.queries[%d].transform[%d] %w", i, j, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateDataTransform checks a transform step sets exactly one valid field.
+func validateDataTransform(step DataTransform) error {
+	set := 0
+	for _, isSet := range []bool{step.Reduce != "", len(step.RenameLabels) > 0, len(step.DropLabels) > 0, step.Unit != "", step.TopN != 0, step.Downsample != 0} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("must set exactly one of reduce, rename_labels, drop_labels, unit, top_n or downsample")
+	}
+
+	switch {
+	case step.Reduce != "":
+		switch step.Reduce {
+		case DataTransformReduceLast, DataTransformReduceAvg, DataTransformReduceMin, DataTransformReduceMax:
+		default:
+			return fmt.Errorf("reduce must be %s, %s, %s or %s", DataTransformReduceLast, DataTransformReduceAvg, DataTransformReduceMin, DataTransformReduceMax)
+		}
+	case len(step.RenameLabels) > 0:
+		for from, to := range step.RenameLabels {
+			if !labelNamePattern.MatchString(from) || !labelNamePattern.MatchString(to) {
+				return fmt.Errorf("rename_labels must map label names to valid label names")
+			}
+		}
+	case len(step.DropLabels) > 0:
+		for _, label := range step.DropLabels {
+			if !labelNamePattern.MatchString(label) {
+				return fmt.Errorf("drop_labels contains an invalid label name %q", label)
+			}
+		}
+	case step.Unit != "":
+		if !slices.Contains(DataTransformUnits, step.Unit) {
+			return fmt.Errorf("unit must be one of %s", strings.Join(DataTransformUnits, ", "))
+		}
+	case step.TopN != 0:
+		if step.TopN < 1 {
+			return fmt.Errorf("top_n must be at least 1")
+		}
+	case step.Downsample != 0:
+		if step.Downsample < 2 {
+			return fmt.Errorf("downsample must be at least 2")
+		}
 	}
 
 	return nil
@@ -622,6 +1343,89 @@ func (c *Config) ValidateFeaturesConfig() error {
 		}
 	}
 
+	if c.Features.StatusPage.Enabled {
+		if err := c.ValidateStatusPageConfig(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) ValidateStatusPageConfig() error {
+	statusPage := &c.Features.StatusPage
+
+	// Incidents and maintenance are kept in storage
+	if c.Storage == nil || !c.Storage.Enabled {
+		return fmt.Errorf("storage must be enabled when status_page is enabled")
+	}
+
+	if statusPage.Title == "" {
+		statusPage.Title = DefaultStatusPage.Title
+	}
+
+	if statusPage.HistoryDays == 0 {
+		statusPage.HistoryDays = DefaultStatusPage.HistoryDays
+	} else if statusPage.HistoryDays < 0 {
+		return fmt.Errorf("features.status_page.history_days cannot be negative")
+	}
+
+	if len(statusPage.Services) == 0 {
+		return fmt.Errorf("features.status_page.services requires at least one service")
+	}
+
+	components := make(map[string]bool)
+	for i, service := range statusPage.Services {
+		if service.Name == "" {
+			return fmt.Errorf("features.status_page.services[%d].name is required", i)
+		}
+		if len(service.Components) == 0 {
+			return fmt.Errorf("features.status_page.services[%d].components requires at least one component", i)
+		}
+
+		for j, component := range service.Components {
+			path := fmt.Sprintf("features.status_page.services[%d].components[%d]", i, j)
+
+			if component.Name == "" {
+				return fmt.Errorf("%s.name is required", path)
+			}
+			if components[component.Name] {
+				return fmt.Errorf("%s.name %q is defined more than once", path, component.Name)
+			}
+			components[component.Name] = true
+
+			switch {
+			case component.Probe != "" && component.Query != "":
+				return fmt.Errorf("%s cannot set both probe and query", path)
+			case component.Probe != "":
+				index := slices.IndexFunc(c.Data.Probes, func(probe DataProbe) bool { return probe.Name == component.Probe && !probe.Disabled })
+				if index < 0 {
+					return fmt.Errorf("%s.probe %q is not a configured probe", path, component.Probe)
+				}
+				// The status page is public, so it cannot reveal results only some users may see
+				if probe := c.Data.Probes[index]; probe.RequireAuth || probe.RequiredGroup != "" {
+					return fmt.Errorf("%s.probe %q requires authentication and cannot be shown on the public status page", path, component.Probe)
+				}
+				if component.Condition != "" {
+					return fmt.Errorf("%s.condition is only supported with query", path)
+				}
+			case component.Query != "":
+				index := slices.IndexFunc(c.Data.Queries, func(query PrometheusQuery) bool { return query.Name == component.Query && !query.Disabled })
+				if index < 0 {
+					return fmt.Errorf("%s.query %q is not a configured query", path, component.Query)
+				}
+				if query := c.Data.Queries[index]; query.RequireAuth || query.RequiredGroup != "" {
+					return fmt.Errorf("%s.query %q requires authentication and cannot be shown on the public status page", path, component.Query)
+				}
+				if !alertConditionPattern.MatchString(component.Condition) {
+					return fmt.Errorf("%s.condition must compare with a number, e.g. \"< 1\"", path)
+				}
+			case component.Condition != "":
+				return fmt.Errorf("%s.condition is only supported with query", path)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -825,55 +1629,260 @@ func (c *Config) ValidateFirewallManagementConfig() error {
 		return fmt.Errorf("features.firewall_management.background_job_config.expiration_interval cannot be less than 1 minute")
 	}
 
-	if len(c.Features.FirewallManagement.Aliases) == 0 {
-		return fmt.Errorf("features.firewall_management.aliases must have at least one alias configured when firewall_management is enabled")
+	if c.Features.FirewallManagement.BackgroundJobConfig.ScheduleInterval == 0 {
+		c.Features.FirewallManagement.BackgroundJobConfig.ScheduleInterval = DefaultFirewallBackgroundJobConfig.ScheduleInterval
 	}
 
-	aliasNames := make(map[string]bool)
-	for i, alias := range c.Features.FirewallManagement.Aliases {
-		if alias.Name == "" {
-			return fmt.Errorf("features.firewall_management.aliases[%d].name is required", i)
+	if c.Features.FirewallManagement.BackgroundJobConfig.ScheduleInterval < 10*time.Second {
+		return fmt.Errorf("features.firewall_management.background_job_config.schedule_interval cannot be less than 10 seconds")
+	}
+
+	if c.Features.FirewallManagement.BackgroundJobConfig.SyncDebounce == 0 {
+		c.Features.FirewallManagement.BackgroundJobConfig.SyncDebounce = DefaultFirewallBackgroundJobConfig.SyncDebounce
+	}
+
+	if c.Features.FirewallManagement.BackgroundJobConfig.SyncDebounce < 0 || c.Features.FirewallManagement.BackgroundJobConfig.SyncDebounce > 1*time.Minute {
+		return fmt.Errorf("features.firewall_management.background_job_config.sync_debounce cannot be negative or more than 1 minute; leave it at 0 for the default")
+	}
+
+	if err := c.validateFirewallRateLimits(); err != nil {
+		return err
+	}
+
+	// Aliases may also be enabled at runtime through the admin API, so an empty list is allowed here.
+	for i := range c.Features.FirewallManagement.Aliases {
+		if err := c.ValidateFirewallAlias(&c.Features.FirewallManagement.Aliases[i]); err != nil {
+			return fmt.Errorf("features.firewall_management.aliases[%d].%w", i, err)
 		}
+	}
 
-		if alias.UUID == "" {
-			return fmt.Errorf("features.firewall_management.aliases[%d].uuid is required", i)
+	feedNames := make(map[string]bool)
+	for i := range c.Features.FirewallManagement.BlacklistFeeds {
+		feed := &c.Features.FirewallManagement.BlacklistFeeds[i]
+
+		if feed.Name == "" {
+			return fmt.Errorf("features.firewall_management.blacklist_feeds[%d].name is required", i)
+		}
+
+		if feedNames[feed.Name] {
+			return fmt.Errorf("features.firewall_management.blacklist_feeds[%d].name '%s' is already used by another feed", i, feed.Name)
 		}
+		feedNames[feed.Name] = true
 
-		if len(alias.UUID) != 36 {
-			return fmt.Errorf("features.firewall_management.aliases[%d].uuid must be a valid UUID", i)
+		if (feed.URL == "") == (feed.File == "") {
+			return fmt.Errorf("features.firewall_management.blacklist_feeds[%d] must set exactly one of url or file", i)
+		}
+
+		if feed.URL != "" {
+			if err := validateURL(feed.URL, fmt.Sprintf("features.firewall_management.blacklist_feeds[%d].url", i)); err != nil {
+				return err
+			}
 		}
 
-		if alias.AuthGroup == "" {
-			return fmt.Errorf("features.firewall_management.aliases[%d].auth_group is required", i)
+		// Aliases enabled through the admin API are only known at runtime, so only the format is checked here.
+		if feed.AliasUUID != "" && !isUUID(feed.AliasUUID) {
+			return fmt.Errorf("features.firewall_management.blacklist_feeds[%d].alias_uuid must be a valid UUID", i)
 		}
 
-		if _, exists := c.Authorization.GroupScopes[alias.AuthGroup]; !exists {
-			return fmt.Errorf("features.firewall_management.aliases[%d].auth_group '%s' does not exist in authorization.group_scopes", i, alias.AuthGroup)
+		if feed.Interval == 0 {
+			feed.Interval = DefaultFirewallBlacklistFeedInterval
+		}
+
+		if feed.Interval < 1*time.Hour {
+			return fmt.Errorf("features.firewall_management.blacklist_feeds[%d].interval cannot be less than 1 hour", i)
+		}
+	}
+
+	// Feeds such as ASN-DROP list AS numbers, which only match addresses the ASN database resolves. A feed
+	// of plain ranges works without it, so this is not an error.
+	if geoIP := c.Features.FirewallManagement.GeoIP; len(c.Features.FirewallManagement.BlacklistFeeds) > 0 && (geoIP == nil || geoIP.ASNDatabase == "") {
+		slog.Warn("AS numbers in features.firewall_management.blacklist_feeds never match without features.firewall_management.geoip.asn_database")
+	}
+
+	return nil
+}
+
+func (c *Config) validateFirewallRateLimits() error {
+	if c.Features.FirewallManagement.RateLimits == nil {
+		defaults := *DefaultFirewallRateLimitConfig
+		c.Features.FirewallManagement.RateLimits = &defaults
+	}
+
+	// A zero budget is kept rather than defaulted, so an action can be left unlimited.
+	limits := c.Features.FirewallManagement.RateLimits
+
+	if limits.CreatesPerWindow < 0 || limits.DeletesPerWindow < 0 {
+		return fmt.Errorf("features.firewall_management.rate_limits.creates_per_window and deletes_per_window cannot be negative")
+	}
+
+	if limits.Window == 0 {
+		limits.Window = DefaultFirewallRateLimitConfig.Window
+	}
+
+	if limits.Window < 1*time.Minute || limits.Window > 24*time.Hour {
+		return fmt.Errorf("features.firewall_management.rate_limits.window must be between 1 minute and 24 hours")
+	}
+
+	detection := limits.AbuseDetection
+	if detection == nil {
+		return nil
+	}
+
+	if detection.MaxDistinctASNs < 0 || detection.MaxDistinctCountries < 0 {
+		return fmt.Errorf("features.firewall_management.rate_limits.abuse_detection thresholds cannot be negative")
+	}
+
+	if detection.MaxDistinctASNs == 0 && detection.MaxDistinctCountries == 0 {
+		return fmt.Errorf("features.firewall_management.rate_limits.abuse_detection must set max_distinct_asns or max_distinct_countries")
+	}
+
+	if detection.Window == 0 {
+		detection.Window = DefaultFirewallAbuseDetectionWindow
+	}
+
+	if detection.Window < 1*time.Hour {
+		return fmt.Errorf("features.firewall_management.rate_limits.abuse_detection.window cannot be less than 1 hour")
+	}
+
+	geoIP := c.Features.FirewallManagement.GeoIP
+	if detection.MaxDistinctASNs > 0 && (geoIP == nil || geoIP.ASNDatabase == "") {
+		return fmt.Errorf("features.firewall_management.rate_limits.abuse_detection.max_distinct_asns requires features.firewall_management.geoip.asn_database")
+	}
+
+	if detection.MaxDistinctCountries > 0 && (geoIP == nil || geoIP.CountryDatabase == "") {
+		return fmt.Errorf("features.firewall_management.rate_limits.abuse_detection.max_distinct_countries requires features.firewall_management.geoip.country_database")
+	}
+
+	return nil
+}
+
+// ValidateFirewallAlias checks an alias's settings, whether it comes from the config file or the admin API.
+// Country codes are normalized in place. Errors name the offending field so callers can prefix its location.
+func (c *Config) ValidateFirewallAlias(alias *FirewallAliasConfig) error {
+	if alias.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if alias.Traefik != nil {
+		if err := c.validateFirewallTraefikMiddleware(alias); err != nil {
+			return err
 		}
+	}
+
+	if alias.UUID == "" {
+		return fmt.Errorf("uuid is required")
+	}
+
+	if !isUUID(alias.UUID) {
+		return fmt.Errorf("uuid must be a valid UUID")
+	}
+
+	if alias.AuthGroup == "" {
+		return fmt.Errorf("auth_group is required")
+	}
+
+	if _, exists := c.Authorization.GroupScopes[alias.AuthGroup]; !exists {
+		return fmt.Errorf("auth_group '%s' does not exist in authorization.group_scopes", alias.AuthGroup)
+	}
+
+	if alias.MaxIPsPerUser <= 0 {
+		return fmt.Errorf("max_ips_per_user must be greater than 0")
+	}
+
+	if alias.MaxTotalIPs <= 0 {
+		return fmt.Errorf("max_total_ips must be greater than 0")
+	}
 
-		if alias.MaxIPsPerUser <= 0 {
-			return fmt.Errorf("features.firewall_management.aliases[%d].max_ips_per_user must be greater than 0", i)
+	if alias.MaxIPsPerUser > alias.MaxTotalIPs {
+		return fmt.Errorf("max_ips_per_user (%d) cannot be greater than max_total_ips (%d)", alias.MaxIPsPerUser, alias.MaxTotalIPs)
+	}
+
+	if alias.DefaultTTL != nil && *alias.DefaultTTL < 1*time.Hour {
+		return fmt.Errorf("default_ttl cannot be less than 1 hour if set")
+	}
+
+	if alias.MaxExtensions < 0 {
+		return fmt.Errorf("max_extensions cannot be negative")
+	}
+
+	if alias.MaxLifetime != nil {
+		if *alias.MaxLifetime < 1*time.Hour {
+			return fmt.Errorf("max_lifetime cannot be less than 1 hour if set")
+		}
+		if alias.DefaultTTL != nil && *alias.MaxLifetime < *alias.DefaultTTL {
+			return fmt.Errorf("max_lifetime (%s) cannot be less than default_ttl (%s)", *alias.MaxLifetime, *alias.DefaultTTL)
 		}
+	}
+
+	if alias.MaxExtensions > 0 && alias.DefaultTTL == nil {
+		return fmt.Errorf("max_extensions requires default_ttl, entries without an expiry cannot be extended")
+	}
+
+	return c.validateFirewallAliasGeoPolicy(alias)
+}
+
+// validateFirewallTraefikMiddleware checks a Traefik-backed alias and derives its uuid from the middleware
+// when none is set, so the same middleware always maps to the same whitelist entries.
+func (c *Config) validateFirewallTraefikMiddleware(alias *FirewallAliasConfig) error {
+	if c.Features.FirewallManagement.Kubernetes == nil {
+		return fmt.Errorf("traefik requires features.firewall_management.kubernetes")
+	}
+
+	if !isDNSLabel(alias.Traefik.Namespace) {
+		return fmt.Errorf("traefik.namespace must be a valid Kubernetes namespace")
+	}
+
+	if !isDNSSubdomain(alias.Traefik.Name) {
+		return fmt.Errorf("traefik.name must be a valid Kubernetes resource name")
+	}
+
+	if alias.UUID == "" {
+		alias.UUID = uuid.NewSHA1(uuid.NameSpaceURL, []byte("traefik://"+alias.Traefik.Namespace+"/"+alias.Traefik.Name)).String()
+	}
 
-		if alias.MaxTotalIPs <= 0 {
-			return fmt.Errorf("features.firewall_management.aliases[%d].max_total_ips must be greater than 0", i)
+	return nil
+}
+
+// validateFirewallAliasGeoPolicy normalizes an alias's country codes and checks the lookups its policies rely on are configured.
+func (c *Config) validateFirewallAliasGeoPolicy(alias *FirewallAliasConfig) error {
+	geoIP := c.Features.FirewallManagement.GeoIP
+
+	for _, list := range []struct {
+		name  string
+		codes []string
+	}{
+		{"allowed_countries", alias.AllowedCountries},
+		{"denied_countries", alias.DeniedCountries},
+	} {
+		for j, code := range list.codes {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if len(code) != 2 {
+				return fmt.Errorf("%s[%d] must be an ISO 3166-1 alpha-2 country code (got '%s')", list.name, j, list.codes[j])
+			}
+			list.codes[j] = code
 		}
 
-		if alias.MaxIPsPerUser > alias.MaxTotalIPs {
-			return fmt.Errorf("features.firewall_management.aliases[%d].max_ips_per_user (%d) cannot be greater than max_total_ips (%d)",
-				i, alias.MaxIPsPerUser, alias.MaxTotalIPs)
+		if len(list.codes) > 0 && (geoIP == nil || geoIP.CountryDatabase == "") {
+			return fmt.Errorf("%s requires features.firewall_management.geoip.country_database", list.name)
 		}
+	}
 
-		if alias.DefaultTTL != nil && *alias.DefaultTTL < 1*time.Hour {
-			return fmt.Errorf("features.firewall_management.aliases[%d].default_ttl cannot be less than 1 hour if set", i)
+	for _, list := range []struct {
+		name string
+		asns []int64
+	}{
+		{"allowed_asns", alias.AllowedASNs},
+		{"denied_asns", alias.DeniedASNs},
+	} {
+		for j, asn := range list.asns {
+			if asn <= 0 || asn > math.MaxUint32 {
+				return fmt.Errorf("%s[%d] is not a valid AS number", list.name, j)
+			}
 		}
 
-		key := fmt.Sprintf("%s:%s", alias.Name, alias.UUID)
-		if aliasNames[key] {
-			// This is actually intentional - same UUID with different groups/limits
-			// So we just note it but don't error
+		if len(list.asns) > 0 && (geoIP == nil || geoIP.ASNDatabase == "") {
+			return fmt.Errorf("%s requires features.firewall_management.geoip.asn_database", list.name)
 		}
-		aliasNames[key] = true
 	}
 
 	return nil
@@ -98,10 +98,233 @@ var DefaultSessionConfig = SessionConfig{
 }
 
 type DataConfig struct {
-	PrometheusURL         string            `yaml:"prometheus_url"`
-	BasicAuth             *BasicAuth        `yaml:"basic_auth"`
-	Queries               []PrometheusQuery `yaml:"queries"`
-	FallbackFetchInterval time.Duration     `yaml:"fallback_fetch_interval"`
+	PrometheusURL         string                  `yaml:"prometheus_url"` // shorthand for a backend named "default"
+	BasicAuth             *BasicAuth              `yaml:"basic_auth"`
+	Backends              []DataPrometheusBackend `yaml:"backends,omitempty"`   // prometheus-compatible APIs queries choose with backend
+	Loki                  *DataLokiConfig         `yaml:"loki,omitempty"`       // required by queries with source: loki
+	Kubernetes            *KubernetesConfig       `yaml:"kubernetes,omitempty"` // required by queries with source: kubernetes
+	Queries               []PrometheusQuery       `yaml:"queries"`
+	Variables             []DataVariable          `yaml:"variables,omitempty"` // template variables referenced by queries as $name
+	OnDemand              *DataOnDemandConfig     `yaml:"on_demand,omitempty"`
+	RangeQueries          *DataRangeQueries       `yaml:"range_queries,omitempty"`
+	FallbackFetchInterval time.Duration           `yaml:"fallback_fetch_interval"` // refresh interval of queries without a ttl
+	Scheduler             *DataSchedulerConfig    `yaml:"scheduler,omitempty"`
+	CircuitBreaker        *DataCircuitBreaker     `yaml:"circuit_breaker,omitempty"`
+	History               *DataHistory            `yaml:"history,omitempty"`
+	Alerts                *DataAlerts             `yaml:"alerts,omitempty"`
+	Probes                []DataProbe             `yaml:"probes,omitempty"` // synthetic uptime checks served like queries
+}
+
+// DefaultDataBackendName is the name of the backend configured through prometheus_url and basic_auth.
+const DefaultDataBackendName = "default"
+
+// DefaultDataBackendTimeout bounds a backend's requests when it does not set a timeout.
+const DefaultDataBackendTimeout = 30 * time.Second
+
+// DataPrometheusBackend is a named Prometheus-compatible API, e.g. a Prometheus server or one tenant of a Mimir
+// cluster. Prometheus queries and label_values variables pick theirs by name and default to the first one.
+type DataPrometheusBackend struct {
+	Name            string            `yaml:"name"`
+	URL             string            `yaml:"url"`
+	TenantID        string            `yaml:"tenant_id,omitempty"` // sent as X-Scope-OrgID for multi-tenant Mimir
+	Headers         map[string]string `yaml:"headers,omitempty"`   // extra request headers
+	BasicAuth       *BasicAuth        `yaml:"basic_auth,omitempty"`
+	BearerTokenFile string            `yaml:"bearer_token_file,omitempty"` // re-read on every request, so rotated tokens are picked up
+	TLS             *DataBackendTLS   `yaml:"tls,omitempty"`
+	Timeout         time.Duration     `yaml:"timeout,omitempty"`
+}
+
+// DataBackendTLS verifies a backend against a private CA and authenticates with a client certificate (mTLS).
+type DataBackendTLS struct {
+	CAFile   string `yaml:"ca_file,omitempty"`
+	CertFile string `yaml:"cert_file,omitempty"`
+	KeyFile  string `yaml:"key_file,omitempty"`
+}
+
+// DataVariable is a template variable that prometheus and loki queries reference as $name or ${name}. Its
+// options are either a static list of values or the values of a Prometheus label.
+type DataVariable struct {
+	Name        string                   `yaml:"name"`
+	Values      []string                 `yaml:"values,omitempty"`
+	LabelValues *DataVariableLabelValues `yaml:"label_values,omitempty"`
+	Default     string                   `yaml:"default,omitempty"`    // selected when a request does not choose a value; defaults to the first value
+	Precompute  []string                 `yaml:"precompute,omitempty"` // values kept cached by the scheduler, always including the default
+}
+
+// DataVariableLabelValues looks up a variable's options through /api/v1/label/<label>/values.
+type DataVariableLabelValues struct {
+	Label           string        `yaml:"label"`
+	Match           []string      `yaml:"match,omitempty"`   // series selectors limiting which series the values are taken from
+	RefreshInterval time.Duration `yaml:"refresh_interval"`  // how long looked up values are cached
+	Backend         string        `yaml:"backend,omitempty"` // defaults to the first backend
+}
+
+// DataOnDemandConfig limits selections of template variables that are not precomputed. Those are queried
+// when requested rather than cached, so each caller may only run so many of them per window.
+type DataOnDemandConfig struct {
+	RequestsPerWindow int           `yaml:"requests_per_window"`
+	Window            time.Duration `yaml:"window"`
+}
+
+var DefaultDataOnDemandConfig = &DataOnDemandConfig{
+	RequestsPerWindow: 30,
+	Window:            time.Minute,
+}
+
+// DataRangeQueries limits ad-hoc range queries, which run a configured prometheus query over a window chosen
+// in the UI. MaxRange and MaxPoints apply to queries that do not set their own.
+type DataRangeQueries struct {
+	MaxRange          time.Duration `yaml:"max_range"`           // longest window that may be requested
+	MaxPoints         int           `yaml:"max_points"`          // most points per series, which bounds how fine the step can be
+	CacheTTL          time.Duration `yaml:"cache_ttl"`           // how long the result for a window is reused
+	RequestsPerWindow int           `yaml:"requests_per_window"` // uncached range queries each principal may run per window
+	Window            time.Duration `yaml:"window"`
+}
+
+var DefaultDataRangeQueries = &DataRangeQueries{
+	MaxRange:          7 * 24 * time.Hour,
+	MaxPoints:         1000,
+	CacheTTL:          5 * time.Minute,
+	RequestsPerWindow: 20,
+	Window:            time.Minute,
+}
+
+// DataCircuitBreaker stops querying Prometheus during an outage. After FailureThreshold consecutive failures
+// queries fail fast for InitialBackoff, then a single trial query decides whether to resume or to back off
+// twice as long, up to MaxBackoff.
+type DataCircuitBreaker struct {
+	FailureThreshold int           `yaml:"failure_threshold"`
+	InitialBackoff   time.Duration `yaml:"initial_backoff"`
+	MaxBackoff       time.Duration `yaml:"max_backoff"`
+}
+
+var DefaultDataCircuitBreaker = &DataCircuitBreaker{
+	FailureThreshold: 3,
+	InitialBackoff:   30 * time.Second,
+	MaxBackoff:       10 * time.Minute,
+}
+
+// DataHistory records the results of instant queries that opt in with history.enabled into rollups in storage,
+// so long-term panels outlive the TSDB's retention. Retention applies to queries that do not set their own.
+type DataHistory struct {
+	Enabled       bool                  `yaml:"enabled"`
+	Retention     *DataHistoryRetention `yaml:"retention,omitempty"`
+	PruneInterval time.Duration         `yaml:"prune_interval"` // how often rollups past their retention are deleted
+}
+
+// DataHistoryRetention is how long each rollup of a query's history is kept.
+type DataHistoryRetention struct {
+	FiveMinutes time.Duration `yaml:"5m"`
+	Hourly      time.Duration `yaml:"1h"`
+	Daily       time.Duration `yaml:"1d"`
+}
+
+var DefaultDataHistory = &DataHistory{
+	Retention: &DataHistoryRetention{
+		FiveMinutes: 7 * 24 * time.Hour,
+		Hourly:      90 * 24 * time.Hour,
+		Daily:       5 * 365 * 24 * time.Hour,
+	},
+	PruneInterval: time.Hour,
+}
+
+// DataQueryHistory opts a query into history. Retention values left out fall back to data.history.retention.
+type DataQueryHistory struct {
+	Enabled   bool                  `yaml:"enabled"`
+	Retention *DataHistoryRetention `yaml:"retention,omitempty"`
+}
+
+// DataAlerts evaluates threshold rules against the cached results of dashboard queries and notifies receivers
+// when an alert starts firing or resolves. Alert state is kept in storage.
+type DataAlerts struct {
+	EvaluationInterval time.Duration       `yaml:"evaluation_interval"` // how often rules are evaluated besides on fresh results
+	Rules              []DataAlertRule     `yaml:"rules"`
+	Receivers          []DataAlertReceiver `yaml:"receivers,omitempty"`
+	SMTP               *DataAlertSMTP      `yaml:"smtp,omitempty"` // required by email receivers
+}
+
+var DefaultDataAlerts = &DataAlerts{
+	EvaluationInterval: 30 * time.Second,
+}
+
+// DataAlertRule fires when every value of a series of Query has met Condition for at least For. Each series of
+// a vector result, and each variable selection of a templated query, is alerted on separately.
+type DataAlertRule struct {
+	Name      string        `yaml:"name"`
+	Query     string        `yaml:"query"`     // name of the query whose cached result is evaluated
+	Condition string        `yaml:"condition"` // comparison with a threshold, e.g. "< 1" or ">= 90"
+	For       time.Duration `yaml:"for"`       // how long the condition must hold before the alert fires
+	Severity  string        `yaml:"severity"`  // info, warning or critical, defaults to warning
+	Summary   string        `yaml:"summary,omitempty"`
+	Receivers []string      `yaml:"receivers,omitempty"` // names of the receivers notified, all of them when empty
+}
+
+// Severities of alert rules.
+const (
+	DataAlertSeverityInfo     = "info"
+	DataAlertSeverityWarning  = "warning"
+	DataAlertSeverityCritical = "critical"
+)
+
+// DataAlertReceiver is where notifications of a rule are sent: a webhook that receives them as JSON, or a
+// list of email addresses.
+type DataAlertReceiver struct {
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"`              // webhook or email
+	URL     string            `yaml:"url,omitempty"`     // webhook: endpoint notifications are posted to
+	Headers map[string]string `yaml:"headers,omitempty"` // webhook: extra request headers, e.g. an API token
+	To      []string          `yaml:"to,omitempty"`      // email: recipients
+}
+
+// Types of alert receivers.
+const (
+	DataAlertReceiverWebhook = "webhook"
+	DataAlertReceiverEmail   = "email"
+)
+
+// DataAlertSMTP is the mail server email receivers send through.
+type DataAlertSMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// DataSchedulerConfig controls how queries are refreshed. Each query is re-run once its ttl has passed.
+type DataSchedulerConfig struct {
+	Workers   int           `yaml:"workers"`    // queries fetched at the same time
+	MaxJitter time.Duration `yaml:"max_jitter"` // upper bound on the random delay added to each run, 0 disables jitter
+}
+
+var DefaultDataSchedulerConfig = &DataSchedulerConfig{
+	Workers:   4,
+	MaxJitter: 5 * time.Second,
+}
+
+// Data sources a query can be answered by.
+const (
+	DataSourcePrometheus = "prometheus"
+	DataSourceLoki       = "loki"
+	DataSourceHTTPJSON   = "http_json"
+	DataSourceKubernetes = "kubernetes"
+)
+
+// DataSourceProbe answers the queries probes are run as. Configured queries cannot use it.
+const DataSourceProbe = "probe"
+
+// Kubernetes resources a query with source: kubernetes can count.
+const (
+	DataKubernetesNodes                  = "nodes"
+	DataKubernetesPods                   = "pods"
+	DataKubernetesPersistentVolumeClaims = "persistentvolumeclaims"
+)
+
+// DataLokiConfig points at the Loki instance that answers LogQL queries.
+type DataLokiConfig struct {
+	URL       string     `yaml:"url"`
+	BasicAuth *BasicAuth `yaml:"basic_auth"`
+	TenantID  string     `yaml:"tenant_id"` // sent as X-Scope-OrgID for multi-tenant Loki
 }
 
 var defaultDataConfig = DataConfig{
@@ -113,9 +336,13 @@ type BasicAuth struct {
 	Password string `yaml:"password"`
 }
 
+// PrometheusQuery is a single dashboard query. What Query holds depends on Source: PromQL for prometheus,
+// LogQL for loki, a JSONPath expression for http_json and the resource to count for kubernetes.
 type PrometheusQuery struct {
 	Name          string        `yaml:"name"`
 	Disabled      bool          `yaml:"disabled"`
+	Source        string        `yaml:"source"`            // defaults to prometheus
+	Backend       string        `yaml:"backend,omitempty"` // prometheus: the backend to query, defaults to the first one
 	Query         string        `yaml:"query"`
 	Type          string        `yaml:"type"`
 	TTL           time.Duration `yaml:"ttl"`
@@ -123,6 +350,44 @@ type PrometheusQuery struct {
 	Step          string        `yaml:"step"`
 	RequireAuth   bool          `yaml:"require_auth"`
 	RequiredGroup string        `yaml:"required_group"`
+
+	URL       string            `yaml:"url,omitempty"`       // http_json: endpoint to fetch
+	Headers   map[string]string `yaml:"headers,omitempty"`   // http_json: extra request headers, e.g. an API token
+	Namespace string            `yaml:"namespace,omitempty"` // kubernetes: limits pod and PVC counts to one namespace
+
+	MaxRange  time.Duration `yaml:"max_range,omitempty"`  // prometheus: longest window of an ad-hoc range query, see DataRangeQueries
+	MaxPoints int           `yaml:"max_points,omitempty"` // prometheus: most points per series of an ad-hoc range query
+
+	History *DataQueryHistory `yaml:"history,omitempty"` // instant queries: record results into long-term history, see DataHistory
+
+	Transform []DataTransform `yaml:"transform,omitempty"` // steps shaping the result for display before it is cached
+
+	Variables map[string]string `yaml:"-"` // selected template variable values, set on queries expanded from a template
+}
+
+// DataTransform is one step of a query's transform pipeline. Steps run in order and each sets exactly one field.
+type DataTransform struct {
+	Reduce       string            `yaml:"reduce,omitempty"`        // last, avg, min or max: reduce each series of a matrix to one sample
+	RenameLabels map[string]string `yaml:"rename_labels,omitempty"` // label names to the names they are renamed to
+	DropLabels   []string          `yaml:"drop_labels,omitempty"`
+	Unit         string            `yaml:"unit,omitempty"`       // conversion of every value, see DataTransformUnits
+	TopN         int               `yaml:"top_n,omitempty"`      // keep the series with the highest (last) values
+	Downsample   int               `yaml:"downsample,omitempty"` // most points kept per series of a matrix
+}
+
+// Reductions a transform can reduce a series with.
+const (
+	DataTransformReduceLast = "last"
+	DataTransformReduceAvg  = "avg"
+	DataTransformReduceMin  = "min"
+	DataTransformReduceMax  = "max"
+)
+
+// DataTransformUnits lists the unit conversions a transform can apply. seconds_to_duration keeps the value and
+// adds the human-readable duration, e.g. "3d 4h", as a display label; a scalar becomes that string.
+var DataTransformUnits = []string{
+	"bytes_to_kib", "bytes_to_mib", "bytes_to_gib", "bytes_to_tib",
+	"seconds_to_minutes", "seconds_to_hours", "seconds_to_days", "seconds_to_duration",
 }
 
 type CacheConfig struct {
@@ -179,6 +444,7 @@ var DefaultStorageConfig = StorageConfig{
 type FeaturesConfig struct {
 	MTLSManagement     MTLSManagement     `yaml:"mtls_management,omitempty"`
 	FirewallManagement FirewallManagement `yaml:"firewall_management,omitempty"`
+	StatusPage         StatusPage         `yaml:"status_page,omitempty"`
 }
 
 var DefaultFeaturesConfig = FeaturesConfig{
@@ -198,6 +464,8 @@ type MTLSManagement struct {
 	Database                        *DatabaseConfig          `yaml:"database,omitempty"`
 }
 
+// KubernetesConfig locates a cluster through in_cluster or kubeconfig. Enabled, Namespace and Issuer only
+// apply to mtls_management; elsewhere the block being set is what turns the integration on.
 type KubernetesConfig struct {
 	Enabled    bool               `yaml:"enabled"`
 	Namespace  string             `yaml:"namespace"`
@@ -269,6 +537,10 @@ type FirewallManagement struct {
 	RouterAPIKey        string                       `yaml:"router_api_key"`
 	RouterAPISecret     string                       `yaml:"router_api_secret"`
 	Aliases             []FirewallAliasConfig        `yaml:"aliases"`
+	BlacklistFeeds      []FirewallBlacklistFeed      `yaml:"blacklist_feeds,omitempty"`
+	GeoIP               *FirewallGeoIPConfig         `yaml:"geoip,omitempty"`
+	Kubernetes          *KubernetesConfig            `yaml:"kubernetes,omitempty"` // required by aliases backed by a Traefik middleware
+	RateLimits          *FirewallRateLimitConfig     `yaml:"rate_limits,omitempty"`
 	BackgroundJobConfig *FirewallBackgroundJobConfig `yaml:"background_job_config,omitempty"`
 }
 
@@ -280,21 +552,95 @@ type FirewallAliasConfig struct {
 	MaxTotalIPs   int            `yaml:"max_total_ips"`
 	DefaultTTL    *time.Duration `yaml:"default_ttl"` // nil = no expiration
 	AuthGroup     string         `yaml:"auth_group"`  // References authorization.group_scopes key
+	DryRun        bool           `yaml:"dry_run"`     // Compute and record sync plans without changing the router
+
+	// Traefik applies the alias to a Middleware's ipAllowList instead of an OPNsense alias.
+	// The uuid then only identifies the alias and is derived from the middleware when omitted.
+	Traefik *FirewallTraefikMiddleware `yaml:"traefik,omitempty"`
+
+	// Self-service extensions: each one renews the entry for DefaultTTL, up to MaxLifetime after the request.
+	MaxLifetime   *time.Duration `yaml:"max_lifetime"`   // nil = no lifetime cap
+	MaxExtensions int            `yaml:"max_extensions"` // 0 = entries cannot be extended
+
+	// GeoIP policies, evaluated against features.firewall_management.geoip lookups. Empty lists allow everything.
+	AllowedCountries []string `yaml:"allowed_countries"` // ISO 3166-1 alpha-2 codes
+	DeniedCountries  []string `yaml:"denied_countries"`
+	AllowedASNs      []int64  `yaml:"allowed_asns"`
+	DeniedASNs       []int64  `yaml:"denied_asns"`
+}
+
+// FirewallTraefikMiddleware names a Traefik Middleware whose spec.ipAllowList.sourceRange is managed by the alias.
+type FirewallTraefikMiddleware struct {
+	Namespace string `yaml:"namespace"`
+	Name      string `yaml:"name"`
+}
+
+// FirewallGeoIPConfig points at MaxMind-format databases, e.g. GeoLite2-Country.mmdb and GeoLite2-ASN.mmdb.
+type FirewallGeoIPConfig struct {
+	CountryDatabase string `yaml:"country_database"`
+	ASNDatabase     string `yaml:"asn_database"`
+}
+
+// FirewallBlacklistFeed is a plain-text threat feed (e.g. Spamhaus DROP) imported into the blacklist on a schedule.
+type FirewallBlacklistFeed struct {
+	Name      string        `yaml:"name"`
+	URL       string        `yaml:"url"`
+	File      string        `yaml:"file"`
+	AliasUUID string        `yaml:"alias_uuid"` // empty = applies to every alias
+	Interval  time.Duration `yaml:"interval"`
+}
+
+var DefaultFirewallBlacklistFeedInterval = 24 * time.Hour
+
+// FirewallRateLimitConfig bounds how often a user may create and delete entries on a single alias,
+// counted over a sliding window in the cache so every replica shares the same budget. Without a rate_limits
+// block the defaults apply; within one, a zero budget disables that limit.
+type FirewallRateLimitConfig struct {
+	CreatesPerWindow int           `yaml:"creates_per_window"`
+	DeletesPerWindow int           `yaml:"deletes_per_window"`
+	Window           time.Duration `yaml:"window"`
+
+	// AbuseDetection flags users whose entries rotate through many networks; nil disables it.
+	AbuseDetection *FirewallAbuseDetectionConfig `yaml:"abuse_detection,omitempty"`
+}
+
+// FirewallAbuseDetectionConfig raises an admin event when a user's entries created within Window span more
+// than MaxDistinctASNs ASNs or MaxDistinctCountries countries. A zero threshold disables that check.
+type FirewallAbuseDetectionConfig struct {
+	MaxDistinctASNs      int           `yaml:"max_distinct_asns"`
+	MaxDistinctCountries int           `yaml:"max_distinct_countries"`
+	Window               time.Duration `yaml:"window"`
+}
+
+var DefaultFirewallRateLimitConfig = &FirewallRateLimitConfig{
+	CreatesPerWindow: 10,
+	DeletesPerWindow: 10,
+	Window:           1 * time.Hour,
 }
 
+var DefaultFirewallAbuseDetectionWindow = 24 * time.Hour
+
 type FirewallBackgroundJobConfig struct {
 	SyncInterval       time.Duration `yaml:"sync_interval"`
 	ExpirationInterval time.Duration `yaml:"expiration_interval"`
+	ScheduleInterval   time.Duration `yaml:"schedule_interval"`
+
+	// SyncDebounce is how long the leader collects on-demand sync requests before syncing the affected aliases.
+	// Zero, like the other intervals, means the default; it cannot be turned off.
+	SyncDebounce time.Duration `yaml:"sync_debounce"`
 }
 
 var DefaultFirewallBackgroundJobConfig = &FirewallBackgroundJobConfig{
 	SyncInterval:       5 * time.Minute,
 	ExpirationInterval: 1 * time.Hour,
+	ScheduleInterval:   1 * time.Minute,
+	SyncDebounce:       2 * time.Second,
 }
 
 var DefaultFirewallManagement = FirewallManagement{
 	Enabled:             false,
 	Aliases:             []FirewallAliasConfig{},
+	RateLimits:          DefaultFirewallRateLimitConfig,
 	BackgroundJobConfig: DefaultFirewallBackgroundJobConfig,
 }
 
@@ -328,6 +674,82 @@ var DefaultAuthorizationConfig = AuthorizationConfig{
 			authorization.ScopeFirewallReadAll,
 			authorization.ScopeFirewallRevokeAll,
 			authorization.ScopeFirewallBlacklist,
+			authorization.ScopeFirewallAliases,
+		},
+		"conduit:status:admin": {
+			authorization.ScopeStatusWrite,
 		},
 	},
 }
+
+// DataProbe is a synthetic uptime check of a service. Its result is cached under the probe's name and served by
+// /api/data like a query's: a vector of probe_success, probe_duration_seconds and, when the target speaks TLS,
+// probe_cert_days_left.
+type DataProbe struct {
+	Name     string        `yaml:"name"`
+	Disabled bool          `yaml:"disabled"`
+	Type     string        `yaml:"type"`     // http, tcp, dns or icmp
+	Target   string        `yaml:"target"`   // http: URL, tcp: host:port, dns: name to resolve, icmp: host
+	Interval time.Duration `yaml:"interval"` // defaults to 1m
+	Timeout  time.Duration `yaml:"timeout"`  // defaults to 10s, and to the interval when that is shorter
+
+	ExpectedStatus []int  `yaml:"expected_status,omitempty"`  // http: defaults to any 2xx
+	BodyRegex      string `yaml:"body_regex,omitempty"`       // http: the body must match
+	TLS            bool   `yaml:"tls,omitempty"`              // tcp: complete a TLS handshake, reporting certificate expiry
+	CertExpiryDays int    `yaml:"cert_expiry_days,omitempty"` // http and tcp: fail when the certificate expires sooner
+	CAFile         string `yaml:"ca_file,omitempty"`          // http and tcp: PEM bundle trusted on top of the system roots
+	RecordType     string `yaml:"record_type,omitempty"`      // dns: A, AAAA, CNAME, MX, NS or TXT, defaults to A
+	Resolver       string `yaml:"resolver,omitempty"`         // dns: host:port of the server to ask, defaults to the system's
+
+	History       *DataQueryHistory `yaml:"history,omitempty"` // record results into long-term history, see DataHistory
+	RequireAuth   bool              `yaml:"require_auth"`
+	RequiredGroup string            `yaml:"required_group"`
+}
+
+// Checks a probe can run.
+const (
+	DataProbeHTTP = "http"
+	DataProbeTCP  = "tcp"
+	DataProbeDNS  = "dns"
+	DataProbeICMP = "icmp"
+)
+
+// DataProbeRecordTypes are the DNS record types a dns probe can resolve.
+var DataProbeRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "TXT"}
+
+var DefaultDataProbe = DataProbe{
+	Interval:   time.Minute,
+	Timeout:    10 * time.Second,
+	RecordType: "A",
+}
+
+// StatusPage publishes the state of the homelab's services at /api/status, along with the incidents and
+// maintenance windows posted by principals with the status:write scope.
+type StatusPage struct {
+	Enabled     bool                `yaml:"enabled"`
+	Title       string              `yaml:"title"`
+	Services    []StatusPageService `yaml:"services"`
+	HistoryDays int                 `yaml:"history_days"` // how long resolved incidents stay listed and in the feed
+}
+
+var DefaultStatusPage = StatusPage{
+	Title:       "Status",
+	HistoryDays: 14,
+}
+
+// StatusPageService groups the components of one service, e.g. the media server and its web interface.
+type StatusPageService struct {
+	Name       string                `yaml:"name"`
+	Components []StatusPageComponent `yaml:"components"`
+}
+
+// StatusPageComponent is a part of a service whose status is shown. It is observed through a probe, or through
+// a query whose series fail the condition, and is otherwise only affected by incidents and maintenance. As the
+// page is public, probes and queries with require_auth or required_group cannot be used.
+type StatusPageComponent struct {
+	Name        string `yaml:"name"` // unique across services, incidents refer to components by name
+	Description string `yaml:"description,omitempty"`
+	Probe       string `yaml:"probe,omitempty"`     // a data probe: down while probe_success is 0
+	Query       string `yaml:"query,omitempty"`     // a dashboard query, checked for every precomputed selection
+	Condition   string `yaml:"condition,omitempty"` // query: series meeting it are failing, e.g. "< 1"
+}
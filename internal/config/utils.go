@@ -3,8 +3,24 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+// variableNamePattern matches the names template variables can be referenced by in a query.
+var variableNamePattern = regexp.MustCompile(`^\w+$`)
+
+// labelNamePattern matches valid Prometheus label names.
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// backendNamePattern matches the names of prometheus backends, which also name their circuit breakers.
+var backendNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// alertConditionPattern matches the conditions of alert rules: a comparison operator followed by a number.
+var alertConditionPattern = regexp.MustCompile(`^\s*(<=|>=|==|!=|<|>)\s*[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?\s*$`)
+
 func validateURL(urlStr, fieldName string) error {
 	if urlStr == "" {
 		return fmt.Errorf("OIDC %s is required", fieldName)
@@ -21,3 +37,45 @@ func validateURL(urlStr, fieldName string) error {
 
 	return nil
 }
+
+// isUUID reports whether s is a UUID in the canonical 8-4-4-4-12 hex form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isDNSLabel reports whether s is a valid RFC 1123 label, the format of Kubernetes namespaces.
+func isDNSLabel(s string) bool {
+	return len(validation.IsDNS1123Label(s)) == 0
+}
+
+// isDNSSubdomain reports whether s is a valid RFC 1123 subdomain, the format of most Kubernetes resource names.
+func isDNSSubdomain(s string) bool {
+	return len(validation.IsDNS1123Subdomain(s)) == 0
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL.
+func isHTTPURL(s string) bool {
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -18,6 +18,15 @@ type Provider interface {
 	GetKey(ctx context.Context, key string) (string, error)
 	SetKey(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	GetDelKey(ctx context.Context, key string) (string, error)
+	// SlidingWindowAllow records a hit on key unless limit hits were already recorded within the last window,
+	// in which case it reports how long until the oldest of them leaves the window.
+	SlidingWindowAllow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+	// SlidingWindowRelease gives back the most recent hit on key, for an allowed request that then failed.
+	SlidingWindowRelease(ctx context.Context, key string) error
+	// PublishUpdate notifies subscribers that a query's cached data changed, on every replica when backed by Redis.
+	PublishUpdate(ctx context.Context, queryName string) error
+	// SubscribeUpdates returns the names of queries whose cached data changes, until ctx is done.
+	SubscribeUpdates(ctx context.Context) <-chan string
 }
 
 // NewCacheProvider returns a new Provider
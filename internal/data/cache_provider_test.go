@@ -724,3 +724,136 @@ func BenchmarkCache_Size(b *testing.B) {
 		})
 	}
 }
+
+func TestMemCache_SlidingWindowAllow(t *testing.T) {
+	cache := setupMemCache()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		allowed, _, err := cache.SlidingWindowAllow(ctx, "ratelimit:test", 3, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := cache.SlidingWindowAllow(ctx, "ratelimit:test", 3, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	assert.LessOrEqual(t, retryAfter, time.Minute)
+
+	// Other keys have their own budget
+	allowed, _, err = cache.SlidingWindowAllow(ctx, "ratelimit:other", 3, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestMemCache_SlidingWindowExpires(t *testing.T) {
+	cache := setupMemCache()
+	ctx := context.Background()
+
+	allowed, _, err := cache.SlidingWindowAllow(ctx, "ratelimit:test", 1, 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, _ = cache.SlidingWindowAllow(ctx, "ratelimit:test", 1, 20*time.Millisecond)
+	assert.False(t, allowed)
+
+	time.Sleep(30 * time.Millisecond)
+
+	allowed, _, err = cache.SlidingWindowAllow(ctx, "ratelimit:test", 1, 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestMemCache_SlidingWindowEvictsIdleKeys(t *testing.T) {
+	cache := setupMemCache()
+	ctx := context.Background()
+
+	allowed, _, err := cache.SlidingWindowAllow(ctx, "ratelimit:test", 1, 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	assert.Eventually(t, func() bool {
+		cache.mutex.RLock()
+		defer cache.mutex.RUnlock()
+		return len(cache.windows) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestMemCache_SlidingWindowRelease(t *testing.T) {
+	cache := setupMemCache()
+	ctx := context.Background()
+
+	allowed, _, err := cache.SlidingWindowAllow(ctx, "ratelimit:test", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	assert.NoError(t, cache.SlidingWindowRelease(ctx, "ratelimit:test"))
+
+	allowed, _, err = cache.SlidingWindowAllow(ctx, "ratelimit:test", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, allowed, "a released hit no longer counts")
+}
+
+func TestMemCache_SubscribeUpdates(t *testing.T) {
+	cache, _ := NewMemCache(&config.Config{}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := cache.SubscribeUpdates(ctx)
+	second := cache.SubscribeUpdates(context.Background())
+
+	require.NoError(t, cache.PublishUpdate(context.Background(), "cpu_usage"))
+
+	for _, updates := range []<-chan string{first, second} {
+		select {
+		case name := <-updates:
+			assert.Equal(t, "cpu_usage", name)
+		case <-time.After(time.Second):
+			t.Fatal("expected an update notification")
+		}
+	}
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, open := <-first
+		return !open
+	}, time.Second, 10*time.Millisecond, "cancelled subscriptions should be closed")
+}
+
+func TestService_PublishesOnlyChangedData(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cache, _ := NewMemCache(&config.Config{}, logger)
+
+	source := &staticSource{value: &model.Scalar{Value: 1, Timestamp: 1000}}
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: source}, cache, logger,
+		[]config.PrometheusQuery{{Name: "cpu_usage", Query: "up"}})
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates := cache.SubscribeUpdates(subCtx)
+
+	received := func() int {
+		count := 0
+		for {
+			select {
+			case <-updates:
+				count++
+			case <-time.After(50 * time.Millisecond):
+				return count
+			}
+		}
+	}
+
+	query := service.Queries()[0]
+
+	require.NoError(t, service.ExecuteQuery(ctx, nil, query))
+	assert.Equal(t, 1, received(), "first result should be published")
+
+	require.NoError(t, service.ExecuteQuery(ctx, nil, query))
+	assert.Equal(t, 0, received(), "unchanged result should not be published")
+
+	source.value = &model.Scalar{Value: 2, Timestamp: 2000}
+	require.NoError(t, service.ExecuteQuery(ctx, nil, query))
+	assert.Equal(t, 1, received(), "changed result should be published")
+}
@@ -0,0 +1,259 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"homelab-dashboard/internal/config"
+	"log/slog"
+	"sync"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// PrometheusCircuitName is the name the Prometheus circuit breaker reports its state under.
+const PrometheusCircuitName = "prometheus"
+
+// BackendCircuitName returns the name a prometheus backend's circuit breaker reports its state under. The
+// default backend keeps the name the single Prometheus breaker has always had.
+func BackendCircuitName(backend string) string {
+	if backend == config.DefaultDataBackendName {
+		return PrometheusCircuitName
+	}
+	return PrometheusCircuitName + ":" + backend
+}
+
+// circuitStatusKeyPrefix namespaces the breaker states published to the cache for /api/v1/health.
+const circuitStatusKeyPrefix = "circuit:"
+
+// circuitStatusRetention is how long a published state is kept without being refreshed.
+const circuitStatusRetention = time.Hour
+
+// ErrCircuitOpen is returned instead of querying a backend that is backing off after repeated failures.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // requests flow normally
+	CircuitOpen     CircuitState = "open"      // requests fail fast until the backoff ends
+	CircuitHalfOpen CircuitState = "half_open" // a single trial request decides whether to close again
+)
+
+// CircuitStatus is a snapshot of a breaker, as reported by the health endpoint.
+type CircuitStatus struct {
+	Name                string       `json:"name"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	LastError           string       `json:"last_error,omitempty"`
+	LastFailure         *time.Time   `json:"last_failure,omitempty"`
+	OpenUntil           *time.Time   `json:"open_until,omitempty"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+}
+
+// CircuitBreaker backs off from a backend during outages, so a down Mimir is not hammered by every scheduled
+// query. Only outages count as failures: invalid or failing queries are the query's problem, not the backend's.
+type CircuitBreaker struct {
+	name             string
+	failureThreshold int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	cache            Provider
+	logger           *slog.Logger
+	now              func() time.Time
+
+	mu                  sync.Mutex
+	state               CircuitState
+	consecutiveFailures int
+	backoff             time.Duration
+	openUntil           time.Time
+	trialInFlight       bool
+	lastError           string
+	lastFailure         time.Time
+}
+
+// NewCircuitBreaker creates a closed breaker. When cache is set, every state change is published there so
+// all replicas can report it, not only the leader issuing the queries.
+func NewCircuitBreaker(name string, cfg *config.DataCircuitBreaker, cache Provider, logger *slog.Logger) *CircuitBreaker {
+	if cfg == nil {
+		cfg = config.DefaultDataCircuitBreaker
+	}
+
+	return &CircuitBreaker{
+		name:             name,
+		failureThreshold: max(1, cfg.FailureThreshold),
+		initialBackoff:   cfg.InitialBackoff,
+		maxBackoff:       cfg.MaxBackoff,
+		cache:            cache,
+		logger:           logger,
+		now:              time.Now,
+		state:            CircuitClosed,
+		backoff:          cfg.InitialBackoff,
+	}
+}
+
+// Allow reports whether a request may be sent, returning ErrCircuitOpen while backing off. Once the backoff
+// has passed, a single trial request is let through; every allowed request must be followed by Record.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Before(b.openUntil) {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.trialInFlight = true
+		return nil
+
+	case CircuitHalfOpen:
+		if b.trialInFlight {
+			return ErrCircuitOpen
+		}
+		b.trialInFlight = true
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// Record reports the outcome of an allowed request. A cancelled request has no outcome: the breaker stays as
+// it was, and when it was the half-open trial the next request becomes the trial instead.
+func (b *CircuitBreaker) Record(ctx context.Context, err error) {
+	b.mu.Lock()
+
+	previous := b.state
+	b.trialInFlight = false
+
+	if isCancellation(err) {
+		b.mu.Unlock()
+		return
+	}
+
+	if err == nil || !isBackendOutage(err) {
+		b.state = CircuitClosed
+		b.consecutiveFailures = 0
+		b.backoff = b.initialBackoff
+	} else {
+		b.consecutiveFailures++
+		b.lastError = err.Error()
+		b.lastFailure = b.now()
+
+		switch {
+		case b.state == CircuitHalfOpen:
+			b.backoff = min(2*b.backoff, b.maxBackoff)
+			b.open()
+		case b.consecutiveFailures >= b.failureThreshold:
+			b.open()
+		}
+	}
+
+	status := b.statusLocked()
+	b.mu.Unlock()
+
+	if status.State != previous {
+		switch status.State {
+		case CircuitOpen:
+			b.logger.Warn("circuit breaker opened", "name", b.name, "until", status.OpenUntil, "error", status.LastError)
+		case CircuitClosed:
+			b.logger.Info("circuit breaker closed", "name", b.name)
+		}
+	}
+
+	b.publish(ctx, status)
+}
+
+// open starts a backoff period; callers hold b.mu.
+func (b *CircuitBreaker) open() {
+	b.state = CircuitOpen
+	b.openUntil = b.now().Add(b.backoff)
+}
+
+// Status returns a snapshot of the breaker.
+func (b *CircuitBreaker) Status() CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.statusLocked()
+}
+
+func (b *CircuitBreaker) statusLocked() CircuitStatus {
+	status := CircuitStatus{
+		Name:                b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.consecutiveFailures,
+		LastError:           b.lastError,
+		UpdatedAt:           b.now(),
+	}
+
+	if !b.lastFailure.IsZero() {
+		lastFailure := b.lastFailure
+		status.LastFailure = &lastFailure
+	}
+
+	if b.state == CircuitOpen {
+		openUntil := b.openUntil
+		status.OpenUntil = &openUntil
+	}
+
+	return status
+}
+
+func (b *CircuitBreaker) publish(ctx context.Context, status CircuitStatus) {
+	if b.cache == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(status)
+	if err != nil {
+		b.logger.Error("failed to encode circuit breaker status", "name", b.name, "error", err)
+		return
+	}
+
+	if err := b.cache.SetKey(ctx, circuitStatusKeyPrefix+b.name, string(encoded), circuitStatusRetention); err != nil {
+		b.logger.Warn("failed to publish circuit breaker status", "name", b.name, "error", err)
+	}
+}
+
+// GetCircuitStatus returns the state last published by the named breaker.
+func GetCircuitStatus(ctx context.Context, cache Provider, name string) (CircuitStatus, bool) {
+	raw, err := cache.GetKey(ctx, circuitStatusKeyPrefix+name)
+	if err != nil || raw == "" {
+		return CircuitStatus{}, false
+	}
+
+	var status CircuitStatus
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		return CircuitStatus{}, false
+	}
+
+	return status, true
+}
+
+// isCancellation reports whether a request was cancelled by the caller, e.g. on shutdown, which says nothing
+// about the backend.
+func isCancellation(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var apiErr *v1.Error
+	return errors.As(err, &apiErr) && apiErr.Type == v1.ErrCanceled
+}
+
+// isBackendOutage reports whether an error means the backend is unavailable, as opposed to a bad or failing
+// query.
+func isBackendOutage(err error) bool {
+	var apiErr *v1.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Type {
+		case v1.ErrBadData, v1.ErrExec:
+			return false
+		}
+	}
+
+	return true
+}
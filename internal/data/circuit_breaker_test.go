@@ -0,0 +1,142 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCircuitBreaker(t *testing.T, cache Provider) (*CircuitBreaker, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(PrometheusCircuitName, &config.DataCircuitBreaker{
+		FailureThreshold: 2,
+		InitialBackoff:   30 * time.Second,
+		MaxBackoff:       time.Minute,
+	}, cache, slog.Default())
+	breaker.now = func() time.Time { return now }
+
+	return breaker, &now
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	ctx := context.Background()
+	breaker, _ := newTestCircuitBreaker(t, nil)
+	outage := errors.New("connection refused")
+
+	require.NoError(t, breaker.Allow())
+	breaker.Record(ctx, outage)
+	assert.Equal(t, CircuitClosed, breaker.Status().State, "a single failure should not open the circuit")
+
+	require.NoError(t, breaker.Allow())
+	breaker.Record(ctx, outage)
+	assert.Equal(t, CircuitOpen, breaker.Status().State)
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_HalfOpenTrial(t *testing.T) {
+	ctx := context.Background()
+	breaker, now := newTestCircuitBreaker(t, nil)
+	outage := errors.New("connection refused")
+
+	breaker.Record(ctx, outage)
+	breaker.Record(ctx, outage)
+	require.Equal(t, CircuitOpen, breaker.Status().State)
+
+	*now = now.Add(31 * time.Second)
+	require.NoError(t, breaker.Allow(), "a trial request should be allowed once the backoff has passed")
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen, "only one trial request should be in flight")
+
+	breaker.Record(ctx, outage)
+	status := breaker.Status()
+	assert.Equal(t, CircuitOpen, status.State)
+	require.NotNil(t, status.OpenUntil)
+	assert.Equal(t, now.Add(time.Minute), *status.OpenUntil, "a failed trial should double the backoff")
+
+	*now = now.Add(2 * time.Minute)
+	require.NoError(t, breaker.Allow())
+	breaker.Record(ctx, nil)
+	status = breaker.Status()
+	assert.Equal(t, CircuitClosed, status.State)
+	assert.Zero(t, status.ConsecutiveFailures)
+	assert.Nil(t, status.OpenUntil)
+}
+
+func TestCircuitBreaker_BackoffIsCapped(t *testing.T) {
+	ctx := context.Background()
+	breaker, now := newTestCircuitBreaker(t, nil)
+	outage := errors.New("connection refused")
+
+	breaker.Record(ctx, outage)
+	breaker.Record(ctx, outage)
+
+	for range 5 {
+		*now = now.Add(time.Hour)
+		require.NoError(t, breaker.Allow())
+		breaker.Record(ctx, outage)
+	}
+
+	status := breaker.Status()
+	require.NotNil(t, status.OpenUntil)
+	assert.Equal(t, now.Add(time.Minute), *status.OpenUntil)
+}
+
+func TestCircuitBreaker_IgnoresQueryErrors(t *testing.T) {
+	ctx := context.Background()
+	breaker, _ := newTestCircuitBreaker(t, nil)
+
+	for range 5 {
+		breaker.Record(ctx, &v1.Error{Type: v1.ErrBadData, Msg: "parse error"})
+		breaker.Record(ctx, context.Canceled)
+	}
+
+	assert.Equal(t, CircuitClosed, breaker.Status().State)
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestCircuitBreaker_CancelledTrialStaysHalfOpen(t *testing.T) {
+	ctx := context.Background()
+	breaker, now := newTestCircuitBreaker(t, nil)
+	outage := errors.New("connection refused")
+
+	breaker.Record(ctx, outage)
+	breaker.Record(ctx, outage)
+
+	*now = now.Add(31 * time.Second)
+	require.NoError(t, breaker.Allow())
+	breaker.Record(ctx, context.Canceled)
+
+	status := breaker.Status()
+	assert.Equal(t, CircuitHalfOpen, status.State, "a cancelled trial should not close the circuit")
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	require.NoError(t, breaker.Allow(), "the next request should become the trial")
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_PublishesStatus(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewMemCache(&config.Config{}, slog.Default())
+	require.NoError(t, err)
+
+	breaker, _ := newTestCircuitBreaker(t, cache)
+	breaker.Record(ctx, errors.New("connection refused"))
+	breaker.Record(ctx, errors.New("connection refused"))
+
+	status, ok := GetCircuitStatus(ctx, cache, PrometheusCircuitName)
+	require.True(t, ok)
+	assert.Equal(t, CircuitOpen, status.State)
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.Equal(t, "connection refused", status.LastError)
+
+	_, ok = GetCircuitStatus(ctx, cache, "loki")
+	assert.False(t, ok)
+}
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Condition compares a value with a threshold, e.g. "< 1" or ">= 90".
+type Condition struct {
+	Operator  string
+	Threshold float64
+}
+
+// ParseCondition reads a condition as written in an alert rule or a status page component.
+func ParseCondition(raw string) (Condition, error) {
+	trimmed := strings.TrimSpace(raw)
+
+	// Two-character operators go first so "<=" is not read as "<"
+	for _, operator := range []string{"<=", ">=", "==", "!=", "<", ">"} {
+		rest, ok := strings.CutPrefix(trimmed, operator)
+		if !ok {
+			continue
+		}
+
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(rest), 64)
+		if err != nil {
+			return Condition{}, fmt.Errorf("invalid threshold in condition %q", raw)
+		}
+		return Condition{Operator: operator, Threshold: threshold}, nil
+	}
+
+	return Condition{}, fmt.Errorf("condition %q must start with one of <, <=, >, >=, == or !=", raw)
+}
+
+// Matches reports whether a value meets the condition.
+func (c Condition) Matches(value float64) bool {
+	switch c.Operator {
+	case "<":
+		return value < c.Threshold
+	case "<=":
+		return value <= c.Threshold
+	case ">":
+		return value > c.Threshold
+	case ">=":
+		return value >= c.Threshold
+	case "==":
+		return value == c.Threshold
+	case "!=":
+		return value != c.Threshold
+	default:
+		return false
+	}
+}
+
+func (c Condition) String() string {
+	return c.Operator + " " + strconv.FormatFloat(c.Threshold, 'g', -1, 64)
+}
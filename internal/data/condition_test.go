@@ -0,0 +1,41 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCondition(t *testing.T) {
+	testCases := []struct {
+		raw         string
+		expected    Condition
+		expectError bool
+	}{
+		{raw: "< 1", expected: Condition{Operator: "<", Threshold: 1}},
+		{raw: ">=90.5", expected: Condition{Operator: ">=", Threshold: 90.5}},
+		{raw: " != -2 ", expected: Condition{Operator: "!=", Threshold: -2}},
+		{raw: "== 0", expected: Condition{Operator: "==", Threshold: 0}},
+		{raw: "90", expectError: true},
+		{raw: "> ninety", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.raw, func(t *testing.T) {
+			condition, err := ParseCondition(tc.raw)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, condition)
+		})
+	}
+
+	condition := Condition{Operator: "<=", Threshold: 1}
+	assert.True(t, condition.Matches(1))
+	assert.False(t, condition.Matches(1.5))
+	assert.Equal(t, "<= 1", condition.String())
+}
@@ -2,8 +2,14 @@ package data
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"homelab-dashboard/internal/config"
+	"log/slog"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/api"
@@ -11,68 +17,213 @@ import (
 	"github.com/prometheus/common/model"
 )
 
+// MimirClient queries one Prometheus-compatible backend.
 type MimirClient struct {
-	api v1.API
+	backend string
+	api     v1.API
+	breaker *CircuitBreaker
+	logger  *slog.Logger
 }
 
-func NewMimirClient(baseUrl, username, password string) (*MimirClient, error) {
-	cfg := api.Config{
-		Address: baseUrl,
+func NewMimirClient(backend config.DataPrometheusBackend, logger *slog.Logger) (*MimirClient, error) {
+	transport, err := newBackendTransport(backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up transport of backend %s: %w", backend.Name, err)
 	}
 
-	if username != "" && password != "" {
-		cfg.RoundTripper = &BasicAuthTransport{
-			Username: username,
-			Password: password,
-			Proxied:  api.DefaultRoundTripper,
-		}
+	timeout := backend.Timeout
+	if timeout == 0 {
+		timeout = config.DefaultDataBackendTimeout
 	}
 
-	client, err := api.NewClient(cfg)
+	client, err := api.NewClient(api.Config{
+		Address: backend.URL,
+		Client:  &http.Client{Transport: transport, Timeout: timeout},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
 
-	v1api := v1.NewAPI(client)
+	return &MimirClient{
+		backend: backend.Name,
+		api:     v1.NewAPI(client),
+		logger:  logger,
+	}, nil
+}
+
+// WithCircuitBreaker makes the client fail fast with ErrCircuitOpen while the breaker is backing off.
+func (m *MimirClient) WithCircuitBreaker(breaker *CircuitBreaker) *MimirClient {
+	m.breaker = breaker
+	return m
+}
+
+// guard runs a request through the circuit breaker, if there is one.
+func (m *MimirClient) guard(ctx context.Context, request func() (model.Value, error)) (model.Value, error) {
+	if m.breaker == nil {
+		return request()
+	}
+
+	if err := m.breaker.Allow(); err != nil {
+		return nil, err
+	}
 
-	return &MimirClient{api: v1api}, nil
+	result, err := request()
+	m.breaker.Record(ctx, err)
+	return result, err
 }
 
 func (m *MimirClient) Query(ctx context.Context, query string, timestamp time.Time) (model.Value, error) {
+	return m.guard(ctx, func() (model.Value, error) {
+		return m.query(ctx, query, timestamp)
+	})
+}
+
+func (m *MimirClient) QueryRange(ctx context.Context, query string, r v1.Range) (model.Value, error) {
+	return m.guard(ctx, func() (model.Value, error) {
+		return m.queryRange(ctx, query, r)
+	})
+}
+
+func (m *MimirClient) query(ctx context.Context, query string, timestamp time.Time) (model.Value, error) {
 	result, warnings, err := m.api.Query(ctx, query, timestamp)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
 
-	if len(warnings) > 0 {
-		fmt.Printf("query warnings: %v\n", warnings)
-	}
-
+	m.warn(ctx, "query returned warnings", query, warnings)
 	return result, nil
 }
 
-func (m *MimirClient) QueryRange(ctx context.Context, query string, r v1.Range) (model.Value, error) {
+func (m *MimirClient) queryRange(ctx context.Context, query string, r v1.Range) (model.Value, error) {
 	result, warnings, err := m.api.QueryRange(ctx, query, r)
 	if err != nil {
 		return nil, fmt.Errorf("range query failed: %w", err)
 	}
 
-	if len(warnings) > 0 {
-		fmt.Printf("range query warnings: %v\n", warnings)
+	m.warn(ctx, "range query returned warnings", query, warnings)
+	return result, nil
+}
+
+// LabelValues lists the values of a label, optionally limited to the series matching the given selectors.
+func (m *MimirClient) LabelValues(ctx context.Context, label string, matches []string) ([]string, error) {
+	if m.breaker != nil {
+		if err := m.breaker.Allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	values, warnings, err := m.api.LabelValues(ctx, label, matches, time.Time{}, time.Time{})
+	if m.breaker != nil {
+		m.breaker.Record(ctx, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("label values lookup failed: %w", err)
+	}
+
+	m.warn(ctx, "label values lookup returned warnings", label, warnings)
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, string(value))
 	}
 
 	return result, nil
 }
 
-type BasicAuthTransport struct {
-	Username string
-	Password string
-	Proxied  http.RoundTripper
+// warn logs the warnings a backend returned alongside a result, e.g. Mimir answering with partial data after
+// hitting a limit, and adds them to the query's warnings so they reach the caller.
+func (m *MimirClient) warn(ctx context.Context, msg, query string, warnings v1.Warnings) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	if m.logger != nil {
+		m.logger.Warn(msg, "backend", m.backend, "query", query, "warnings", []string(warnings))
+	}
+	addQueryWarnings(ctx, warnings...)
 }
 
-func (b *BasicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if b.Username != "" && b.Password != "" {
-		req.SetBasicAuth(b.Username, b.Password)
+// backendTransport adds a backend's credentials, tenant and headers to every request.
+type backendTransport struct {
+	backend config.DataPrometheusBackend
+	proxied http.RoundTripper
+}
+
+func newBackendTransport(backend config.DataPrometheusBackend) (*backendTransport, error) {
+	base := api.DefaultRoundTripper.(*http.Transport).Clone()
+
+	if backend.TLS != nil {
+		tlsConfig, err := backendTLSConfig(backend.TLS)
+		if err != nil {
+			return nil, err
+		}
+		base.TLSClientConfig = tlsConfig
+	}
+
+	return &backendTransport{backend: backend, proxied: base}, nil
+}
+
+func (b *backendTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	for name, value := range b.backend.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if b.backend.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", b.backend.TenantID)
+	}
+
+	switch {
+	case b.backend.BasicAuth != nil:
+		req.SetBasicAuth(b.backend.BasicAuth.Username, b.backend.BasicAuth.Password)
+	case b.backend.BearerTokenFile != "":
+		token, err := os.ReadFile(b.backend.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	return b.proxied.RoundTrip(req)
+}
+
+// backendTLSConfig trusts the backend's CA bundle on top of the system roots and presents its client certificate.
+func backendTLSConfig(cfg *config.DataBackendTLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		pool, err := loadCABundle(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCABundle returns the system roots with the certificates of a PEM bundle added.
+func loadCABundle(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s contains no certificates", file)
 	}
-	return b.Proxied.RoundTrip(req)
+	return pool, nil
 }
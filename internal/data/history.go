@@ -0,0 +1,118 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// HistoryRecorder keeps the results of queries in long-term history, see storage.Provider.
+type HistoryRecorder interface {
+	RecordDataHistory(ctx context.Context, samples []models.DataHistorySample) error
+}
+
+// WithHistory records the results of instant queries that opt into history with the given recorder.
+func (s *Service) WithHistory(recorder HistoryRecorder) *Service {
+	s.history = recorder
+	return s
+}
+
+// RecordsHistory reports whether a query's results are recorded into history.
+func RecordsHistory(query config.PrometheusQuery) bool {
+	return query.History != nil && query.History.Enabled && query.Type != "range"
+}
+
+// HistoryVariablesKey encodes a selection of template variables the way history is recorded under, e.g.
+// "namespace=media". It is empty for queries without variables.
+func HistoryVariablesKey(selection map[string]string) string {
+	values := make(url.Values, len(selection))
+	for name, value := range selection {
+		values.Set(name, value)
+	}
+	return values.Encode()
+}
+
+// HistoryRetention returns how long a query keeps the given rollup of its history.
+func HistoryRetention(query config.PrometheusQuery, resolution models.DataHistoryResolution) time.Duration {
+	if query.History == nil || query.History.Retention == nil {
+		return 0
+	}
+
+	switch resolution {
+	case models.DataHistoryFiveMinutes:
+		return query.History.Retention.FiveMinutes
+	case models.DataHistoryHourly:
+		return query.History.Retention.Hourly
+	case models.DataHistoryDaily:
+		return query.History.Retention.Daily
+	default:
+		return 0
+	}
+}
+
+// HistorySamples converts an instant query result into samples, one for each series of a vector or the value of
+// a scalar. Other results and values that are not finite have nothing to record.
+func HistorySamples(query config.PrometheusQuery, value model.Value, recordedAt time.Time) []models.DataHistorySample {
+	variables := HistoryVariablesKey(query.Variables)
+
+	sample := func(metric model.Metric, v model.SampleValue) (models.DataHistorySample, bool) {
+		f := float64(v)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return models.DataHistorySample{}, false
+		}
+
+		labels := make(map[string]string, len(metric))
+		for name, labelValue := range metric {
+			labels[string(name)] = string(labelValue)
+		}
+
+		return models.DataHistorySample{
+			QueryName:  query.Name,
+			Variables:  variables,
+			Series:     metric.String(),
+			Labels:     labels,
+			Value:      f,
+			RecordedAt: recordedAt,
+		}, true
+	}
+
+	var samples []models.DataHistorySample
+	switch v := value.(type) {
+	case model.Vector:
+		for _, s := range v {
+			if recorded, ok := sample(s.Metric, s.Value); ok {
+				samples = append(samples, recorded)
+			}
+		}
+	case *model.Scalar:
+		if recorded, ok := sample(model.Metric{}, v.Value); ok {
+			samples = append(samples, recorded)
+		}
+	}
+
+	return samples
+}
+
+// recordHistory adds a query's result to its history when the query opts in.
+func (s *Service) recordHistory(ctx context.Context, query config.PrometheusQuery, value model.Value) error {
+	if s.history == nil || !RecordsHistory(query) {
+		return nil
+	}
+
+	samples := HistorySamples(query, value, time.Now())
+	if len(samples) == 0 {
+		return nil
+	}
+
+	if err := s.history.RecordDataHistory(ctx, samples); err != nil {
+		return fmt.Errorf("failed to record history of query %s: %w", query.Name, err)
+	}
+
+	return nil
+}
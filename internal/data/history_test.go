@@ -0,0 +1,87 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyRecorder collects the samples a service records, or fails when err is set.
+type historyRecorder struct {
+	samples []models.DataHistorySample
+	err     error
+}
+
+func (h *historyRecorder) RecordDataHistory(ctx context.Context, samples []models.DataHistorySample) error {
+	if h.err != nil {
+		return h.err
+	}
+	h.samples = append(h.samples, samples...)
+	return nil
+}
+
+func TestHistorySamples(t *testing.T) {
+	recordedAt := time.Date(2026, 3, 10, 12, 3, 0, 0, time.UTC)
+	query := ExpandQuery(config.PrometheusQuery{Name: "power", Query: `power_watts{namespace="$namespace"}`}, map[string]string{"namespace": "media"})
+
+	vector := model.Vector{
+		{Metric: model.Metric{"instance": "nas:9100"}, Value: 42},
+		{Metric: model.Metric{"instance": "pi:9100"}, Value: model.SampleValue(math.NaN())},
+	}
+
+	samples := HistorySamples(query, vector, recordedAt)
+	require.Len(t, samples, 1, "values that are not finite should be skipped")
+	assert.Equal(t, models.DataHistorySample{
+		QueryName:  "power",
+		Variables:  "namespace=media",
+		Series:     `{instance="nas:9100"}`,
+		Labels:     map[string]string{"instance": "nas:9100"},
+		Value:      42,
+		RecordedAt: recordedAt,
+	}, samples[0])
+
+	scalar := HistorySamples(config.PrometheusQuery{Name: "uptime"}, &model.Scalar{Value: 1}, recordedAt)
+	require.Len(t, scalar, 1)
+	assert.Equal(t, "{}", scalar[0].Series)
+	assert.Empty(t, scalar[0].Variables)
+
+	assert.Empty(t, HistorySamples(query, model.Matrix{}, recordedAt), "range results are not recorded")
+
+	assert.Equal(t, time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC), models.DataHistoryFiveMinutes.Bucket(recordedAt))
+	assert.Equal(t, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), models.DataHistoryDaily.Bucket(recordedAt))
+}
+
+func TestService_ExecuteQueryRecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	recorded := config.PrometheusQuery{Name: "uptime", Query: "up", History: &config.DataQueryHistory{Enabled: true}}
+	unrecorded := config.PrometheusQuery{Name: "load", Query: "node_load1"}
+
+	recorder := &historyRecorder{}
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: newCountingSource(0)}, cache, logger, nil).WithHistory(recorder)
+
+	require.NoError(t, service.ExecuteQuery(ctx, cache, recorded))
+	require.NoError(t, service.ExecuteQuery(ctx, cache, unrecorded))
+
+	require.Len(t, recorder.samples, 1, "only queries that opt into history should be recorded")
+	assert.Equal(t, "uptime", recorder.samples[0].QueryName)
+
+	recorder.err = errors.New("database is down")
+	require.NoError(t, service.ExecuteQuery(ctx, cache, recorded), "a failure to record history should not fail the query")
+
+	_, ok := cache.Get(ctx, "uptime")
+	assert.True(t, ok)
+}
@@ -0,0 +1,121 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// HTTPJSONSource fetches a JSON document, e.g. from a UPS or Pi-hole API, and extracts numbers with a JSONPath
+// expression. A path selecting one value becomes a scalar; a wildcard path becomes a vector labelled by the
+// concrete path of each value. Booleans count as 1 or 0 and numeric strings are parsed; other values are skipped.
+type HTTPJSONSource struct {
+	client *http.Client
+}
+
+func NewHTTPJSONSource() *HTTPJSONSource {
+	return &HTTPJSONSource{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (h *HTTPJSONSource) Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error) {
+	segments, err := parseJSONPath(query.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jsonpath %s: %w", query.Query, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, query.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	for name, value := range query.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return jsonPathValue(document, segments, model.TimeFromUnixNano(time.Now().UnixNano()))
+}
+
+// jsonPathValue turns the numbers selected from a document into a scalar or vector.
+func jsonPathValue(document any, segments []jsonPathSegment, now model.Time) (model.Value, error) {
+	wildcard := false
+	for _, segment := range segments {
+		wildcard = wildcard || segment.wildcard
+	}
+
+	matches := evaluateJSONPath(document, segments)
+
+	vector := make(model.Vector, 0, len(matches))
+	for _, match := range matches {
+		value, ok := jsonNumber(match.value)
+		if !ok {
+			continue
+		}
+
+		vector = append(vector, &model.Sample{
+			Metric:    model.Metric{"path": model.LabelValue(match.path)},
+			Value:     model.SampleValue(value),
+			Timestamp: now,
+		})
+	}
+
+	if wildcard {
+		return vector, nil
+	}
+
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("jsonpath did not select a numeric value")
+	}
+
+	return &model.Scalar{Value: vector[0].Value, Timestamp: now}, nil
+}
+
+func jsonNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		parsed, err := v.Float64()
+		return parsed, err == nil
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,138 @@
+package data
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegment is one step of a JSONPath expression: an object key, an array index or a wildcard.
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// jsonPathMatch is a value selected by a JSONPath expression together with its concrete path.
+type jsonPathMatch struct {
+	path  string
+	value any
+}
+
+// parseJSONPath parses the subset of JSONPath that dashboard queries need: dot and bracket child access
+// ($.a.b, $['a']), array indexes including negative ones ($.a[0], $.a[-1]) and wildcards ($.a[*], $.a.*).
+// Filters, slices and recursive descent are not supported.
+func parseJSONPath(expr string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath must start with $")
+	}
+
+	var segments []jsonPathSegment
+	rest := expr[1:]
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			if strings.HasPrefix(rest, ".") {
+				return nil, fmt.Errorf("jsonpath recursive descent is not supported")
+			}
+
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("jsonpath has an empty key")
+			}
+
+			if key == "*" {
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			} else {
+				segments = append(segments, jsonPathSegment{key: key})
+			}
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("jsonpath has an unterminated [")
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("jsonpath index %q is not supported", inner)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+
+		default:
+			return nil, fmt.Errorf("unexpected %q in jsonpath", rest[0])
+		}
+	}
+
+	return segments, nil
+}
+
+// evaluateJSONPath returns every value the segments select from a decoded JSON document, in document order.
+// Keys and indexes that do not exist select nothing rather than failing.
+func evaluateJSONPath(document any, segments []jsonPathSegment) []jsonPathMatch {
+	matches := []jsonPathMatch{{path: "$", value: document}}
+
+	for _, segment := range segments {
+		var next []jsonPathMatch
+
+		for _, match := range matches {
+			switch node := match.value.(type) {
+			case map[string]any:
+				if segment.wildcard {
+					for _, key := range slices.Sorted(maps.Keys(node)) {
+						next = append(next, jsonPathMatch{path: jsonPathChild(match.path, key), value: node[key]})
+					}
+				} else if !segment.isIndex {
+					if value, ok := node[segment.key]; ok {
+						next = append(next, jsonPathMatch{path: jsonPathChild(match.path, segment.key), value: value})
+					}
+				}
+
+			case []any:
+				if segment.wildcard {
+					for i, value := range node {
+						next = append(next, jsonPathMatch{path: fmt.Sprintf("%s[%d]", match.path, i), value: value})
+					}
+				} else if segment.isIndex {
+					index := segment.index
+					if index < 0 {
+						index += len(node)
+					}
+					if index >= 0 && index < len(node) {
+						next = append(next, jsonPathMatch{path: fmt.Sprintf("%s[%d]", match.path, index), value: node[index]})
+					}
+				}
+			}
+		}
+
+		matches = next
+	}
+
+	return matches
+}
+
+func jsonPathChild(parent, key string) string {
+	if key != "" && !strings.ContainsAny(key, ".[]'\" ") {
+		return parent + "." + key
+	}
+	return fmt.Sprintf("%s['%s']", parent, key)
+}
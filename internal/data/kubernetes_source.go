@@ -0,0 +1,133 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/utils"
+	"slices"
+	"time"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Node readiness values reported by the nodes query.
+const (
+	kubernetesNodeReady    = "ready"
+	kubernetesNodeNotReady = "not_ready"
+)
+
+// KubernetesSource counts cluster resources. Each query returns a vector with one sample per state, nodes by
+// readiness and pods and PVCs by phase, always including zero counts so panels keep a stable set of series.
+type KubernetesSource struct {
+	client kubernetes.Interface
+}
+
+func NewKubernetesSource(client kubernetes.Interface) *KubernetesSource {
+	return &KubernetesSource{client: client}
+}
+
+// NewKubernetesSourceFromConfig connects to the cluster configured under data.kubernetes.
+func NewKubernetesSourceFromConfig(cfg *config.KubernetesConfig) (*KubernetesSource, error) {
+	restConfig, err := utils.KubernetesRestConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return NewKubernetesSource(client), nil
+}
+
+func (k *KubernetesSource) Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error) {
+	now := model.TimeFromUnixNano(time.Now().UnixNano())
+
+	switch query.Query {
+	case config.DataKubernetesNodes:
+		nodes, err := k.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %w", err)
+		}
+
+		counts := map[string]int{kubernetesNodeReady: 0, kubernetesNodeNotReady: 0}
+		for _, node := range nodes.Items {
+			counts[nodeReadiness(&node)]++
+		}
+		return countsToVector("status", []string{kubernetesNodeReady, kubernetesNodeNotReady}, counts, "", now), nil
+
+	case config.DataKubernetesPods:
+		pods, err := k.client.CoreV1().Pods(query.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+
+		phases := []string{
+			string(corev1.PodPending), string(corev1.PodRunning), string(corev1.PodSucceeded),
+			string(corev1.PodFailed), string(corev1.PodUnknown),
+		}
+		counts := make(map[string]int, len(phases))
+		for _, pod := range pods.Items {
+			counts[string(pod.Status.Phase)]++
+		}
+		return countsToVector("phase", phases, counts, query.Namespace, now), nil
+
+	case config.DataKubernetesPersistentVolumeClaims:
+		claims, err := k.client.CoreV1().PersistentVolumeClaims(query.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+		}
+
+		phases := []string{string(corev1.ClaimPending), string(corev1.ClaimBound), string(corev1.ClaimLost)}
+		counts := make(map[string]int, len(phases))
+		for _, claim := range claims.Items {
+			counts[string(claim.Status.Phase)]++
+		}
+		return countsToVector("phase", phases, counts, query.Namespace, now), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported kubernetes resource %q", query.Query)
+	}
+}
+
+func nodeReadiness(node *corev1.Node) string {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			return kubernetesNodeReady
+		}
+	}
+	return kubernetesNodeNotReady
+}
+
+// countsToVector emits the known states in order, followed by any state the API reported that is not known.
+func countsToVector(label string, known []string, counts map[string]int, namespace string, now model.Time) model.Vector {
+	var unknown []string
+	for state := range counts {
+		if !slices.Contains(known, state) {
+			unknown = append(unknown, state)
+		}
+	}
+	slices.Sort(unknown)
+	states := append(slices.Clone(known), unknown...)
+
+	vector := make(model.Vector, 0, len(states))
+	for _, state := range states {
+		metric := model.Metric{model.LabelName(label): model.LabelValue(state)}
+		if namespace != "" {
+			metric["namespace"] = model.LabelValue(namespace)
+		}
+
+		vector = append(vector, &model.Sample{
+			Metric:    metric,
+			Value:     model.SampleValue(counts[state]),
+			Timestamp: now,
+		})
+	}
+
+	return vector
+}
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// lokiResultTypeStreams is the result type of LogQL log queries, which return log lines instead of samples.
+const lokiResultTypeStreams = "streams"
+
+// LokiSource answers LogQL queries through Loki's HTTP API. Metric queries (e.g. rate or count_over_time)
+// already return vectors and matrices; log queries are reduced to the number of lines returned per stream,
+// which is capped by Loki's default line limit, so prefer metric queries for counts.
+type LokiSource struct {
+	baseURL  string
+	username string
+	password string
+	tenantID string
+	client   *http.Client
+}
+
+func NewLokiSource(cfg *config.DataLokiConfig) *LokiSource {
+	source := &LokiSource{
+		baseURL:  strings.TrimSuffix(cfg.URL, "/"),
+		tenantID: cfg.TenantID,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if cfg.BasicAuth != nil {
+		source.username = cfg.BasicAuth.Username
+		source.password = cfg.BasicAuth.Password
+	}
+
+	return source
+}
+
+type lokiResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (l *LokiSource) Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error) {
+	params := url.Values{}
+	params.Set("query", query.Query)
+
+	endpoint := "/loki/api/v1/query"
+	if query.Type == "range" {
+		r, err := queryRange(query)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoint = "/loki/api/v1/query_range"
+		params.Set("start", strconv.FormatInt(r.Start.UnixNano(), 10))
+		params.Set("end", strconv.FormatInt(r.End.UnixNano(), 10))
+		params.Set("step", strconv.FormatFloat(r.Step.Seconds(), 'f', -1, 64))
+	} else {
+		params.Set("time", strconv.FormatInt(time.Now().UnixNano(), 10))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.baseURL+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create loki request: %w", err)
+	}
+
+	if l.username != "" && l.password != "" {
+		req.SetBasicAuth(l.username, l.password)
+	}
+	if l.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", l.tenantID)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("loki query failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read loki response: %w", err)
+	}
+
+	var decoded lokiResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("loki query failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		return nil, fmt.Errorf("failed to decode loki response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || decoded.Status != "success" {
+		return nil, fmt.Errorf("loki query failed with status %d: %s", resp.StatusCode, decoded.Error)
+	}
+
+	return decodeLokiResult(decoded.Data.ResultType, decoded.Data.Result)
+}
+
+// decodeLokiResult converts a Loki result into the matching Prometheus value. Loki encodes metric
+// results exactly like Prometheus does, so only log streams need converting.
+func decodeLokiResult(resultType string, raw json.RawMessage) (model.Value, error) {
+	switch resultType {
+	case model.ValVector.String():
+		var vector model.Vector
+		if err := json.Unmarshal(raw, &vector); err != nil {
+			return nil, fmt.Errorf("failed to decode loki vector: %w", err)
+		}
+		return vector, nil
+
+	case model.ValMatrix.String():
+		var matrix model.Matrix
+		if err := json.Unmarshal(raw, &matrix); err != nil {
+			return nil, fmt.Errorf("failed to decode loki matrix: %w", err)
+		}
+		return matrix, nil
+
+	case model.ValScalar.String():
+		var scalar model.Scalar
+		if err := json.Unmarshal(raw, &scalar); err != nil {
+			return nil, fmt.Errorf("failed to decode loki scalar: %w", err)
+		}
+		return &scalar, nil
+
+	case lokiResultTypeStreams:
+		var streams []lokiStream
+		if err := json.Unmarshal(raw, &streams); err != nil {
+			return nil, fmt.Errorf("failed to decode loki streams: %w", err)
+		}
+		return lokiStreamsToVector(streams)
+
+	default:
+		return nil, fmt.Errorf("unsupported loki result type %q", resultType)
+	}
+}
+
+// lokiStreamsToVector counts the lines of each stream, timestamped with the stream's newest line.
+func lokiStreamsToVector(streams []lokiStream) (model.Vector, error) {
+	vector := make(model.Vector, 0, len(streams))
+
+	for _, stream := range streams {
+		metric := make(model.Metric, len(stream.Stream))
+		for name, value := range stream.Stream {
+			metric[model.LabelName(name)] = model.LabelValue(value)
+		}
+
+		var newest int64
+		for _, entry := range stream.Values {
+			ts, err := strconv.ParseInt(entry[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid loki entry timestamp %q: %w", entry[0], err)
+			}
+			newest = max(newest, ts)
+		}
+
+		vector = append(vector, &model.Sample{
+			Metric:    metric,
+			Value:     model.SampleValue(len(stream.Values)),
+			Timestamp: model.TimeFromUnixNano(newest),
+		})
+	}
+
+	return vector, nil
+}
@@ -14,6 +14,7 @@ func NewMemCache(cfg *config.Config, logger *slog.Logger) (*MemCache, error) {
 	return &MemCache{
 		cache:   make(map[string]CachedData),
 		kvStore: make(map[string]*kvEntry),
+		windows: make(map[string][]time.Time),
 		logger:  logger,
 	}, nil
 }
@@ -26,6 +27,8 @@ type kvEntry struct {
 type MemCache struct {
 	cache   map[string]CachedData
 	kvStore map[string]*kvEntry
+	windows map[string][]time.Time // sliding-window hits, oldest first
+	updates updateBroker
 	mutex   sync.RWMutex
 	logger  *slog.Logger
 }
@@ -148,3 +151,72 @@ func (d *MemCache) GetDelKey(ctx context.Context, key string) (string, error) {
 	delete(d.kvStore, key)
 	return value, nil
 }
+
+// SlidingWindowAllow records a hit on key if fewer than limit hits fall within the last window
+func (d *MemCache) SlidingWindowAllow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	hits := d.pruneWindow(key, now.Add(-window))
+
+	if len(hits) >= limit {
+		return false, hits[0].Add(window).Sub(now), nil
+	}
+
+	d.windows[key] = append(hits, now)
+
+	// Drop the key once this hit leaves the window, unless newer hits keep it alive.
+	time.AfterFunc(window, func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.pruneWindow(key, time.Now().Add(-window))
+	})
+
+	return true, 0, nil
+}
+
+// SlidingWindowRelease removes the most recent hit on key
+func (d *MemCache) SlidingWindowRelease(ctx context.Context, key string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	hits := d.windows[key]
+	if len(hits) <= 1 {
+		delete(d.windows, key)
+		return nil
+	}
+
+	d.windows[key] = hits[:len(hits)-1]
+	return nil
+}
+
+// pruneWindow drops the hits on key at or before cutoff and returns the rest, deleting the key when none are
+// left. The caller must hold the lock.
+func (d *MemCache) pruneWindow(key string, cutoff time.Time) []time.Time {
+	hits := d.windows[key]
+	expired := 0
+	for expired < len(hits) && !hits[expired].After(cutoff) {
+		expired++
+	}
+	hits = hits[expired:]
+
+	if len(hits) == 0 {
+		delete(d.windows, key)
+		return nil
+	}
+
+	d.windows[key] = hits
+	return hits
+}
+
+// PublishUpdate notifies this process's subscribers; a memory cache is never shared between replicas.
+func (d *MemCache) PublishUpdate(ctx context.Context, queryName string) error {
+	d.updates.publish(queryName)
+	return nil
+}
+
+// SubscribeUpdates returns the names of queries whose cached data changes, until ctx is done.
+func (d *MemCache) SubscribeUpdates(ctx context.Context) <-chan string {
+	return d.updates.subscribe(ctx)
+}
@@ -0,0 +1,368 @@
+package data
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Metric names of the series a probe's result is made of, named like those of the blackbox exporter.
+const (
+	ProbeSuccessMetric  = "probe_success"
+	ProbeDurationMetric = "probe_duration_seconds"
+	ProbeCertDaysMetric = "probe_cert_days_left"
+)
+
+// probeBodyLimit is how much of a response body is matched against an http probe's body_regex.
+const probeBodyLimit = 1 << 20
+
+// ProbeQuery describes a probe as the query it is run as, so its result is cached, served by /api/data and
+// recorded into history like the result of any other query.
+func ProbeQuery(probe config.DataProbe) config.PrometheusQuery {
+	return config.PrometheusQuery{
+		Name:          probe.Name,
+		Disabled:      probe.Disabled,
+		Source:        config.DataSourceProbe,
+		Query:         probe.Target,
+		TTL:           probe.Interval,
+		History:       probe.History,
+		RequireAuth:   probe.RequireAuth,
+		RequiredGroup: probe.RequiredGroup,
+	}
+}
+
+// ProbeQueries returns the queries of the enabled probes.
+func ProbeQueries(probes []config.DataProbe) []config.PrometheusQuery {
+	queries := make([]config.PrometheusQuery, 0, len(probes))
+	for _, probe := range probes {
+		if !probe.Disabled {
+			queries = append(queries, ProbeQuery(probe))
+		}
+	}
+	return queries
+}
+
+// probeOutcome is what a check found out about its target.
+type probeOutcome struct {
+	err        error     // why the target is down, nil when it is up
+	certExpiry time.Time // when the certificate the target presented expires, zero without TLS
+}
+
+// probeCheck runs one type of probe against its target.
+type probeCheck func(ctx context.Context, probe config.DataProbe) probeOutcome
+
+// ProbeSource answers the queries probes are run as by checking their targets. A target that is down is a
+// result, not an error: it is reported with probe_success 0 and the reason as a warning.
+type ProbeSource struct {
+	probes  map[string]config.DataProbe
+	checks  map[string]probeCheck
+	rootCAs map[string]*x509.CertPool // by name, of the probes that set a ca_file
+}
+
+func NewProbeSource(probes []config.DataProbe) (*ProbeSource, error) {
+	source := &ProbeSource{
+		probes:  make(map[string]config.DataProbe, len(probes)),
+		rootCAs: make(map[string]*x509.CertPool),
+	}
+	for _, probe := range probes {
+		source.probes[probe.Name] = probe
+		if probe.CAFile != "" {
+			pool, err := loadCABundle(probe.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("probe %s: %w", probe.Name, err)
+			}
+			source.rootCAs[probe.Name] = pool
+		}
+	}
+
+	source.checks = map[string]probeCheck{
+		config.DataProbeHTTP: source.checkHTTP,
+		config.DataProbeTCP:  source.checkTCP,
+		config.DataProbeDNS:  checkDNS,
+		config.DataProbeICMP: checkICMP,
+	}
+
+	return source, nil
+}
+
+func (p *ProbeSource) Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error) {
+	probe, ok := p.probes[query.Name]
+	if !ok {
+		return nil, fmt.Errorf("probe %s is not configured", query.Name)
+	}
+
+	check, ok := p.checks[probe.Type]
+	if !ok {
+		return nil, fmt.Errorf("probe type %q is not supported", probe.Type)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, probe.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	outcome := check(checkCtx, probe)
+	duration := time.Since(start)
+
+	var certDaysLeft float64
+	if !outcome.certExpiry.IsZero() {
+		certDaysLeft = math.Floor(time.Until(outcome.certExpiry).Hours() / 24)
+		if outcome.err == nil && probe.CertExpiryDays > 0 && certDaysLeft < float64(probe.CertExpiryDays) {
+			outcome.err = fmt.Errorf("certificate expires in %.0f days", certDaysLeft)
+		}
+	}
+
+	success := 1.0
+	if outcome.err != nil {
+		success = 0
+		addQueryWarnings(ctx, outcome.err.Error())
+	}
+
+	timestamp := model.TimeFromUnixNano(start.UnixNano())
+	sample := func(name string, value float64) *model.Sample {
+		return &model.Sample{
+			Metric: model.Metric{
+				model.MetricNameLabel: model.LabelValue(name),
+				"probe":               model.LabelValue(probe.Name),
+				"type":                model.LabelValue(probe.Type),
+				"target":              model.LabelValue(probe.Target),
+			},
+			Value:     model.SampleValue(value),
+			Timestamp: timestamp,
+		}
+	}
+
+	result := model.Vector{
+		sample(ProbeSuccessMetric, success),
+		sample(ProbeDurationMetric, duration.Seconds()),
+	}
+	if !outcome.certExpiry.IsZero() {
+		result = append(result, sample(ProbeCertDaysMetric, certDaysLeft))
+	}
+
+	return result, nil
+}
+
+// tlsConfig returns the TLS settings of http and tls probes, which verify certificates like any client would,
+// against the probe's CA bundle when it sets one and the system roots otherwise.
+func (p *ProbeSource) tlsConfig(probe config.DataProbe, serverName string) *tls.Config {
+	return &tls.Config{ServerName: serverName, RootCAs: p.rootCAs[probe.Name], MinVersion: tls.VersionTLS12}
+}
+
+// checkHTTP requests the target and checks the response's status and body.
+func (p *ProbeSource) checkHTTP(ctx context.Context, probe config.DataProbe) probeOutcome {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = p.tlsConfig(probe, "")
+	transport.DisableKeepAlives = true
+	client := &http.Client{Transport: transport}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.Target, nil)
+	if err != nil {
+		return probeOutcome{err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("User-Agent", "homelab-dashboard-probe")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return probeOutcome{err: fmt.Errorf("request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	var outcome probeOutcome
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		outcome.certExpiry = resp.TLS.PeerCertificates[0].NotAfter
+	}
+
+	if len(probe.ExpectedStatus) > 0 {
+		if !slices.Contains(probe.ExpectedStatus, resp.StatusCode) {
+			outcome.err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+			return outcome
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		outcome.err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return outcome
+	}
+
+	if probe.BodyRegex != "" {
+		pattern, err := regexp.Compile(probe.BodyRegex)
+		if err != nil {
+			outcome.err = fmt.Errorf("invalid body_regex: %w", err)
+			return outcome
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, probeBodyLimit))
+		if err != nil {
+			outcome.err = fmt.Errorf("failed to read body: %w", err)
+			return outcome
+		}
+		if !pattern.Match(body) {
+			outcome.err = fmt.Errorf("body does not match %s", probe.BodyRegex)
+		}
+	}
+
+	return outcome
+}
+
+// checkTCP connects to the target, completing a TLS handshake when the probe asks for one.
+func (p *ProbeSource) checkTCP(ctx context.Context, probe config.DataProbe) probeOutcome {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", probe.Target)
+	if err != nil {
+		return probeOutcome{err: fmt.Errorf("connection failed: %w", err)}
+	}
+	defer conn.Close()
+
+	if !probe.TLS {
+		return probeOutcome{}
+	}
+
+	host, _, _ := net.SplitHostPort(probe.Target)
+	tlsConn := tls.Client(conn, p.tlsConfig(probe, host))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return probeOutcome{err: fmt.Errorf("tls handshake failed: %w", err)}
+	}
+
+	var outcome probeOutcome
+	if certificates := tlsConn.ConnectionState().PeerCertificates; len(certificates) > 0 {
+		outcome.certExpiry = certificates[0].NotAfter
+	}
+	return outcome
+}
+
+// checkDNS resolves the target, through the probe's resolver when it has one, and fails without any records.
+func checkDNS(ctx context.Context, probe config.DataProbe) probeOutcome {
+	resolver := net.DefaultResolver
+	if probe.Resolver != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, probe.Resolver)
+			},
+		}
+	}
+
+	var records int
+	var err error
+	switch probe.RecordType {
+	case "AAAA":
+		var ips []net.IP
+		ips, err = resolver.LookupIP(ctx, "ip6", probe.Target)
+		records = len(ips)
+	case "CNAME":
+		var cname string
+		cname, err = resolver.LookupCNAME(ctx, probe.Target)
+		if cname != "" {
+			records = 1
+		}
+	case "MX":
+		var mx []*net.MX
+		mx, err = resolver.LookupMX(ctx, probe.Target)
+		records = len(mx)
+	case "NS":
+		var ns []*net.NS
+		ns, err = resolver.LookupNS(ctx, probe.Target)
+		records = len(ns)
+	case "TXT":
+		var txt []string
+		txt, err = resolver.LookupTXT(ctx, probe.Target)
+		records = len(txt)
+	default:
+		var ips []net.IP
+		ips, err = resolver.LookupIP(ctx, "ip4", probe.Target)
+		records = len(ips)
+	}
+
+	if err != nil {
+		return probeOutcome{err: fmt.Errorf("lookup failed: %w", err)}
+	}
+	if records == 0 {
+		return probeOutcome{err: fmt.Errorf("no %s records", probe.RecordType)}
+	}
+	return probeOutcome{}
+}
+
+// checkICMP sends an echo request and waits for the reply. It uses unprivileged ICMP sockets where the kernel
+// allows them (net.ipv4.ping_group_range) and raw sockets, which need CAP_NET_RAW, otherwise.
+func checkICMP(ctx context.Context, probe config.DataProbe) probeOutcome {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, probe.Target)
+	if err != nil {
+		return probeOutcome{err: fmt.Errorf("lookup failed: %w", err)}
+	}
+	if len(addrs) == 0 {
+		return probeOutcome{err: fmt.Errorf("%s has no addresses", probe.Target)}
+	}
+	ip := addrs[0].IP
+
+	networks := []string{"udp4", "ip4:icmp"}
+	listen, protocol := "0.0.0.0", 1
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if ip.To4() == nil {
+		networks = []string{"udp6", "ip6:ipv6-icmp"}
+		listen, protocol = "::", 58
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	var conn *icmp.PacketConn
+	for _, network := range networks {
+		if conn, err = icmp.ListenPacket(network, listen); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return probeOutcome{err: fmt.Errorf("failed to open icmp socket: %w", err)}
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	seq := int(time.Now().UnixNano() & 0xffff)
+	request, err := (&icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: seq, Data: []byte("homelab-dashboard")},
+	}).Marshal(nil)
+	if err != nil {
+		return probeOutcome{err: fmt.Errorf("failed to build echo request: %w", err)}
+	}
+
+	var dst net.Addr = &net.IPAddr{IP: ip}
+	if _, unprivileged := conn.LocalAddr().(*net.UDPAddr); unprivileged {
+		dst = &net.UDPAddr{IP: ip}
+	}
+	if _, err := conn.WriteTo(request, dst); err != nil {
+		return probeOutcome{err: fmt.Errorf("failed to send echo request: %w", err)}
+	}
+
+	// Unprivileged sockets rewrite the echo ID, so replies are matched on their sequence number
+	buffer := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return probeOutcome{err: fmt.Errorf("no echo reply: %w", err)}
+		}
+
+		reply, err := icmp.ParseMessage(protocol, buffer[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.Seq == seq {
+			return probeOutcome{}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package data
+
+import (
+	"context"
+	"encoding/pem"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// probeValues maps the metric names of a probe's result onto their values.
+func probeValues(t *testing.T, value model.Value) map[string]float64 {
+	t.Helper()
+
+	vector, ok := value.(model.Vector)
+	require.True(t, ok, "expected a vector, got %T", value)
+
+	values := make(map[string]float64, len(vector))
+	for _, sample := range vector {
+		values[string(sample.Metric[model.MetricNameLabel])] = float64(sample.Value)
+	}
+	return values
+}
+
+func TestProbeSource_HTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		case "/login":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		probe           config.DataProbe
+		expectedSuccess float64
+		expectedWarning string
+	}{
+		{
+			name:            "2xx is up by default",
+			probe:           config.DataProbe{Target: server.URL + "/health"},
+			expectedSuccess: 1,
+		},
+		{
+			name:            "body matching the regex is up",
+			probe:           config.DataProbe{Target: server.URL + "/health", BodyRegex: `"status":\s*"ok"`},
+			expectedSuccess: 1,
+		},
+		{
+			name:            "body not matching the regex is down",
+			probe:           config.DataProbe{Target: server.URL + "/health", BodyRegex: `degraded`},
+			expectedWarning: "body does not match degraded",
+		},
+		{
+			name:            "expected status is up",
+			probe:           config.DataProbe{Target: server.URL + "/login", ExpectedStatus: []int{200, 401}},
+			expectedSuccess: 1,
+		},
+		{
+			name:            "unexpected status is down",
+			probe:           config.DataProbe{Target: server.URL + "/down"},
+			expectedWarning: "unexpected status 503",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.probe.Name, tt.probe.Type, tt.probe.Timeout = "web", config.DataProbeHTTP, 5*time.Second
+			source, err := NewProbeSource([]config.DataProbe{tt.probe})
+			require.NoError(t, err)
+
+			ctx, warnings := withQueryWarnings(context.Background())
+			value, err := source.Query(ctx, ProbeQuery(tt.probe))
+			require.NoError(t, err)
+
+			values := probeValues(t, value)
+			assert.Equal(t, tt.expectedSuccess, values[ProbeSuccessMetric])
+			assert.Contains(t, values, ProbeDurationMetric)
+			assert.NotContains(t, values, ProbeCertDaysMetric)
+
+			if tt.expectedWarning == "" {
+				assert.Empty(t, warnings.list())
+			} else {
+				assert.Equal(t, []string{tt.expectedWarning}, warnings.list())
+			}
+		})
+	}
+}
+
+func TestProbeSource_HTTPSCertificateExpiry(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+
+	probes := []config.DataProbe{
+		{Name: "https", Type: config.DataProbeHTTP, Target: server.URL, Timeout: 5 * time.Second, CAFile: caFile},
+		{Name: "expiring", Type: config.DataProbeHTTP, Target: server.URL, Timeout: 5 * time.Second, CertExpiryDays: 365000, CAFile: caFile},
+		{Name: "tls", Type: config.DataProbeTCP, Target: server.Listener.Addr().String(), Timeout: 5 * time.Second, TLS: true, CAFile: caFile},
+	}
+
+	source, err := NewProbeSource(probes)
+	require.NoError(t, err)
+
+	expectedDays := float64(int(time.Until(server.Certificate().NotAfter).Hours() / 24))
+
+	for _, probe := range probes {
+		t.Run(probe.Name, func(t *testing.T) {
+			ctx, warnings := withQueryWarnings(context.Background())
+			value, err := source.Query(ctx, ProbeQuery(probe))
+			require.NoError(t, err)
+
+			values := probeValues(t, value)
+			assert.Equal(t, expectedDays, values[ProbeCertDaysMetric])
+
+			if probe.CertExpiryDays > 0 {
+				assert.Equal(t, 0.0, values[ProbeSuccessMetric])
+				require.Len(t, warnings.list(), 1)
+				assert.True(t, strings.HasPrefix(warnings.list()[0], "certificate expires in"))
+			} else {
+				assert.Equal(t, 1.0, values[ProbeSuccessMetric], "warnings: %v", warnings.list())
+			}
+		})
+	}
+
+	t.Run("untrusted certificate is down", func(t *testing.T) {
+		probe := probes[0]
+		probe.CAFile = ""
+		untrusted, err := NewProbeSource([]config.DataProbe{probe})
+		require.NoError(t, err)
+		value, err := untrusted.Query(context.Background(), ProbeQuery(probe))
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, probeValues(t, value)[ProbeSuccessMetric])
+	})
+}
+
+func TestProbeSource_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	open := listener.Addr().String()
+
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := closedListener.Addr().String()
+	require.NoError(t, closedListener.Close())
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	defer listener.Close()
+
+	probes := []config.DataProbe{
+		{Name: "open", Type: config.DataProbeTCP, Target: open, Timeout: 5 * time.Second},
+		{Name: "closed", Type: config.DataProbeTCP, Target: closed, Timeout: 5 * time.Second},
+	}
+	source, err := NewProbeSource(probes)
+	require.NoError(t, err)
+
+	value, err := source.Query(context.Background(), ProbeQuery(probes[0]))
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, probeValues(t, value)[ProbeSuccessMetric])
+
+	value, err = source.Query(context.Background(), ProbeQuery(probes[1]))
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, probeValues(t, value)[ProbeSuccessMetric])
+
+	_, err = source.Query(context.Background(), config.PrometheusQuery{Name: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestService_ProbeResultsAreCachedAndKept(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	cache, _ := NewMemCache(&config.Config{}, logger)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	probes := []config.DataProbe{
+		{Name: "jellyfin", Type: config.DataProbeHTTP, Target: server.URL, Interval: time.Minute, Timeout: 5 * time.Second, RequireAuth: true, RequiredGroup: "admins"},
+	}
+
+	source, err := NewProbeSource(probes)
+	require.NoError(t, err)
+	service := NewService(map[string]DataSource{config.DataSourceProbe: source}, cache, logger, nil).WithProbes(probes)
+	require.NoError(t, service.ExecuteQuery(ctx, nil, ProbeQuery(probes[0])))
+
+	cached, ok := cache.Get(ctx, "jellyfin")
+	require.True(t, ok)
+	assert.Equal(t, "vector", cached.ValueType)
+	assert.Equal(t, []string{"unexpected status 502"}, cached.Warnings)
+	assert.True(t, cached.RequireAuth)
+	assert.Equal(t, "admins", cached.RequiredGroup)
+
+	cache.Set(ctx, "removed_probe", CachedData{Name: "removed_probe"})
+	assert.Equal(t, 1, service.EvictRemovedQueries(ctx, cache))
+
+	_, ok = cache.Get(ctx, "jellyfin")
+	assert.True(t, ok)
+}
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"math"
+	"strconv"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// rangeWindowKeyPrefix namespaces cached results of ad-hoc range queries.
+const rangeWindowKeyPrefix = "range:"
+
+// defaultRangePoints is how many points a range query returns when no step is requested.
+const defaultRangePoints = 250
+
+// ErrInvalidRangeWindow is returned for a requested window that cannot be queried.
+var ErrInvalidRangeWindow = errors.New("invalid range window")
+
+// RangeQuerySource is implemented by sources that can run a query over an arbitrary window.
+type RangeQuerySource interface {
+	QueryRange(ctx context.Context, query config.PrometheusQuery, r v1.Range) (model.Value, error)
+}
+
+// RangeLimits returns the longest window and the most points an ad-hoc range query may request.
+func RangeLimits(query config.PrometheusQuery, limits *config.DataRangeQueries) (time.Duration, int) {
+	if limits == nil {
+		limits = config.DefaultDataRangeQueries
+	}
+
+	maxRange, maxPoints := limits.MaxRange, limits.MaxPoints
+	if query.MaxRange > 0 {
+		maxRange = query.MaxRange
+	}
+	if query.MaxPoints > 0 {
+		maxPoints = query.MaxPoints
+	}
+
+	return maxRange, maxPoints
+}
+
+// NormalizeRangeWindow aligns a requested window to its step, so requests for nearly the same window share a
+// cached result, and checks it against the query's limits. A zero step picks one giving about
+// defaultRangePoints points. The end is clamped to now, since the future has no samples.
+func NormalizeRangeWindow(start, end time.Time, step time.Duration, now time.Time, maxRange time.Duration, maxPoints int) (v1.Range, error) {
+	if end.After(now) {
+		end = now
+	}
+	if !end.After(start) {
+		return v1.Range{}, fmt.Errorf("%w: end must be after start", ErrInvalidRangeWindow)
+	}
+
+	window := end.Sub(start)
+	if window > maxRange {
+		return v1.Range{}, fmt.Errorf("%w: window of %s exceeds the maximum of %s", ErrInvalidRangeWindow, window, maxRange)
+	}
+
+	if step == 0 {
+		step = time.Duration(math.Ceil(window.Seconds()/float64(min(defaultRangePoints, maxPoints-1)))) * time.Second
+	}
+	step = max(time.Second, step.Truncate(time.Second))
+
+	stepSeconds := int64(step.Seconds())
+	alignedStart := time.Unix(start.Unix()/stepSeconds*stepSeconds, 0)
+	alignedEnd := time.Unix((end.Unix()+stepSeconds-1)/stepSeconds*stepSeconds, 0)
+
+	if points := alignedEnd.Sub(alignedStart)/step + 1; int(points) > maxPoints {
+		return v1.Range{}, fmt.Errorf("%w: %d points exceed the maximum of %d, use a larger step", ErrInvalidRangeWindow, points, maxPoints)
+	}
+
+	return v1.Range{Start: alignedStart.UTC(), End: alignedEnd.UTC(), Step: step}, nil
+}
+
+// rangeWindowKey identifies a query's result for a normalised window.
+func rangeWindowKey(query config.PrometheusQuery, r v1.Range) string {
+	return rangeWindowKeyPrefix + QueryCacheKey(query) + ":" + strconv.FormatInt(r.Start.Unix(), 10) + ":" +
+		strconv.FormatInt(r.End.Unix(), 10) + ":" + strconv.FormatInt(int64(r.Step.Seconds()), 10)
+}
+
+// QueryRangeWindow runs a prometheus query over a normalised window. Results are cached for limits.CacheTTL
+// under the window, and uncached windows count against the caller's rate limit; beyond it
+// ErrOnDemandRateLimited is returned along with how long until the next one is allowed.
+func (s *Service) QueryRangeWindow(ctx context.Context, query config.PrometheusQuery, r v1.Range, caller string, limits *config.DataRangeQueries) (CachedData, time.Duration, error) {
+	if limits == nil {
+		limits = config.DefaultDataRangeQueries
+	}
+
+	key := rangeWindowKey(query, r)
+	if s.cache != nil {
+		if raw, err := s.cache.GetKey(ctx, key); err == nil && raw != "" {
+			var cached CachedData
+			if err := json.Unmarshal([]byte(raw), &cached); err == nil {
+				return cached, 0, nil
+			}
+		}
+
+		allowed, retryAfter, err := s.cache.SlidingWindowAllow(ctx, "ratelimit:data:range:"+caller, limits.RequestsPerWindow, limits.Window)
+		if err != nil {
+			return CachedData{}, 0, fmt.Errorf("failed to check range query rate limit: %w", err)
+		}
+		if !allowed {
+			return CachedData{}, retryAfter, ErrOnDemandRateLimited
+		}
+	}
+
+	source, ok := s.sources[querySource(query)].(RangeQuerySource)
+	if !ok {
+		return CachedData{}, 0, fmt.Errorf("query %s does not support range queries", query.Name)
+	}
+
+	rangeCtx, warnings := withQueryWarnings(ctx)
+	result, err := source.QueryRange(rangeCtx, query, r)
+	if err != nil {
+		return CachedData{}, 0, fmt.Errorf("failed to execute range query %s: %w", query.Name, err)
+	}
+
+	// Reducing would collapse the window the caller asked for, so only the other transforms apply
+	query.Transform = rangeTransforms(query.Transform)
+	cached := s.prepareCacheData(query.Name, result, query)
+	cached.Warnings = warnings.list()
+	cached.TTL = limits.CacheTTL
+	cached.ExpiresAt = cached.Timestamp.Add(limits.CacheTTL)
+
+	if s.cache != nil && limits.CacheTTL > 0 {
+		if encoded, err := json.Marshal(cached); err == nil {
+			if err := s.cache.SetKey(ctx, key, string(encoded), limits.CacheTTL); err != nil {
+				s.logger.Warn("failed to cache range query result", "query", query.Name, "error", err)
+			}
+		}
+	}
+
+	return cached, 0, nil
+}
@@ -0,0 +1,148 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeSource records the range queries it answers.
+type rangeSource struct {
+	*countingSource
+	ranges []v1.Range
+	query  string
+}
+
+func (r *rangeSource) QueryRange(ctx context.Context, query config.PrometheusQuery, window v1.Range) (model.Value, error) {
+	r.ranges = append(r.ranges, window)
+	r.query = query.Query
+	return model.Matrix{}, nil
+}
+
+func TestNormalizeRangeWindow(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		start, end    time.Time
+		step          time.Duration
+		expectedRange v1.Range
+		expectError   bool
+	}{
+		{
+			name:          "ShouldAlignToStep",
+			start:         time.Date(2026, 3, 10, 1, 2, 3, 0, time.UTC),
+			end:           time.Date(2026, 3, 10, 4, 58, 1, 0, time.UTC),
+			step:          5 * time.Minute,
+			expectedRange: v1.Range{Start: time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 10, 5, 0, 0, 0, time.UTC), Step: 5 * time.Minute},
+		},
+		{
+			name:          "ShouldPickStepWhenOmitted",
+			start:         now.Add(-250 * time.Minute),
+			end:           now,
+			expectedRange: v1.Range{Start: now.Add(-250 * time.Minute), End: now, Step: time.Minute},
+		},
+		{
+			name:          "ShouldClampEndToNow",
+			start:         now.Add(-time.Hour),
+			end:           now.Add(time.Hour),
+			step:          time.Minute,
+			expectedRange: v1.Range{Start: now.Add(-time.Hour), End: now, Step: time.Minute},
+		},
+		{
+			name:        "ShouldRejectEndBeforeStart",
+			start:       now.Add(-time.Hour),
+			end:         now.Add(-2 * time.Hour),
+			step:        time.Minute,
+			expectError: true,
+		},
+		{
+			name:        "ShouldRejectWindowAboveMaximum",
+			start:       now.Add(-48 * time.Hour),
+			end:         now,
+			step:        time.Hour,
+			expectError: true,
+		},
+		{
+			name:        "ShouldRejectTooManyPoints",
+			start:       now.Add(-24 * time.Hour),
+			end:         now,
+			step:        time.Second,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := NormalizeRangeWindow(tt.start, tt.end, tt.step, now, 24*time.Hour, 1000)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidRangeWindow)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRange, window)
+		})
+	}
+}
+
+func TestRangeLimits(t *testing.T) {
+	limits := &config.DataRangeQueries{MaxRange: 24 * time.Hour, MaxPoints: 500}
+
+	maxRange, maxPoints := RangeLimits(config.PrometheusQuery{}, limits)
+	assert.Equal(t, 24*time.Hour, maxRange)
+	assert.Equal(t, 500, maxPoints)
+
+	maxRange, maxPoints = RangeLimits(config.PrometheusQuery{MaxRange: time.Hour, MaxPoints: 60}, limits)
+	assert.Equal(t, time.Hour, maxRange)
+	assert.Equal(t, 60, maxPoints)
+}
+
+func TestService_QueryRangeWindow(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewMemCache(&config.Config{}, slog.Default())
+	require.NoError(t, err)
+
+	source := &rangeSource{countingSource: newCountingSource(0)}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: source}, cache, logger, nil)
+
+	limits := &config.DataRangeQueries{CacheTTL: time.Minute, RequestsPerWindow: 2, Window: time.Minute}
+	query := ExpandQuery(config.PrometheusQuery{Name: "pods", Query: `kube_pod_info{namespace="$namespace"}`}, map[string]string{"namespace": "media"})
+	first := v1.Range{Start: time.Unix(0, 0), End: time.Unix(3600, 0), Step: time.Minute}
+	second := v1.Range{Start: time.Unix(3600, 0), End: time.Unix(7200, 0), Step: time.Minute}
+	third := v1.Range{Start: time.Unix(7200, 0), End: time.Unix(10800, 0), Step: time.Minute}
+
+	result, _, err := service.QueryRangeWindow(ctx, query, first, "alice", limits)
+	require.NoError(t, err)
+	assert.Equal(t, "pods", result.Name)
+	assert.Equal(t, "matrix", result.ValueType)
+	assert.Equal(t, `kube_pod_info{namespace="media"}`, source.query)
+
+	_, _, err = service.QueryRangeWindow(ctx, query, first, "alice", limits)
+	require.NoError(t, err)
+	assert.Len(t, source.ranges, 1, "a cached window should not be queried again")
+
+	_, _, err = service.QueryRangeWindow(ctx, query, second, "alice", limits)
+	require.NoError(t, err)
+
+	_, retryAfter, err := service.QueryRangeWindow(ctx, query, third, "alice", limits)
+	assert.ErrorIs(t, err, ErrOnDemandRateLimited)
+	assert.Positive(t, retryAfter)
+
+	_, _, err = service.QueryRangeWindow(ctx, query, first, "alice", limits)
+	assert.NoError(t, err, "cached windows should be served even when rate limited")
+
+	_, _, err = service.QueryRangeWindow(ctx, query, third, "bob", limits)
+	assert.NoError(t, err, "the limit applies per caller")
+	assert.Len(t, source.ranges, 3)
+}
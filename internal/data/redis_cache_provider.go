@@ -7,6 +7,8 @@ import (
 	"homelab-dashboard/internal/config"
 	"homelab-dashboard/internal/metrics"
 	"log/slog"
+	"math/rand/v2"
+	"sync"
 	"time"
 
 	"encoding/json"
@@ -21,15 +23,45 @@ type RedisCacheClient interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
 	GetDel(ctx context.Context, key string) *redis.StringCmd
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
 	Keys(ctx context.Context, pattern string) *redis.StringSliceCmd
 	Ping(ctx context.Context) *redis.StatusCmd
 	PoolStats() *redis.PoolStats
 	Close() error
 }
 
+// slidingWindowScript keeps one sorted-set member per hit, scored by its time in milliseconds.
+// It returns {1, 0} when the hit was recorded, or {0, ms until the oldest hit expires} when over the limit.
+var slidingWindowScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) >= limit then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	return {0, tonumber(oldest[2]) + window - now}
+end
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, 0}
+`
+
+// slidingWindowReleaseScript removes the newest hit recorded by slidingWindowScript.
+var slidingWindowReleaseScript = `
+redis.call('ZPOPMAX', KEYS[1])
+return 1
+`
+
 type RedisCache struct {
 	client RedisCacheClient
 	logger *slog.Logger
+
+	// A single pub/sub connection per replica feeds updates to every local subscriber.
+	updates       updateBroker
+	subscribeOnce sync.Once
+	pubsub        *redis.PubSub
 }
 
 // NewRedisCache creates a new Redis-backed cache
@@ -83,6 +115,9 @@ func (r *RedisCache) key(queryName string) string {
 
 // ClosePool closes the Redis connection pool
 func (r *RedisCache) ClosePool() error {
+	if r.pubsub != nil {
+		_ = r.pubsub.Close()
+	}
 	return r.client.Close()
 }
 
@@ -186,3 +221,50 @@ func (r *RedisCache) SetKey(ctx context.Context, key string, value interface{},
 func (r *RedisCache) GetDelKey(ctx context.Context, key string) (string, error) {
 	return r.client.GetDel(ctx, key).Result()
 }
+
+// SlidingWindowAllow records a hit on key if fewer than limit hits fall within the last window.
+// The check and the insert run in one script so concurrent replicas cannot both take the last slot.
+func (r *RedisCache) SlidingWindowAllow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := time.Now()
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), rand.Int64())
+
+	result, err := r.client.Eval(ctx, slidingWindowScript, []string{key}, now.UnixMilli(), window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("sliding window script failed: %w", err)
+	}
+
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected sliding window result %v", result)
+	}
+
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// SlidingWindowRelease removes the most recent hit on key.
+func (r *RedisCache) SlidingWindowRelease(ctx context.Context, key string) error {
+	if err := r.client.Eval(ctx, slidingWindowReleaseScript, []string{key}).Err(); err != nil {
+		return fmt.Errorf("sliding window release script failed: %w", err)
+	}
+	return nil
+}
+
+// PublishUpdate notifies the subscribers on every replica that a query's cached data changed.
+func (r *RedisCache) PublishUpdate(ctx context.Context, queryName string) error {
+	return r.client.Publish(ctx, cacheUpdatesChannel, queryName).Err()
+}
+
+// SubscribeUpdates returns the names of queries whose cached data changes on any replica, until ctx is done.
+// The Redis subscription is opened on first use and reconnects on its own if the connection drops.
+func (r *RedisCache) SubscribeUpdates(ctx context.Context) <-chan string {
+	r.subscribeOnce.Do(func() {
+		r.pubsub = r.client.Subscribe(context.Background(), cacheUpdatesChannel)
+
+		go func() {
+			for msg := range r.pubsub.Channel() {
+				r.updates.publish(msg.Payload)
+			}
+		}()
+	})
+
+	return r.updates.subscribe(ctx)
+}
@@ -23,6 +23,21 @@ func (m *MockRedisCacheClient) GetDel(ctx context.Context, key string) *redis.St
 	return args.Get(0).(*redis.StringCmd)
 }
 
+func (m *MockRedisCacheClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	callArgs := m.Called(ctx, script, keys, args)
+	return callArgs.Get(0).(*redis.Cmd)
+}
+
+func (m *MockRedisCacheClient) Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd {
+	args := m.Called(ctx, channel, message)
+	return args.Get(0).(*redis.IntCmd)
+}
+
+func (m *MockRedisCacheClient) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	args := m.Called(ctx, channels)
+	return args.Get(0).(*redis.PubSub)
+}
+
 func (m *MockRedisCacheClient) Get(ctx context.Context, key string) *redis.StringCmd {
 	args := m.Called(ctx, key)
 	return args.Get(0).(*redis.StringCmd)
@@ -91,6 +106,17 @@ func createIntCmd(result int64, err error) *redis.IntCmd {
 	return cmd
 }
 
+// Helper function to create a Cmd, as returned by Eval
+func createCmd(result interface{}, err error) *redis.Cmd {
+	cmd := redis.NewCmd(context.Background())
+	if err != nil {
+		cmd.SetErr(err)
+	} else {
+		cmd.SetVal(result)
+	}
+	return cmd
+}
+
 // Helper function to create a StringSliceCmd
 func createStringSliceCmd(result []string, err error) *redis.StringSliceCmd {
 	cmd := redis.NewStringSliceCmd(context.Background())
@@ -433,3 +459,88 @@ func TestRedisCache_ClosePool(t *testing.T) {
 		mockClient.AssertExpectations(t)
 	})
 }
+
+func TestRedisCache_SlidingWindowAllow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hit recorded", func(t *testing.T) {
+		mockClient := new(MockRedisCacheClient)
+		cache := &RedisCache{
+			client: mockClient,
+			logger: slog.Default(),
+		}
+
+		mockClient.On("Eval", ctx, slidingWindowScript, []string{"ratelimit:test"}, mock.Anything).
+			Return(createCmd([]interface{}{int64(1), int64(0)}, nil))
+
+		allowed, retryAfter, err := cache.SlidingWindowAllow(ctx, "ratelimit:test", 5, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Zero(t, retryAfter)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		mockClient := new(MockRedisCacheClient)
+		cache := &RedisCache{
+			client: mockClient,
+			logger: slog.Default(),
+		}
+
+		mockClient.On("Eval", ctx, slidingWindowScript, []string{"ratelimit:test"}, mock.Anything).
+			Return(createCmd([]interface{}{int64(0), int64(1500)}, nil))
+
+		allowed, retryAfter, err := cache.SlidingWindowAllow(ctx, "ratelimit:test", 5, time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, 1500*time.Millisecond, retryAfter)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		mockClient := new(MockRedisCacheClient)
+		cache := &RedisCache{
+			client: mockClient,
+			logger: slog.Default(),
+		}
+
+		mockClient.On("Eval", ctx, slidingWindowScript, []string{"ratelimit:test"}, mock.Anything).
+			Return(createCmd(nil, errors.New("connection error")))
+
+		allowed, _, err := cache.SlidingWindowAllow(ctx, "ratelimit:test", 5, time.Minute)
+		assert.Error(t, err)
+		assert.False(t, allowed)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestRedisCache_SlidingWindowRelease(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockRedisCacheClient)
+	cache := &RedisCache{
+		client: mockClient,
+		logger: slog.Default(),
+	}
+
+	mockClient.On("Eval", ctx, slidingWindowReleaseScript, []string{"ratelimit:test"}, mock.Anything).
+		Return(createCmd(int64(1), nil))
+
+	assert.NoError(t, cache.SlidingWindowRelease(ctx, "ratelimit:test"))
+	mockClient.AssertExpectations(t)
+}
+
+func TestRedisCache_PublishUpdate(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockRedisCacheClient)
+	cache := &RedisCache{
+		client: mockClient,
+		logger: slog.Default(),
+	}
+
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(2)
+	mockClient.On("Publish", ctx, cacheUpdatesChannel, "cpu_usage").Return(cmd)
+
+	assert.NoError(t, cache.PublishUpdate(ctx, "cpu_usage"))
+	mockClient.AssertExpectations(t)
+}
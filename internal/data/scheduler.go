@@ -0,0 +1,289 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// queryStatusKeyPrefix namespaces the schedule status the scheduler publishes for each query.
+const queryStatusKeyPrefix = "scheduler:query:"
+
+// QueryScheduleStatus is the last published scheduling state of one query. It is stored in the cache so
+// every replica can report it, not only the leader running the scheduler.
+type QueryScheduleStatus struct {
+	Name        string            `json:"name"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Source      string            `json:"source"`
+	TTLSeconds  float64           `json:"ttl_seconds"`
+	LastRun     *time.Time        `json:"last_run,omitempty"`
+	LastSuccess *time.Time        `json:"last_success,omitempty"`
+	LastError   string            `json:"last_error,omitempty"`
+	NextRun     *time.Time        `json:"next_run,omitempty"`
+}
+
+// GetQueryScheduleStatus returns the schedule status last published for a query, identified by its cache key
+// (see QueryCacheKey).
+func GetQueryScheduleStatus(ctx context.Context, cache Provider, key string) (QueryScheduleStatus, bool) {
+	raw, err := cache.GetKey(ctx, queryStatusKeyPrefix+key)
+	if err != nil || raw == "" {
+		return QueryScheduleStatus{}, false
+	}
+
+	var status QueryScheduleStatus
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		return QueryScheduleStatus{}, false
+	}
+
+	return status, true
+}
+
+// scheduledQuery tracks when a query is next due.
+type scheduledQuery struct {
+	query   config.PrometheusQuery
+	ttl     time.Duration
+	nextRun time.Time
+	status  QueryScheduleStatus
+	running bool
+}
+
+// Scheduler refreshes each query once its own TTL has passed, instead of refreshing every query at the rate of
+// the shortest TTL. Due queries run concurrently on a bounded number of workers, and each run is delayed by a
+// little jitter so queries sharing a TTL do not all hit the backend at once.
+type Scheduler struct {
+	service     *Service
+	cache       Provider
+	logger      *slog.Logger
+	workers     int
+	maxJitter   time.Duration
+	fallbackTTL time.Duration
+
+	mu      sync.Mutex
+	entries []*scheduledQuery
+	wake    chan struct{}
+}
+
+// NewScheduler schedules the service's enabled queries. Queries without a TTL are refreshed every fallbackTTL.
+func NewScheduler(service *Service, cache Provider, cfg *config.DataSchedulerConfig, fallbackTTL time.Duration, logger *slog.Logger) *Scheduler {
+	if cfg == nil {
+		cfg = config.DefaultDataSchedulerConfig
+	}
+
+	return &Scheduler{
+		service:     service,
+		cache:       cache,
+		logger:      logger,
+		workers:     max(1, cfg.Workers),
+		maxJitter:   cfg.MaxJitter,
+		fallbackTTL: fallbackTTL,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Run refreshes queries as they fall due until ctx is done. Results still fresh in the cache, e.g. written
+// before a restart or by a previous leader, are not fetched again until they expire.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.cache == nil {
+		return fmt.Errorf("cache is nil, cannot schedule queries")
+	}
+
+	if evicted := s.service.EvictRemovedQueries(ctx, s.cache); evicted > 0 {
+		s.logger.Info("evicted cached results of removed queries", "count", evicted)
+	}
+
+	s.schedule(ctx, time.Now())
+
+	semaphore := make(chan struct{}, s.workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		case <-s.wake:
+		}
+
+		now := time.Now()
+		for _, entry := range s.claimDue(now) {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				s.release(entry)
+				return ctx.Err()
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				s.runQuery(ctx, entry)
+			}()
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(s.untilNextDue(time.Now()))
+	}
+}
+
+// schedule sets the first run of every query, either immediately (with jitter) or when its cached result expires.
+func (s *Scheduler) schedule(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queries := s.service.Queries()
+	s.entries = make([]*scheduledQuery, 0, len(queries))
+
+	for _, query := range queries {
+		ttl := query.TTL
+		if ttl <= 0 {
+			ttl = s.fallbackTTL
+		}
+		if ttl <= 0 {
+			ttl = 30 * time.Second
+		}
+
+		entry := &scheduledQuery{
+			query:   query,
+			ttl:     ttl,
+			nextRun: now.Add(s.jitter(ttl)),
+			status: QueryScheduleStatus{
+				Name:       query.Name,
+				Variables:  query.Variables,
+				Source:     querySource(query),
+				TTLSeconds: ttl.Seconds(),
+			},
+		}
+
+		key := QueryCacheKey(query)
+
+		if previous, ok := GetQueryScheduleStatus(ctx, s.cache, key); ok {
+			entry.status.LastRun = previous.LastRun
+			entry.status.LastSuccess = previous.LastSuccess
+			entry.status.LastError = previous.LastError
+		}
+
+		if cached, ok := s.cache.Get(ctx, key); ok {
+			if expires := cached.Timestamp.Add(ttl); expires.After(entry.nextRun) {
+				entry.nextRun = expires
+			}
+		}
+
+		nextRun := entry.nextRun
+		entry.status.NextRun = &nextRun
+
+		s.entries = append(s.entries, entry)
+		s.publishStatus(ctx, entry.status, ttl)
+	}
+}
+
+// claimDue marks every due query that is not already running as running and returns them.
+func (s *Scheduler) claimDue(now time.Time) []*scheduledQuery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*scheduledQuery
+	for _, entry := range s.entries {
+		if !entry.running && !entry.nextRun.After(now) {
+			entry.running = true
+			due = append(due, entry)
+		}
+	}
+	return due
+}
+
+func (s *Scheduler) release(entry *scheduledQuery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.running = false
+}
+
+// untilNextDue returns how long until the earliest query that is not running falls due. Running queries
+// wake the scheduler when they finish, so an hour is only waited when nothing is scheduled at all.
+func (s *Scheduler) untilNextDue(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := time.Hour
+	for _, entry := range s.entries {
+		if !entry.running {
+			next = min(next, entry.nextRun.Sub(now))
+		}
+	}
+	return max(0, next)
+}
+
+func (s *Scheduler) runQuery(ctx context.Context, entry *scheduledQuery) {
+	started := time.Now()
+	err := s.service.ExecuteQuery(ctx, s.cache, entry.query)
+	finished := time.Now()
+
+	if ctx.Err() != nil {
+		s.release(entry)
+		return
+	}
+
+	s.mu.Lock()
+	entry.running = false
+	entry.status.LastRun = &started
+	if err != nil {
+		entry.status.LastError = err.Error()
+	} else {
+		entry.status.LastSuccess = &finished
+		entry.status.LastError = ""
+	}
+	entry.nextRun = started.Add(entry.ttl + s.jitter(entry.ttl))
+	nextRun := entry.nextRun
+	entry.status.NextRun = &nextRun
+	status := entry.status
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("failed to execute query", "query", entry.query.Name, "error", err)
+	}
+
+	recordCacheSize(ctx, s.cache)
+	s.publishStatus(ctx, status, entry.ttl)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// jitter returns a random delay of up to a tenth of the TTL, capped at the configured maximum.
+func (s *Scheduler) jitter(ttl time.Duration) time.Duration {
+	limit := min(s.maxJitter, ttl/10)
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
+
+// publishStatus stores a query's status for long enough to outlive a few missed runs.
+func (s *Scheduler) publishStatus(ctx context.Context, status QueryScheduleStatus, ttl time.Duration) {
+	encoded, err := json.Marshal(status)
+	if err != nil {
+		s.logger.Error("failed to encode query schedule status", "query", status.Name, "error", err)
+		return
+	}
+
+	key := QueryCacheKey(config.PrometheusQuery{Name: status.Name, Variables: status.Variables})
+	if err := s.cache.SetKey(ctx, queryStatusKeyPrefix+key, string(encoded), max(3*ttl, 10*time.Minute)); err != nil {
+		s.logger.Warn("failed to publish query schedule status", "query", status.Name, "error", err)
+	}
+}
@@ -0,0 +1,184 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSource records how often and how concurrently each query runs.
+type countingSource struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	active   int
+	peak     int
+	delay    time.Duration
+	failures map[string]bool
+}
+
+func newCountingSource(delay time.Duration) *countingSource {
+	return &countingSource{calls: make(map[string]int), failures: make(map[string]bool), delay: delay}
+}
+
+func (c *countingSource) Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error) {
+	c.mu.Lock()
+	c.calls[query.Name]++
+	c.active++
+	c.peak = max(c.peak, c.active)
+	fail := c.failures[query.Name]
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	c.active--
+	c.mu.Unlock()
+
+	if fail {
+		return nil, fmt.Errorf("backend unavailable")
+	}
+	return &model.Scalar{Value: model.SampleValue(time.Now().UnixNano())}, nil
+}
+
+func (c *countingSource) count(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[name]
+}
+
+func runScheduler(t *testing.T, source *countingSource, cache Provider, queries []config.PrometheusQuery, cfg *config.DataSchedulerConfig, duration time.Duration) {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: source}, cache, logger, queries)
+	scheduler := NewScheduler(service, cache, cfg, time.Minute, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+
+	err := scheduler.Run(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestScheduler_RunsEachQueryOnItsOwnTTL(t *testing.T) {
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	source := newCountingSource(0)
+
+	queries := []config.PrometheusQuery{
+		{Name: "fast", Query: "up", TTL: 50 * time.Millisecond},
+		{Name: "slow", Query: "up", TTL: time.Hour},
+		{Name: "disabled", Query: "up", TTL: 50 * time.Millisecond, Disabled: true},
+	}
+
+	runScheduler(t, source, cache, queries, &config.DataSchedulerConfig{Workers: 2}, 400*time.Millisecond)
+
+	assert.GreaterOrEqual(t, source.count("fast"), 5, "fast query should be refreshed repeatedly")
+	assert.Equal(t, 1, source.count("slow"), "slow query should only run once")
+	assert.Zero(t, source.count("disabled"))
+
+	status, ok := GetQueryScheduleStatus(context.Background(), cache, "slow")
+	require.True(t, ok)
+	require.NotNil(t, status.LastSuccess)
+	require.NotNil(t, status.NextRun)
+	assert.WithinDuration(t, status.LastRun.Add(time.Hour), *status.NextRun, time.Second)
+	assert.Equal(t, config.DataSourcePrometheus, status.Source)
+	assert.Equal(t, time.Hour.Seconds(), status.TTLSeconds)
+}
+
+func TestScheduler_SkipsResultsStillFreshInCache(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	cachedAt := time.Now().Add(-10 * time.Minute)
+	cache.Set(ctx, "capacity", CachedData{Name: "capacity", ValueType: "scalar", Timestamp: cachedAt})
+
+	source := newCountingSource(0)
+	queries := []config.PrometheusQuery{{Name: "capacity", Query: "up", TTL: time.Hour}}
+
+	runScheduler(t, source, cache, queries, &config.DataSchedulerConfig{Workers: 1}, 100*time.Millisecond)
+
+	assert.Zero(t, source.count("capacity"), "a fresh cached result should not be fetched again")
+
+	status, ok := GetQueryScheduleStatus(ctx, cache, "capacity")
+	require.True(t, ok)
+	require.NotNil(t, status.NextRun)
+	assert.WithinDuration(t, cachedAt.Add(time.Hour), *status.NextRun, time.Second)
+	assert.Nil(t, status.LastRun)
+}
+
+func TestScheduler_LimitsConcurrentQueries(t *testing.T) {
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	source := newCountingSource(40 * time.Millisecond)
+
+	var queries []config.PrometheusQuery
+	for i := range 6 {
+		queries = append(queries, config.PrometheusQuery{Name: fmt.Sprintf("query_%d", i), Query: "up", TTL: time.Hour})
+	}
+
+	runScheduler(t, source, cache, queries, &config.DataSchedulerConfig{Workers: 2}, 300*time.Millisecond)
+
+	for _, query := range queries {
+		assert.Equal(t, 1, source.count(query.Name))
+	}
+	assert.Equal(t, 2, source.peak, "no more than the configured workers should run at once")
+}
+
+func TestScheduler_RecordsFailures(t *testing.T) {
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	source := newCountingSource(0)
+	source.failures["broken"] = true
+
+	queries := []config.PrometheusQuery{{Name: "broken", Query: "up", TTL: time.Hour}}
+
+	runScheduler(t, source, cache, queries, &config.DataSchedulerConfig{Workers: 1}, 100*time.Millisecond)
+
+	status, ok := GetQueryScheduleStatus(context.Background(), cache, "broken")
+	require.True(t, ok)
+	require.NotNil(t, status.LastRun)
+	assert.Nil(t, status.LastSuccess)
+	assert.Contains(t, status.LastError, "backend unavailable")
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	scheduler := &Scheduler{maxJitter: 5 * time.Second}
+
+	for range 100 {
+		jitter := scheduler.jitter(10 * time.Second)
+		assert.GreaterOrEqual(t, jitter, time.Duration(0))
+		assert.Less(t, jitter, time.Second, "jitter should stay under a tenth of the TTL")
+
+		assert.Less(t, scheduler.jitter(24*time.Hour), 5*time.Second, "jitter should stay under the configured maximum")
+	}
+
+	assert.Zero(t, (&Scheduler{}).jitter(time.Hour), "a zero maximum disables jitter")
+}
+
+func TestScheduler_EvictsRemovedQueries(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	cache.Set(ctx, "removed", CachedData{Name: "removed", Timestamp: time.Now()})
+	cache.Set(ctx, "disabled", CachedData{Name: "disabled", Timestamp: time.Now()})
+	cache.Set(ctx, "kept", CachedData{Name: "kept", Timestamp: time.Now()})
+
+	source := newCountingSource(0)
+	queries := []config.PrometheusQuery{
+		{Name: "kept", Query: "up", TTL: time.Hour},
+		{Name: "disabled", Query: "up", TTL: time.Hour, Disabled: true},
+	}
+
+	runScheduler(t, source, cache, queries, &config.DataSchedulerConfig{Workers: 1}, 50*time.Millisecond)
+
+	assert.ElementsMatch(t, []string{"kept"}, cache.ListAll(ctx))
+}
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/common/model"
+)
+
+// SeriesValue is the latest value of one series of a query result.
+type SeriesValue struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// LatestValues reads the latest value of each series of a cached result, keyed by the series' labels, as
+// alert rules and status page components see it. Range results are reduced to their last sample; values that
+// are not finite are left out.
+func LatestValues(cached CachedData) (map[string]SeriesValue, error) {
+	values := make(map[string]SeriesValue)
+
+	add := func(metric model.Metric, value model.SampleValue) {
+		f := float64(value)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return
+		}
+
+		labels := make(map[string]string, len(metric))
+		for name, labelValue := range metric {
+			labels[string(name)] = string(labelValue)
+		}
+		values[metric.String()] = SeriesValue{Labels: labels, Value: f}
+	}
+
+	switch cached.ValueType {
+	case "vector":
+		var vector model.Vector
+		if err := json.Unmarshal(cached.JSONBytes, &vector); err != nil {
+			return nil, fmt.Errorf("failed to decode vector: %w", err)
+		}
+		for _, sample := range vector {
+			add(sample.Metric, sample.Value)
+		}
+	case "matrix":
+		var matrix model.Matrix
+		if err := json.Unmarshal(cached.JSONBytes, &matrix); err != nil {
+			return nil, fmt.Errorf("failed to decode matrix: %w", err)
+		}
+		for _, stream := range matrix {
+			if len(stream.Values) > 0 {
+				add(stream.Metric, stream.Values[len(stream.Values)-1].Value)
+			}
+		}
+	case "scalar":
+		var scalar model.Scalar
+		if err := json.Unmarshal(cached.JSONBytes, &scalar); err != nil {
+			return nil, fmt.Errorf("failed to decode scalar: %w", err)
+		}
+		add(model.Metric{}, scalar.Value)
+	default:
+		return nil, fmt.Errorf("results of type %s cannot be compared with a condition", cached.ValueType)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,33 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestValues(t *testing.T) {
+	vector, err := json.Marshal(model.Vector{
+		{Metric: model.Metric{"node": "a"}, Value: 0},
+		{Metric: model.Metric{"node": "b"}, Value: 1},
+	})
+	require.NoError(t, err)
+
+	values, err := LatestValues(CachedData{ValueType: "vector", JSONBytes: vector})
+	require.NoError(t, err)
+	assert.Equal(t, SeriesValue{Labels: map[string]string{"node": "a"}, Value: 0}, values[`{node="a"}`])
+	assert.Len(t, values, 2)
+
+	scalar, err := json.Marshal(&model.Scalar{Value: 42})
+	require.NoError(t, err)
+
+	values, err = LatestValues(CachedData{ValueType: "scalar", JSONBytes: scalar})
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, values["{}"].Value)
+
+	_, err = LatestValues(CachedData{ValueType: "string", JSONBytes: []byte(`[0, "x"]`)})
+	assert.Error(t, err)
+}
@@ -6,41 +6,88 @@ import (
 	"fmt"
 	"homelab-dashboard/internal/config"
 	"homelab-dashboard/internal/metrics"
-	"homelab-dashboard/internal/utils"
 	"log/slog"
+	"slices"
 	"time"
 
-	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 )
 
 type Service struct {
-	client  *MimirClient
-	cache   Provider
-	logger  *slog.Logger
-	queries []config.PrometheusQuery
+	sources   map[string]DataSource
+	cache     Provider
+	logger    *slog.Logger
+	queries   []config.PrometheusQuery
+	variables []config.DataVariable
+	onDemand  *config.DataOnDemandConfig
+	history   HistoryRecorder
+	probes    []config.DataProbe
 }
 
-func NewService(client *MimirClient, cache Provider, logger *slog.Logger, queries []config.PrometheusQuery) *Service {
+// NewService answers each query with the source registered under its source name (see config.DataSourcePrometheus and friends).
+func NewService(sources map[string]DataSource, cache Provider, logger *slog.Logger, queries []config.PrometheusQuery) *Service {
 	return &Service{
-		client:  client,
+		sources: sources,
 		cache:   cache,
 		queries: queries,
 		logger:  logger,
 	}
 }
 
-func (s *Service) ExecuteQueries(ctx context.Context, cache Provider) error {
+// WithVariables sets the template variables queries may reference and the limit on selections that are run
+// on demand.
+func (s *Service) WithVariables(variables []config.DataVariable, onDemand *config.DataOnDemandConfig) *Service {
+	s.variables = variables
+	s.onDemand = onDemand
+	return s
+}
+
+// WithProbes sets the probes whose results are cached next to those of queries, see ProbeQuery.
+func (s *Service) WithProbes(probes []config.DataProbe) *Service {
+	s.probes = probes
+	return s
+}
+
+// EvictRemovedQueries deletes cached results of queries, variable selections or probes that are no longer
+// configured or are disabled, so they stop being served once they are gone. It returns how many entries were evicted.
+func (s *Service) EvictRemovedQueries(ctx context.Context, cache Provider) int {
+	configured := make(map[string]bool, len(s.queries)+len(s.probes))
+	for _, query := range slices.Concat(s.Queries(), ProbeQueries(s.probes)) {
+		configured[QueryCacheKey(query)] = true
+	}
+
+	evicted := 0
+	for _, name := range cache.ListAll(ctx) {
+		if !configured[name] {
+			cache.Delete(ctx, name)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+// Queries returns the queries that are not disabled, with templated queries expanded into each of their
+// precomputed variable selections.
+func (s *Service) Queries() []config.PrometheusQuery {
+	return PrecomputedQueries(s.queries, s.variables)
+}
+
+// ExecuteQuery runs a single query and caches its result, falling back to the service's cache when cache is nil.
+func (s *Service) ExecuteQuery(ctx context.Context, cache Provider, query config.PrometheusQuery) error {
 	if cache == nil {
 		cache = s.cache
 	}
 
 	if cache == nil {
-		s.logger.Error("cache is nil, skipping metrics update")
-		return nil
+		return fmt.Errorf("cache is nil, skipping query %s", query.Name)
 	}
 
+	return s.executeQuery(ctx, cache, query)
+}
+
+func recordCacheSize(ctx context.Context, cache Provider) {
 	cacheType := "memory"
 	if _, ok := cache.(*RedisCache); ok {
 		cacheType = "redis"
@@ -48,62 +95,12 @@ func (s *Service) ExecuteQueries(ctx context.Context, cache Provider) error {
 
 	size := cache.Size(ctx)
 	metrics.CacheItems.WithLabelValues(cacheType).Set(float64(size))
-
-	for _, queryConfig := range s.queries {
-
-		if queryConfig.Disabled {
-			continue
-		}
-
-		if err := s.executeQuery(ctx, cache, queryConfig); err != nil {
-			s.logger.Error("failed to execute query",
-				"query", queryConfig.Name,
-				"error", err)
-			continue
-		}
-	}
-	return nil
 }
 
 func (s *Service) executeQuery(ctx context.Context, cache Provider, config config.PrometheusQuery) error {
-	var result model.Value
-	var err error
-
-	switch config.Type {
-	case "range":
-		rangeDuration, err := utils.ParseDurationString(config.Range)
-		if err != nil {
-			return fmt.Errorf("invalid range duration %s: %w", config.Range, err)
-		}
-
-		stepDuration, err := utils.ParseDurationString(config.Step)
-		if err != nil {
-			return fmt.Errorf("invalid step duration %s: %w", config.Step, err)
-		}
-
-		end := time.Now()
-		start := end.Add(-rangeDuration)
-
-		r := v1.Range{
-			Start: start,
-			End:   end,
-			Step:  stepDuration,
-		}
-
-		timer := prometheus.NewTimer(metrics.DataFetchDuration.WithLabelValues(config.Name, metrics.DataSourceTypeMimir))
-		result, err = s.client.QueryRange(ctx, config.Query, r)
-		timer.ObserveDuration()
-
-	default:
-		timer := prometheus.NewTimer(metrics.DataFetchDuration.WithLabelValues(config.Name, metrics.DataSourceTypeMimir))
-		result, err = s.client.Query(ctx, config.Query, time.Now())
-		timer.ObserveDuration()
-
-	}
-
+	result, warnings, err := s.runQuery(ctx, config)
 	if err != nil {
-		metrics.DataFetchErrors.WithLabelValues(config.Name, metrics.DataSourceTypeMimir).Inc()
-		return fmt.Errorf("failed to execute query %s: %w", config.Name, err)
+		return err
 	}
 
 	// Add nil check for result before processing
@@ -113,35 +110,94 @@ func (s *Service) executeQuery(ctx context.Context, cache Provider, config confi
 	}
 
 	if s.cache != nil {
-		ttl := config.TTL
-		if ttl == 0 {
-			ttl = 5 * time.Minute
-		}
+		ttl := queryTTL(config)
+		key := QueryCacheKey(config)
 
 		cachedData := s.prepareCacheData(config.Name, result, config)
+		cachedData.Warnings = warnings
+
+		previous, existed := cache.Get(ctx, key)
+		cache.Set(ctx, key, cachedData)
 
-		cache.Set(ctx, config.Name, cachedData)
-		s.logger.Debug("cached query result", "query", config.Name, "type", config.Type, "ttl", ttl)
+		if !existed || cachedDataChanged(previous, cachedData) {
+			if err := cache.PublishUpdate(ctx, key); err != nil {
+				s.logger.Warn("failed to publish cache update", "query", key, "error", err)
+			}
+		}
+		s.logger.Debug("cached query result", "query", key, "source", querySource(config), "type", config.Type, "ttl", ttl)
 	} else {
 		s.logger.Warn("cache is nil, skipping cache storage", "query", config.Name)
 	}
 
+	// A result that cannot be recorded is still served from the cache
+	if err := s.recordHistory(ctx, config, result); err != nil {
+		s.logger.Warn("failed to record query history", "query", QueryCacheKey(config), "error", err)
+	}
+
 	return nil
 }
 
+// runQuery asks the query's source for its current result, along with any warnings the backend returned.
+func (s *Service) runQuery(ctx context.Context, config config.PrometheusQuery) (model.Value, []string, error) {
+	source := querySource(config)
+	sourceLabel := dataSourceMetricLabel(source)
+
+	dataSource, ok := s.sources[source]
+	if !ok {
+		metrics.DataFetchErrors.WithLabelValues(config.Name, sourceLabel).Inc()
+		return nil, nil, fmt.Errorf("no %s data source is configured for query %s", source, config.Name)
+	}
+
+	ctx, warnings := withQueryWarnings(ctx)
+
+	timer := prometheus.NewTimer(metrics.DataFetchDuration.WithLabelValues(config.Name, sourceLabel))
+	result, err := dataSource.Query(ctx, config)
+	timer.ObserveDuration()
+
+	if err != nil {
+		metrics.DataFetchErrors.WithLabelValues(config.Name, sourceLabel).Inc()
+		return nil, nil, fmt.Errorf("failed to execute query %s: %w", config.Name, err)
+	}
+
+	return result, warnings.list(), nil
+}
+
+// queryTTL returns how long a query's result stays fresh.
+func queryTTL(query config.PrometheusQuery) time.Duration {
+	if query.TTL == 0 {
+		return 5 * time.Minute
+	}
+	return query.TTL
+}
+
 func (s *Service) prepareCacheData(name string, value model.Value, config config.PrometheusQuery) CachedData {
+	now := time.Now()
+	ttl := queryTTL(config)
+
 	if value == nil {
 		s.logger.Error("received nil value for cache preparation", "query", name)
 		return CachedData{
 			Name:          name,
 			ValueType:     "unknown",
 			JSONBytes:     []byte("null"),
-			Timestamp:     time.Now(),
+			Timestamp:     now,
+			TTL:           ttl,
+			ExpiresAt:     now.Add(ttl + cacheExpiryGrace),
+			Variables:     config.Variables,
 			RequireAuth:   config.RequireAuth,
 			RequiredGroup: config.RequiredGroup,
 		}
 	}
 
+	// A result the pipeline cannot shape is cached as is, so the panel still has data
+	if len(config.Transform) > 0 {
+		if transformed, err := ApplyTransforms(value, config.Transform); err != nil {
+			s.logger.Warn("failed to transform query result", "query", name, "error", err)
+		} else {
+			value = transformed
+		}
+	}
+
 	jsonBytes, err := json.Marshal(value)
 	if err != nil {
 		s.logger.Error("failed to marshal value for cache", "query", name, "error", err)
@@ -170,7 +226,10 @@ func (s *Service) prepareCacheData(name string, value model.Value, config config
 		Name:          name,
 		ValueType:     typeStr,
 		JSONBytes:     jsonBytes,
-		Timestamp:     time.Now(),
+		Timestamp:     now,
+		TTL:           ttl,
+		ExpiresAt:     now.Add(ttl + cacheExpiryGrace),
+		Variables:     config.Variables,
 		RequireAuth:   config.RequireAuth,
 		RequiredGroup: config.RequiredGroup,
 	}
@@ -0,0 +1,32 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ExecuteQuerySetsExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	query := config.PrometheusQuery{Name: "capacity", Query: "up", TTL: time.Minute}
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: newCountingSource(0)}, cache, logger, []config.PrometheusQuery{query})
+
+	require.NoError(t, service.ExecuteQuery(ctx, cache, query))
+
+	cached, ok := cache.Get(ctx, "capacity")
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, cached.TTL)
+	assert.Equal(t, cached.Timestamp.Add(time.Minute+cacheExpiryGrace), cached.ExpiresAt)
+	assert.False(t, cached.IsStale(cached.Timestamp.Add(time.Minute)), "an entry should stay fresh through the grace period")
+	assert.True(t, cached.IsStale(cached.ExpiresAt.Add(time.Second)))
+	assert.Equal(t, 90*time.Second, cached.Age(cached.Timestamp.Add(90*time.Second)))
+}
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/metrics"
+	"homelab-dashboard/internal/utils"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// DataSource answers dashboard queries from one backend. Every source normalises its results into
+// Prometheus value types (vector, matrix or scalar), so they are cached and rendered the same way.
+type DataSource interface {
+	Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error)
+}
+
+// LabelValuesSource is implemented by sources that can list the values of a label, which is how template
+// variables defined with label_values get their options.
+type LabelValuesSource interface {
+	LabelValues(ctx context.Context, backend, label string, matches []string) ([]string, error)
+}
+
+// PrometheusSource answers PromQL queries through Prometheus-compatible APIs such as Mimir, sending each query
+// to the backend it names.
+type PrometheusSource struct {
+	clients map[string]*MimirClient
+}
+
+// NewPrometheusSource takes the client of every backend, keyed by the backend's name.
+func NewPrometheusSource(clients map[string]*MimirClient) *PrometheusSource {
+	return &PrometheusSource{clients: clients}
+}
+
+func (p *PrometheusSource) Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error) {
+	client, err := p.client(query.Backend)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Type != "range" {
+		return client.Query(ctx, query.Query, time.Now())
+	}
+
+	r, err := queryRange(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.QueryRange(ctx, query.Query, r)
+}
+
+func (p *PrometheusSource) QueryRange(ctx context.Context, query config.PrometheusQuery, r v1.Range) (model.Value, error) {
+	client, err := p.client(query.Backend)
+	if err != nil {
+		return nil, err
+	}
+	return client.QueryRange(ctx, query.Query, r)
+}
+
+func (p *PrometheusSource) LabelValues(ctx context.Context, backend, label string, matches []string) ([]string, error) {
+	client, err := p.client(backend)
+	if err != nil {
+		return nil, err
+	}
+	return client.LabelValues(ctx, label, matches)
+}
+
+// client returns the client of a backend. Validation gives every query and variable a backend, so an empty
+// name only comes from callers that predate backends and falls back to the default one.
+func (p *PrometheusSource) client(backend string) (*MimirClient, error) {
+	if backend == "" {
+		backend = config.DefaultDataBackendName
+	}
+
+	client, ok := p.clients[backend]
+	if !ok {
+		return nil, fmt.Errorf("prometheus backend %q is not configured", backend)
+	}
+	return client, nil
+}
+
+// queryRange resolves a range query's range and step into a window ending now.
+func queryRange(query config.PrometheusQuery) (v1.Range, error) {
+	rangeDuration, err := utils.ParseDurationString(query.Range)
+	if err != nil {
+		return v1.Range{}, fmt.Errorf("invalid range duration %s: %w", query.Range, err)
+	}
+
+	stepDuration, err := utils.ParseDurationString(query.Step)
+	if err != nil {
+		return v1.Range{}, fmt.Errorf("invalid step duration %s: %w", query.Step, err)
+	}
+
+	end := time.Now()
+	return v1.Range{
+		Start: end.Add(-rangeDuration),
+		End:   end,
+		Step:  stepDuration,
+	}, nil
+}
+
+// querySource returns the source a query is answered by, defaulting to Prometheus.
+func querySource(query config.PrometheusQuery) string {
+	if query.Source == "" {
+		return config.DataSourcePrometheus
+	}
+	return query.Source
+}
+
+// dataSourceMetricLabel maps a query source onto the data_source label of the fetch metrics.
+func dataSourceMetricLabel(source string) string {
+	switch source {
+	case config.DataSourcePrometheus:
+		return metrics.DataSourceTypeMimir
+	case config.DataSourceLoki:
+		return metrics.DataSourceTypeLoki
+	case config.DataSourceHTTPJSON:
+		return metrics.DataSourceTypeHTTPJSON
+	case config.DataSourceKubernetes:
+		return metrics.DataSourceTypeKubernetes
+	case config.DataSourceProbe:
+		return metrics.DataSourceTypeProbe
+	default:
+		return source
+	}
+}
@@ -0,0 +1,405 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"homelab-dashboard/internal/config"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type staticSource struct {
+	value model.Value
+	err   error
+}
+
+func (s staticSource) Query(context.Context, config.PrometheusQuery) (model.Value, error) {
+	return s.value, s.err
+}
+
+// executeAll runs every configured query once, as the scheduler's first pass would. Queries that fail are
+// left out of the cache, which is what the tests check.
+func executeAll(ctx context.Context, service *Service) {
+	for _, query := range service.Queries() {
+		_ = service.ExecuteQuery(ctx, nil, query)
+	}
+}
+
+func TestService_ExecuteQueryDispatchesBySource(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cache, _ := NewMemCache(&config.Config{}, logger)
+
+	sources := map[string]DataSource{
+		config.DataSourcePrometheus: staticSource{value: &model.Scalar{Value: 1}},
+		config.DataSourceHTTPJSON:   staticSource{value: createTestVector(2)},
+		config.DataSourceLoki:       staticSource{err: fmt.Errorf("loki unavailable")},
+	}
+
+	queries := []config.PrometheusQuery{
+		{Name: "default_source", Query: "up"},
+		{Name: "json", Source: config.DataSourceHTTPJSON, Query: "$.value", RequireAuth: true, RequiredGroup: "admins"},
+		{Name: "logs", Source: config.DataSourceLoki, Query: `{job="x"}`},
+		{Name: "cluster", Source: config.DataSourceKubernetes, Query: config.DataKubernetesNodes},
+	}
+
+	service := NewService(sources, cache, logger, queries)
+	executeAll(ctx, service)
+
+	scalar, ok := cache.Get(ctx, "default_source")
+	require.True(t, ok)
+	assert.Equal(t, "scalar", scalar.ValueType)
+
+	vector, ok := cache.Get(ctx, "json")
+	require.True(t, ok)
+	assert.Equal(t, "vector", vector.ValueType)
+	assert.True(t, vector.RequireAuth)
+	assert.Equal(t, "admins", vector.RequiredGroup)
+
+	_, ok = cache.Get(ctx, "logs")
+	assert.False(t, ok, "failed queries should not be cached")
+
+	_, ok = cache.Get(ctx, "cluster")
+	assert.False(t, ok, "queries without a configured source should not be cached")
+}
+
+func TestLokiSource_Query(t *testing.T) {
+	testCases := []struct {
+		testName     string
+		queryType    string
+		response     string
+		status       int
+		expectedPath string
+		expectError  bool
+		check        func(t *testing.T, value model.Value)
+	}{
+		{
+			testName:     "metric query returns a vector",
+			response:     `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"traefik"},"value":[1700000000,"4.5"]}]}}`,
+			status:       http.StatusOK,
+			expectedPath: "/loki/api/v1/query",
+			check: func(t *testing.T, value model.Value) {
+				vector, ok := value.(model.Vector)
+				require.True(t, ok)
+				require.Len(t, vector, 1)
+				assert.Equal(t, model.LabelValue("traefik"), vector[0].Metric["job"])
+				assert.Equal(t, model.SampleValue(4.5), vector[0].Value)
+			},
+		},
+		{
+			testName:     "range metric query returns a matrix",
+			queryType:    "range",
+			response:     `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"job":"traefik"},"values":[[1700000000,"1"],[1700000060,"2"]]}]}}`,
+			status:       http.StatusOK,
+			expectedPath: "/loki/api/v1/query_range",
+			check: func(t *testing.T, value model.Value) {
+				matrix, ok := value.(model.Matrix)
+				require.True(t, ok)
+				require.Len(t, matrix, 1)
+				assert.Len(t, matrix[0].Values, 2)
+			},
+		},
+		{
+			testName:     "log query counts lines per stream",
+			response:     `{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"auth"},"values":[["1700000000000000000","a"],["1700000005000000000","b"]]}]}}`,
+			status:       http.StatusOK,
+			expectedPath: "/loki/api/v1/query",
+			check: func(t *testing.T, value model.Value) {
+				vector, ok := value.(model.Vector)
+				require.True(t, ok)
+				require.Len(t, vector, 1)
+				assert.Equal(t, model.LabelValue("auth"), vector[0].Metric["app"])
+				assert.Equal(t, model.SampleValue(2), vector[0].Value)
+				assert.Equal(t, model.TimeFromUnixNano(1700000005000000000), vector[0].Timestamp)
+			},
+		},
+		{
+			testName:     "error response",
+			response:     `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			status:       http.StatusBadRequest,
+			expectedPath: "/loki/api/v1/query",
+			expectError:  true,
+		},
+		{
+			testName:     "plain text error",
+			response:     "too many outstanding requests",
+			status:       http.StatusTooManyRequests,
+			expectedPath: "/loki/api/v1/query",
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tc.expectedPath, r.URL.Path)
+				assert.Equal(t, "tenant-a", r.Header.Get("X-Scope-OrgID"))
+				username, password, ok := r.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "loki", username)
+				assert.Equal(t, "secret", password)
+
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			source := NewLokiSource(&config.DataLokiConfig{
+				URL:       server.URL + "/",
+				BasicAuth: &config.BasicAuth{Username: "loki", Password: "secret"},
+				TenantID:  "tenant-a",
+			})
+
+			value, err := source.Query(context.Background(), config.PrometheusQuery{
+				Name:  "logs",
+				Query: `sum(rate({job="traefik"}[5m]))`,
+				Type:  tc.queryType,
+				Range: "1h",
+				Step:  "1m",
+			})
+
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			tc.check(t, value)
+		})
+	}
+}
+
+func TestPrometheusSource_QueryPicksBackend(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	homelab := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "prom", username)
+		assert.Equal(t, "secret", password)
+		assert.Empty(t, r.Header.Get("X-Scope-OrgID"))
+
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`))
+	}))
+	defer homelab.Close()
+
+	mimir := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer rotated-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "tenant-b", r.Header.Get("X-Scope-OrgID"))
+		assert.Equal(t, "dashboard", r.Header.Get("X-Source"))
+
+		_, _ = w.Write([]byte(`{"status":"success","warnings":["partial data: ingester unavailable"],"data":{"resultType":"scalar","result":[1700000000,"2"]}}`))
+	}))
+	defer mimir.Close()
+
+	tokenFile := t.TempDir() + "/token"
+	require.NoError(t, os.WriteFile(tokenFile, []byte("rotated-token\n"), 0o600))
+
+	clients := make(map[string]*MimirClient)
+	for _, backend := range []config.DataPrometheusBackend{
+		{Name: config.DefaultDataBackendName, URL: homelab.URL, BasicAuth: &config.BasicAuth{Username: "prom", Password: "secret"}},
+		{Name: "mimir", URL: mimir.URL, TenantID: "tenant-b", BearerTokenFile: tokenFile, Headers: map[string]string{"X-Source": "dashboard"}},
+	} {
+		client, err := NewMimirClient(backend, logger)
+		require.NoError(t, err)
+		clients[backend.Name] = client
+	}
+
+	cache, _ := NewMemCache(&config.Config{}, logger)
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: NewPrometheusSource(clients)}, cache, logger, []config.PrometheusQuery{
+		{Name: "homelab_up", Source: config.DataSourcePrometheus, Backend: config.DefaultDataBackendName, Query: "up"},
+		{Name: "tenant_up", Source: config.DataSourcePrometheus, Backend: "mimir", Query: "up"},
+		{Name: "missing", Source: config.DataSourcePrometheus, Backend: "unknown", Query: "up"},
+	})
+	executeAll(ctx, service)
+
+	homelabUp, ok := cache.Get(ctx, "homelab_up")
+	require.True(t, ok)
+	assert.JSONEq(t, `[1700000000,"1"]`, string(homelabUp.JSONBytes))
+	assert.Empty(t, homelabUp.Warnings)
+
+	tenantUp, ok := cache.Get(ctx, "tenant_up")
+	require.True(t, ok)
+	assert.JSONEq(t, `[1700000000,"2"]`, string(tenantUp.JSONBytes))
+	assert.Equal(t, []string{"partial data: ingester unavailable"}, tenantUp.Warnings)
+
+	_, ok = cache.Get(ctx, "missing")
+	assert.False(t, ok)
+}
+
+func TestJSONPath(t *testing.T) {
+	var document any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"status": {"battery.charge": 98, "online": true},
+		"devices": [{"load": 12}, {"load": "30.5"}, {"load": "n/a"}],
+		"dns_queries_today": 1200
+	}`), &document))
+
+	testCases := []struct {
+		testName    string
+		path        string
+		expected    []jsonPathMatch
+		expectError bool
+	}{
+		{
+			testName: "dot child",
+			path:     "$.dns_queries_today",
+			expected: []jsonPathMatch{{path: "$.dns_queries_today", value: float64(1200)}},
+		},
+		{
+			testName: "quoted key",
+			path:     "$.status['battery.charge']",
+			expected: []jsonPathMatch{{path: "$.status['battery.charge']", value: float64(98)}},
+		},
+		{
+			testName: "negative index",
+			path:     "$.devices[-1].load",
+			expected: []jsonPathMatch{{path: "$.devices[2].load", value: "n/a"}},
+		},
+		{
+			testName: "wildcard",
+			path:     "$.devices[*].load",
+			expected: []jsonPathMatch{
+				{path: "$.devices[0].load", value: float64(12)},
+				{path: "$.devices[1].load", value: "30.5"},
+				{path: "$.devices[2].load", value: "n/a"},
+			},
+		},
+		{
+			testName: "missing key",
+			path:     "$.missing.value",
+			expected: nil,
+		},
+		{testName: "missing root", path: "devices", expectError: true},
+		{testName: "recursive descent", path: "$..load", expectError: true},
+		{testName: "unterminated bracket", path: "$.devices[0", expectError: true},
+		{testName: "filter expression", path: "$.devices[?(@.load > 1)]", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			segments, err := parseJSONPath(tc.path)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, evaluateJSONPath(document, segments))
+		})
+	}
+}
+
+func TestHTTPJSONSource_Query(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ups": {"load": 42.5, "on_battery": false}, "upstreams": [{"ms": 3}, {"ms": "7"}, {"ms": null}]}`))
+	}))
+	defer server.Close()
+
+	source := NewHTTPJSONSource()
+	headers := map[string]string{"Authorization": "Bearer token"}
+
+	value, err := source.Query(context.Background(), config.PrometheusQuery{URL: server.URL, Headers: headers, Query: "$.ups.load"})
+	require.NoError(t, err)
+	scalar, ok := value.(*model.Scalar)
+	require.True(t, ok)
+	assert.Equal(t, model.SampleValue(42.5), scalar.Value)
+
+	value, err = source.Query(context.Background(), config.PrometheusQuery{URL: server.URL, Headers: headers, Query: "$.ups.on_battery"})
+	require.NoError(t, err)
+	assert.Equal(t, model.SampleValue(0), value.(*model.Scalar).Value)
+
+	value, err = source.Query(context.Background(), config.PrometheusQuery{URL: server.URL, Headers: headers, Query: "$.upstreams[*].ms"})
+	require.NoError(t, err)
+	vector, ok := value.(model.Vector)
+	require.True(t, ok)
+	require.Len(t, vector, 2, "null values should be skipped")
+	assert.Equal(t, model.LabelValue("$.upstreams[1].ms"), vector[1].Metric["path"])
+	assert.Equal(t, model.SampleValue(7), vector[1].Value)
+
+	_, err = source.Query(context.Background(), config.PrometheusQuery{URL: server.URL, Headers: headers, Query: "$.ups.missing"})
+	assert.Error(t, err)
+
+	_, err = source.Query(context.Background(), config.PrometheusQuery{URL: server.URL, Query: "$.ups.load"})
+	assert.Error(t, err, "non-200 responses should fail")
+}
+
+func TestKubernetesSource_Query(t *testing.T) {
+	readyNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		}},
+	}
+	notReadyNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-b"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionUnknown},
+		}},
+	}
+	pod := func(namespace, name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Status: corev1.PodStatus{Phase: phase}}
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "media", Name: "library"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+
+	client := fake.NewClientset(
+		readyNode, notReadyNode,
+		pod("media", "jellyfin", corev1.PodRunning),
+		pod("media", "sonarr", corev1.PodRunning),
+		pod("monitoring", "grafana", corev1.PodPending),
+		claim,
+	)
+	source := NewKubernetesSource(client)
+
+	counts := func(t *testing.T, value model.Value, label model.LabelName) map[string]float64 {
+		vector, ok := value.(model.Vector)
+		require.True(t, ok)
+
+		result := make(map[string]float64, len(vector))
+		for _, sample := range vector {
+			result[string(sample.Metric[label])] = float64(sample.Value)
+		}
+		return result
+	}
+
+	value, err := source.Query(context.Background(), config.PrometheusQuery{Query: config.DataKubernetesNodes})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"ready": 1, "not_ready": 1}, counts(t, value, "status"))
+
+	value, err = source.Query(context.Background(), config.PrometheusQuery{Query: config.DataKubernetesPods})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"Pending": 1, "Running": 2, "Succeeded": 0, "Failed": 0, "Unknown": 0}, counts(t, value, "phase"))
+
+	value, err = source.Query(context.Background(), config.PrometheusQuery{Query: config.DataKubernetesPods, Namespace: "monitoring"})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), counts(t, value, "phase")["Pending"])
+	assert.Equal(t, model.LabelValue("monitoring"), value.(model.Vector)[0].Metric["namespace"])
+
+	value, err = source.Query(context.Background(), config.PrometheusQuery{Query: config.DataKubernetesPersistentVolumeClaims})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"Pending": 0, "Bound": 1, "Lost": 0}, counts(t, value, "phase"))
+
+	_, err = source.Query(context.Background(), config.PrometheusQuery{Query: "deployments"})
+	assert.Error(t, err)
+}
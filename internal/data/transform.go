@@ -0,0 +1,296 @@
+package data
+
+import (
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// displayLabel is the label seconds_to_duration writes the human-readable duration to.
+const displayLabel = "display"
+
+// unitFactors are what each numeric unit conversion divides values by.
+var unitFactors = map[string]float64{
+	"bytes_to_kib":       1 << 10,
+	"bytes_to_mib":       1 << 20,
+	"bytes_to_gib":       1 << 30,
+	"bytes_to_tib":       1 << 40,
+	"seconds_to_minutes": 60,
+	"seconds_to_hours":   60 * 60,
+	"seconds_to_days":    24 * 60 * 60,
+}
+
+// ApplyTransforms runs a query's transform pipeline over its result. The result passed in is left untouched.
+func ApplyTransforms(value model.Value, steps []config.DataTransform) (model.Value, error) {
+	for i, step := range steps {
+		var err error
+		switch {
+		case step.Reduce != "":
+			value = reduceSeries(value, step.Reduce)
+		case len(step.RenameLabels) > 0:
+			value = mapMetrics(value, func(metric model.Metric) model.Metric {
+				renamed := make(model.Metric, len(metric))
+				for name, labelValue := range metric {
+					if to, ok := step.RenameLabels[string(name)]; ok {
+						name = model.LabelName(to)
+					}
+					renamed[name] = labelValue
+				}
+				return renamed
+			})
+		case len(step.DropLabels) > 0:
+			value = mapMetrics(value, func(metric model.Metric) model.Metric {
+				kept := make(model.Metric, len(metric))
+				for name, labelValue := range metric {
+					if !slices.Contains(step.DropLabels, string(name)) {
+						kept[name] = labelValue
+					}
+				}
+				return kept
+			})
+		case step.Unit != "":
+			value, err = convertUnit(value, step.Unit)
+		case step.TopN > 0:
+			value = topN(value, step.TopN)
+		case step.Downsample > 0:
+			value = downsample(value, step.Downsample)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("transform step %d: %w", i, err)
+		}
+	}
+
+	return value, nil
+}
+
+// rangeTransforms drops the reduce steps of a pipeline, so ad-hoc range queries still return a matrix.
+func rangeTransforms(steps []config.DataTransform) []config.DataTransform {
+	var kept []config.DataTransform
+	for _, step := range steps {
+		if step.Reduce == "" {
+			kept = append(kept, step)
+		}
+	}
+	return kept
+}
+
+// reduceSeries turns a matrix into a vector holding one sample per series, stamped with the series' last
+// timestamp. Other results already hold a single value per series.
+func reduceSeries(value model.Value, reduction string) model.Value {
+	matrix, ok := value.(model.Matrix)
+	if !ok {
+		return value
+	}
+
+	vector := make(model.Vector, 0, len(matrix))
+	for _, stream := range matrix {
+		if len(stream.Values) == 0 {
+			continue
+		}
+
+		reduced := stream.Values[len(stream.Values)-1].Value
+		switch reduction {
+		case config.DataTransformReduceAvg:
+			var sum model.SampleValue
+			for _, point := range stream.Values {
+				sum += point.Value
+			}
+			reduced = sum / model.SampleValue(len(stream.Values))
+		case config.DataTransformReduceMin, config.DataTransformReduceMax:
+			for _, point := range stream.Values {
+				if (reduction == config.DataTransformReduceMin) == (point.Value < reduced) {
+					reduced = point.Value
+				}
+			}
+		}
+
+		vector = append(vector, &model.Sample{
+			Metric:    stream.Metric,
+			Value:     reduced,
+			Timestamp: stream.Values[len(stream.Values)-1].Timestamp,
+		})
+	}
+
+	return vector
+}
+
+// mapMetrics replaces the labels of every series of a vector or matrix.
+func mapMetrics(value model.Value, fn func(model.Metric) model.Metric) model.Value {
+	switch v := value.(type) {
+	case model.Vector:
+		mapped := make(model.Vector, len(v))
+		for i, sample := range v {
+			mapped[i] = &model.Sample{Metric: fn(sample.Metric), Value: sample.Value, Timestamp: sample.Timestamp, Histogram: sample.Histogram}
+		}
+		return mapped
+	case model.Matrix:
+		mapped := make(model.Matrix, len(v))
+		for i, stream := range v {
+			mapped[i] = &model.SampleStream{Metric: fn(stream.Metric), Values: stream.Values, Histograms: stream.Histograms}
+		}
+		return mapped
+	default:
+		return value
+	}
+}
+
+// convertUnit divides every value by a unit's factor. seconds_to_duration instead labels each sample of a
+// vector with its formatted duration, and turns a scalar into the formatted string.
+func convertUnit(value model.Value, unit string) (model.Value, error) {
+	if unit == "seconds_to_duration" {
+		switch v := value.(type) {
+		case *model.Scalar:
+			return &model.String{Value: FormatDuration(float64(v.Value)), Timestamp: v.Timestamp}, nil
+		case model.Vector:
+			labelled := make(model.Vector, len(v))
+			for i, sample := range v {
+				metric := sample.Metric.Clone()
+				metric[displayLabel] = model.LabelValue(FormatDuration(float64(sample.Value)))
+				labelled[i] = &model.Sample{Metric: metric, Value: sample.Value, Timestamp: sample.Timestamp}
+			}
+			return labelled, nil
+		default:
+			return nil, fmt.Errorf("seconds_to_duration only applies to scalars and vectors, not %s", value.Type())
+		}
+	}
+
+	factor := model.SampleValue(unitFactors[unit])
+
+	switch v := value.(type) {
+	case *model.Scalar:
+		return &model.Scalar{Value: v.Value / factor, Timestamp: v.Timestamp}, nil
+	case model.Vector:
+		converted := make(model.Vector, len(v))
+		for i, sample := range v {
+			converted[i] = &model.Sample{Metric: sample.Metric, Value: sample.Value / factor, Timestamp: sample.Timestamp}
+		}
+		return converted, nil
+	case model.Matrix:
+		converted := make(model.Matrix, len(v))
+		for i, stream := range v {
+			points := make([]model.SamplePair, len(stream.Values))
+			for j, point := range stream.Values {
+				points[j] = model.SamplePair{Timestamp: point.Timestamp, Value: point.Value / factor}
+			}
+			converted[i] = &model.SampleStream{Metric: stream.Metric, Values: points}
+		}
+		return converted, nil
+	default:
+		return nil, fmt.Errorf("%s cannot be applied to a %s result", unit, value.Type())
+	}
+}
+
+// topN keeps the n series with the highest values, highest first. Series of a matrix are ranked by their last
+// value, and values that are not numbers rank last.
+func topN(value model.Value, n int) model.Value {
+	rank := func(a, b model.SampleValue) int {
+		switch {
+		case math.IsNaN(float64(a)) && math.IsNaN(float64(b)):
+			return 0
+		case math.IsNaN(float64(a)):
+			return 1
+		case math.IsNaN(float64(b)):
+			return -1
+		case a > b:
+			return -1
+		case a < b:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	switch v := value.(type) {
+	case model.Vector:
+		sorted := slices.Clone(v)
+		slices.SortStableFunc(sorted, func(a, b *model.Sample) int { return rank(a.Value, b.Value) })
+		return sorted[:min(n, len(sorted))]
+	case model.Matrix:
+		last := func(stream *model.SampleStream) model.SampleValue {
+			if len(stream.Values) == 0 {
+				return model.SampleValue(math.NaN())
+			}
+			return stream.Values[len(stream.Values)-1].Value
+		}
+
+		sorted := slices.Clone(v)
+		slices.SortStableFunc(sorted, func(a, b *model.SampleStream) int { return rank(last(a), last(b)) })
+		return sorted[:min(n, len(sorted))]
+	default:
+		return value
+	}
+}
+
+// downsample averages consecutive points of each series of a matrix into at most n points, each stamped with
+// the time of the last point it covers so the series still ends at the same time.
+func downsample(value model.Value, n int) model.Value {
+	matrix, ok := value.(model.Matrix)
+	if !ok {
+		return value
+	}
+
+	downsampled := make(model.Matrix, len(matrix))
+	for i, stream := range matrix {
+		if len(stream.Values) <= n {
+			downsampled[i] = stream
+			continue
+		}
+
+		size := int(math.Ceil(float64(len(stream.Values)) / float64(n)))
+		points := make([]model.SamplePair, 0, n)
+		for start := 0; start < len(stream.Values); start += size {
+			bucket := stream.Values[start:min(start+size, len(stream.Values))]
+
+			var sum model.SampleValue
+			for _, point := range bucket {
+				sum += point.Value
+			}
+			points = append(points, model.SamplePair{
+				Timestamp: bucket[len(bucket)-1].Timestamp,
+				Value:     sum / model.SampleValue(len(bucket)),
+			})
+		}
+
+		downsampled[i] = &model.SampleStream{Metric: stream.Metric, Values: points}
+	}
+
+	return downsampled
+}
+
+// FormatDuration renders a number of seconds as its two largest units, e.g. "3d 4h" or "12m 5s".
+func FormatDuration(seconds float64) string {
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return strconv.FormatFloat(seconds, 'f', -1, 64)
+	}
+
+	sign := ""
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+
+	remaining := int64(math.Round(seconds))
+	units := []struct {
+		suffix  string
+		seconds int64
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}}
+
+	var parts []string
+	for _, unit := range units {
+		if count := remaining / unit.seconds; count > 0 || (unit.suffix == "s" && len(parts) == 0) {
+			parts = append(parts, fmt.Sprintf("%d%s", count, unit.suffix))
+			remaining -= count * unit.seconds
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+
+	return sign + strings.Join(parts, " ")
+}
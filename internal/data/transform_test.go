@@ -0,0 +1,127 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMatrix() model.Matrix {
+	return model.Matrix{
+		{Metric: model.Metric{"instance": "a", "job": "node"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 5}, {Timestamp: 3000, Value: 3}}},
+		{Metric: model.Metric{"instance": "b", "job": "node"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 8}, {Timestamp: 2000, Value: 2}, {Timestamp: 3000, Value: 2}}},
+	}
+}
+
+func TestApplyTransforms_Reduce(t *testing.T) {
+	testCases := []struct {
+		reduction string
+		expected  []model.SampleValue
+	}{
+		{config.DataTransformReduceLast, []model.SampleValue{3, 2}},
+		{config.DataTransformReduceAvg, []model.SampleValue{3, 4}},
+		{config.DataTransformReduceMin, []model.SampleValue{1, 2}},
+		{config.DataTransformReduceMax, []model.SampleValue{5, 8}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.reduction, func(t *testing.T) {
+			result, err := ApplyTransforms(testMatrix(), []config.DataTransform{{Reduce: tc.reduction}})
+			require.NoError(t, err)
+
+			vector, ok := result.(model.Vector)
+			require.True(t, ok, "reducing a matrix should give a vector")
+			require.Len(t, vector, 2)
+			assert.Equal(t, tc.expected, []model.SampleValue{vector[0].Value, vector[1].Value})
+			assert.Equal(t, model.Time(3000), vector[0].Timestamp)
+		})
+	}
+}
+
+func TestApplyTransforms_Labels(t *testing.T) {
+	original := testMatrix()
+
+	result, err := ApplyTransforms(original, []config.DataTransform{
+		{RenameLabels: map[string]string{"instance": "host"}},
+		{DropLabels: []string{"job"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, model.Metric{"host": "a"}, result.(model.Matrix)[0].Metric)
+	assert.Equal(t, model.Metric{"instance": "a", "job": "node"}, original[0].Metric, "the original result should be left untouched")
+}
+
+func TestApplyTransforms_Units(t *testing.T) {
+	result, err := ApplyTransforms(model.Vector{{Metric: model.Metric{"mountpoint": "/"}, Value: 3 << 30}}, []config.DataTransform{{Unit: "bytes_to_gib"}})
+	require.NoError(t, err)
+	assert.Equal(t, model.SampleValue(3), result.(model.Vector)[0].Value)
+
+	result, err = ApplyTransforms(model.Vector{{Metric: model.Metric{"pod": "db"}, Value: 273600}}, []config.DataTransform{{Unit: "seconds_to_duration"}})
+	require.NoError(t, err)
+	assert.Equal(t, model.LabelValue("3d 4h"), result.(model.Vector)[0].Metric["display"])
+	assert.Equal(t, model.SampleValue(273600), result.(model.Vector)[0].Value)
+
+	result, err = ApplyTransforms(&model.Scalar{Value: 725}, []config.DataTransform{{Unit: "seconds_to_duration"}})
+	require.NoError(t, err)
+	assert.Equal(t, "12m 5s", result.(*model.String).Value)
+
+	_, err = ApplyTransforms(testMatrix(), []config.DataTransform{{Unit: "seconds_to_duration"}})
+	assert.Error(t, err)
+}
+
+func TestApplyTransforms_TopNAndDownsample(t *testing.T) {
+	vector := model.Vector{
+		{Metric: model.Metric{"pod": "a"}, Value: 1},
+		{Metric: model.Metric{"pod": "b"}, Value: 9},
+		{Metric: model.Metric{"pod": "c"}, Value: 5},
+	}
+
+	result, err := ApplyTransforms(vector, []config.DataTransform{{TopN: 2}})
+	require.NoError(t, err)
+	require.Len(t, result.(model.Vector), 2)
+	assert.Equal(t, model.Metric{"pod": "b"}, result.(model.Vector)[0].Metric)
+	assert.Equal(t, model.Metric{"pod": "c"}, result.(model.Vector)[1].Metric)
+
+	result, err = ApplyTransforms(testMatrix(), []config.DataTransform{{TopN: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, model.LabelValue("a"), result.(model.Matrix)[0].Metric["instance"], "matrices should be ranked by their last value")
+
+	result, err = ApplyTransforms(testMatrix(), []config.DataTransform{{Downsample: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, []model.SamplePair{{Timestamp: 2000, Value: 3}, {Timestamp: 3000, Value: 3}}, result.(model.Matrix)[0].Values)
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "0s", FormatDuration(0))
+	assert.Equal(t, "45s", FormatDuration(45))
+	assert.Equal(t, "1h 1m", FormatDuration(3665))
+	assert.Equal(t, "2d 5m", FormatDuration(2*86400+300))
+	assert.Equal(t, "-1m 30s", FormatDuration(-90))
+}
+
+func TestService_ExecuteQueryAppliesTransforms(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	query := config.PrometheusQuery{Name: "uptime", Query: "up", TTL: time.Minute, Transform: []config.DataTransform{{Unit: "seconds_to_duration"}}}
+	recorder := &historyRecorder{}
+	query.History = &config.DataQueryHistory{Enabled: true}
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: newCountingSource(0)}, cache, logger, nil).WithHistory(recorder)
+
+	require.NoError(t, service.ExecuteQuery(ctx, cache, query))
+
+	cached, ok := cache.Get(ctx, "uptime")
+	require.True(t, ok)
+	assert.Equal(t, "string", cached.ValueType, "the cached result should be shaped for display")
+
+	require.Len(t, recorder.samples, 1, "history should record the untransformed value")
+}
@@ -17,14 +17,34 @@ type DashboardData struct {
 	Queries     []QueryResult `json:"queries"`
 }
 
+// cacheExpiryGrace is how long past its TTL an entry stays fresh. It covers scheduler jitter and slow fetches,
+// so data is not reported stale between refreshes that are merely running a little late.
+const cacheExpiryGrace = time.Minute
+
 // CachedData represents a cache entry of the data for a single query.
+// Entries outlive their expiry: when a refresh fails the last value keeps being served, marked as stale.
 type CachedData struct {
-	Name          string      `json:"name"`
-	Value         model.Value `json:"-"`          //for memcache use
-	ValueJSON     string      `json:"value_json"` // raw JSON for Redis
-	ValueType     string      `json:"value_type"` // "vector", "matrix", "scalar", "string"
-	JSONBytes     []byte      `json:"json_bytes"`
-	Timestamp     time.Time   `json:"timestamp"`
-	RequireAuth   bool        `json:"require_auth"`
-	RequiredGroup string      `json:"required_group"`
+	Name          string            `json:"name"`
+	Value         model.Value       `json:"-"`          //for memcache use
+	ValueJSON     string            `json:"value_json"` // raw JSON for Redis
+	ValueType     string            `json:"value_type"` // "vector", "matrix", "scalar", "string"
+	JSONBytes     []byte            `json:"json_bytes"`
+	Timestamp     time.Time         `json:"timestamp"`
+	TTL           time.Duration     `json:"ttl"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	Variables     map[string]string `json:"variables,omitempty"` // selected template variable values
+	Warnings      []string          `json:"warnings,omitempty"`  // returned by the backend alongside the result, e.g. partial data
+	RequireAuth   bool              `json:"require_auth"`
+	RequiredGroup string            `json:"required_group"`
+}
+
+// IsStale reports whether the entry has not been refreshed within its TTL. Entries cached before expiry was
+// tracked have no ExpiresAt and are never reported stale.
+func (c CachedData) IsStale(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && now.After(c.ExpiresAt)
+}
+
+// Age returns how long ago the entry was fetched.
+func (c CachedData) Age(now time.Time) time.Duration {
+	return max(0, now.Sub(c.Timestamp))
 }
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sync"
+)
+
+// cacheUpdatesChannel is the Redis pub/sub channel carrying the names of queries whose cached data changed.
+const cacheUpdatesChannel = "cache:updates"
+
+// updateSubscriberBuffer is how many notifications a subscriber can fall behind before it starts missing them.
+const updateSubscriberBuffer = 64
+
+// updateBroker fans cache update notifications out to in-process subscribers. A subscriber that falls behind
+// misses notifications instead of blocking the publisher. The zero value is ready to use.
+type updateBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan string]struct{}
+}
+
+// subscribe returns a channel of query names that is closed once ctx is done.
+func (b *updateBroker) subscribe(ctx context.Context) <-chan string {
+	ch := make(chan string, updateSubscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[chan string]struct{})
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		close(ch)
+		b.mu.Unlock()
+	}()
+
+	return ch
+}
+
+func (b *updateBroker) publish(queryName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- queryName:
+		default:
+		}
+	}
+}
+
+// cachedDataChanged reports whether replacing previous with next changes what clients are sent.
+func cachedDataChanged(previous, next CachedData) bool {
+	return previous.ValueType != next.ValueType ||
+		previous.RequireAuth != next.RequireAuth ||
+		previous.RequiredGroup != next.RequiredGroup ||
+		!bytes.Equal(previous.JSONBytes, next.JSONBytes) ||
+		!slices.Equal(previous.Warnings, next.Warnings)
+}
@@ -0,0 +1,236 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// variableReference matches $name and ${name} references to template variables.
+var variableReference = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+
+// variableOptionsKeyPrefix namespaces the label values looked up for label_values variables.
+const variableOptionsKeyPrefix = "variables:options:"
+
+// ErrInvalidVariableValue is returned when a request selects a value that is not one of a variable's options.
+var ErrInvalidVariableValue = errors.New("invalid template variable value")
+
+// ErrOnDemandRateLimited is returned when a caller has run too many on-demand queries within the window.
+var ErrOnDemandRateLimited = errors.New("too many on-demand queries")
+
+// QueryVariables returns the names of the variables a query references, sorted. Only prometheus and loki
+// queries are templated, and references to names that are not defined are left alone.
+func QueryVariables(query config.PrometheusQuery, variables []config.DataVariable) []string {
+	if source := querySource(query); source != config.DataSourcePrometheus && source != config.DataSourceLoki {
+		return nil
+	}
+
+	var names []string
+	for _, match := range variableReference.FindAllStringSubmatch(query.Query, -1) {
+		name := match[1] + match[2]
+		if findVariable(variables, name) != nil && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// ExpandQuery substitutes the selected values into a query. The expanded query keeps its name and carries the
+// selection in Variables, which gives it its own cache key.
+func ExpandQuery(query config.PrometheusQuery, selection map[string]string) config.PrometheusQuery {
+	if len(selection) == 0 {
+		return query
+	}
+
+	expanded := query
+	expanded.Query = variableReference.ReplaceAllStringFunc(query.Query, func(reference string) string {
+		if value, ok := selection[strings.Trim(reference, "${}")]; ok {
+			return value
+		}
+		return reference
+	})
+	expanded.Variables = selection
+
+	return expanded
+}
+
+// QueryCacheKey returns the key a query's result is cached under: its name, followed by the selected values of
+// an expanded query, e.g. "pods?namespace=media".
+func QueryCacheKey(query config.PrometheusQuery) string {
+	if len(query.Variables) == 0 {
+		return query.Name
+	}
+
+	values := make(url.Values, len(query.Variables))
+	for name, value := range query.Variables {
+		values.Set(name, value)
+	}
+
+	return query.Name + "?" + values.Encode()
+}
+
+// CacheKeyQueryName returns the name of the query a cache key belongs to.
+func CacheKeyQueryName(key string) string {
+	name, _, _ := strings.Cut(key, "?")
+	return name
+}
+
+// PrecomputedQueries expands every enabled query into the selections the scheduler keeps cached: every
+// combination of the precompute values of the variables it references.
+func PrecomputedQueries(queries []config.PrometheusQuery, variables []config.DataVariable) []config.PrometheusQuery {
+	var expanded []config.PrometheusQuery
+
+	for _, query := range queries {
+		if query.Disabled {
+			continue
+		}
+
+		selections := []map[string]string{{}}
+		for _, name := range QueryVariables(query, variables) {
+			variable := findVariable(variables, name)
+
+			next := make([]map[string]string, 0, len(selections)*len(variable.Precompute))
+			for _, selection := range selections {
+				for _, value := range variable.Precompute {
+					combined := make(map[string]string, len(selection)+1)
+					for k, v := range selection {
+						combined[k] = v
+					}
+					combined[name] = value
+					next = append(next, combined)
+				}
+			}
+			selections = next
+		}
+
+		for _, selection := range selections {
+			expanded = append(expanded, ExpandQuery(query, selection))
+		}
+	}
+
+	return expanded
+}
+
+// IsPrecomputed reports whether the scheduler keeps the given selection of a query's variables cached.
+func IsPrecomputed(selection map[string]string, variables []config.DataVariable) bool {
+	for name, value := range selection {
+		variable := findVariable(variables, name)
+		if variable == nil || !slices.Contains(variable.Precompute, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func findVariable(variables []config.DataVariable, name string) *config.DataVariable {
+	for i := range variables {
+		if variables[i].Name == name {
+			return &variables[i]
+		}
+	}
+	return nil
+}
+
+// ResolveVariables selects a value for every variable a query references: the requested value if it is one
+// of the variable's options, otherwise the variable's default.
+func (s *Service) ResolveVariables(ctx context.Context, query config.PrometheusQuery, requested map[string]string) (map[string]string, error) {
+	names := QueryVariables(query, s.variables)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	selection := make(map[string]string, len(names))
+	for _, name := range names {
+		variable := findVariable(s.variables, name)
+
+		value := requested[name]
+		if value == "" || value == variable.Default {
+			selection[name] = variable.Default
+			continue
+		}
+
+		options, err := s.VariableOptions(ctx, *variable)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(options, value) {
+			return nil, fmt.Errorf("%w: %q is not an option of %s", ErrInvalidVariableValue, value, name)
+		}
+
+		selection[name] = value
+	}
+
+	return selection, nil
+}
+
+// VariableOptions returns the values a variable can take. Label values are looked up through the prometheus
+// source and cached for the variable's refresh interval.
+func (s *Service) VariableOptions(ctx context.Context, variable config.DataVariable) ([]string, error) {
+	if variable.LabelValues == nil {
+		return variable.Values, nil
+	}
+
+	key := variableOptionsKeyPrefix + variable.Name
+	if s.cache != nil {
+		if raw, err := s.cache.GetKey(ctx, key); err == nil && raw != "" {
+			var options []string
+			if err := json.Unmarshal([]byte(raw), &options); err == nil {
+				return options, nil
+			}
+		}
+	}
+
+	source, ok := s.sources[config.DataSourcePrometheus].(LabelValuesSource)
+	if !ok {
+		return nil, fmt.Errorf("no %s data source is configured for variable %s", config.DataSourcePrometheus, variable.Name)
+	}
+
+	options, err := source.LabelValues(ctx, variable.LabelValues.Backend, variable.LabelValues.Label, variable.LabelValues.Match)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up options of variable %s: %w", variable.Name, err)
+	}
+
+	if s.cache != nil {
+		if encoded, err := json.Marshal(options); err == nil {
+			if err := s.cache.SetKey(ctx, key, string(encoded), variable.LabelValues.RefreshInterval); err != nil {
+				s.logger.Warn("failed to cache variable options", "variable", variable.Name, "error", err)
+			}
+		}
+	}
+
+	return options, nil
+}
+
+// QueryOnDemand runs an expanded query whose selection is not precomputed and returns its result without
+// caching it. Each caller may run a limited number of them per window; beyond that ErrOnDemandRateLimited is
+// returned along with how long until the next one is allowed.
+func (s *Service) QueryOnDemand(ctx context.Context, query config.PrometheusQuery, caller string) (CachedData, time.Duration, error) {
+	if s.cache != nil && s.onDemand != nil {
+		key := "ratelimit:data:on_demand:" + caller
+		allowed, retryAfter, err := s.cache.SlidingWindowAllow(ctx, key, s.onDemand.RequestsPerWindow, s.onDemand.Window)
+		if err != nil {
+			return CachedData{}, 0, fmt.Errorf("failed to check on-demand rate limit: %w", err)
+		}
+		if !allowed {
+			return CachedData{}, retryAfter, ErrOnDemandRateLimited
+		}
+	}
+
+	result, warnings, err := s.runQuery(ctx, query)
+	if err != nil {
+		return CachedData{}, 0, err
+	}
+
+	cached := s.prepareCacheData(query.Name, result, query)
+	cached.Warnings = warnings
+	return cached, 0, nil
+}
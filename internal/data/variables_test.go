@@ -0,0 +1,157 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labelSource is a prometheus source that records label value lookups.
+type labelSource struct {
+	*countingSource
+	values  []string
+	lookups int
+}
+
+func (l *labelSource) LabelValues(ctx context.Context, backend, label string, matches []string) ([]string, error) {
+	l.lookups++
+	return l.values, nil
+}
+
+var testVariables = []config.DataVariable{
+	{Name: "namespace", Values: []string{"media", "monitoring", "default"}, Default: "media", Precompute: []string{"media", "monitoring"}},
+	{Name: "node", Values: []string{"a", "b"}, Default: "a", Precompute: []string{"a"}},
+	{Name: "instance", LabelValues: &config.DataVariableLabelValues{Label: "instance", RefreshInterval: time.Minute}, Default: "host:9100", Precompute: []string{"host:9100"}},
+}
+
+func TestQueryVariables(t *testing.T) {
+	query := config.PrometheusQuery{Name: "pods", Query: `sum(kube_pod_info{namespace="$namespace", node="${node}"}) + $namespace + $undefined`}
+	assert.Equal(t, []string{"namespace", "node"}, QueryVariables(query, testVariables))
+
+	httpQuery := config.PrometheusQuery{Name: "json", Source: config.DataSourceHTTPJSON, Query: "$namespace"}
+	assert.Empty(t, QueryVariables(httpQuery, testVariables), "only prometheus and loki queries are templated")
+}
+
+func TestExpandQuery(t *testing.T) {
+	query := config.PrometheusQuery{Name: "pods", Query: `kube_pod_info{namespace="$namespace", node="${node}", job="$undefined"}`}
+	expanded := ExpandQuery(query, map[string]string{"namespace": "media", "node": "a"})
+
+	assert.Equal(t, `kube_pod_info{namespace="media", node="a", job="$undefined"}`, expanded.Query)
+	assert.Equal(t, "pods", expanded.Name)
+	assert.Equal(t, "pods?namespace=media&node=a", QueryCacheKey(expanded))
+	assert.Equal(t, "pods", CacheKeyQueryName(QueryCacheKey(expanded)))
+	assert.Equal(t, "pods", QueryCacheKey(query))
+}
+
+func TestPrecomputedQueries(t *testing.T) {
+	queries := []config.PrometheusQuery{
+		{Name: "pods", Query: `kube_pod_info{namespace="$namespace", node="$node"}`},
+		{Name: "up", Query: "up"},
+		{Name: "disabled", Query: `up{namespace="$namespace"}`, Disabled: true},
+	}
+
+	var keys []string
+	for _, query := range PrecomputedQueries(queries, testVariables) {
+		keys = append(keys, QueryCacheKey(query))
+	}
+
+	assert.ElementsMatch(t, []string{"pods?namespace=media&node=a", "pods?namespace=monitoring&node=a", "up"}, keys)
+	assert.True(t, IsPrecomputed(map[string]string{"namespace": "monitoring", "node": "a"}, testVariables))
+	assert.False(t, IsPrecomputed(map[string]string{"namespace": "default", "node": "a"}, testVariables))
+}
+
+func newVariablesService(t *testing.T, source DataSource, onDemand *config.DataOnDemandConfig) (*Service, Provider) {
+	t.Helper()
+
+	cache, err := NewMemCache(&config.Config{}, slog.Default())
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: source}, cache, logger, nil).WithVariables(testVariables, onDemand)
+
+	return service, cache
+}
+
+func TestService_ResolveVariables(t *testing.T) {
+	ctx := context.Background()
+	source := &labelSource{countingSource: newCountingSource(0), values: []string{"host:9100", "nas:9100"}}
+	service, _ := newVariablesService(t, source, nil)
+
+	query := config.PrometheusQuery{Name: "load", Query: `node_load1{instance="$instance", namespace="$namespace"}`}
+
+	selection, err := service.ResolveVariables(ctx, query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"instance": "host:9100", "namespace": "media"}, selection, "defaults should be selected")
+
+	selection, err = service.ResolveVariables(ctx, query, map[string]string{"instance": "nas:9100", "namespace": "default"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"instance": "nas:9100", "namespace": "default"}, selection)
+
+	_, err = service.ResolveVariables(ctx, query, map[string]string{"instance": "nas:9100", "namespace": `media"} or up{`})
+	assert.ErrorIs(t, err, ErrInvalidVariableValue)
+
+	_, err = service.ResolveVariables(ctx, query, map[string]string{"instance": "unknown:9100"})
+	assert.ErrorIs(t, err, ErrInvalidVariableValue)
+
+	assert.Equal(t, 1, source.lookups, "label values should be looked up once and then cached")
+}
+
+func TestService_QueryOnDemandIsRateLimited(t *testing.T) {
+	ctx := context.Background()
+	source := newCountingSource(0)
+	service, cache := newVariablesService(t, source, &config.DataOnDemandConfig{RequestsPerWindow: 2, Window: time.Minute})
+
+	query := ExpandQuery(config.PrometheusQuery{Name: "pods", Query: `kube_pod_info{namespace="$namespace"}`}, map[string]string{"namespace": "default"})
+
+	for range 2 {
+		result, _, err := service.QueryOnDemand(ctx, query, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "pods", result.Name)
+		assert.Equal(t, map[string]string{"namespace": "default"}, result.Variables)
+		assert.Equal(t, "scalar", result.ValueType)
+	}
+
+	_, retryAfter, err := service.QueryOnDemand(ctx, query, "alice")
+	assert.ErrorIs(t, err, ErrOnDemandRateLimited)
+	assert.Positive(t, retryAfter)
+
+	_, _, err = service.QueryOnDemand(ctx, query, "bob")
+	assert.NoError(t, err, "the limit applies per caller")
+
+	assert.Equal(t, 3, source.count("pods"))
+	assert.Empty(t, cache.ListAll(ctx), "on-demand results should not be cached")
+}
+
+func TestScheduler_PrecomputesVariableSelections(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := NewMemCache(&config.Config{}, slog.Default())
+	source := newCountingSource(0)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	queries := []config.PrometheusQuery{{Name: "pods", Query: `kube_pod_info{namespace="$namespace"}`, TTL: time.Hour}}
+	service := NewService(map[string]DataSource{config.DataSourcePrometheus: source}, cache, logger, queries).WithVariables(testVariables, nil)
+	scheduler := NewScheduler(service, cache, &config.DataSchedulerConfig{Workers: 1}, time.Minute, logger)
+
+	runCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, scheduler.Run(runCtx), context.DeadlineExceeded)
+
+	assert.ElementsMatch(t, []string{"pods?namespace=media", "pods?namespace=monitoring"}, cache.ListAll(ctx))
+
+	cached, ok := cache.Get(ctx, "pods?namespace=monitoring")
+	require.True(t, ok)
+	assert.Equal(t, "pods", cached.Name)
+	assert.Equal(t, map[string]string{"namespace": "monitoring"}, cached.Variables)
+
+	status, ok := GetQueryScheduleStatus(ctx, cache, "pods?namespace=media")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"namespace": "media"}, status.Variables)
+	assert.NotNil(t, status.LastSuccess)
+}
@@ -0,0 +1,46 @@
+package data
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// queryWarningsKey is the context key of the collector a query's warnings are added to.
+type queryWarningsKey struct{}
+
+// queryWarnings collects the warnings a backend returned alongside a result. Sources add to it through the
+// query's context, so a result can carry warnings without every source having to return them.
+type queryWarnings struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+// withQueryWarnings returns a context that collects the warnings of the queries run with it.
+func withQueryWarnings(ctx context.Context) (context.Context, *queryWarnings) {
+	collector := &queryWarnings{}
+	return context.WithValue(ctx, queryWarningsKey{}, collector), collector
+}
+
+// addQueryWarnings adds warnings to the context's collector, if it has one. Repeated warnings are kept once.
+func addQueryWarnings(ctx context.Context, warnings ...string) {
+	collector, ok := ctx.Value(queryWarningsKey{}).(*queryWarnings)
+	if !ok {
+		return
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	for _, warning := range warnings {
+		if !slices.Contains(collector.warnings, warning) {
+			collector.warnings = append(collector.warnings, warning)
+		}
+	}
+}
+
+// list returns the collected warnings, or nil when there are none.
+func (w *queryWarnings) list() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.warnings)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"net/http"
+	"slices"
+)
+
+// ActiveAlert is a firing alert as shown in the dashboard's banner.
+type ActiveAlert struct {
+	Rule      string            `json:"rule"`
+	Severity  string            `json:"severity"`
+	Summary   string            `json:"summary,omitempty"`
+	QueryName string            `json:"query_name"`
+	Labels    map[string]string `json:"labels"`
+	Value     float64           `json:"value"`
+	FiredAt   int64             `json:"fired_at"`
+}
+
+// GetAlertsGET lists the firing alerts of rules on queries the caller may read, oldest first. Pending alerts
+// are left out until they fire.
+func GetAlertsGET(ctx *middlewares.AppContext) {
+	alerts := make([]ActiveAlert, 0)
+
+	if ctx.Config.Data.Alerts == nil || len(ctx.Config.Data.Alerts.Rules) == 0 || ctx.Storage == nil {
+		ctx.WriteJSON(http.StatusOK, alerts)
+		return
+	}
+
+	var userGroups []string
+	if user, userExists := ctx.SessionManager.GetAuthenticatedUser(ctx); userExists {
+		userGroups = user.Groups
+	}
+
+	active, err := ctx.Storage.GetActiveDataAlerts(ctx)
+	if err != nil {
+		ctx.Logger.Error("failed to get active alerts", "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for _, alert := range active {
+		if alert.State != models.DataAlertFiring || alert.FiredAt == nil {
+			continue
+		}
+
+		ruleIndex := slices.IndexFunc(ctx.Config.Data.Alerts.Rules, func(rule config.DataAlertRule) bool { return rule.Name == alert.RuleName })
+		if ruleIndex < 0 {
+			continue
+		}
+		rule := ctx.Config.Data.Alerts.Rules[ruleIndex]
+
+		query, found := findEnabledQuery(ctx.Config.Data.Queries, rule.Query)
+		if !found || (query.RequireAuth && !slices.Contains(userGroups, query.RequiredGroup)) {
+			continue
+		}
+
+		alerts = append(alerts, ActiveAlert{
+			Rule:      rule.Name,
+			Severity:  alert.Severity,
+			Summary:   rule.Summary,
+			QueryName: query.Name,
+			Labels:    alert.Labels,
+			Value:     alert.Value,
+			FiredAt:   alert.FiredAt.Unix(),
+		})
+	}
+
+	ctx.WriteJSON(http.StatusOK, alerts)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/testutil"
+	"testing"
+	"time"
+)
+
+func TestGetAlertsGET(t *testing.T) {
+	firedAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	active := []*models.DataAlert{
+		{ID: 1, RuleName: "node_not_ready", Severity: "critical", State: models.DataAlertFiring, Labels: map[string]string{"node": "a"}, Value: 0, FiredAt: &firedAt},
+		{ID: 2, RuleName: "node_not_ready", Severity: "critical", State: models.DataAlertPending, Labels: map[string]string{"node": "b"}, Value: 0},
+		{ID: 3, RuleName: "admin_disk_full", Severity: "warning", State: models.DataAlertFiring, Labels: map[string]string{}, Value: 95, FiredAt: &firedAt},
+	}
+
+	tests := []struct {
+		name          string
+		user          *models.User
+		expectedRules []string
+	}{
+		{
+			name:          "AnonymousShouldOnlySeePublicFiringAlerts",
+			expectedRules: []string{"node_not_ready"},
+		},
+		{
+			name:          "GroupMemberShouldSeeRestrictedAlerts",
+			user:          &models.User{Iss: "iss", Sub: "sub", Groups: []string{"admin"}},
+			expectedRules: []string{"node_not_ready", "admin_disk_full"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := testutil.NewTestContextWithURL(t, "GET", "/api/alerts")
+			defer tc.Finish()
+
+			tc.AppContext.Config.Data.Queries = []config.PrometheusQuery{
+				{Name: "node_status", Query: "kube_node_status_condition"},
+				{Name: "disk_usage", Query: "disk_used_percent", RequireAuth: true, RequiredGroup: "admin"},
+			}
+			tc.AppContext.Config.Data.Alerts = &config.DataAlerts{Rules: []config.DataAlertRule{
+				{Name: "node_not_ready", Query: "node_status", Condition: "< 1", Summary: "A node is not ready"},
+				{Name: "admin_disk_full", Query: "disk_usage", Condition: "> 90"},
+			}}
+
+			tc.MockSession.EXPECT().GetAuthenticatedUser(tc.AppContext).Return(tt.user, tt.user != nil)
+			tc.MockStorageProvider.EXPECT().GetActiveDataAlerts(tc.AppContext).Return(active, nil)
+
+			tc.CallHandler(GetAlertsGET)
+
+			tc.AssertStatus(t, 200)
+			alerts := tc.GetJSONResponseArray(t)
+			if len(alerts) != len(tt.expectedRules) {
+				t.Fatalf("Expected %d alerts, got %v", len(tt.expectedRules), alerts)
+			}
+
+			for i, rule := range tt.expectedRules {
+				alert := alerts[i].(map[string]interface{})
+				if alert["rule"] != rule {
+					t.Errorf("Expected alert %d to be %s, got %v", i, rule, alert["rule"])
+				}
+			}
+
+			first := alerts[0].(map[string]interface{})
+			if first["summary"] != "A node is not ready" || first["fired_at"] != float64(firedAt.Unix()) {
+				t.Errorf("Expected the rule's summary and firing time, got %v", first)
+			}
+		})
+	}
+}
@@ -1,54 +1,216 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/config"
 	"homelab-dashboard/internal/data"
 	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/utils"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// dataStreamKeepAlive is how often an idle data stream sends a comment, so proxies do not close it.
+const dataStreamKeepAlive = 30 * time.Second
+
+// dataStreamRetry is the reconnect delay, in milliseconds, suggested to EventSource clients.
+const dataStreamRetry = 5000
+
+// variableParamPrefix prefixes the query parameters that select template variable values, as in ?var.namespace=media.
+const variableParamPrefix = "var."
+
+// dataRequest is who is asking for data and which template variable values they selected.
+type dataRequest struct {
+	userGroups []string
+	variables  map[string]string
+	caller     string
+}
+
+func newDataRequest(ctx *middlewares.AppContext) dataRequest {
+	var request dataRequest
+
+	if user, userExists := ctx.SessionManager.GetAuthenticatedUser(ctx); userExists {
+		request.userGroups = user.Groups
+		request.caller = user.Iss + "|" + user.Sub
+	} else if host, _, err := net.SplitHostPort(ctx.Request.RemoteAddr); err == nil {
+		request.caller = host
+	} else {
+		request.caller = ctx.Request.RemoteAddr
+	}
+
+	request.variables = requestedVariables(ctx.Request.URL.Query())
+
+	return request
+}
+
+// requestedVariables collects the var.<name> query parameters.
+func requestedVariables(params url.Values) map[string]string {
+	variables := make(map[string]string)
+	for param, values := range params {
+		if name, ok := strings.CutPrefix(param, variableParamPrefix); ok && name != "" && len(values) > 0 {
+			variables[name] = values[0]
+		}
+	}
+	return variables
+}
+
 func GetMetricsGET(ctx *middlewares.AppContext) {
 	queryParam := ctx.Request.URL.Query().Get("queries")
 	queries := strings.Split(queryParam, ",")
-	user, userExists := ctx.SessionManager.GetAuthenticatedUser(ctx)
-
-	var userGroups []string
-	if userExists {
-		userGroups = user.Groups
-	}
+	request := newDataRequest(ctx)
 
-	var resultData []ResultData
 	if queryParam == "" {
-		resultData = addRecordsIfAuthorized(ctx, ctx.Cache.ListAll(ctx.Context), userGroups)
-	} else {
-		resultData = addRecordsIfAuthorized(ctx, queries, userGroups)
+		queries = cachedQueryNames(ctx)
+	}
 
+	resultData, err := addRecordsIfAuthorized(ctx, queries, request)
+	if err != nil {
+		writeDataRequestError(ctx, err)
+		return
 	}
 
 	ctx.WriteJSON(http.StatusOK, resultData)
 }
 
+// cachedQueryNames lists the queries with a cached result, each once however many variable selections of it
+// are cached.
+func cachedQueryNames(ctx *middlewares.AppContext) []string {
+	var names []string
+	for _, key := range ctx.Cache.ListAll(ctx.Context) {
+		if name := data.CacheKeyQueryName(key); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// writeDataRequestError answers a request whose variable selection is invalid or whose on-demand queries are
+// rate limited.
+func writeDataRequestError(ctx *middlewares.AppContext, err error) {
+	var rateLimited *onDemandRateLimitError
+	switch {
+	case errors.Is(err, data.ErrInvalidVariableValue):
+		ctx.SetJSONError(http.StatusBadRequest, err.Error())
+	case errors.As(err, &rateLimited):
+		seconds := utils.RetryAfterSeconds(rateLimited.retryAfter)
+		ctx.Response.Header().Set("Retry-After", strconv.Itoa(seconds))
+		ctx.SetJSONError(http.StatusTooManyRequests,
+			fmt.Sprintf("Too many queries for variable selections that are not precomputed, try again in %d seconds", seconds))
+	default:
+		ctx.Logger.Error("failed to look up data", "error", err)
+		ctx.SetJSONError(http.StatusBadGateway, "Failed to query data source")
+	}
+}
+
+type onDemandRateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *onDemandRateLimitError) Error() string {
+	return data.ErrOnDemandRateLimited.Error()
+}
+
+// templatedQuery returns the enabled query with the given name if it references template variables.
+func templatedQuery(ctx *middlewares.AppContext, name string) (config.PrometheusQuery, bool) {
+	query, found := findEnabledQuery(ctx.Config.Data.Queries, name)
+	return query, found && len(data.QueryVariables(query, ctx.Config.Data.Variables)) > 0
+}
+
+// selectedCacheKey returns the cache key of the query's result for the request's variable selection.
+func selectedCacheKey(ctx *middlewares.AppContext, name string, request dataRequest) (string, error) {
+	query, templated := templatedQuery(ctx, name)
+	if !templated || ctx.DataService == nil {
+		return name, nil
+	}
+
+	selection, err := ctx.DataService.ResolveVariables(ctx, query, request.variables)
+	if err != nil {
+		return "", err
+	}
+
+	return data.QueryCacheKey(data.ExpandQuery(query, selection)), nil
+}
+
+// lookupCachedData returns a query's result for the request's variable selection. Precomputed selections are
+// read from the cache; any other selection is run on demand, but only for callers allowed to read the query.
+func lookupCachedData(ctx *middlewares.AppContext, name string, request dataRequest) (data.CachedData, bool, error) {
+	query, templated := templatedQuery(ctx, name)
+	if !templated || ctx.DataService == nil {
+		entry, exists := ctx.Cache.Get(ctx.Context, name)
+		return entry, exists, nil
+	}
+
+	if query.RequireAuth && !slices.Contains(request.userGroups, query.RequiredGroup) {
+		return data.CachedData{}, false, nil
+	}
+
+	selection, err := ctx.DataService.ResolveVariables(ctx, query, request.variables)
+	if err != nil {
+		return data.CachedData{}, false, err
+	}
+
+	expanded := data.ExpandQuery(query, selection)
+	if data.IsPrecomputed(selection, ctx.Config.Data.Variables) {
+		entry, exists := ctx.Cache.Get(ctx.Context, data.QueryCacheKey(expanded))
+		return entry, exists, nil
+	}
+
+	entry, retryAfter, err := ctx.DataService.QueryOnDemand(ctx, expanded, request.caller)
+	if errors.Is(err, data.ErrOnDemandRateLimited) {
+		return data.CachedData{}, false, &onDemandRateLimitError{retryAfter: retryAfter}
+	}
+	if err != nil {
+		return data.CachedData{}, false, err
+	}
+
+	return entry, true, nil
+}
+
+// convertCachedDataToResultData reports stale entries as such instead of hiding them, so panels keep showing
+// the last known value while the backend is unreachable.
 func convertCachedDataToResultData(data *data.CachedData) (*ResultData, error) {
-	return &ResultData{
+	now := time.Now()
+
+	result := &ResultData{
 		QueryName: data.Name,
 		Type:      data.ValueType,
 		Data:      data.JSONBytes,
-	}, nil
+		Variables: data.Variables,
+		Warnings:  data.Warnings,
+		Stale:     data.IsStale(now),
+	}
+
+	if !data.Timestamp.IsZero() {
+		result.Timestamp = data.Timestamp.Unix()
+		result.AgeSeconds = math.Round(data.Age(now).Seconds())
+	}
+
+	return result, nil
 }
 
-func addRecordsIfAuthorized(ctx *middlewares.AppContext, queryNames []string, userGroups []string) []ResultData {
+func addRecordsIfAuthorized(ctx *middlewares.AppContext, queryNames []string, request dataRequest) ([]ResultData, error) {
 	var resultData []ResultData
 	resultData = make([]ResultData, 0, len(queryNames))
 
 	for _, entryName := range queryNames {
-		entry, exists := ctx.Cache.Get(ctx.Context, entryName)
+		entry, exists, err := lookupCachedData(ctx, entryName, request)
+		if err != nil {
+			return nil, err
+		}
 		if !exists {
 			continue
 		}
 
 		if entry.RequireAuth {
-			if canAccess := slices.Contains(userGroups, entry.RequiredGroup); canAccess {
+			if canAccess := slices.Contains(request.userGroups, entry.RequiredGroup); canAccess {
 				dataRecord, err := convertCachedDataToResultData(&entry)
 				if err != nil {
 					ctx.Logger.Error("failed to add cached data to result", "error", err)
@@ -68,25 +230,25 @@ func addRecordsIfAuthorized(ctx *middlewares.AppContext, queryNames []string, us
 		}
 	}
 
-	return resultData
+	return resultData, nil
 }
 
 func GetQueriesGET(ctx *middlewares.AppContext) {
 	queryParam := ctx.Request.URL.Query().Get("queries")
 	queries := strings.Split(queryParam, ",")
-	user, userExists := ctx.SessionManager.GetAuthenticatedUser(ctx)
 
-	var userGroups []string
-	if userExists {
-		userGroups = user.Groups
-	}
+	// Only the names are listed, so variable selections are ignored rather than run on demand.
+	request := newDataRequest(ctx)
+	request.variables = nil
 
-	var resultData []ResultData
 	if queryParam == "" {
-		resultData = addRecordsIfAuthorized(ctx, ctx.Cache.ListAll(ctx.Context), userGroups)
-	} else {
-		resultData = addRecordsIfAuthorized(ctx, queries, userGroups)
+		queries = cachedQueryNames(ctx)
+	}
 
+	resultData, err := addRecordsIfAuthorized(ctx, queries, request)
+	if err != nil {
+		writeDataRequestError(ctx, err)
+		return
 	}
 
 	dataNames := make([]string, 0, len(resultData))
@@ -96,3 +258,173 @@ func GetQueriesGET(ctx *middlewares.AppContext) {
 
 	ctx.WriteJSON(http.StatusOK, dataNames)
 }
+
+// GetDataStreamGET streams query results as Server-Sent Events: every accessible query once on connect, then each
+// query again whenever its cached data changes. ?queries= limits the stream like it limits GetMetricsGET, and the
+// same require_auth/required_group rules apply, evaluated against the session at connect time.
+func GetDataStreamGET(ctx *middlewares.AppContext) {
+	controller := http.NewResponseController(ctx.Response)
+
+	var wanted []string
+	for _, name := range strings.Split(ctx.Request.URL.Query().Get("queries"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted = append(wanted, name)
+		}
+	}
+
+	request := newDataRequest(ctx)
+
+	// Subscribe before reading the snapshot so a change made in between is not missed.
+	updates := ctx.Cache.SubscribeUpdates(ctx)
+
+	snapshot := wanted
+	if len(snapshot) == 0 {
+		snapshot = cachedQueryNames(ctx)
+	}
+	initial, err := addRecordsIfAuthorized(ctx, snapshot, request)
+	if err != nil {
+		writeDataRequestError(ctx, err)
+		return
+	}
+
+	ctx.Response.Header().Set("Content-Type", "text/event-stream")
+	ctx.Response.Header().Set("Cache-Control", "no-cache")
+	ctx.Response.Header().Set("Connection", "keep-alive")
+	ctx.Response.Header().Set("X-Accel-Buffering", "no")
+	ctx.Response.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(ctx.Response, "retry: %d\n\n", dataStreamRetry); err != nil {
+		return
+	}
+
+	if err := writeDataStreamEvents(ctx, initial); err != nil {
+		return
+	}
+	if err := controller.Flush(); err != nil {
+		ctx.Logger.Error("data stream requires a flushable response", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(dataStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(ctx.Response, ": keep-alive\n\n"); err != nil {
+				return
+			}
+
+		case key, ok := <-updates:
+			if !ok {
+				return
+			}
+			name := data.CacheKeyQueryName(key)
+			if len(wanted) > 0 && !slices.Contains(wanted, name) {
+				continue
+			}
+			// Only forward the variable selection this stream was opened with.
+			if selected, err := selectedCacheKey(ctx, name, request); err != nil || selected != key {
+				continue
+			}
+			results, err := addRecordsIfAuthorized(ctx, []string{name}, request)
+			if err != nil {
+				ctx.Logger.Warn("failed to look up updated data", "query", key, "error", err)
+				continue
+			}
+			if err := writeDataStreamEvents(ctx, results); err != nil {
+				return
+			}
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeDataStreamEvents writes one message event per result, carrying the same JSON as an /api/data entry.
+func writeDataStreamEvents(ctx *middlewares.AppContext, results []ResultData) error {
+	for _, result := range results {
+		payload, err := json.Marshal(result)
+		if err != nil {
+			ctx.Logger.Error("failed to encode data stream event", "query", result.QueryName, "error", err)
+			continue
+		}
+
+		if _, err := fmt.Fprintf(ctx.Response, "data: %s\n\n", payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetDataStatusGET reports, for every query the caller may read, when it last ran and succeeded and when it
+// runs next. Queries the scheduler has not picked up yet are listed without times.
+func GetDataStatusGET(ctx *middlewares.AppContext) {
+	var userGroups []string
+	if user, userExists := ctx.SessionManager.GetAuthenticatedUser(ctx); userExists {
+		userGroups = user.Groups
+	}
+
+	queries := data.PrecomputedQueries(ctx.Config.Data.Queries, ctx.Config.Data.Variables)
+	statuses := make([]data.QueryScheduleStatus, 0, len(queries))
+	for _, query := range queries {
+		if query.RequireAuth && !slices.Contains(userGroups, query.RequiredGroup) {
+			continue
+		}
+
+		status, ok := data.GetQueryScheduleStatus(ctx, ctx.Cache, data.QueryCacheKey(query))
+		if !ok {
+			status = data.QueryScheduleStatus{
+				Name:       query.Name,
+				Variables:  query.Variables,
+				Source:     query.Source,
+				TTLSeconds: query.TTL.Seconds(),
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	ctx.WriteJSON(http.StatusOK, statuses)
+}
+
+// DataVariableOptions describes a template variable to the frontend, so it can offer its options for selection.
+type DataVariableOptions struct {
+	Name       string   `json:"name"`
+	Default    string   `json:"default"`
+	Options    []string `json:"options"`
+	Precompute []string `json:"precompute"`
+}
+
+// GetDataVariablesGET lists the template variables with their options. A variable whose label values cannot
+// be looked up is listed with only its default, so the dashboard still renders.
+func GetDataVariablesGET(ctx *middlewares.AppContext) {
+	variables := make([]DataVariableOptions, 0, len(ctx.Config.Data.Variables))
+
+	for _, variable := range ctx.Config.Data.Variables {
+		options := variable.Values
+		if ctx.DataService != nil {
+			found, err := ctx.DataService.VariableOptions(ctx, variable)
+			if err != nil {
+				ctx.Logger.Warn("failed to look up variable options", "variable", variable.Name, "error", err)
+				found = []string{variable.Default}
+			}
+			options = found
+		}
+
+		variables = append(variables, DataVariableOptions{
+			Name:       variable.Name,
+			Default:    variable.Default,
+			Options:    options,
+			Precompute: variable.Precompute,
+		})
+	}
+
+	ctx.WriteJSON(http.StatusOK, variables)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"fmt"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxHistoryPoints bounds the buckets per series a history request may return.
+const maxHistoryPoints = 2000
+
+// defaultHistoryWindow is the window returned when a history request does not set a start.
+const defaultHistoryWindow = 30 * 24 * time.Hour
+
+// DataHistoryResult is one rollup of a query's recorded history between start and end.
+type DataHistoryResult struct {
+	QueryName  string                       `json:"query_name"`
+	Variables  map[string]string            `json:"variables,omitempty"`
+	Resolution models.DataHistoryResolution `json:"resolution"`
+	Start      int64                        `json:"start"`
+	End        int64                        `json:"end"`
+	Series     []DataHistorySeries          `json:"series"`
+}
+
+// DataHistorySeries is the recorded history of one series of a query.
+type DataHistorySeries struct {
+	Labels map[string]string  `json:"labels"`
+	Points []DataHistoryPoint `json:"points"`
+}
+
+// DataHistoryPoint summarises the samples recorded within one bucket, which starts at Timestamp.
+type DataHistoryPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Samples   int     `json:"samples"`
+	Avg       float64 `json:"avg"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	Last      float64 `json:"last"`
+}
+
+// GetDataHistoryGET returns the long-term history of a query or probe that records it, read from storage rather than
+// the TSDB: /api/data/{query}/history?start=&end=&resolution=. Start and end are RFC 3339 or unix timestamps
+// and default to the last 30 days; resolution is one of 5m, 1h or 1d and defaults to the finest that fits the
+// window. Template variables are selected as on /api/data.
+func GetDataHistoryGET(ctx *middlewares.AppContext) {
+	request := newDataRequest(ctx)
+
+	name := chi.URLParam(ctx.Request, "query")
+	// Probes record their results like queries do
+	query, found := findEnabledQuery(slices.Concat(ctx.Config.Data.Queries, data.ProbeQueries(ctx.Config.Data.Probes)), name)
+	if !found || (query.RequireAuth && !slices.Contains(request.userGroups, query.RequiredGroup)) {
+		ctx.SetJSONError(http.StatusNotFound, "Query not found")
+		return
+	}
+
+	if ctx.Config.Data.History == nil || !ctx.Config.Data.History.Enabled || !data.RecordsHistory(query) {
+		ctx.SetJSONError(http.StatusNotFound, "History is not recorded for this query")
+		return
+	}
+
+	if ctx.Storage == nil {
+		ctx.SetJSONError(http.StatusServiceUnavailable, "Storage is not available")
+		return
+	}
+
+	start, end, resolution, err := parseHistoryWindow(ctx.Request.URL.Query(), time.Now())
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var selection map[string]string
+	if ctx.DataService != nil {
+		selection, err = ctx.DataService.ResolveVariables(ctx, query, request.variables)
+		if err != nil {
+			writeDataRequestError(ctx, err)
+			return
+		}
+	}
+
+	points, err := ctx.Storage.GetDataHistory(ctx, query.Name, data.HistoryVariablesKey(selection), resolution, start, end)
+	if err != nil {
+		ctx.Logger.Error("failed to get data history", "query", name, "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	ctx.WriteJSON(http.StatusOK, DataHistoryResult{
+		QueryName:  query.Name,
+		Variables:  selection,
+		Resolution: resolution,
+		Start:      start.Unix(),
+		End:        end.Unix(),
+		Series:     groupDataHistory(points),
+	})
+}
+
+// parseHistoryWindow reads the start, end and resolution parameters. Without a resolution the finest one that
+// keeps the window within maxHistoryPoints buckets is picked.
+func parseHistoryWindow(params url.Values, now time.Time) (time.Time, time.Time, models.DataHistoryResolution, error) {
+	end := now
+	if raw := params.Get("end"); raw != "" {
+		parsed, err := parseRangeTime(raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("invalid end: %w", err)
+		}
+		end = parsed
+	}
+
+	start := end.Add(-defaultHistoryWindow)
+	if raw := params.Get("start"); raw != "" {
+		parsed, err := parseRangeTime(raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("invalid start: %w", err)
+		}
+		start = parsed
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("start must be before end")
+	}
+
+	window := end.Sub(start)
+
+	if raw := params.Get("resolution"); raw != "" {
+		resolution := models.DataHistoryResolution(raw)
+		if !slices.Contains(models.DataHistoryResolutions, resolution) {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("invalid resolution %q, must be one of 5m, 1h or 1d", raw)
+		}
+		if window/resolution.Duration() > maxHistoryPoints {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("a %s resolution returns more than %d points for this window", raw, maxHistoryPoints)
+		}
+		return start, end, resolution, nil
+	}
+
+	for _, resolution := range models.DataHistoryResolutions {
+		if window/resolution.Duration() <= maxHistoryPoints {
+			return start, end, resolution, nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, "", fmt.Errorf("window cannot be longer than %d days", maxHistoryPoints)
+}
+
+// groupDataHistory splits points, ordered by series and bucket, into their series.
+func groupDataHistory(points []models.DataHistoryPoint) []DataHistorySeries {
+	series := []DataHistorySeries{}
+
+	for i, point := range points {
+		if i == 0 || point.Series != points[i-1].Series {
+			series = append(series, DataHistorySeries{Labels: point.Labels})
+		}
+
+		current := &series[len(series)-1]
+		current.Points = append(current.Points, DataHistoryPoint{
+			Timestamp: point.Bucket.Unix(),
+			Samples:   point.SampleCount,
+			Avg:       point.Avg,
+			Min:       point.Min,
+			Max:       point.Max,
+			Last:      point.Last,
+		})
+	}
+
+	return series
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/testutil"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseHistoryWindow(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testName           string
+		query              string
+		expectError        bool
+		expectedStart      time.Time
+		expectedResolution models.DataHistoryResolution
+	}{
+		{
+			testName:           "ShouldDefaultToLast30DaysHourly",
+			query:              "",
+			expectedStart:      now.Add(-30 * 24 * time.Hour),
+			expectedResolution: models.DataHistoryHourly,
+		},
+		{
+			testName:           "ShouldPickFiveMinutesForShortWindows",
+			query:              "start=2026-03-09T12:00:00Z",
+			expectedStart:      now.Add(-24 * time.Hour),
+			expectedResolution: models.DataHistoryFiveMinutes,
+		},
+		{
+			testName:           "ShouldPickDailyForYearOverYear",
+			query:              "start=2024-03-10T12:00:00Z",
+			expectedStart:      time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+			expectedResolution: models.DataHistoryDaily,
+		},
+		{
+			testName:           "ShouldAcceptExplicitResolution",
+			query:              "start=2026-03-09T12:00:00Z&resolution=1d",
+			expectedStart:      now.Add(-24 * time.Hour),
+			expectedResolution: models.DataHistoryDaily,
+		},
+		{
+			testName:    "ShouldRejectUnknownResolution",
+			query:       "resolution=1w",
+			expectError: true,
+		},
+		{
+			testName:    "ShouldRejectTooFineResolution",
+			query:       "start=2025-03-10T12:00:00Z&resolution=5m",
+			expectError: true,
+		},
+		{
+			testName:    "ShouldRejectStartAfterEnd",
+			query:       "start=2026-03-11T00:00:00Z",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			params, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+
+			start, end, resolution, err := parseHistoryWindow(params, now)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStart, start)
+			require.Equal(t, now, end)
+			require.Equal(t, tc.expectedResolution, resolution)
+		})
+	}
+}
+
+func TestGetDataHistoryGET(t *testing.T) {
+	history := &config.DataQueryHistory{Enabled: true}
+	queries := []config.PrometheusQuery{
+		{Name: "power_usage", Query: "sum(power_watts)", History: history},
+		{Name: "admin_only", Query: "up", RequireAuth: true, RequiredGroup: "admin", History: history},
+		{Name: "cpu_usage", Query: "up"},
+	}
+
+	bucket := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	points := []models.DataHistoryPoint{
+		{Series: `{instance="a"}`, Labels: map[string]string{"instance": "a"}, Bucket: bucket, SampleCount: 12, Avg: 100, Min: 90, Max: 110, Last: 95},
+		{Series: `{instance="a"}`, Labels: map[string]string{"instance": "a"}, Bucket: bucket.Add(time.Hour), SampleCount: 12, Avg: 120, Min: 100, Max: 130, Last: 125},
+		{Series: `{instance="b"}`, Labels: map[string]string{"instance": "b"}, Bucket: bucket, SampleCount: 12, Avg: 50, Min: 40, Max: 60, Last: 55},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		user           *models.User
+		expectedStatus int
+	}{
+		{
+			name:           "QueryWithoutHistoryShouldNotBeFound",
+			query:          "cpu_usage",
+			expectedStatus: 404,
+		},
+		{
+			name:           "RestrictedQueryShouldNotBeFoundOutsideGroup",
+			query:          "admin_only",
+			user:           &models.User{Iss: "iss", Sub: "sub", Groups: []string{"users"}},
+			expectedStatus: 404,
+		},
+		{
+			name:           "PublicQueryShouldReturnHistory",
+			query:          "power_usage",
+			expectedStatus: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := testutil.NewTestContextWithURL(t, "GET", "/api/data/"+tt.query+"/history?start=2026-03-09T00:00:00Z&end=2026-03-10T00:00:00Z&resolution=1h")
+			defer tc.Finish()
+			tc.WithURLParam("query", tt.query)
+
+			tc.AppContext.Config.Data.Queries = queries
+			tc.AppContext.Config.Data.History = &config.DataHistory{Enabled: true}
+			tc.MockSession.EXPECT().GetAuthenticatedUser(tc.AppContext).Return(tt.user, tt.user != nil)
+
+			if tt.expectedStatus == 200 {
+				tc.MockStorageProvider.EXPECT().
+					GetDataHistory(gomock.Any(), tt.query, "", models.DataHistoryHourly, bucket, bucket.Add(24*time.Hour)).
+					Return(points, nil)
+			}
+
+			tc.CallHandler(GetDataHistoryGET)
+
+			tc.AssertStatus(t, tt.expectedStatus)
+			if tt.expectedStatus != 200 {
+				return
+			}
+
+			result := tc.GetJSONResponse(t)
+			if result["query_name"] != tt.query || result["resolution"] != "1h" {
+				t.Errorf("Expected the hourly history of %s, got %v", tt.query, result)
+			}
+
+			series, ok := result["series"].([]interface{})
+			if !ok || len(series) != 2 {
+				t.Fatalf("Expected 2 series, got %v", result["series"])
+			}
+
+			first := series[0].(map[string]interface{})
+			if len(first["points"].([]interface{})) != 2 {
+				t.Errorf("Expected the first series to have 2 points, got %v", first["points"])
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/utils"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// RangeResultData is a query's result over a requested window, along with the window it was normalised to.
+type RangeResultData struct {
+	ResultData
+	Start int64   `json:"start"`
+	End   int64   `json:"end"`
+	Step  float64 `json:"step"`
+}
+
+// GetDataRangeGET runs a configured prometheus query over a window chosen by the caller, e.g. to zoom into
+// last night's outage: /api/data/{query}/range?start=&end=&step=. Start and end are RFC 3339 or unix
+// timestamps, and default to the last hour; step is a duration or a number of seconds and is picked to fit the
+// window when omitted. Template variables are selected as on /api/data.
+func GetDataRangeGET(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	name := chi.URLParam(ctx.Request, "query")
+	query, found := findEnabledQuery(ctx.Config.Data.Queries, name)
+	if !found || (query.RequireAuth && !slices.Contains(principal.GetGroups(), query.RequiredGroup)) {
+		ctx.SetJSONError(http.StatusNotFound, "Query not found")
+		return
+	}
+
+	if query.Source != "" && query.Source != config.DataSourcePrometheus {
+		ctx.SetJSONError(http.StatusBadRequest, "Range queries are only supported for prometheus queries")
+		return
+	}
+
+	if ctx.DataService == nil {
+		ctx.SetJSONError(http.StatusServiceUnavailable, "Data service is not available")
+		return
+	}
+
+	params := ctx.Request.URL.Query()
+	maxRange, maxPoints := data.RangeLimits(query, ctx.Config.Data.RangeQueries)
+
+	window, err := parseRangeWindow(params, time.Now(), maxRange, maxPoints)
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	selection, err := ctx.DataService.ResolveVariables(ctx, query, requestedVariables(params))
+	if err != nil {
+		writeDataRequestError(ctx, err)
+		return
+	}
+
+	caller := principal.GetIss() + "|" + principal.GetSub()
+	result, retryAfter, err := ctx.DataService.QueryRangeWindow(ctx, data.ExpandQuery(query, selection), window, caller, ctx.Config.Data.RangeQueries)
+	if errors.Is(err, data.ErrOnDemandRateLimited) {
+		writeDataRequestError(ctx, &onDemandRateLimitError{retryAfter: retryAfter})
+		return
+	}
+	if err != nil {
+		writeDataRequestError(ctx, err)
+		return
+	}
+
+	resultData, err := convertCachedDataToResultData(&result)
+	if err != nil {
+		ctx.Logger.Error("failed to convert range query result", "query", name, "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	ctx.WriteJSON(http.StatusOK, RangeResultData{
+		ResultData: *resultData,
+		Start:      window.Start.Unix(),
+		End:        window.End.Unix(),
+		Step:       window.Step.Seconds(),
+	})
+}
+
+// findEnabledQuery returns the enabled query with the given name.
+func findEnabledQuery(queries []config.PrometheusQuery, name string) (config.PrometheusQuery, bool) {
+	for _, query := range queries {
+		if query.Name == name && !query.Disabled {
+			return query, true
+		}
+	}
+	return config.PrometheusQuery{}, false
+}
+
+// parseRangeWindow reads the start, end and step parameters and normalises them into a window within limits.
+func parseRangeWindow(params url.Values, now time.Time, maxRange time.Duration, maxPoints int) (v1.Range, error) {
+	end := now
+	if raw := params.Get("end"); raw != "" {
+		parsed, err := parseRangeTime(raw)
+		if err != nil {
+			return v1.Range{}, fmt.Errorf("invalid end: %w", err)
+		}
+		end = parsed
+	}
+
+	start := end.Add(-time.Hour)
+	if raw := params.Get("start"); raw != "" {
+		parsed, err := parseRangeTime(raw)
+		if err != nil {
+			return v1.Range{}, fmt.Errorf("invalid start: %w", err)
+		}
+		start = parsed
+	}
+
+	var step time.Duration
+	if raw := params.Get("step"); raw != "" {
+		parsed, err := utils.ParseDurationString(raw)
+		if err != nil || parsed <= 0 {
+			return v1.Range{}, fmt.Errorf("invalid step %q", raw)
+		}
+		step = parsed
+	}
+
+	return data.NormalizeRangeWindow(start, end, step, now, maxRange, maxPoints)
+}
+
+// parseRangeTime accepts RFC 3339 times and unix timestamps in seconds, like the Prometheus HTTP API.
+func parseRangeTime(raw string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a unix timestamp", raw)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/testutil"
+	"net/url"
+	"testing"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRangeWindow(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testName    string
+		query       string
+		expectError bool
+		expected    v1.Range
+	}{
+		{
+			testName: "ShouldDefaultToLastHour",
+			query:    "",
+			expected: v1.Range{Start: now.Add(-time.Hour), End: now, Step: 15 * time.Second},
+		},
+		{
+			testName: "ShouldAcceptRFC3339AndDurationStep",
+			query:    "start=2026-03-10T01:00:00Z&end=2026-03-10T03:00:00Z&step=5m",
+			expected: v1.Range{Start: time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC), Step: 5 * time.Minute},
+		},
+		{
+			testName: "ShouldAcceptUnixTimestampsAndSecondsStep",
+			query:    "start=1773104400&end=1773111600&step=60",
+			expected: v1.Range{Start: time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC), Step: time.Minute},
+		},
+		{
+			testName:    "ShouldRejectInvalidStart",
+			query:       "start=yesterday",
+			expectError: true,
+		},
+		{
+			testName:    "ShouldRejectInvalidStep",
+			query:       "step=soon",
+			expectError: true,
+		},
+		{
+			testName:    "ShouldRejectWindowAboveMaximum",
+			query:       "start=2026-03-01T00:00:00Z&end=2026-03-10T00:00:00Z&step=1h",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			params, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+
+			window, err := parseRangeWindow(params, now, 7*24*time.Hour, 1000)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, window)
+		})
+	}
+}
+
+// matrixSource answers range queries with a single series.
+type matrixSource struct{}
+
+func (matrixSource) Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error) {
+	return &model.Scalar{Value: 1}, nil
+}
+
+func (matrixSource) QueryRange(ctx context.Context, query config.PrometheusQuery, r v1.Range) (model.Value, error) {
+	return model.Matrix{{Metric: model.Metric{"job": "node"}, Values: []model.SamplePair{{Timestamp: model.TimeFromUnix(r.Start.Unix()), Value: 1}}}}, nil
+}
+
+func TestGetDataRangeGET(t *testing.T) {
+	queries := []config.PrometheusQuery{
+		{Name: "cpu_usage", Source: config.DataSourcePrometheus, Query: "up"},
+		{Name: "admin_only", Source: config.DataSourcePrometheus, Query: "up", RequireAuth: true, RequiredGroup: "admin"},
+		{Name: "nodes", Source: config.DataSourceKubernetes, Query: config.DataKubernetesNodes},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		params         string
+		user           *models.User
+		expectedStatus int
+	}{
+		{
+			name:           "UnauthenticatedShouldBeRejected",
+			query:          "cpu_usage",
+			expectedStatus: 401,
+		},
+		{
+			name:           "UnknownQueryShouldNotBeFound",
+			query:          "missing",
+			user:           &models.User{Iss: "iss", Sub: "sub"},
+			expectedStatus: 404,
+		},
+		{
+			name:           "RestrictedQueryShouldNotBeFoundOutsideGroup",
+			query:          "admin_only",
+			user:           &models.User{Iss: "iss", Sub: "sub", Groups: []string{"users"}},
+			expectedStatus: 404,
+		},
+		{
+			name:           "NonPrometheusQueryShouldBeRejected",
+			query:          "nodes",
+			user:           &models.User{Iss: "iss", Sub: "sub"},
+			expectedStatus: 400,
+		},
+		{
+			name:           "InvalidWindowShouldBeRejected",
+			query:          "cpu_usage",
+			params:         "?start=2026-03-10T03:00:00Z&end=2026-03-10T01:00:00Z",
+			user:           &models.User{Iss: "iss", Sub: "sub"},
+			expectedStatus: 400,
+		},
+		{
+			name:           "ValidWindowShouldReturnMatrix",
+			query:          "admin_only",
+			params:         "?start=2026-03-10T01:00:00Z&end=2026-03-10T03:00:00Z&step=5m",
+			user:           &models.User{Iss: "iss", Sub: "sub", Groups: []string{"admin"}},
+			expectedStatus: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := testutil.NewTestContextWithURL(t, "GET", "/api/data/"+tt.query+"/range"+tt.params)
+			defer tc.Finish()
+			tc.WithURLParam("query", tt.query)
+
+			tc.AppContext.Config.Data.Queries = queries
+			if tt.user != nil {
+				tc.AppContext.SetPrincipal(tt.user)
+			}
+
+			serviceCache, err := data.NewMemCache(&config.Config{}, tc.AppContext.Logger)
+			require.NoError(t, err)
+			tc.AppContext.DataService = data.NewService(map[string]data.DataSource{config.DataSourcePrometheus: matrixSource{}}, serviceCache, tc.AppContext.Logger, queries)
+
+			tc.CallHandler(GetDataRangeGET)
+
+			tc.AssertStatus(t, tt.expectedStatus)
+			if tt.expectedStatus != 200 {
+				return
+			}
+
+			result := tc.GetJSONResponse(t)
+			if result["query_name"] != tt.query || result["type"] != "matrix" {
+				t.Errorf("Expected a matrix for %s, got %v", tt.query, result)
+			}
+			if result["start"] != float64(1773104400) || result["end"] != float64(1773111600) || result["step"] != float64(300) {
+				t.Errorf("Expected the normalised window to be reported, got start=%v end=%v step=%v", result["start"], result["end"], result["step"])
+			}
+		})
+	}
+}
@@ -2,10 +2,18 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
 	"homelab-dashboard/internal/models"
 	"homelab-dashboard/internal/testutil"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
 )
 
 func TestGetMetricsGET(t *testing.T) {
@@ -41,6 +49,33 @@ func TestGetMetricsGET(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "ExpiredMetricShouldBeServedAsStale",
+			queries:        []string{"cpu_usage"},
+			expectedStatus: 200,
+			expectedCount:  1,
+			setupMocks: func(tc *testutil.TestContext) {
+				cachedData := tc.CreateCachedDataWithScalar("cpu_usage", 85.5, false, "")
+				cachedData.Timestamp = time.Now().Add(-10 * time.Minute)
+				cachedData.TTL = 5 * time.Minute
+				cachedData.ExpiresAt = cachedData.Timestamp.Add(6 * time.Minute)
+				tc.MockSession.EXPECT().GetAuthenticatedUser(tc.AppContext).Return(nil, false)
+
+				tc.MockCache.EXPECT().
+					Get(tc.AppContext.Context, "cpu_usage").
+					Return(cachedData, true).
+					Times(1)
+			},
+			validate: func(t *testing.T, results []interface{}) {
+				metric := results[0].(map[string]interface{})
+				if metric["stale"] != true {
+					t.Errorf("Expected stale to be true, got %v", metric["stale"])
+				}
+				if age, ok := metric["age_seconds"].(float64); !ok || age < 600 || age > 601 {
+					t.Errorf("Expected age_seconds of about 600, got %v", metric["age_seconds"])
+				}
+			},
+		},
 		{
 			name:           "SinglePrivateMetricShouldReturnMetricForAuthorizedUser",
 			queries:        []string{"cpu_usage"},
@@ -227,3 +262,271 @@ func TestGetMetricsGET(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDataStreamGET(t *testing.T) {
+	tests := []struct {
+		name           string
+		queries        string
+		updates        []string
+		setupMocks     func(tc *testutil.TestContext)
+		expectedEvents []string
+	}{
+		{
+			name:    "SnapshotAndUpdatesShouldOnlyIncludeAuthorizedMetrics",
+			updates: []string{"cpu_usage", "admin_only"},
+			setupMocks: func(tc *testutil.TestContext) {
+				tc.MockSession.EXPECT().GetAuthenticatedUser(tc.AppContext).Return(nil, false)
+
+				tc.MockCache.EXPECT().
+					ListAll(tc.AppContext.Context).
+					Return([]string{"cpu_usage", "admin_only"})
+
+				tc.MockCache.EXPECT().
+					Get(tc.AppContext.Context, "cpu_usage").
+					Return(tc.CreateCachedDataWithScalar("cpu_usage", 85.5, false, ""), true).
+					Times(2)
+
+				tc.MockCache.EXPECT().
+					Get(tc.AppContext.Context, "admin_only").
+					Return(tc.CreateCachedDataWithScalar("admin_only", 1, true, "admin"), true).
+					Times(2)
+			},
+			expectedEvents: []string{"cpu_usage", "cpu_usage"},
+		},
+		{
+			name:    "QueriesParamShouldFilterSnapshotAndUpdates",
+			queries: "memory_usage",
+			updates: []string{"cpu_usage", "memory_usage"},
+			setupMocks: func(tc *testutil.TestContext) {
+				testUser := &models.User{Sub: "sub_claim", Iss: "iss_claim", Username: "steve", Groups: []string{"admin"}}
+				tc.MockSession.EXPECT().GetAuthenticatedUser(tc.AppContext).Return(testUser, true)
+
+				tc.MockCache.EXPECT().
+					Get(tc.AppContext.Context, "memory_usage").
+					Return(tc.CreateCachedDataWithScalar("memory_usage", 42, true, "admin"), true).
+					Times(2)
+			},
+			expectedEvents: []string{"memory_usage", "memory_usage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := testutil.NewTestContextWithURL(t, "GET", "/api/data/stream")
+			defer tc.Finish()
+
+			if tt.queries != "" {
+				tc = tc.WithQueryParam("queries", tt.queries)
+			}
+
+			updates := make(chan string, len(tt.updates))
+			for _, name := range tt.updates {
+				updates <- name
+			}
+			close(updates)
+
+			tc.MockCache.EXPECT().SubscribeUpdates(tc.AppContext).Return((<-chan string)(updates))
+			tt.setupMocks(tc)
+
+			tc.CallHandler(GetDataStreamGET)
+
+			tc.AssertStatus(t, 200)
+			tc.AssertContentType(t, "text/event-stream")
+
+			var events []string
+			for _, line := range strings.Split(tc.Response.Body.String(), "\n") {
+				payload, ok := strings.CutPrefix(line, "data: ")
+				if !ok {
+					continue
+				}
+
+				var result map[string]interface{}
+				if err := json.Unmarshal([]byte(payload), &result); err != nil {
+					t.Fatalf("Invalid event payload %q: %v", payload, err)
+				}
+				events = append(events, result["query_name"].(string))
+			}
+
+			if strings.Join(events, ",") != strings.Join(tt.expectedEvents, ",") {
+				t.Errorf("Expected events %v, got %v", tt.expectedEvents, events)
+			}
+		})
+	}
+}
+
+func TestGetDataStatusGET(t *testing.T) {
+	queries := []config.PrometheusQuery{
+		{Name: "cpu_usage", Source: config.DataSourcePrometheus, TTL: 30 * time.Second},
+		{Name: "admin_only", Source: config.DataSourcePrometheus, TTL: time.Hour, RequireAuth: true, RequiredGroup: "admin"},
+		{Name: "disabled", Disabled: true},
+	}
+
+	lastSuccess := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	scheduled, _ := json.Marshal(data.QueryScheduleStatus{
+		Name:        "cpu_usage",
+		Source:      config.DataSourcePrometheus,
+		TTLSeconds:  30,
+		LastRun:     &lastSuccess,
+		LastSuccess: &lastSuccess,
+		NextRun:     &lastSuccess,
+	})
+
+	tests := []struct {
+		name          string
+		user          *models.User
+		expectedNames []string
+	}{
+		{
+			name:          "AnonymousUserShouldOnlySeePublicQueries",
+			expectedNames: []string{"cpu_usage"},
+		},
+		{
+			name:          "GroupMemberShouldSeeRestrictedQueries",
+			user:          &models.User{Sub: "sub_claim", Iss: "iss_claim", Username: "steve", Groups: []string{"admin"}},
+			expectedNames: []string{"cpu_usage", "admin_only"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := testutil.NewTestContextWithURL(t, "GET", "/api/data/status")
+			defer tc.Finish()
+
+			tc.AppContext.Config.Data.Queries = queries
+			tc.MockSession.EXPECT().GetAuthenticatedUser(tc.AppContext).Return(tt.user, tt.user != nil)
+			tc.MockCache.EXPECT().GetKey(tc.AppContext, "scheduler:query:cpu_usage").Return(string(scheduled), nil)
+			if tt.user != nil {
+				tc.MockCache.EXPECT().GetKey(tc.AppContext, "scheduler:query:admin_only").Return("", errors.New("key not found"))
+			}
+
+			tc.CallHandler(GetDataStatusGET)
+
+			tc.AssertStatus(t, 200)
+			results := tc.GetJSONResponseArray(t)
+
+			var names []string
+			for _, result := range results {
+				names = append(names, result.(map[string]interface{})["name"].(string))
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expectedNames, ",") {
+				t.Fatalf("Expected queries %v, got %v", tt.expectedNames, names)
+			}
+
+			first := results[0].(map[string]interface{})
+			if first["last_success"] != "2026-01-02T03:04:05Z" {
+				t.Errorf("Expected last_success to be reported, got %v", first["last_success"])
+			}
+
+			if len(results) > 1 {
+				second := results[1].(map[string]interface{})
+				if _, ok := second["next_run"]; ok {
+					t.Errorf("Expected unscheduled query to have no next_run, got %v", second["next_run"])
+				}
+				if second["ttl_seconds"] != float64(3600) {
+					t.Errorf("Expected ttl_seconds 3600, got %v", second["ttl_seconds"])
+				}
+			}
+		})
+	}
+}
+
+// scalarSource answers every query with the same scalar.
+type scalarSource struct{}
+
+func (scalarSource) Query(ctx context.Context, query config.PrometheusQuery) (model.Value, error) {
+	return &model.Scalar{Value: 42}, nil
+}
+
+func TestGetMetricsGETWithVariables(t *testing.T) {
+	variables := []config.DataVariable{
+		{Name: "namespace", Values: []string{"media", "monitoring", "default"}, Default: "media", Precompute: []string{"media", "monitoring"}},
+	}
+	queries := []config.PrometheusQuery{
+		{Name: "pods", Source: config.DataSourcePrometheus, Query: `count(kube_pod_info{namespace="$namespace"})`, TTL: time.Minute},
+	}
+
+	tests := []struct {
+		name              string
+		url               string
+		setupMocks        func(tc *testutil.TestContext)
+		expectedStatus    int
+		expectedNamespace string
+	}{
+		{
+			name:           "DefaultSelectionShouldBeReadFromCache",
+			url:            "/api/data?queries=pods",
+			expectedStatus: 200,
+			setupMocks: func(tc *testutil.TestContext) {
+				cachedData := tc.CreateCachedDataWithScalar("pods", 3, false, "")
+				cachedData.Variables = map[string]string{"namespace": "media"}
+				tc.MockCache.EXPECT().Get(tc.AppContext.Context, "pods?namespace=media").Return(cachedData, true)
+			},
+			expectedNamespace: "media",
+		},
+		{
+			name:           "PrecomputedSelectionShouldBeReadFromCache",
+			url:            "/api/data?queries=pods&var.namespace=monitoring",
+			expectedStatus: 200,
+			setupMocks: func(tc *testutil.TestContext) {
+				cachedData := tc.CreateCachedDataWithScalar("pods", 5, false, "")
+				cachedData.Variables = map[string]string{"namespace": "monitoring"}
+				tc.MockCache.EXPECT().Get(tc.AppContext.Context, "pods?namespace=monitoring").Return(cachedData, true)
+			},
+			expectedNamespace: "monitoring",
+		},
+		{
+			name:              "OtherSelectionShouldRunOnDemand",
+			url:               "/api/data?queries=pods&var.namespace=default",
+			expectedStatus:    200,
+			setupMocks:        func(tc *testutil.TestContext) {},
+			expectedNamespace: "default",
+		},
+		{
+			name:           "UnknownValueShouldBeRejected",
+			url:            "/api/data?queries=pods&var.namespace=kube-system",
+			expectedStatus: 400,
+			setupMocks:     func(tc *testutil.TestContext) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := testutil.NewTestContextWithURL(t, "GET", tt.url)
+			defer tc.Finish()
+
+			tc.AppContext.Config.Data.Queries = queries
+			tc.AppContext.Config.Data.Variables = variables
+
+			serviceCache, err := data.NewMemCache(&config.Config{}, tc.AppContext.Logger)
+			if err != nil {
+				t.Fatalf("Failed to create cache: %v", err)
+			}
+			tc.AppContext.DataService = data.NewService(map[string]data.DataSource{config.DataSourcePrometheus: scalarSource{}}, serviceCache, tc.AppContext.Logger, queries).
+				WithVariables(variables, &config.DataOnDemandConfig{RequestsPerWindow: 1, Window: time.Minute})
+
+			tc.MockSession.EXPECT().GetAuthenticatedUser(tc.AppContext).Return(nil, false)
+			tt.setupMocks(tc)
+
+			tc.CallHandler(GetMetricsGET)
+
+			tc.AssertStatus(t, tt.expectedStatus)
+			if tt.expectedStatus != 200 {
+				return
+			}
+
+			results := tc.GetJSONResponseArray(t)
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 result, got %d", len(results))
+			}
+
+			metric := results[0].(map[string]interface{})
+			if metric["query_name"] != "pods" {
+				t.Errorf("Expected query_name 'pods', got %v", metric["query_name"])
+			}
+			selected, _ := metric["variables"].(map[string]interface{})
+			if selected["namespace"] != tt.expectedNamespace {
+				t.Errorf("Expected namespace %q, got %v", tt.expectedNamespace, metric["variables"])
+			}
+		})
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"homelab-dashboard/internal/config"
 	"homelab-dashboard/internal/middlewares"
 	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/services/firewall"
+	"homelab-dashboard/internal/utils"
 	"net"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,7 +25,9 @@ type AvailableAliasResponse struct {
 	Description   string `json:"description"`
 	MaxIPsPerUser int    `json:"max_ips_per_user"`
 	MaxTotalIPs   int    `json:"max_total_ips"`
-	DefaultTTL    *int64 `json:"default_ttl_hours,omitempty"` // hours, null = no expiration
+	DefaultTTL    *int64 `json:"default_ttl_hours,omitempty"`  // hours, null = no expiration
+	MaxLifetime   *int64 `json:"max_lifetime_hours,omitempty"` // hours, null = no lifetime cap
+	MaxExtensions int    `json:"max_extensions"`
 }
 
 func GETAvailableAliases(ctx *middlewares.AppContext) {
@@ -37,20 +42,15 @@ func GETAvailableAliases(ctx *middlewares.AppContext) {
 		return
 	}
 
-	principalGroups := principal.GetGroups()
-	if principalGroups == nil {
-		principalGroups = []string{}
-	}
-
-	principalGroupMap := make(map[string]bool)
-	for _, group := range principalGroups {
-		principalGroupMap[group] = true
+	aliases, ok := loadFirewallAliases(ctx)
+	if !ok {
+		return
 	}
 
 	var availableAliases []AvailableAliasResponse
 
-	for _, alias := range ctx.Config.Features.FirewallManagement.Aliases {
-		if !principalGroupMap[alias.AuthGroup] {
+	for _, alias := range aliases {
+		if !principalHasAliasAccess(principal, &alias) {
 			continue
 		}
 
@@ -77,6 +77,12 @@ func GETAvailableAliases(ctx *middlewares.AppContext) {
 			ttlHours = &hours
 		}
 
+		var lifetimeHours *int64
+		if alias.MaxLifetime != nil {
+			hours := int64(alias.MaxLifetime.Hours())
+			lifetimeHours = &hours
+		}
+
 		availableAliases = append(availableAliases, AvailableAliasResponse{
 			Name:          alias.Name,
 			UUID:          alias.UUID,
@@ -84,6 +90,8 @@ func GETAvailableAliases(ctx *middlewares.AppContext) {
 			MaxIPsPerUser: alias.MaxIPsPerUser,
 			MaxTotalIPs:   alias.MaxTotalIPs,
 			DefaultTTL:    ttlHours,
+			MaxLifetime:   lifetimeHours,
+			MaxExtensions: alias.MaxExtensions,
 		})
 	}
 
@@ -178,9 +186,10 @@ func POSTAddIPEntry(ctx *middlewares.AppContext) {
 	}
 
 	var req struct {
-		AliasName   string `json:"alias_name"`
-		IPAddress   string `json:"ip_address"`
-		Description string `json:"description"`
+		AliasName   string                 `json:"alias_name"`
+		IPAddress   string                 `json:"ip_address"`
+		Description string                 `json:"description"`
+		Schedule    *models.AccessSchedule `json:"schedule"` // optional recurring access window
 	}
 
 	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
@@ -210,25 +219,22 @@ func POSTAddIPEntry(ctx *middlewares.AppContext) {
 
 	req.IPAddress = parsedIP.String()
 
-	user, ok := principal.(*models.User)
-	if !ok {
-		ctx.SetJSONError(http.StatusForbidden, "Firewall management is only available for user accounts")
-		return
-	}
-
-	userGroups := user.Groups
-	if userGroups == nil {
-		userGroups = []string{}
+	if req.Schedule != nil {
+		if err := req.Schedule.Validate(); err != nil {
+			ctx.SetJSONError(http.StatusBadRequest, fmt.Sprintf("Invalid schedule: %s", err))
+			return
+		}
 	}
 
 	var matchedAlias *config.FirewallAliasConfig
-	userGroupSet := make(map[string]bool)
-	for _, group := range userGroups {
-		userGroupSet[group] = true
+
+	aliases, ok := loadFirewallAliases(ctx)
+	if !ok {
+		return
 	}
 
-	for _, alias := range ctx.Config.Features.FirewallManagement.Aliases {
-		if alias.Name == req.AliasName && userGroupSet[alias.AuthGroup] {
+	for _, alias := range aliases {
+		if alias.Name == req.AliasName && principalHasAliasAccess(principal, &alias) {
 			matchedAlias = &alias
 			break
 		}
@@ -239,7 +245,9 @@ func POSTAddIPEntry(ctx *middlewares.AppContext) {
 		return
 	}
 
-	isBlacklisted, err := ctx.Storage.IsIPBlacklisted(ctx, matchedAlias.UUID, req.IPAddress)
+	enrichment := lookupIPEnrichment(ctx, parsedIP)
+
+	isBlacklisted, err := ctx.Storage.IsIPBlacklisted(ctx, matchedAlias.UUID, req.IPAddress, enrichment.ASN)
 	if err != nil {
 		ctx.Logger.Error("failed to check if IP is blacklisted",
 			"error", err,
@@ -255,6 +263,17 @@ func POSTAddIPEntry(ctx *middlewares.AppContext) {
 		return
 	}
 
+	if err := firewall.CheckAliasGeoPolicy(matchedAlias, enrichment); err != nil {
+		ctx.Logger.Warn("IP rejected by alias geoip policy",
+			"user", principal.GetUsername(),
+			"ip", req.IPAddress,
+			"alias", req.AliasName,
+			"reason", err,
+		)
+		ctx.SetJSONError(http.StatusForbidden, fmt.Sprintf("This IP address cannot be added: %s", err))
+		return
+	}
+
 	userCount, err := ctx.Storage.CountUserActiveIPs(ctx, principal.GetIss(), principal.GetSub(), matchedAlias.UUID)
 	if err != nil {
 		ctx.Logger.Error("failed to count user active IPs",
@@ -289,6 +308,10 @@ func POSTAddIPEntry(ctx *middlewares.AppContext) {
 		return
 	}
 
+	if !enforceFirewallRateLimit(ctx, principal, firewall.RateLimitCreate, matchedAlias.UUID) {
+		return
+	}
+
 	// Calculate expiration based on alias config
 	var expiresAt *time.Time
 	if matchedAlias.DefaultTTL != nil {
@@ -312,6 +335,8 @@ func POSTAddIPEntry(ctx *middlewares.AppContext) {
 		userAgentPtr = &userAgentStr
 	}
 
+	clientEnrichment := lookupIPEnrichment(ctx, net.ParseIP(clientIP))
+
 	entry, err := ctx.Storage.AddIPToWhitelist(
 		ctx,
 		principal.GetIss(),
@@ -323,8 +348,13 @@ func POSTAddIPEntry(ctx *middlewares.AppContext) {
 		expiresAt,
 		clientIPPtr,
 		userAgentPtr,
+		enrichment,
+		clientEnrichment,
+		req.Schedule,
 	)
 	if err != nil {
+		releaseFirewallRateLimit(ctx, principal, firewall.RateLimitCreate, matchedAlias.UUID)
+
 		// Check if it's a duplicate IP error
 		if strings.Contains(err.Error(), "you already have this IP address whitelisted") {
 			ctx.SetJSONError(http.StatusConflict, err.Error())
@@ -346,8 +376,19 @@ func POSTAddIPEntry(ctx *middlewares.AppContext) {
 		"ip", req.IPAddress,
 		"alias", req.AliasName,
 		"entry_id", entry.ID,
+		"country", entry.CountryCode,
+		"asn", entry.ASN,
+		"status", entry.Status,
 	)
 
+	reportNetworkRotation(ctx, principal, entry)
+
+	// Scheduled entries wait for their window to open, which the schedule job handles.
+	if entry.Status == models.StatusRequested {
+		estimatedApplyAt := requestFirewallSync(ctx, entry.AliasUUID)
+		entry.EstimatedApplyAt = &estimatedApplyAt
+	}
+
 	ctx.WriteJSON(http.StatusCreated, entry)
 }
 
@@ -401,6 +442,11 @@ func DELETERemoveIPEntry(ctx *middlewares.AppContext) {
 		return
 	}
 
+	// Admins cleaning up other users' entries are not throttled.
+	if isOwner && !enforceFirewallRateLimit(ctx, principal, firewall.RateLimitDelete, entry.AliasUUID) {
+		return
+	}
+
 	clientIP := ""
 	host, _, err := net.SplitHostPort(ctx.Request.RemoteAddr)
 	if err == nil {
@@ -422,6 +468,10 @@ func DELETERemoveIPEntry(ctx *middlewares.AppContext) {
 
 	err = ctx.Storage.RemoveIPFromWhitelist(ctx, entryID, principal.GetIss(), principal.GetSub(), clientIPPtr, userAgentPtr)
 	if err != nil {
+		if isOwner {
+			releaseFirewallRateLimit(ctx, principal, firewall.RateLimitDelete, entry.AliasUUID)
+		}
+
 		ctx.Logger.Error("failed to remove IP from whitelist",
 			"error", err,
 			"user", principal.GetUsername(),
@@ -438,6 +488,15 @@ func DELETERemoveIPEntry(ctx *middlewares.AppContext) {
 		"alias", entry.AliasName,
 	)
 
+	// Only entries that may already be on the router need a sync to take effect.
+	if entry.Status == models.StatusRequested || entry.Status == models.StatusAdded {
+		estimatedApplyAt := requestFirewallSync(ctx, entry.AliasUUID)
+		ctx.WriteJSON(http.StatusAccepted, map[string]time.Time{
+			"estimated_apply_at": estimatedApplyAt,
+		})
+		return
+	}
+
 	ctx.Response.WriteHeader(http.StatusNoContent)
 }
 
@@ -523,6 +582,140 @@ func DELETEBlacklistIPEntry(ctx *middlewares.AppContext) {
 		"reason", req.Reason,
 	)
 
+	requestFirewallSync(ctx, entry.AliasUUID)
+
 	// 9. Return success
 	ctx.Response.WriteHeader(http.StatusNoContent)
 }
+
+// principalHasAliasAccess reports whether principal may use alias as it is offered to alias.AuthGroup:
+// users through membership of that group, service accounts through a grant for the alias and group.
+func principalHasAliasAccess(principal middlewares.Principal, alias *config.FirewallAliasConfig) bool {
+	if serviceAccount, ok := principal.(*models.ServiceAccount); ok {
+		return serviceAccount.HasFirewallGrant(alias.UUID, alias.AuthGroup)
+	}
+
+	return slices.Contains(principal.GetGroups(), alias.AuthGroup)
+}
+
+// enforceFirewallRateLimit counts a create or delete against the principal's budget for an alias, writing a 429
+// with Retry-After when it is exhausted. A cache failure is logged and the change allowed, so an outage of the
+// cache does not lock users out of the firewall.
+func enforceFirewallRateLimit(ctx *middlewares.AppContext, principal middlewares.Principal, action firewall.RateLimitAction, aliasUUID string) bool {
+	allowed, retryAfter, err := firewall.CheckRateLimit(ctx, ctx.Cache, ctx.Config.Features.FirewallManagement.RateLimits,
+		action, aliasUUID, principal.GetIss(), principal.GetSub())
+	if err != nil {
+		ctx.Logger.Warn("failed to check firewall rate limit, allowing request",
+			"error", err,
+			"user", principal.GetUsername(),
+			"action", action,
+			"alias_uuid", aliasUUID,
+		)
+		return true
+	}
+
+	if allowed {
+		return true
+	}
+
+	seconds := utils.RetryAfterSeconds(retryAfter)
+	ctx.Logger.Warn("firewall rate limit exceeded",
+		"user", principal.GetUsername(),
+		"action", action,
+		"alias_uuid", aliasUUID,
+		"retry_after_seconds", seconds,
+	)
+	ctx.Response.Header().Set("Retry-After", strconv.Itoa(seconds))
+	ctx.SetJSONError(http.StatusTooManyRequests,
+		fmt.Sprintf("Too many %s requests for this alias, try again in %d seconds", action, seconds))
+	return false
+}
+
+// releaseFirewallRateLimit gives back the budget enforceFirewallRateLimit took for a change that failed.
+func releaseFirewallRateLimit(ctx *middlewares.AppContext, principal middlewares.Principal, action firewall.RateLimitAction, aliasUUID string) {
+	err := firewall.ReleaseRateLimit(ctx, ctx.Cache, ctx.Config.Features.FirewallManagement.RateLimits,
+		action, aliasUUID, principal.GetIss(), principal.GetSub())
+	if err != nil {
+		ctx.Logger.Warn("failed to release firewall rate limit",
+			"error", err,
+			"user", principal.GetUsername(),
+			"action", action,
+			"alias_uuid", aliasUUID,
+		)
+	}
+}
+
+// reportNetworkRotation records an 'abuse_suspected' event on entry when the principal's recent entries span more
+// ASNs or countries than abuse detection allows. The entry itself is kept; the event is for admins to review.
+func reportNetworkRotation(ctx *middlewares.AppContext, principal middlewares.Principal, entry *models.FirewallIPWhitelistEntry) {
+	limits := ctx.Config.Features.FirewallManagement.RateLimits
+	if limits == nil || limits.AbuseDetection == nil {
+		return
+	}
+	detection := limits.AbuseDetection
+
+	asns, countries, err := ctx.Storage.CountUserDistinctNetworks(ctx, principal.GetIss(), principal.GetSub(), detection.Window)
+	if err != nil {
+		ctx.Logger.Warn("failed to count user networks", "error", err, "user", principal.GetUsername())
+		return
+	}
+
+	if !firewall.ExceedsNetworkRotation(detection, asns, countries) {
+		return
+	}
+
+	report, err := firewall.ShouldReportNetworkRotation(ctx, ctx.Cache, detection, principal.GetIss(), principal.GetSub())
+	if err != nil {
+		ctx.Logger.Warn("failed to check network rotation report", "error", err, "user", principal.GetUsername())
+	}
+	if !report {
+		return
+	}
+
+	ctx.Logger.Warn("user is rotating through networks",
+		"user", principal.GetUsername(),
+		"entry_id", entry.ID,
+		"distinct_asns", asns,
+		"distinct_countries", countries,
+		"window", detection.Window,
+	)
+
+	systemUserIss, systemUserSub, err := ctx.Storage.GetSystemUser(ctx)
+	if err != nil {
+		ctx.Logger.Error("failed to get system user", "error", err)
+		return
+	}
+
+	notes := fmt.Sprintf("entries requested within %s span %d ASNs and %d countries", detection.Window, asns, countries)
+	if err := ctx.Storage.CreateWhitelistEvent(ctx, entry.ID, systemUserIss, systemUserSub, "abuse_suspected", notes, nil, nil); err != nil {
+		ctx.Logger.Error("failed to record abuse event", "error", err, "entry_id", entry.ID)
+	}
+}
+
+// requestFirewallSync asks the leader to sync an alias now rather than at the next interval, and returns
+// when the change is expected to reach the router. A failed request only delays the change.
+func requestFirewallSync(ctx *middlewares.AppContext, aliasUUID string) time.Time {
+	triggered := true
+	if err := ctx.Storage.NotifyFirewallSync(ctx, aliasUUID); err != nil {
+		ctx.Logger.Warn("failed to request firewall sync", "error", err, "alias_uuid", aliasUUID)
+		triggered = false
+	}
+
+	return firewall.EstimateApplyTime(ctx.Config.Features.FirewallManagement.BackgroundJobConfig, triggered, time.Now())
+}
+
+// lookupIPEnrichment resolves GeoIP details for ip. When GeoIP is not configured or the lookup fails
+// an empty enrichment is returned, which alias policies treat like an address no database covers.
+func lookupIPEnrichment(ctx *middlewares.AppContext, ip net.IP) models.IPEnrichment {
+	if ctx.GeoIP == nil || ip == nil {
+		return models.IPEnrichment{}
+	}
+
+	enrichment, err := ctx.GeoIP.Lookup(ip)
+	if err != nil {
+		ctx.Logger.Warn("geoip lookup failed", "error", err, "ip", ip.String())
+		return models.IPEnrichment{}
+	}
+
+	return enrichment
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/testutil"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestPOSTAddIPEntryRateLimit(t *testing.T) {
+	user := &models.User{Iss: "iss", Sub: "sub", Username: "user", Groups: []string{"users"}}
+	aliases := []*models.FirewallManagedAlias{{ID: 1, Name: "lan", UUID: "a", AuthGroup: "users", MaxIPsPerUser: 5, MaxTotalIPs: 50}}
+	rateLimitKey := "ratelimit:firewall:create:a:iss|sub"
+
+	// newTestContext sets up a create request that passes every check before the rate limit.
+	newTestContext := func(t *testing.T) *testutil.TestContext {
+		tc := testutil.NewTestContextWithURL(t, "POST", "/api/firewall/entries")
+		tc.AppContext.Config.Authorization.GroupScopes = map[string][]string{
+			"users": {authorization.ScopeFirewallRequestOwn},
+		}
+		tc.AppContext.Config.Features.FirewallManagement.RateLimits = &config.FirewallRateLimitConfig{
+			CreatesPerWindow: 3,
+			DeletesPerWindow: 3,
+			Window:           time.Hour,
+		}
+		tc.AppContext.SetPrincipal(user)
+		tc.Request.Body = io.NopCloser(strings.NewReader(`{"alias_name":"lan","ip_address":"192.0.2.10"}`))
+
+		tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return(aliases, nil)
+		tc.MockStorageProvider.EXPECT().IsIPBlacklisted(tc.AppContext, "a", "192.0.2.10", gomock.Nil()).Return(false, nil)
+		tc.MockStorageProvider.EXPECT().CountUserActiveIPs(tc.AppContext, "iss", "sub", "a").Return(0, nil)
+		tc.MockStorageProvider.EXPECT().CountTotalActiveIPs(tc.AppContext, "a").Return(0, nil)
+		return tc
+	}
+
+	t.Run("ShouldRejectOverLimitWithRetryAfter", func(t *testing.T) {
+		tc := newTestContext(t)
+		defer tc.Finish()
+
+		tc.MockCache.EXPECT().SlidingWindowAllow(tc.AppContext, rateLimitKey, 3, time.Hour).Return(false, 90*time.Second+500*time.Millisecond, nil)
+
+		tc.CallHandler(POSTAddIPEntry)
+
+		tc.AssertStatus(t, 429)
+		if retryAfter := tc.Response.Header().Get("Retry-After"); retryAfter != "91" {
+			t.Errorf("Expected Retry-After 91, got %q", retryAfter)
+		}
+		tc.AssertJSONField(t, "error", "Too many create requests for this alias, try again in 91 seconds")
+	})
+
+	t.Run("ShouldNotCountRejectedCreate", func(t *testing.T) {
+		tc := newTestContext(t)
+		defer tc.Finish()
+
+		tc.MockCache.EXPECT().SlidingWindowAllow(tc.AppContext, rateLimitKey, 3, time.Hour).Return(true, time.Duration(0), nil)
+		tc.MockStorageProvider.EXPECT().AddIPToWhitelist(tc.AppContext, "iss", "sub", "lan", "a", "192.0.2.10", "",
+			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("you already have this IP address whitelisted"))
+		tc.MockCache.EXPECT().SlidingWindowRelease(tc.AppContext, rateLimitKey).Return(nil)
+
+		tc.CallHandler(POSTAddIPEntry)
+
+		tc.AssertStatus(t, 409)
+	})
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// exportFlushInterval is how many rows an export writes between flushes, so large exports reach the client steadily.
+const exportFlushInterval = 500
+
+var firewallEventCSVHeader = []string{
+	"id", "created_at", "event_type", "whitelist_id", "alias_name", "alias_uuid", "ip_address",
+	"actor_iss", "actor_sub", "actor_username", "client_ip", "client_country_code", "client_asn",
+	"client_as_organization", "user_agent", "notes",
+}
+
+// GETFirewallEvents searches the firewall audit log across all entries (admin-only).
+// Filters: ?actor= (username or sub), ?event_type= (repeatable or comma-separated), ?client_ip= (address or CIDR),
+// ?alias_uuid=, ?since= and ?until= (RFC 3339). Paginate with ?limit= and ?offset=.
+func GETFirewallEvents(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallReadAll) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	query := ctx.Request.URL.Query()
+
+	filter, err := parseFirewallEventFilter(query)
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var params models.PaginationParams
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{
+		{"limit", &params.Limit},
+		{"offset", &params.Offset},
+	} {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			ctx.SetJSONError(http.StatusBadRequest, fmt.Sprintf("Invalid %s", param.name))
+			return
+		}
+		*param.value = parsed
+	}
+
+	result, err := ctx.Storage.SearchWhitelistEvents(ctx, filter, params)
+	if err != nil {
+		ctx.Logger.Error("failed to search firewall events", "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to search firewall events")
+		return
+	}
+
+	ctx.WriteJSON(http.StatusOK, result)
+}
+
+// GETFirewallEventsExport streams every event matching the GETFirewallEvents filters as CSV or NDJSON,
+// chosen with ?format=csv|ndjson (admin-only). Pagination parameters are ignored. The route is exempt from the
+// request timeout, as the 200 is already sent when a long export would be cut off.
+func GETFirewallEventsExport(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallReadAll) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	query := ctx.Request.URL.Query()
+
+	filter, err := parseFirewallEventFilter(query)
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = "ndjson"
+	}
+
+	var contentType string
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+	case "ndjson":
+		contentType = "application/x-ndjson"
+	default:
+		ctx.SetJSONError(http.StatusBadRequest, "format must be csv or ndjson")
+		return
+	}
+
+	filename := fmt.Sprintf("firewall-events-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	ctx.Response.Header().Set("Content-Type", contentType)
+	ctx.Response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Response.WriteHeader(http.StatusOK)
+
+	flusher, _ := ctx.Response.(http.Flusher)
+	rows := 0
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	var writeEvent func(*models.FirewallAuditEvent) error
+	var finish func() error
+
+	if format == "csv" {
+		writer := csv.NewWriter(ctx.Response)
+		if err := writer.Write(firewallEventCSVHeader); err != nil {
+			ctx.Logger.Warn("failed to write firewall event export", "error", err)
+			return
+		}
+		writeEvent = func(event *models.FirewallAuditEvent) error {
+			if err := writer.Write(firewallEventCSVRecord(event)); err != nil {
+				return err
+			}
+			if rows++; rows%exportFlushInterval == 0 {
+				writer.Flush()
+				flush()
+			}
+			return writer.Error()
+		}
+		finish = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	} else {
+		encoder := json.NewEncoder(ctx.Response)
+		writeEvent = func(event *models.FirewallAuditEvent) error {
+			if err := encoder.Encode(event); err != nil {
+				return err
+			}
+			if rows++; rows%exportFlushInterval == 0 {
+				flush()
+			}
+			return nil
+		}
+		finish = func() error { return nil }
+	}
+
+	// The status is already sent, so a failure part-way through can only be logged; the client sees a truncated file.
+	if err := ctx.Storage.StreamWhitelistEvents(ctx, filter, writeEvent); err != nil {
+		ctx.Logger.Error("firewall event export failed", "error", err, "rows", rows, "format", format)
+		return
+	}
+	if err := finish(); err != nil {
+		ctx.Logger.Warn("failed to finish firewall event export", "error", err)
+		return
+	}
+
+	ctx.Logger.Info("firewall events exported",
+		"admin", principal.GetUsername(),
+		"format", format,
+		"rows", rows,
+	)
+}
+
+// parseFirewallEventFilter reads the audit log filters shared by the search and export endpoints.
+func parseFirewallEventFilter(query url.Values) (models.FirewallEventFilter, error) {
+	filter := models.FirewallEventFilter{
+		Actor: strings.TrimSpace(query.Get("actor")),
+	}
+
+	for _, raw := range query["event_type"] {
+		for _, eventType := range strings.Split(raw, ",") {
+			eventType = strings.ToLower(strings.TrimSpace(eventType))
+			if eventType == "" {
+				continue
+			}
+			if !slices.Contains(models.FirewallEventTypes, eventType) {
+				return filter, fmt.Errorf("Unknown event_type %q", eventType)
+			}
+			filter.EventTypes = append(filter.EventTypes, eventType)
+		}
+	}
+
+	if raw := strings.TrimSpace(query.Get("client_ip")); raw != "" {
+		var prefix netip.Prefix
+		if strings.Contains(raw, "/") {
+			parsed, err := netip.ParsePrefix(raw)
+			if err != nil {
+				return filter, fmt.Errorf("Invalid client_ip, expected an IP address or CIDR")
+			}
+			prefix = parsed.Masked()
+		} else {
+			addr, err := netip.ParseAddr(raw)
+			if err != nil {
+				return filter, fmt.Errorf("Invalid client_ip, expected an IP address or CIDR")
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		filter.ClientNet = &prefix
+	}
+
+	if raw := strings.TrimSpace(query.Get("alias_uuid")); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid alias_uuid")
+		}
+		filter.AliasUUID = parsed.String()
+	}
+
+	for _, param := range []struct {
+		name  string
+		value **time.Time
+	}{
+		{"since", &filter.Since},
+		{"until", &filter.Until},
+	} {
+		raw := strings.TrimSpace(query.Get(param.name))
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid %s, expected an RFC 3339 timestamp", param.name)
+		}
+		*param.value = &parsed
+	}
+
+	if filter.Since != nil && filter.Until != nil && !filter.Until.After(*filter.Since) {
+		return filter, fmt.Errorf("until must be after since")
+	}
+
+	return filter, nil
+}
+
+func firewallEventCSVRecord(event *models.FirewallAuditEvent) []string {
+	optional := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	asn := ""
+	if event.ClientASN != nil {
+		asn = strconv.FormatInt(*event.ClientASN, 10)
+	}
+
+	return []string{
+		strconv.Itoa(event.ID),
+		event.CreatedAt.UTC().Format(time.RFC3339),
+		event.EventType,
+		strconv.Itoa(event.WhitelistID),
+		event.AliasName,
+		event.AliasUUID,
+		event.IPAddress,
+		event.ActorISS,
+		event.ActorSub,
+		event.ActorUsername,
+		optional(event.ClientIP),
+		optional(event.ClientCountryCode),
+		asn,
+		optional(event.ClientASOrganization),
+		optional(event.UserAgent),
+		optional(event.Notes),
+	}
+}
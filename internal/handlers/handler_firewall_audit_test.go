@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/models"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFirewallEventFilter(t *testing.T) {
+	testCases := []struct {
+		testName    string
+		query       string
+		expectError bool
+		check       func(t *testing.T, filter models.FirewallEventFilter)
+	}{
+		{
+			testName: "ShouldAcceptEmptyQuery",
+			query:    "",
+			check: func(t *testing.T, filter models.FirewallEventFilter) {
+				require.Equal(t, models.FirewallEventFilter{}, filter)
+			},
+		},
+		{
+			testName: "ShouldCombineEventTypes",
+			query:    "event_type=added,Removed&event_type=expired",
+			check: func(t *testing.T, filter models.FirewallEventFilter) {
+				require.Equal(t, []string{"added", "removed", "expired"}, filter.EventTypes)
+			},
+		},
+		{
+			testName:    "ShouldRejectUnknownEventType",
+			query:       "event_type=deleted",
+			expectError: true,
+		},
+		{
+			testName: "ShouldTreatSingleAddressAsHostNetwork",
+			query:    "client_ip=192.0.2.10",
+			check: func(t *testing.T, filter models.FirewallEventFilter) {
+				require.Equal(t, "192.0.2.10/32", filter.ClientNet.String())
+			},
+		},
+		{
+			testName: "ShouldMaskCIDR",
+			query:    "client_ip=2001:db8::1/64",
+			check: func(t *testing.T, filter models.FirewallEventFilter) {
+				require.Equal(t, "2001:db8::/64", filter.ClientNet.String())
+			},
+		},
+		{
+			testName:    "ShouldRejectInvalidClientIP",
+			query:       "client_ip=not-an-ip",
+			expectError: true,
+		},
+		{
+			testName: "ShouldNormalizeAliasUUID",
+			query:    "alias_uuid=C0DAEF37-718C-40E4-BB2B-BA5AAB418D0D",
+			check: func(t *testing.T, filter models.FirewallEventFilter) {
+				require.Equal(t, "c0daef37-718c-40e4-bb2b-ba5aab418d0d", filter.AliasUUID)
+			},
+		},
+		{
+			testName:    "ShouldRejectInvalidAliasUUID",
+			query:       "alias_uuid=database",
+			expectError: true,
+		},
+		{
+			testName: "ShouldParseTimeRange",
+			query:    "since=2025-01-01T00:00:00Z&until=2025-02-01T00:00:00Z&actor=alice",
+			check: func(t *testing.T, filter models.FirewallEventFilter) {
+				require.Equal(t, "alice", filter.Actor)
+				require.True(t, filter.Since.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+				require.True(t, filter.Until.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)))
+			},
+		},
+		{
+			testName:    "ShouldRejectInvertedTimeRange",
+			query:       "since=2025-02-01T00:00:00Z&until=2025-01-01T00:00:00Z",
+			expectError: true,
+		},
+		{
+			testName:    "ShouldRejectInvalidTimestamp",
+			query:       "since=yesterday",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+
+			filter, err := parseFirewallEventFilter(query)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, filter)
+		})
+	}
+}
+
+func TestFirewallEventCSVRecord(t *testing.T) {
+	clientIP := "198.51.100.7"
+	asn := int64(64500)
+	event := &models.FirewallAuditEvent{
+		FirewallIPWhitelistEvent: models.FirewallIPWhitelistEvent{
+			ID:            7,
+			WhitelistID:   3,
+			ActorISS:      "https://idp.example.com",
+			ActorSub:      "sub-1",
+			ActorUsername: "alice",
+			EventType:     "added",
+			ClientIP:      &clientIP,
+			ClientASN:     &asn,
+			CreatedAt:     time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC),
+		},
+		AliasName: "VPNUsers",
+		AliasUUID: "7f93ff45-6c60-4a21-9767-3fc246f4d335",
+		IPAddress: "192.0.2.10/32",
+	}
+
+	record := firewallEventCSVRecord(event)
+	require.Len(t, record, len(firewallEventCSVHeader))
+	require.Equal(t, []string{
+		"7", "2025-03-04T05:06:07Z", "added", "3", "VPNUsers", "7f93ff45-6c60-4a21-9767-3fc246f4d335", "192.0.2.10/32",
+		"https://idp.example.com", "sub-1", "alice", "198.51.100.7", "", "64500", "", "", "",
+	}, record)
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/services/firewall"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GETBlacklistRules lists blacklist rules (admin-only).
+// Filter with ?alias_uuid= (global rules are always included) and ?include_inactive=1 for lifted or expired rules.
+func GETBlacklistRules(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallReadAll) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	aliasUUID := strings.TrimSpace(ctx.Request.URL.Query().Get("alias_uuid"))
+	if aliasUUID != "" {
+		aliasConfig := findAliasByUUID(ctx, aliasUUID, http.StatusNotFound, "Alias not found")
+		if aliasConfig == nil {
+			return
+		}
+		aliasUUID = aliasConfig.UUID
+	}
+
+	includeInactive := ctx.Request.URL.Query().Get("include_inactive") == "1"
+
+	rules, err := ctx.Storage.GetBlacklistRules(ctx, aliasUUID, includeInactive)
+	if err != nil {
+		ctx.Logger.Error("failed to get blacklist rules", "error", err, "alias_uuid", aliasUUID)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get blacklist rules")
+		return
+	}
+
+	if rules == nil {
+		rules = []*models.FirewallBlacklistRule{}
+	}
+
+	ctx.WriteJSON(http.StatusOK, rules)
+}
+
+// GETBlacklistRule returns a single blacklist rule (admin-only).
+func GETBlacklistRule(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallReadAll) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	ruleID, ok := parseBlacklistRuleID(ctx)
+	if !ok {
+		return
+	}
+
+	rule, err := ctx.Storage.GetBlacklistRuleByID(ctx, ruleID)
+	if err != nil {
+		ctx.Logger.Error("failed to get blacklist rule", "error", err, "rule_id", ruleID)
+		ctx.SetJSONError(http.StatusNotFound, "Blacklist rule not found")
+		return
+	}
+
+	ctx.WriteJSON(http.StatusOK, rule)
+}
+
+// POSTBlacklistRule creates a blacklist rule for an IP, CIDR block, start-end range or ASN (admin-only).
+// Omitting alias_uuid applies the rule to every alias.
+func POSTBlacklistRule(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallBlacklist) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	var req struct {
+		Value     string     `json:"value"`
+		AliasUUID string     `json:"alias_uuid"`
+		Reason    string     `json:"reason"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := firewall.ParseBlacklistValue(req.Value)
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// An ASN rule is matched against the ASN database, and without one it would silently never apply
+	if geoIP := ctx.Config.Features.FirewallManagement.GeoIP; rule.ASN != nil && (geoIP == nil || geoIP.ASNDatabase == "") {
+		ctx.SetJSONError(http.StatusBadRequest, "ASN rules require features.firewall_management.geoip.asn_database")
+		return
+	}
+
+	if req.AliasUUID = strings.TrimSpace(req.AliasUUID); req.AliasUUID != "" {
+		aliasConfig := findAliasByUUID(ctx, req.AliasUUID, http.StatusBadRequest, "Unknown alias_uuid")
+		if aliasConfig == nil {
+			return
+		}
+		rule.AliasUUID = &aliasConfig.UUID
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		ctx.SetJSONError(http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	if req.Reason = strings.TrimSpace(req.Reason); req.Reason != "" {
+		rule.Reason = &req.Reason
+	}
+
+	rule.Source = models.BlacklistSourceManual
+	rule.ExpiresAt = req.ExpiresAt
+	rule.CreatedByIss = principal.GetIss()
+	rule.CreatedBySub = principal.GetSub()
+
+	created, err := ctx.Storage.CreateBlacklistRule(ctx, rule)
+	if err != nil {
+		ctx.Logger.Error("failed to create blacklist rule",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"value", rule.Value,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to create blacklist rule")
+		return
+	}
+
+	ctx.Logger.Info("blacklist rule created",
+		"admin", principal.GetUsername(),
+		"rule_id", created.ID,
+		"value", created.Value,
+		"alias_uuid", req.AliasUUID,
+		"expires_at", req.ExpiresAt,
+	)
+
+	ctx.WriteJSON(http.StatusCreated, created)
+}
+
+// PATCHBlacklistRule updates the reason and/or expiry of an active blacklist rule (admin-only).
+// Sending "expires_at": null makes the rule permanent.
+func PATCHBlacklistRule(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallBlacklist) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	ruleID, ok := parseBlacklistRuleID(ctx)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason    *string         `json:"reason"`
+		ExpiresAt json.RawMessage `json:"expires_at"`
+	}
+
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := ctx.Storage.GetBlacklistRuleByID(ctx, ruleID)
+	if err != nil {
+		ctx.Logger.Error("failed to get blacklist rule", "error", err, "rule_id", ruleID)
+		ctx.SetJSONError(http.StatusNotFound, "Blacklist rule not found")
+		return
+	}
+
+	if rule.RemovedAt != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Blacklist rule has already been lifted")
+		return
+	}
+
+	reason := rule.Reason
+	if req.Reason != nil {
+		trimmed := strings.TrimSpace(*req.Reason)
+		reason = nil
+		if trimmed != "" {
+			reason = &trimmed
+		}
+	}
+
+	expiresAt := rule.ExpiresAt
+	if len(req.ExpiresAt) > 0 {
+		if bytes.Equal(req.ExpiresAt, []byte("null")) {
+			expiresAt = nil
+		} else {
+			var parsed time.Time
+			if err := json.Unmarshal(req.ExpiresAt, &parsed); err != nil {
+				ctx.SetJSONError(http.StatusBadRequest, "Invalid expires_at")
+				return
+			}
+			if !parsed.After(time.Now()) {
+				ctx.SetJSONError(http.StatusBadRequest, "expires_at must be in the future")
+				return
+			}
+			expiresAt = &parsed
+		}
+	}
+
+	if err := ctx.Storage.UpdateBlacklistRule(ctx, ruleID, reason, expiresAt); err != nil {
+		ctx.Logger.Error("failed to update blacklist rule",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"rule_id", ruleID,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to update blacklist rule")
+		return
+	}
+
+	updated, err := ctx.Storage.GetBlacklistRuleByID(ctx, ruleID)
+	if err != nil {
+		ctx.Logger.Error("failed to reload blacklist rule", "error", err, "rule_id", ruleID)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get blacklist rule")
+		return
+	}
+
+	ctx.Logger.Info("blacklist rule updated",
+		"admin", principal.GetUsername(),
+		"rule_id", ruleID,
+		"expires_at", expiresAt,
+	)
+
+	ctx.WriteJSON(http.StatusOK, updated)
+}
+
+// DELETEBlacklistRule lifts a blacklist rule (admin-only). The rule is kept for auditing.
+func DELETEBlacklistRule(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallBlacklist) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	ruleID, ok := parseBlacklistRuleID(ctx)
+	if !ok {
+		return
+	}
+
+	rule, err := ctx.Storage.GetBlacklistRuleByID(ctx, ruleID)
+	if err != nil {
+		ctx.Logger.Error("failed to get blacklist rule", "error", err, "rule_id", ruleID)
+		ctx.SetJSONError(http.StatusNotFound, "Blacklist rule not found")
+		return
+	}
+
+	if rule.RemovedAt != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Blacklist rule has already been lifted")
+		return
+	}
+
+	if err := ctx.Storage.RemoveBlacklistRule(ctx, ruleID, principal.GetIss(), principal.GetSub()); err != nil {
+		ctx.Logger.Error("failed to lift blacklist rule",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"rule_id", ruleID,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to lift blacklist rule")
+		return
+	}
+
+	ctx.Logger.Info("blacklist rule lifted",
+		"admin", principal.GetUsername(),
+		"rule_id", ruleID,
+		"value", rule.Value,
+		"source", rule.Source,
+	)
+
+	ctx.Response.WriteHeader(http.StatusNoContent)
+}
+
+// parseBlacklistRuleID reads the {id} path parameter, writing a 400 response when it is invalid.
+func parseBlacklistRuleID(ctx *middlewares.AppContext) (int, bool) {
+	idParam := strings.TrimSpace(chi.URLParam(ctx.Request, "id"))
+	if idParam == "" {
+		ctx.SetJSONError(http.StatusBadRequest, "Rule ID is required")
+		return 0, false
+	}
+
+	ruleID, err := strconv.Atoi(idParam)
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid rule ID")
+		return 0, false
+	}
+
+	return ruleID, true
+}
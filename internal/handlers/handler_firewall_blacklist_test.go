@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/testutil"
+	"io"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestPOSTBlacklistRule(t *testing.T) {
+	admin := &models.User{Iss: "iss", Sub: "sub", Username: "admin", Groups: []string{"admins"}}
+
+	newTestContext := func(t *testing.T, body string) *testutil.TestContext {
+		tc := testutil.NewTestContextWithURL(t, "POST", "/api/firewall/blacklist")
+		tc.Request.Body = io.NopCloser(strings.NewReader(body))
+		tc.AppContext.Config.Authorization.GroupScopes = map[string][]string{
+			"admins": {authorization.ScopeFirewallBlacklist},
+		}
+		tc.AppContext.SetPrincipal(admin)
+		return tc
+	}
+
+	t.Run("ShouldRejectASNWithoutDatabase", func(t *testing.T) {
+		tc := newTestContext(t, `{"value":"AS64496"}`)
+		defer tc.Finish()
+
+		tc.CallHandler(POSTBlacklistRule)
+
+		tc.AssertStatus(t, 400)
+		tc.AssertJSONField(t, "error", "ASN rules require features.firewall_management.geoip.asn_database")
+	})
+
+	t.Run("ShouldCreateASNWithDatabase", func(t *testing.T) {
+		tc := newTestContext(t, `{"value":"AS64496"}`)
+		defer tc.Finish()
+		tc.AppContext.Config.Features.FirewallManagement.GeoIP = &config.FirewallGeoIPConfig{ASNDatabase: "GeoLite2-ASN.mmdb"}
+
+		tc.MockStorageProvider.EXPECT().CreateBlacklistRule(tc.AppContext, gomock.Any()).DoAndReturn(
+			func(_ any, rule *models.FirewallBlacklistRule) (*models.FirewallBlacklistRule, error) {
+				return rule, nil
+			})
+
+		tc.CallHandler(POSTBlacklistRule)
+
+		tc.AssertStatus(t, 201)
+		tc.AssertJSONField(t, "value", "AS64496")
+	})
+}
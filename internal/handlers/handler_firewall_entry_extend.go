@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"fmt"
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PATCHExtendIPEntry renews the owner's entry for another default TTL from now, keeping its ID and access.
+// Extensions are limited by the alias's max_extensions and capped at max_lifetime after the original request.
+func PATCHExtendIPEntry(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallRequestOwn) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	entryID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(ctx.Request, "id")))
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid entry ID")
+		return
+	}
+
+	entry, err := ctx.Storage.GetWhitelistEntryByID(ctx, entryID)
+	if err != nil {
+		ctx.Logger.Error("failed to get whitelist entry",
+			"error", err,
+			"entry_id", entryID,
+		)
+		ctx.SetJSONError(http.StatusNotFound, "Whitelist entry not found")
+		return
+	}
+
+	if !principal.MatchesOwner(entry.OwnerIss, entry.OwnerSub) {
+		ctx.SetJSONError(http.StatusForbidden, "You can only extend your own IP addresses")
+		return
+	}
+
+	if entry.Status != models.StatusRequested && entry.Status != models.StatusAdded && entry.Status != models.StatusScheduled {
+		ctx.SetJSONError(http.StatusBadRequest, fmt.Sprintf("Only active entries can be extended (status: %s)", entry.Status))
+		return
+	}
+
+	now := time.Now()
+	if entry.ExpiresAt == nil {
+		ctx.SetJSONError(http.StatusBadRequest, "This entry does not expire")
+		return
+	}
+	if !entry.ExpiresAt.After(now) {
+		ctx.SetJSONError(http.StatusBadRequest, "This entry has already expired")
+		return
+	}
+
+	aliases, ok := loadFirewallAliases(ctx)
+	if !ok {
+		return
+	}
+
+	aliasConfig := findExtendableAlias(aliases, entry.AliasUUID, principal)
+	if aliasConfig == nil {
+		ctx.SetJSONError(http.StatusForbidden, "You no longer have access to this alias")
+		return
+	}
+
+	if aliasConfig.MaxExtensions == 0 || aliasConfig.DefaultTTL == nil {
+		ctx.SetJSONError(http.StatusForbidden, "Entries in this alias cannot be extended")
+		return
+	}
+
+	if entry.ExtensionCount >= aliasConfig.MaxExtensions {
+		ctx.SetJSONError(http.StatusConflict,
+			fmt.Sprintf("This entry has reached the maximum number of extensions (%d)", aliasConfig.MaxExtensions))
+		return
+	}
+
+	expiresAt := now.Add(*aliasConfig.DefaultTTL)
+	if aliasConfig.MaxLifetime != nil {
+		if limit := entry.RequestedAt.Add(*aliasConfig.MaxLifetime); expiresAt.After(limit) {
+			expiresAt = limit
+		}
+	}
+
+	if !expiresAt.After(*entry.ExpiresAt) {
+		ctx.SetJSONError(http.StatusBadRequest, "This entry cannot be extended beyond its current expiry")
+		return
+	}
+
+	var clientIPPtr, userAgentPtr *string
+	if host, _, err := net.SplitHostPort(ctx.Request.RemoteAddr); err == nil && host != "" {
+		clientIPPtr = &host
+	}
+	if userAgent := ctx.Request.UserAgent(); userAgent != "" {
+		userAgentPtr = &userAgent
+	}
+
+	notes := fmt.Sprintf("expiry extended from %s to %s", entry.ExpiresAt.UTC().Format(time.RFC3339), expiresAt.UTC().Format(time.RFC3339))
+
+	extended, err := ctx.Storage.ExtendWhitelistEntry(ctx, entryID, entry.ExtensionCount, expiresAt,
+		principal.GetIss(), principal.GetSub(), notes, clientIPPtr, userAgentPtr)
+	if err != nil {
+		ctx.Logger.Error("failed to extend whitelist entry",
+			"error", err,
+			"user", principal.GetUsername(),
+			"entry_id", entryID,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to extend whitelist entry")
+		return
+	}
+
+	if !extended {
+		ctx.SetJSONError(http.StatusConflict, "The entry was changed by another request, please try again")
+		return
+	}
+
+	updated, err := ctx.Storage.GetWhitelistEntryByID(ctx, entryID)
+	if err != nil {
+		ctx.Logger.Error("failed to reload whitelist entry", "error", err, "entry_id", entryID)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get whitelist entry")
+		return
+	}
+
+	ctx.Logger.Info("whitelist entry extended",
+		"user", principal.GetUsername(),
+		"entry_id", entryID,
+		"ip", entry.IPAddress,
+		"alias", entry.AliasName,
+		"expires_at", expiresAt,
+		"extension", updated.ExtensionCount,
+	)
+
+	ctx.WriteJSON(http.StatusOK, updated)
+}
+
+// findExtendableAlias returns the alias with the given uuid as offered to principal, preferring the
+// most generous extension limits when principal has access through several groups.
+func findExtendableAlias(aliases []config.FirewallAliasConfig, aliasUUID string, principal middlewares.Principal) *config.FirewallAliasConfig {
+	var best *config.FirewallAliasConfig
+	for i := range aliases {
+		alias := &aliases[i]
+		if !strings.EqualFold(alias.UUID, aliasUUID) || !principalHasAliasAccess(principal, alias) {
+			continue
+		}
+		if best == nil || alias.MaxExtensions > best.MaxExtensions {
+			best = alias
+		}
+	}
+	return best
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFindExtendableAlias(t *testing.T) {
+	aliases := []config.FirewallAliasConfig{
+		{Name: "lan", UUID: "a", AuthGroup: "users", MaxExtensions: 1},
+		{Name: "lan", UUID: "a", AuthGroup: "admins", MaxExtensions: 5},
+		{Name: "dmz", UUID: "b", AuthGroup: "users"},
+	}
+
+	testCases := []struct {
+		testName      string
+		aliasUUID     string
+		principal     middlewares.Principal
+		expectedFound bool
+		expectedMax   int
+	}{
+		{"ShouldFindAliasForGroup", "a", &models.User{Groups: []string{"users"}}, true, 1},
+		{"ShouldPreferMostGenerousGroup", "A", &models.User{Groups: []string{"users", "admins"}}, true, 5},
+		{"ShouldReturnAliasWithoutExtensions", "b", &models.User{Groups: []string{"users"}}, true, 0},
+		{"ShouldNotFindAliasForOtherGroup", "b", &models.User{Groups: []string{"admins"}}, false, 0},
+		{"ShouldNotFindUnknownAlias", "c", &models.User{Groups: []string{"users"}}, false, 0},
+		{"ShouldFindAliasForServiceAccountGrant", "a", &models.ServiceAccount{
+			FirewallGrants: []models.FirewallAliasGrant{{AliasUUID: "a", AuthGroup: "users"}},
+		}, true, 1},
+		{"ShouldNotFindAliasWithoutServiceAccountGrant", "b", &models.ServiceAccount{
+			FirewallGrants: []models.FirewallAliasGrant{{AliasUUID: "a", AuthGroup: "users"}},
+		}, false, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			alias := findExtendableAlias(aliases, tc.aliasUUID, tc.principal)
+			if !tc.expectedFound {
+				require.Nil(t, alias)
+				return
+			}
+			require.NotNil(t, alias)
+			require.Equal(t, tc.expectedMax, alias.MaxExtensions)
+		})
+	}
+}
+
+func TestPATCHExtendIPEntry(t *testing.T) {
+	owner := &models.User{Iss: "iss", Sub: "sub", Username: "owner", Groups: []string{"users"}}
+	ttl := int64(3600)
+	alias := &models.FirewallManagedAlias{ID: 1, Name: "lan", UUID: "a", AuthGroup: "users", DefaultTTLSeconds: &ttl, MaxExtensions: 2}
+
+	newEntry := func(ownerSub string, extensionCount int) *models.FirewallIPWhitelistEntry {
+		expiresAt := time.Now().Add(10 * time.Minute)
+		return &models.FirewallIPWhitelistEntry{
+			ID:             5,
+			OwnerIss:       "iss",
+			OwnerSub:       ownerSub,
+			AliasUUID:      "a",
+			AliasName:      "lan",
+			IPAddress:      "192.0.2.10",
+			Status:         models.StatusAdded,
+			RequestedAt:    time.Now().Add(-time.Hour),
+			ExpiresAt:      &expiresAt,
+			ExtensionCount: extensionCount,
+		}
+	}
+
+	newTestContext := func(t *testing.T) *testutil.TestContext {
+		tc := testutil.NewTestContextWithURL(t, "PATCH", "/api/firewall/entries/5/extend")
+		tc.WithURLParam("id", "5")
+		tc.AppContext.Config.Authorization.GroupScopes = map[string][]string{
+			"users": {authorization.ScopeFirewallRequestOwn},
+		}
+		tc.AppContext.SetPrincipal(owner)
+		return tc
+	}
+
+	t.Run("ShouldExtendOwnEntry", func(t *testing.T) {
+		tc := newTestContext(t)
+		defer tc.Finish()
+
+		entry := newEntry("sub", 0)
+		extended := *entry
+		extended.ExtensionCount = 1
+
+		tc.MockStorageProvider.EXPECT().GetWhitelistEntryByID(tc.AppContext, 5).Return(entry, nil)
+		tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return([]*models.FirewallManagedAlias{alias}, nil)
+		tc.MockStorageProvider.EXPECT().ExtendWhitelistEntry(tc.AppContext, 5, 0, gomock.Any(), "iss", "sub", gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, _, _ int, expiresAt time.Time, _, _, _ string, _, _ *string) (bool, error) {
+				require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+				return true, nil
+			})
+		tc.MockStorageProvider.EXPECT().GetWhitelistEntryByID(tc.AppContext, 5).Return(&extended, nil)
+
+		tc.CallHandler(PATCHExtendIPEntry)
+
+		tc.AssertStatus(t, 200)
+		tc.AssertJSONField(t, "extension_count", float64(1))
+	})
+
+	t.Run("ShouldRejectOtherUsersEntry", func(t *testing.T) {
+		tc := newTestContext(t)
+		defer tc.Finish()
+
+		tc.MockStorageProvider.EXPECT().GetWhitelistEntryByID(tc.AppContext, 5).Return(newEntry("other", 0), nil)
+
+		tc.CallHandler(PATCHExtendIPEntry)
+
+		tc.AssertStatus(t, 403)
+		tc.AssertJSONField(t, "error", "You can only extend your own IP addresses")
+	})
+
+	t.Run("ShouldRejectExtensionOverLimit", func(t *testing.T) {
+		tc := newTestContext(t)
+		defer tc.Finish()
+
+		tc.MockStorageProvider.EXPECT().GetWhitelistEntryByID(tc.AppContext, 5).Return(newEntry("sub", 2), nil)
+		tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return([]*models.FirewallManagedAlias{alias}, nil)
+
+		tc.CallHandler(PATCHExtendIPEntry)
+
+		tc.AssertStatus(t, 409)
+		tc.AssertJSONField(t, "error", "This entry has reached the maximum number of extensions (2)")
+	})
+
+	t.Run("ShouldReportConcurrentExtension", func(t *testing.T) {
+		tc := newTestContext(t)
+		defer tc.Finish()
+
+		tc.MockStorageProvider.EXPECT().GetWhitelistEntryByID(tc.AppContext, 5).Return(newEntry("sub", 1), nil)
+		tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return([]*models.FirewallManagedAlias{alias}, nil)
+		tc.MockStorageProvider.EXPECT().ExtendWhitelistEntry(tc.AppContext, 5, 1, gomock.Any(), "iss", "sub", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(false, nil)
+
+		tc.CallHandler(PATCHExtendIPEntry)
+
+		tc.AssertStatus(t, 409)
+		tc.AssertJSONField(t, "error", "The entry was changed by another request, please try again")
+	})
+}
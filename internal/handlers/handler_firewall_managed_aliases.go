@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/services/firewall"
+	"homelab-dashboard/internal/storage"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RouterAliasResponse struct {
+	UUID        string   `json:"uuid"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Manageable  bool     `json:"manageable"`  // host and network aliases only
+	AuthGroups  []string `json:"auth_groups"` // groups the alias is currently offered to
+}
+
+type managedAliasRequest struct {
+	UUID             string   `json:"uuid"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	MaxIPsPerUser    int      `json:"max_ips_per_user"`
+	MaxTotalIPs      int      `json:"max_total_ips"`
+	DefaultTTLHours  *int64   `json:"default_ttl_hours"` // null = no expiration
+	AuthGroup        string   `json:"auth_group"`
+	DryRun           bool     `json:"dry_run"`
+	MaxLifetimeHours *int64   `json:"max_lifetime_hours"` // null = no lifetime cap
+	MaxExtensions    int      `json:"max_extensions"`
+	AllowedCountries []string `json:"allowed_countries"`
+	DeniedCountries  []string `json:"denied_countries"`
+	AllowedASNs      []int64  `json:"allowed_asns"`
+	DeniedASNs       []int64  `json:"denied_asns"`
+
+	// Set to manage a Traefik Middleware's ipAllowList instead of an OPNsense alias; uuid is then optional
+	TraefikNamespace string `json:"traefik_namespace"`
+	TraefikName      string `json:"traefik_name"`
+}
+
+// GETRouterAliases lists every alias on the router and which auth groups each is offered to (admin-only).
+func GETRouterAliases(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallAliases) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	if ctx.RouterClient == nil {
+		ctx.SetJSONError(http.StatusServiceUnavailable, "Firewall router client is not configured")
+		return
+	}
+
+	rows, err := ctx.RouterClient.ListAliases(ctx)
+	if err != nil {
+		ctx.Logger.Error("failed to list router aliases", "error", err)
+		ctx.SetJSONError(http.StatusBadGateway, "Failed to list aliases from router")
+		return
+	}
+
+	aliases, ok := loadFirewallAliases(ctx)
+	if !ok {
+		return
+	}
+
+	authGroups := make(map[string][]string)
+	for _, alias := range aliases {
+		key := strings.ToLower(alias.UUID)
+		authGroups[key] = append(authGroups[key], alias.AuthGroup)
+	}
+
+	response := make([]RouterAliasResponse, 0, len(rows))
+	for _, row := range rows {
+		groups := authGroups[strings.ToLower(row.UUID)]
+		if groups == nil {
+			groups = []string{}
+		}
+
+		response = append(response, RouterAliasResponse{
+			UUID:        row.UUID,
+			Name:        row.Name,
+			Type:        row.Type,
+			Description: row.Description,
+			Enabled:     row.Enabled == "1",
+			Manageable:  firewall.IsManageableAliasType(row.Type),
+			AuthGroups:  groups,
+		})
+	}
+
+	ctx.WriteJSON(http.StatusOK, response)
+}
+
+// GETManagedAliases lists every managed alias (admin-only), including those imported from the config file at
+// startup, which can be changed here like any other.
+func GETManagedAliases(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallAliases) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	aliases, err := ctx.Storage.GetManagedAliases(ctx)
+	if err != nil {
+		ctx.Logger.Error("failed to get managed aliases", "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get managed aliases")
+		return
+	}
+
+	if aliases == nil {
+		aliases = []*models.FirewallManagedAlias{}
+	}
+
+	ctx.WriteJSON(http.StatusOK, aliases)
+}
+
+// POSTManagedAlias enables a router alias or a Traefik middleware for an auth group with the given limits
+// (admin-only). A router alias must exist and be a host or network alias, its name and description default to
+// the router's values. A middleware must exist and have an ipAllowList, its name defaults to the middleware's.
+func POSTManagedAlias(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallAliases) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	var req managedAliasRequest
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.UUID = strings.ToLower(strings.TrimSpace(req.UUID))
+	req.TraefikNamespace = strings.TrimSpace(req.TraefikNamespace)
+	req.TraefikName = strings.TrimSpace(req.TraefikName)
+	traefik := req.TraefikNamespace != "" || req.TraefikName != ""
+
+	if traefik {
+		if strings.TrimSpace(req.Name) == "" {
+			req.Name = req.TraefikName
+		}
+	} else {
+		if ctx.RouterClient == nil {
+			ctx.SetJSONError(http.StatusServiceUnavailable, "Firewall router client is not configured")
+			return
+		}
+
+		routerAlias, ok := validateRouterAlias(ctx, req.UUID)
+		if !ok {
+			return
+		}
+
+		if strings.TrimSpace(req.Name) == "" {
+			req.Name = routerAlias.Name
+		}
+		if strings.TrimSpace(req.Description) == "" {
+			req.Description = routerAlias.Description
+		}
+	}
+
+	alias, ok := buildManagedAlias(ctx, &req)
+	if !ok {
+		return
+	}
+
+	if traefik && !validateAliasBackend(ctx, alias) {
+		return
+	}
+
+	alias.CreatedByIss = principal.GetIss()
+	alias.CreatedBySub = principal.GetSub()
+
+	created, err := ctx.Storage.CreateManagedAlias(ctx, alias)
+	if err != nil {
+		if errors.Is(err, storage.ErrManagedAliasExists) {
+			ctx.SetJSONError(http.StatusConflict, "Alias is already managed for this auth group")
+			return
+		}
+		ctx.Logger.Error("failed to create managed alias",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"alias_uuid", alias.UUID,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to create managed alias")
+		return
+	}
+
+	ctx.Logger.Info("managed alias created",
+		"admin", principal.GetUsername(),
+		"id", created.ID,
+		"alias", created.Name,
+		"alias_uuid", created.UUID,
+		"auth_group", created.AuthGroup,
+	)
+
+	ctx.WriteJSON(http.StatusCreated, created)
+}
+
+// PUTManagedAlias replaces the settings of a managed alias (admin-only). The router alias or middleware cannot be
+// changed.
+func PUTManagedAlias(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallAliases) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	aliasID, ok := parseManagedAliasID(ctx)
+	if !ok {
+		return
+	}
+
+	var req managedAliasRequest
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	existing, err := ctx.Storage.GetManagedAliasByID(ctx, aliasID)
+	if err != nil {
+		ctx.Logger.Error("failed to get managed alias", "error", err, "id", aliasID)
+		ctx.SetJSONError(http.StatusNotFound, "Managed alias not found")
+		return
+	}
+
+	if req.UUID != "" && !strings.EqualFold(strings.TrimSpace(req.UUID), existing.UUID) {
+		ctx.SetJSONError(http.StatusBadRequest, "uuid cannot be changed")
+		return
+	}
+	req.UUID = existing.UUID
+
+	if (req.TraefikNamespace != "" && strings.TrimSpace(req.TraefikNamespace) != existing.TraefikNamespace) ||
+		(req.TraefikName != "" && strings.TrimSpace(req.TraefikName) != existing.TraefikName) {
+		ctx.SetJSONError(http.StatusBadRequest, "traefik middleware cannot be changed")
+		return
+	}
+	req.TraefikNamespace = existing.TraefikNamespace
+	req.TraefikName = existing.TraefikName
+
+	if strings.TrimSpace(req.Name) == "" {
+		req.Name = existing.Name
+	}
+
+	alias, ok := buildManagedAlias(ctx, &req)
+	if !ok {
+		return
+	}
+	alias.ID = existing.ID
+
+	if err := ctx.Storage.UpdateManagedAlias(ctx, alias); err != nil {
+		if errors.Is(err, storage.ErrManagedAliasExists) {
+			ctx.SetJSONError(http.StatusConflict, "Alias is already managed for this auth group")
+			return
+		}
+		ctx.Logger.Error("failed to update managed alias",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"id", aliasID,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to update managed alias")
+		return
+	}
+
+	updated, err := ctx.Storage.GetManagedAliasByID(ctx, aliasID)
+	if err != nil {
+		ctx.Logger.Error("failed to reload managed alias", "error", err, "id", aliasID)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get managed alias")
+		return
+	}
+
+	ctx.Logger.Info("managed alias updated",
+		"admin", principal.GetUsername(),
+		"id", aliasID,
+		"alias", updated.Name,
+		"auth_group", updated.AuthGroup,
+	)
+
+	ctx.WriteJSON(http.StatusOK, updated)
+}
+
+// DELETEManagedAlias stops offering an alias to its auth group (admin-only). When no other auth group is
+// offered the alias, nothing would sync it afterwards, so its entries are removed and synced off the router
+// or middleware first.
+func DELETEManagedAlias(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallAliases) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	aliasID, ok := parseManagedAliasID(ctx)
+	if !ok {
+		return
+	}
+
+	existing, err := ctx.Storage.GetManagedAliasByID(ctx, aliasID)
+	if err != nil {
+		ctx.Logger.Error("failed to get managed alias", "error", err, "id", aliasID)
+		ctx.SetJSONError(http.StatusNotFound, "Managed alias not found")
+		return
+	}
+
+	aliases, ok := loadFirewallAliases(ctx)
+	if !ok {
+		return
+	}
+
+	shared := slices.ContainsFunc(aliases, func(alias config.FirewallAliasConfig) bool {
+		return strings.EqualFold(alias.UUID, existing.UUID) && alias.AuthGroup != existing.AuthGroup
+	})
+	if !shared && !clearManagedAlias(ctx, principal, existing) {
+		return
+	}
+
+	if err := ctx.Storage.DeleteManagedAlias(ctx, aliasID); err != nil {
+		ctx.Logger.Error("failed to delete managed alias",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"id", aliasID,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to delete managed alias")
+		return
+	}
+
+	ctx.Logger.Info("managed alias deleted",
+		"admin", principal.GetUsername(),
+		"id", aliasID,
+		"alias", existing.Name,
+		"alias_uuid", existing.UUID,
+		"auth_group", existing.AuthGroup,
+	)
+
+	ctx.Response.WriteHeader(http.StatusNoContent)
+}
+
+// clearManagedAlias removes the entries of an alias about to be deleted and applies the removal to its backend,
+// writing an error response on failure. The alias is kept in that case, so the sync job or another delete
+// finishes the removal. A dry-run alias was never written to, so only its entries are removed.
+func clearManagedAlias(ctx *middlewares.AppContext, principal middlewares.Principal, alias *models.FirewallManagedAlias) bool {
+	removed, err := ctx.Storage.RemoveAliasEntries(ctx, alias.UUID, principal.GetIss(), principal.GetSub(), "Alias is no longer managed")
+	if err != nil {
+		ctx.Logger.Error("failed to remove alias entries", "error", err, "alias_uuid", alias.UUID)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to remove alias entries")
+		return false
+	}
+
+	if alias.DryRun {
+		return true
+	}
+
+	aliasConfig := firewall.ManagedAliasToConfig(alias)
+	backend, err := firewall.BackendForAlias(ctx.RouterClient, ctx.TraefikClient, &aliasConfig)
+	if err != nil {
+		ctx.SetJSONError(http.StatusServiceUnavailable, "Firewall backend for this alias is not configured")
+		return false
+	}
+
+	currentIPs, err := backend.GetAliasIPs(ctx)
+	if err != nil {
+		ctx.Logger.Error("failed to get current alias IPs", "error", err, "alias", alias.Name)
+		ctx.SetJSONError(http.StatusBadGateway, "Failed to read alias from its backend")
+		return false
+	}
+
+	// Entries requested since the removal are still synced, like the sync job would
+	aliasEntries, err := ctx.Storage.GetAliasSyncEntries(ctx, alias.UUID)
+	if err != nil {
+		ctx.Logger.Error("failed to get alias whitelist entries", "error", err, "alias", alias.Name)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get whitelist entries")
+		return false
+	}
+
+	plan := firewall.BuildSyncPlan(alias.Name, alias.UUID, currentIPs, aliasEntries)
+	if !plan.HasChanges() {
+		return true
+	}
+
+	if _, err := backend.ApplyAliasChanges(ctx, plan.IPsToAdd, plan.IPsToRemove); err != nil {
+		ctx.Logger.Error("failed to remove entries from alias backend", "error", err, "alias", alias.Name)
+		ctx.SetJSONError(http.StatusBadGateway, "Failed to remove entries from the alias backend")
+		return false
+	}
+
+	plan.Status = models.SyncPlanApplied
+	if _, err := ctx.Storage.CreateFirewallSyncPlan(ctx, plan); err != nil {
+		ctx.Logger.Error("failed to record firewall sync plan", "error", err, "alias", alias.Name)
+	}
+
+	ctx.Logger.Info("removed entries of deleted alias",
+		"alias", alias.Name,
+		"alias_uuid", alias.UUID,
+		"entries", removed,
+		"ips_removed", len(plan.IPsToRemove),
+	)
+
+	return true
+}
+
+// validateRouterAlias looks the alias up on the router, writing an error response when it is missing,
+// of an unsupported type, or the router cannot be reached.
+func validateRouterAlias(ctx *middlewares.AppContext, uuid string) (*firewall.AliasDetail, bool) {
+	if uuid == "" {
+		ctx.SetJSONError(http.StatusBadRequest, "uuid is required")
+		return nil, false
+	}
+
+	alias, err := ctx.RouterClient.ValidateAlias(ctx, uuid)
+	switch {
+	case errors.Is(err, firewall.ErrAliasNotFound):
+		ctx.SetJSONError(http.StatusBadRequest, "Alias does not exist on the router")
+		return nil, false
+	case errors.Is(err, firewall.ErrUnsupportedAliasType):
+		ctx.SetJSONError(http.StatusBadRequest, "Only host and network aliases can be managed")
+		return nil, false
+	case err != nil:
+		ctx.Logger.Error("failed to get router alias", "error", err, "alias_uuid", uuid)
+		ctx.SetJSONError(http.StatusBadGateway, "Failed to read alias from router")
+		return nil, false
+	}
+
+	return alias, true
+}
+
+// validateAliasBackend checks the alias exists on its backend and can hold whitelist entries, writing an error
+// response when it cannot.
+func validateAliasBackend(ctx *middlewares.AppContext, alias *models.FirewallManagedAlias) bool {
+	aliasConfig := firewall.ManagedAliasToConfig(alias)
+	backend, err := firewall.BackendForAlias(ctx.RouterClient, ctx.TraefikClient, &aliasConfig)
+	if err == nil {
+		err = backend.Validate(ctx)
+	}
+
+	switch {
+	case errors.Is(err, firewall.ErrBackendNotConfigured):
+		ctx.SetJSONError(http.StatusServiceUnavailable, "Firewall backend for this alias is not configured")
+		return false
+	case errors.Is(err, firewall.ErrAliasNotFound):
+		ctx.SetJSONError(http.StatusBadRequest, "Alias does not exist on its backend")
+		return false
+	case errors.Is(err, firewall.ErrUnsupportedAliasType):
+		ctx.SetJSONError(http.StatusBadRequest, "Alias cannot hold whitelist entries")
+		return false
+	case err != nil:
+		ctx.Logger.Error("failed to validate alias backend", "error", err, "alias_uuid", alias.UUID)
+		ctx.SetJSONError(http.StatusBadGateway, "Failed to read alias from its backend")
+		return false
+	}
+
+	return true
+}
+
+// buildManagedAlias validates a request with the same rules as config file aliases, writing a 400 response on failure.
+func buildManagedAlias(ctx *middlewares.AppContext, req *managedAliasRequest) (*models.FirewallManagedAlias, bool) {
+	alias := &models.FirewallManagedAlias{
+		UUID:             req.UUID,
+		Name:             strings.TrimSpace(req.Name),
+		Description:      strings.TrimSpace(req.Description),
+		MaxIPsPerUser:    req.MaxIPsPerUser,
+		MaxTotalIPs:      req.MaxTotalIPs,
+		AuthGroup:        strings.TrimSpace(req.AuthGroup),
+		DryRun:           req.DryRun,
+		MaxExtensions:    req.MaxExtensions,
+		AllowedCountries: req.AllowedCountries,
+		DeniedCountries:  req.DeniedCountries,
+		AllowedASNs:      req.AllowedASNs,
+		DeniedASNs:       req.DeniedASNs,
+		TraefikNamespace: req.TraefikNamespace,
+		TraefikName:      req.TraefikName,
+	}
+
+	if req.DefaultTTLHours != nil {
+		seconds := *req.DefaultTTLHours * 3600
+		alias.DefaultTTLSeconds = &seconds
+	}
+
+	if req.MaxLifetimeHours != nil {
+		seconds := *req.MaxLifetimeHours * 3600
+		alias.MaxLifetimeSeconds = &seconds
+	}
+
+	aliasConfig := firewall.ManagedAliasToConfig(alias)
+	if alias.TraefikNamespace != "" || alias.TraefikName != "" {
+		// A namespace without a name still asks for a middleware, and must fail validation as one
+		aliasConfig.Traefik = &config.FirewallTraefikMiddleware{Namespace: alias.TraefikNamespace, Name: alias.TraefikName}
+	}
+	if err := ctx.Config.ValidateFirewallAlias(&aliasConfig); err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	// ValidateFirewallAlias normalizes country codes in place, and the slices are shared with alias. A
+	// middleware's uuid is derived from it when omitted.
+	alias.UUID = aliasConfig.UUID
+	return alias, true
+}
+
+// parseManagedAliasID reads the {id} path parameter, writing a 400 response when it is invalid.
+func parseManagedAliasID(ctx *middlewares.AppContext) (int, bool) {
+	idParam := strings.TrimSpace(chi.URLParam(ctx.Request, "id"))
+	if idParam == "" {
+		ctx.SetJSONError(http.StatusBadRequest, "Alias ID is required")
+		return 0, false
+	}
+
+	aliasID, err := strconv.Atoi(idParam)
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid alias ID")
+		return 0, false
+	}
+
+	return aliasID, true
+}
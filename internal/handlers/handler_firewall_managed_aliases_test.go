@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/services/firewall"
+	"homelab-dashboard/internal/services/firewall/opnsensesim"
+	"homelab-dashboard/internal/testutil"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestDELETEManagedAlias(t *testing.T) {
+	const aliasUUID = "c0daef37-718c-40e4-bb2b-ba5aab418d0d"
+	admin := &models.User{Iss: "iss", Sub: "sub", Username: "admin", Groups: []string{"admins"}}
+	alias := &models.FirewallManagedAlias{ID: 1, UUID: aliasUUID, Name: "Database", AuthGroup: "users", MaxIPsPerUser: 1, MaxTotalIPs: 5}
+
+	// newTestContext sets up an admin deleting alias 1, backed by a simulated router holding one entry.
+	newTestContext := func(t *testing.T) (*testutil.TestContext, *opnsensesim.Server) {
+		sim := opnsensesim.NewServer("key", "secret")
+		sim.AddAlias(aliasUUID, "Database", "host", "192.0.2.10")
+		server := httptest.NewServer(sim)
+		t.Cleanup(server.Close)
+
+		tc := testutil.NewTestContextWithURL(t, "DELETE", "/api/firewall/managed-aliases/1")
+		tc.WithURLParam("id", "1")
+		tc.AppContext.Config.Authorization.GroupScopes = map[string][]string{
+			"admins": {authorization.ScopeFirewallAliases},
+		}
+		tc.AppContext.Config.Features.FirewallManagement.RouterEndpoint = server.URL
+		tc.AppContext.Config.Features.FirewallManagement.RouterAPIKey = "key"
+		tc.AppContext.Config.Features.FirewallManagement.RouterAPISecret = "secret"
+		tc.AppContext.RouterClient = firewall.NewRouterClient(*tc.AppContext.Config)
+		tc.AppContext.SetPrincipal(admin)
+
+		tc.MockStorageProvider.EXPECT().GetManagedAliasByID(tc.AppContext, 1).Return(alias, nil)
+		return tc, sim
+	}
+
+	t.Run("ShouldRemoveEntriesFromRouterBeforeDeleting", func(t *testing.T) {
+		tc, sim := newTestContext(t)
+		defer tc.Finish()
+
+		gomock.InOrder(
+			tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return([]*models.FirewallManagedAlias{alias}, nil),
+			tc.MockStorageProvider.EXPECT().RemoveAliasEntries(tc.AppContext, aliasUUID, "iss", "sub", gomock.Any()).Return(1, nil),
+			tc.MockStorageProvider.EXPECT().GetAliasSyncEntries(tc.AppContext, aliasUUID).Return(nil, nil),
+			tc.MockStorageProvider.EXPECT().CreateFirewallSyncPlan(tc.AppContext, gomock.Any()).Return(&models.FirewallSyncPlan{}, nil),
+			tc.MockStorageProvider.EXPECT().DeleteManagedAlias(tc.AppContext, 1).Return(nil),
+		)
+
+		tc.CallHandler(DELETEManagedAlias)
+
+		tc.AssertStatus(t, 204)
+		if content := sim.Content(aliasUUID); len(content) != 0 {
+			t.Errorf("Expected the router alias to be emptied, got %v", content)
+		}
+	})
+
+	t.Run("ShouldKeepAliasWhenRouterFails", func(t *testing.T) {
+		tc, sim := newTestContext(t)
+		defer tc.Finish()
+		sim.InjectFailure(opnsensesim.EndpointGetItem, opnsensesim.Failure{Status: 500})
+
+		tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return([]*models.FirewallManagedAlias{alias}, nil)
+		tc.MockStorageProvider.EXPECT().RemoveAliasEntries(tc.AppContext, aliasUUID, "iss", "sub", gomock.Any()).Return(1, nil)
+
+		tc.CallHandler(DELETEManagedAlias)
+
+		tc.AssertStatus(t, 502)
+	})
+
+	t.Run("ShouldLeaveEntriesOfAliasOfferedToAnotherGroup", func(t *testing.T) {
+		tc, sim := newTestContext(t)
+		defer tc.Finish()
+
+		shared := *alias
+		shared.ID, shared.AuthGroup = 2, "admins"
+		tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return([]*models.FirewallManagedAlias{alias, &shared}, nil)
+		tc.MockStorageProvider.EXPECT().DeleteManagedAlias(tc.AppContext, 1).Return(nil)
+
+		tc.CallHandler(DELETEManagedAlias)
+
+		tc.AssertStatus(t, 204)
+		if content := sim.Content(aliasUUID); len(content) != 1 {
+			t.Errorf("Expected the router alias to be left alone, got %v", content)
+		}
+	})
+}
+
+func TestPOSTManagedAliasTraefik(t *testing.T) {
+	admin := &models.User{Iss: "iss", Sub: "sub", Username: "admin", Groups: []string{"admins"}}
+	middleware := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "traefik.io/v1alpha1",
+		"kind":       "Middleware",
+		"metadata":   map[string]interface{}{"namespace": "ingress", "name": "home-allowlist"},
+		"spec": map[string]interface{}{
+			"ipAllowList": map[string]interface{}{"sourceRange": []interface{}{"192.0.2.1"}},
+		},
+	}}
+
+	newTestContext := func(t *testing.T, body string) *testutil.TestContext {
+		tc := testutil.NewTestContextWithURL(t, "POST", "/api/firewall/managed-aliases")
+		tc.Request.Body = io.NopCloser(strings.NewReader(body))
+		tc.AppContext.Config.Authorization.GroupScopes = map[string][]string{
+			"admins": {authorization.ScopeFirewallAliases},
+		}
+		tc.AppContext.Config.Features.FirewallManagement.Kubernetes = &config.KubernetesConfig{}
+		tc.AppContext.TraefikClient = firewall.NewTraefikClient(dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{firewall.TraefikMiddlewareGVR: "MiddlewareList"}, middleware))
+		tc.AppContext.SetPrincipal(admin)
+		return tc
+	}
+
+	t.Run("ShouldCreateAliasWithoutRouter", func(t *testing.T) {
+		tc := newTestContext(t, `{"traefik_namespace":"ingress","traefik_name":"home-allowlist","auth_group":"admins","max_ips_per_user":1,"max_total_ips":5}`)
+		defer tc.Finish()
+
+		tc.MockStorageProvider.EXPECT().CreateManagedAlias(tc.AppContext, gomock.Any()).DoAndReturn(
+			func(_ any, alias *models.FirewallManagedAlias) (*models.FirewallManagedAlias, error) {
+				if alias.UUID == "" || alias.TraefikNamespace != "ingress" || alias.TraefikName != "home-allowlist" {
+					t.Errorf("Expected a middleware alias with a derived uuid, got %+v", alias)
+				}
+				return alias, nil
+			})
+
+		tc.CallHandler(POSTManagedAlias)
+
+		tc.AssertStatus(t, 201)
+		tc.AssertJSONField(t, "name", "home-allowlist")
+	})
+
+	t.Run("ShouldRejectMissingMiddleware", func(t *testing.T) {
+		tc := newTestContext(t, `{"traefik_namespace":"ingress","traefik_name":"other","auth_group":"admins","max_ips_per_user":1,"max_total_ips":5}`)
+		defer tc.Finish()
+
+		tc.CallHandler(POSTManagedAlias)
+
+		tc.AssertStatus(t, 400)
+		tc.AssertJSONField(t, "error", "Alias does not exist on its backend")
+	})
+
+	t.Run("ShouldRejectNamespaceWithoutName", func(t *testing.T) {
+		tc := newTestContext(t, `{"traefik_namespace":"ingress","name":"Home","auth_group":"admins","max_ips_per_user":1,"max_total_ips":5}`)
+		defer tc.Finish()
+
+		tc.CallHandler(POSTManagedAlias)
+
+		tc.AssertStatus(t, 400)
+		tc.AssertJSONField(t, "error", "traefik.name must be a valid Kubernetes resource name")
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/services/firewall"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultSyncPlanLimit = 20
+	maxSyncPlanLimit     = 200
+)
+
+// GETFirewallSyncPreview computes the changes the sync job would make to an alias right now,
+// without modifying the router or any whitelist entries (admin-only).
+func GETFirewallSyncPreview(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallReadAll) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	aliasConfig := findAliasByUUID(ctx, chi.URLParam(ctx.Request, "uuid"), http.StatusNotFound, "Alias not found")
+	if aliasConfig == nil {
+		return
+	}
+
+	backend, err := firewall.BackendForAlias(ctx.RouterClient, ctx.TraefikClient, aliasConfig)
+	if err != nil {
+		ctx.SetJSONError(http.StatusServiceUnavailable, "Firewall backend for this alias is not configured")
+		return
+	}
+
+	currentIPs, err := backend.GetAliasIPs(ctx)
+	if err != nil {
+		ctx.Logger.Error("failed to get current alias IPs",
+			"error", err,
+			"alias", aliasConfig.Name,
+		)
+		ctx.SetJSONError(http.StatusBadGateway, "Failed to read alias from its backend")
+		return
+	}
+
+	aliasEntries, err := ctx.Storage.GetAliasSyncEntries(ctx, aliasConfig.UUID)
+	if err != nil {
+		ctx.Logger.Error("failed to get alias whitelist entries", "error", err, "alias", aliasConfig.Name)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get whitelist entries")
+		return
+	}
+
+	plan := firewall.BuildSyncPlan(aliasConfig.Name, aliasConfig.UUID, currentIPs, aliasEntries)
+	plan.DryRun = true
+
+	ctx.WriteJSON(http.StatusOK, plan)
+}
+
+// GETFirewallSyncPlans returns the plans recorded by the sync job for an alias, newest first (admin-only).
+func GETFirewallSyncPlans(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeFirewallReadAll) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	aliasConfig := findAliasByUUID(ctx, chi.URLParam(ctx.Request, "uuid"), http.StatusNotFound, "Alias not found")
+	if aliasConfig == nil {
+		return
+	}
+
+	limit := defaultSyncPlanLimit
+	if limitParam := ctx.Request.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			ctx.SetJSONError(http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(parsed, maxSyncPlanLimit)
+	}
+
+	plans, err := ctx.Storage.GetFirewallSyncPlans(ctx, aliasConfig.UUID, limit)
+	if err != nil {
+		ctx.Logger.Error("failed to get firewall sync plans",
+			"error", err,
+			"alias", aliasConfig.Name,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get sync plans")
+		return
+	}
+
+	if plans == nil {
+		plans = []*models.FirewallSyncPlan{}
+	}
+
+	ctx.WriteJSON(http.StatusOK, plans)
+}
+
+// findAliasByUUID returns the managed alias with the given uuid, writing an error response when it
+// cannot be found or the aliases cannot be loaded.
+func findAliasByUUID(ctx *middlewares.AppContext, uuid string, notFoundStatus int, notFoundMessage string) *config.FirewallAliasConfig {
+	aliases, ok := loadFirewallAliases(ctx)
+	if !ok {
+		return nil
+	}
+
+	alias := firewall.FindAliasByUUID(aliases, uuid)
+	if alias == nil {
+		ctx.SetJSONError(notFoundStatus, notFoundMessage)
+		return nil
+	}
+
+	return alias
+}
+
+// loadFirewallAliases returns the config and database aliases, writing a 500 response on failure.
+func loadFirewallAliases(ctx *middlewares.AppContext) ([]config.FirewallAliasConfig, bool) {
+	aliases, err := firewall.LoadAliases(ctx, ctx.Storage)
+	if err != nil {
+		ctx.Logger.Error("failed to load firewall aliases", "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to load firewall aliases")
+		return nil, false
+	}
+	return aliases, true
+}
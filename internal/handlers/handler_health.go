@@ -1,9 +1,39 @@
 package handlers
 
 import (
+	"homelab-dashboard/internal/data"
 	"homelab-dashboard/internal/middlewares"
+	"net/http"
 )
 
+// HealthResponse is the body of the health endpoint.
+type HealthResponse struct {
+	Status      string                        `json:"status"`
+	DataSources map[string]data.CircuitStatus `json:"data_sources,omitempty"`
+}
+
+// HandlerHealth always answers 200, since the server itself is up, but reports "degraded" while a data
+// backend's circuit breaker is open and the dashboard is serving stale data.
 func HandlerHealth(ctx *middlewares.AppContext) {
-	ctx.SetJSONStatus(200, "OK")
+	response := HealthResponse{Status: "OK"}
+
+	if ctx.Cache != nil && ctx.Config != nil {
+		for _, backend := range ctx.Config.Data.Backends {
+			name := data.BackendCircuitName(backend.Name)
+			status, ok := data.GetCircuitStatus(ctx, ctx.Cache, name)
+			if !ok {
+				continue
+			}
+
+			if response.DataSources == nil {
+				response.DataSources = make(map[string]data.CircuitStatus, len(ctx.Config.Data.Backends))
+			}
+			response.DataSources[name] = status
+			if status.State != data.CircuitClosed {
+				response.Status = "degraded"
+			}
+		}
+	}
+
+	ctx.WriteJSON(http.StatusOK, response)
 }
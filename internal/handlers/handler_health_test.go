@@ -2,9 +2,13 @@
 package handlers
 
 import (
+	"encoding/json"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
 	"homelab-dashboard/internal/middlewares"
 	"homelab-dashboard/internal/testutil"
 	"testing"
+	"time"
 )
 
 func TestHandlerHealth(t *testing.T) {
@@ -17,6 +21,64 @@ func TestHandlerHealth(t *testing.T) {
 	tc.AssertJSONField(t, "status", "OK")
 }
 
+func TestHandlerHealthReportsCircuitBreaker(t *testing.T) {
+	tests := []struct {
+		name           string
+		state          data.CircuitState
+		expectedStatus string
+	}{
+		{name: "ClosedCircuitShouldReportOK", state: data.CircuitClosed, expectedStatus: "OK"},
+		{name: "OpenCircuitShouldReportDegraded", state: data.CircuitOpen, expectedStatus: "degraded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := testutil.NewTestContextWithURL(t, "GET", "/api/v1/health")
+			tc.WithConfig(&config.Config{Data: config.DataConfig{Backends: []config.DataPrometheusBackend{{Name: config.DefaultDataBackendName}}}})
+
+			encoded, _ := json.Marshal(data.CircuitStatus{Name: data.PrometheusCircuitName, State: tt.state, UpdatedAt: time.Now()})
+			tc.MockCache.EXPECT().GetKey(tc.AppContext, "circuit:prometheus").Return(string(encoded), nil)
+
+			tc.CallHandler(HandlerHealth)
+
+			tc.AssertStatus(t, 200)
+			tc.AssertJSONField(t, "status", tt.expectedStatus)
+
+			sources, ok := tc.GetJSONResponse(t)["data_sources"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("Expected data_sources in response")
+			}
+			prometheus, ok := sources["prometheus"].(map[string]interface{})
+			if !ok || prometheus["state"] != string(tt.state) {
+				t.Errorf("Expected prometheus state %q, got %v", tt.state, sources["prometheus"])
+			}
+		})
+	}
+}
+
+func TestHandlerHealthReportsEveryBackend(t *testing.T) {
+	tc := testutil.NewTestContextWithURL(t, "GET", "/api/v1/health")
+	tc.WithConfig(&config.Config{Data: config.DataConfig{Backends: []config.DataPrometheusBackend{
+		{Name: config.DefaultDataBackendName},
+		{Name: "tenant-b"},
+	}}})
+
+	closed, _ := json.Marshal(data.CircuitStatus{Name: data.PrometheusCircuitName, State: data.CircuitClosed, UpdatedAt: time.Now()})
+	open, _ := json.Marshal(data.CircuitStatus{Name: "prometheus:tenant-b", State: data.CircuitOpen, UpdatedAt: time.Now()})
+	tc.MockCache.EXPECT().GetKey(tc.AppContext, "circuit:prometheus").Return(string(closed), nil)
+	tc.MockCache.EXPECT().GetKey(tc.AppContext, "circuit:prometheus:tenant-b").Return(string(open), nil)
+
+	tc.CallHandler(HandlerHealth)
+
+	tc.AssertStatus(t, 200)
+	tc.AssertJSONField(t, "status", "degraded")
+
+	sources, ok := tc.GetJSONResponse(t)["data_sources"].(map[string]interface{})
+	if !ok || len(sources) != 2 {
+		t.Fatalf("Expected both backends in data_sources, got %v", sources)
+	}
+}
+
 func TestHandlerError(t *testing.T) {
 	tc := testutil.NewTestContextWithURL(t, "GET", "/error")
 
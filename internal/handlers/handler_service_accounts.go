@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"homelab-dashboard/internal/middlewares"
 	"homelab-dashboard/internal/models"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,17 +14,19 @@ import (
 )
 
 type ServiceAccount struct {
-	Sub          string     `json:"sub"`
-	Iss          string     `json:"iss"`
-	Name         string     `json:"name"`
-	Token        string     `json:"token,omitempty"`
-	ExpiresAt    time.Time  `json:"expires_at"`
-	IsDisabled   bool       `json:"is_disabled"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
-	Scopes       []string   `json:"scopes,omitempty"`
-	CreatedByIss string     `json:"created_by_iss"`
-	CreatedBySub string     `json:"created_by_sub"`
-	CreatedAt    time.Time  `json:"created_at"`
+	Sub        string     `json:"sub"`
+	Iss        string     `json:"iss"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	IsDisabled bool       `json:"is_disabled"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	// FirewallGrants are the aliases the account may manage entries in.
+	FirewallGrants []models.FirewallAliasGrant `json:"firewall_grants,omitempty"`
+	CreatedByIss   string                      `json:"created_by_iss"`
+	CreatedBySub   string                      `json:"created_by_sub"`
+	CreatedAt      time.Time                   `json:"created_at"`
 }
 
 func POSTServiceAccount(ctx *middlewares.AppContext) {
@@ -36,6 +40,9 @@ func POSTServiceAccount(ctx *middlewares.AppContext) {
 		Name           string   `json:"name"`
 		TokenExpiresAt string   `json:"token_expires_at"`
 		Scopes         []string `json:"scopes"`
+		// FirewallAliases are uuids of aliases the account may manage entries in, limited to aliases
+		// offered to one of the creator's groups.
+		FirewallAliases []string `json:"firewall_aliases"`
 	}
 
 	var req request
@@ -86,6 +93,11 @@ func POSTServiceAccount(ctx *middlewares.AppContext) {
 		return
 	}
 
+	firewallGrants, ok := buildFirewallGrants(ctx, user, req.FirewallAliases)
+	if !ok {
+		return
+	}
+
 	token, lookupId, hashedSecret, err := middlewares.GenerateAPIToken()
 	if err != nil {
 		ctx.Logger.Error("failed to generate API token", "error", err)
@@ -101,6 +113,7 @@ func POSTServiceAccount(ctx *middlewares.AppContext) {
 		SecretHash:     hashedSecret,
 		TokenExpiresAt: expiryTime,
 		Scopes:         req.Scopes,
+		FirewallGrants: firewallGrants,
 		CreatedByIss:   user.Iss,
 		CreatedBySub:   user.Sub,
 		CreatedAt:      time.Now(),
@@ -114,17 +127,18 @@ func POSTServiceAccount(ctx *middlewares.AppContext) {
 	}
 
 	response := ServiceAccount{
-		Sub:          sa.Sub,
-		Iss:          sa.Iss,
-		Name:         sa.Name,
-		Token:        token,
-		Scopes:       sa.Scopes,
-		ExpiresAt:    sa.TokenExpiresAt,
-		IsDisabled:   sa.IsDisabled,
-		DeletedAt:    sa.DeletedAt,
-		CreatedByIss: sa.CreatedByIss,
-		CreatedBySub: sa.CreatedBySub,
-		CreatedAt:    sa.CreatedAt,
+		Sub:            sa.Sub,
+		Iss:            sa.Iss,
+		Name:           sa.Name,
+		Token:          token,
+		Scopes:         sa.Scopes,
+		FirewallGrants: sa.FirewallGrants,
+		ExpiresAt:      sa.TokenExpiresAt,
+		IsDisabled:     sa.IsDisabled,
+		DeletedAt:      sa.DeletedAt,
+		CreatedByIss:   sa.CreatedByIss,
+		CreatedBySub:   sa.CreatedBySub,
+		CreatedAt:      sa.CreatedAt,
 	}
 
 	ctx.WriteJSON(http.StatusCreated, response)
@@ -143,16 +157,17 @@ func GETServiceAccounts(ctx *middlewares.AppContext) {
 	response := make([]ServiceAccount, 0, len(serviceAccounts))
 	for _, sa := range serviceAccounts {
 		response = append(response, ServiceAccount{
-			Sub:          sa.Sub,
-			Iss:          sa.Iss,
-			Name:         sa.Name,
-			Scopes:       sa.Scopes,
-			ExpiresAt:    sa.TokenExpiresAt,
-			IsDisabled:   sa.IsDisabled,
-			DeletedAt:    sa.DeletedAt,
-			CreatedByIss: sa.CreatedByIss,
-			CreatedBySub: sa.CreatedBySub,
-			CreatedAt:    sa.CreatedAt,
+			Sub:            sa.Sub,
+			Iss:            sa.Iss,
+			Name:           sa.Name,
+			Scopes:         sa.Scopes,
+			FirewallGrants: sa.FirewallGrants,
+			ExpiresAt:      sa.TokenExpiresAt,
+			IsDisabled:     sa.IsDisabled,
+			DeletedAt:      sa.DeletedAt,
+			CreatedByIss:   sa.CreatedByIss,
+			CreatedBySub:   sa.CreatedBySub,
+			CreatedAt:      sa.CreatedAt,
 		})
 	}
 
@@ -167,16 +182,17 @@ func GETServiceAccountWhoami(ctx *middlewares.AppContext) {
 	}
 
 	response := ServiceAccount{
-		Sub:          sa.Sub,
-		Iss:          sa.Iss,
-		Name:         sa.Name,
-		Scopes:       sa.Scopes,
-		IsDisabled:   sa.IsDisabled,
-		DeletedAt:    sa.DeletedAt,
-		ExpiresAt:    sa.TokenExpiresAt,
-		CreatedByIss: sa.CreatedByIss,
-		CreatedBySub: sa.CreatedBySub,
-		CreatedAt:    sa.CreatedAt,
+		Sub:            sa.Sub,
+		Iss:            sa.Iss,
+		Name:           sa.Name,
+		Scopes:         sa.Scopes,
+		FirewallGrants: sa.FirewallGrants,
+		IsDisabled:     sa.IsDisabled,
+		DeletedAt:      sa.DeletedAt,
+		ExpiresAt:      sa.TokenExpiresAt,
+		CreatedByIss:   sa.CreatedByIss,
+		CreatedBySub:   sa.CreatedBySub,
+		CreatedAt:      sa.CreatedAt,
 	}
 
 	ctx.WriteJSON(http.StatusOK, response)
@@ -321,7 +337,8 @@ func GETUserScopes(ctx *middlewares.AppContext) {
 
 	// Convert scopes to ScopeInfo objects with disabled status
 	scopeInfos := make([]ScopeInfo, 0, len(scopes))
-	firewallDisabledReason := "Firewall management doesn't currently support service accounts"
+	firewallDisabledReason := "Firewall management is not enabled"
+	firewallEnabled := ctx.Config.Features != nil && ctx.Config.Features.FirewallManagement.Enabled
 
 	for _, scope := range scopes {
 		scopeInfo := ScopeInfo{
@@ -329,8 +346,8 @@ func GETUserScopes(ctx *middlewares.AppContext) {
 			Disabled: false,
 		}
 
-		// Disable firewall scopes (all scopes starting with "firewall:")
-		if len(scope) >= 9 && scope[:9] == "firewall:" {
+		// Disable firewall scopes (all scopes starting with "firewall:") while the feature is off
+		if !firewallEnabled && strings.HasPrefix(scope, "firewall:") {
 			scopeInfo.Disabled = true
 			scopeInfo.Reason = &firewallDisabledReason
 		}
@@ -341,6 +358,49 @@ func GETUserScopes(ctx *middlewares.AppContext) {
 	ctx.WriteJSON(http.StatusOK, map[string][]ScopeInfo{"scopes": scopeInfos})
 }
 
+// buildFirewallGrants turns the requested alias uuids into grants for every group through which user has
+// the alias, writing an error response when user cannot access one of them.
+func buildFirewallGrants(ctx *middlewares.AppContext, user *models.User, aliasUUIDs []string) ([]models.FirewallAliasGrant, bool) {
+	grants := []models.FirewallAliasGrant{}
+	if len(aliasUUIDs) == 0 {
+		return grants, true
+	}
+
+	if ctx.Config.Features == nil || !ctx.Config.Features.FirewallManagement.Enabled {
+		ctx.SetJSONError(http.StatusBadRequest, "Firewall management is not enabled")
+		return nil, false
+	}
+
+	aliases, ok := loadFirewallAliases(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	for _, aliasUUID := range aliasUUIDs {
+		aliasUUID = strings.TrimSpace(aliasUUID)
+
+		granted := false
+		for _, alias := range aliases {
+			if !strings.EqualFold(alias.UUID, aliasUUID) || !principalHasAliasAccess(user, &alias) {
+				continue
+			}
+
+			grant := models.FirewallAliasGrant{AliasUUID: alias.UUID, AuthGroup: alias.AuthGroup}
+			if !slices.Contains(grants, grant) {
+				grants = append(grants, grant)
+			}
+			granted = true
+		}
+
+		if !granted {
+			ctx.SetJSONError(http.StatusForbidden, fmt.Sprintf("Cannot grant access to alias %s you don't have", aliasUUID))
+			return nil, false
+		}
+	}
+
+	return grants, true
+}
+
 func HasAllScopes(userScopes, requestedScopes []string) bool {
 	scopeSet := make(map[string]bool, len(userScopes))
 	for _, scope := range userScopes {
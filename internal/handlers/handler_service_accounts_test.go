@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// withFirewallAliases enables firewall management with a lan alias offered to users and a dmz alias offered to
+// admins, both readable by their group.
+func withFirewallAliases(tc *testutil.TestContext) []*models.FirewallManagedAlias {
+	tc.AppContext.Config.Storage = &config.StorageConfig{Enabled: true}
+	tc.AppContext.Config.Features.FirewallManagement.Enabled = true
+	tc.AppContext.Config.Authorization.GroupScopes = map[string][]string{
+		"users":  {authorization.ScopeFirewallReadOwn, authorization.ScopeFirewallRequestOwn},
+		"admins": {authorization.ScopeFirewallReadOwn, authorization.ScopeFirewallRequestOwn},
+	}
+
+	return []*models.FirewallManagedAlias{
+		{ID: 1, Name: "lan", UUID: "a", AuthGroup: "users"},
+		{ID: 2, Name: "dmz", UUID: "b", AuthGroup: "admins"},
+	}
+}
+
+func TestServiceAccountFirewallAccess(t *testing.T) {
+	tc := testutil.NewTestContextWithURL(t, "GET", "/api/firewall/aliases")
+	defer tc.Finish()
+	aliases := withFirewallAliases(tc)
+
+	rawToken, lookupID, secretHash, err := middlewares.GenerateAPIToken()
+	require.NoError(t, err)
+
+	serviceAccount := &models.ServiceAccount{
+		Iss:            "conduit",
+		Sub:            "sa",
+		Name:           "ci",
+		LookupId:       lookupID,
+		SecretHash:     secretHash,
+		TokenExpiresAt: time.Now().Add(time.Hour),
+		Scopes:         []string{authorization.ScopeFirewallReadOwn},
+		FirewallGrants: []models.FirewallAliasGrant{{AliasUUID: "a", AuthGroup: "users"}},
+	}
+
+	tc.MockStorageProvider.EXPECT().GetServiceAccountByLookupId(gomock.Any(), lookupID).Return(serviceAccount, nil)
+	tc.MockStorageProvider.EXPECT().GetManagedAliases(gomock.Any()).Return(aliases, nil)
+
+	r := chi.NewRouter()
+	r.Use(middlewares.AppContextMiddleware(tc.AppContext))
+	r.With(middlewares.RequireAuth).Get("/api/firewall/aliases", tc.AppContext.HandlerFunc(GETAvailableAliases))
+
+	tc.Request.Header.Set("Authorization", "Bearer "+rawToken)
+	r.ServeHTTP(tc.Response, tc.Request)
+
+	tc.AssertStatus(t, 200)
+	available := tc.GetJSONResponseArray(t)
+	require.Len(t, available, 1, "only the granted alias is offered")
+	require.Equal(t, "a", available[0].(map[string]interface{})["uuid"])
+}
+
+func TestBuildFirewallGrants(t *testing.T) {
+	user := &models.User{Iss: "iss", Sub: "sub", Username: "user", Groups: []string{"users"}}
+
+	t.Run("ShouldGrantAccessibleAlias", func(t *testing.T) {
+		tc := testutil.NewTestContextWithURL(t, "POST", "/api/service-accounts")
+		defer tc.Finish()
+		aliases := withFirewallAliases(tc)
+
+		tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return(aliases, nil)
+
+		grants, ok := buildFirewallGrants(tc.AppContext, user, []string{" A "})
+
+		require.True(t, ok)
+		require.Equal(t, []models.FirewallAliasGrant{{AliasUUID: "a", AuthGroup: "users"}}, grants)
+	})
+
+	t.Run("ShouldRejectAliasCreatorCannotAccess", func(t *testing.T) {
+		tc := testutil.NewTestContextWithURL(t, "POST", "/api/service-accounts")
+		defer tc.Finish()
+		aliases := withFirewallAliases(tc)
+
+		tc.MockStorageProvider.EXPECT().GetManagedAliases(tc.AppContext).Return(aliases, nil)
+
+		grants, ok := buildFirewallGrants(tc.AppContext, user, []string{"a", "b"})
+
+		require.False(t, ok)
+		require.Nil(t, grants)
+		tc.AssertStatus(t, 403)
+		tc.AssertJSONField(t, "error", "Cannot grant access to alias b you don't have")
+	})
+}
@@ -0,0 +1,535 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/services/statuspage"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GETStatusPage returns the public status page: the status of every configured service and component, open
+// incidents, maintenance under way or upcoming, and what was closed within history_days.
+func GETStatusPage(ctx *middlewares.AppContext) {
+	now := time.Now()
+
+	incidents, ok := getStatusIncidents(ctx, now)
+	if !ok {
+		return
+	}
+
+	builder, err := statuspage.NewBuilder(ctx.Config, ctx.Cache)
+	if err != nil {
+		ctx.Logger.Error("failed to build status page", "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to build status page")
+		return
+	}
+
+	ctx.WriteJSON(http.StatusOK, builder.Build(ctx, incidents, now))
+}
+
+// GETStatusIncident returns a single incident or maintenance window with its updates.
+func GETStatusIncident(ctx *middlewares.AppContext) {
+	incidentID, ok := parseStatusIncidentID(ctx)
+	if !ok {
+		return
+	}
+
+	incident, err := ctx.Storage.GetStatusIncident(ctx, incidentID)
+	if err != nil {
+		ctx.Logger.Error("failed to get status incident", "error", err, "incident_id", incidentID)
+		ctx.SetJSONError(http.StatusNotFound, "Incident not found")
+		return
+	}
+
+	ctx.WriteJSON(http.StatusOK, incident)
+}
+
+// GETStatusFeed publishes the incidents and maintenance windows of the status page as an Atom feed, one entry
+// per incident with its updates as content. Entries link to the incident on the web status page.
+func GETStatusFeed(ctx *middlewares.AppContext) {
+	now := time.Now()
+
+	incidents, ok := getStatusIncidents(ctx, now)
+	if !ok {
+		return
+	}
+
+	// The feed only changes when an incident does, so readers polling it do not see a change every time
+	var updated time.Time
+	for _, incident := range incidents {
+		if incident.UpdatedAt.After(updated) {
+			updated = incident.UpdatedAt
+		}
+	}
+	if updated.IsZero() {
+		updated = now
+	}
+
+	baseURL := externalBaseURL(ctx.Config.Server.ExternalURL)
+	feed := atomFeed{
+		ID:      baseURL + "/api/status/feed",
+		Title:   ctx.Config.Features.StatusPage.Title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: baseURL + "/api/status/feed", Rel: "self"},
+		Author:  atomAuthor{Name: ctx.Config.Features.StatusPage.Title},
+		Entries: make([]atomEntry, 0, len(incidents)),
+	}
+
+	for _, incident := range incidents {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:        fmt.Sprintf("%s/api/status/incidents/%d", baseURL, incident.ID),
+			Title:     fmt.Sprintf("%s (%s)", incident.Title, strings.ReplaceAll(string(incident.State), "_", " ")),
+			Updated:   incident.UpdatedAt.UTC().Format(time.RFC3339),
+			Published: incident.CreatedAt.UTC().Format(time.RFC3339),
+			Link:      atomLink{Href: fmt.Sprintf("%s/status/incidents/%d", baseURL, incident.ID), Rel: "alternate"},
+			Content:   atomContent{Type: "text", Body: statusIncidentSummary(incident)},
+		})
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		ctx.Logger.Error("failed to encode status feed", "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to build status feed")
+		return
+	}
+
+	ctx.Response.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	ctx.Response.WriteHeader(http.StatusOK)
+	_, _ = ctx.Response.Write([]byte(xml.Header))
+	_, _ = ctx.Response.Write(body)
+}
+
+// POSTStatusIncident opens an incident or schedules a maintenance window, with a first update (status:write).
+// The state defaults to investigating for incidents and scheduled for maintenance.
+func POSTStatusIncident(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeStatusWrite) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	var req struct {
+		Kind           models.StatusIncidentKind   `json:"kind"`
+		Title          string                      `json:"title"`
+		Impact         models.StatusIncidentImpact `json:"impact"`
+		State          models.StatusIncidentState  `json:"state"`
+		Components     []string                    `json:"components"`
+		ScheduledStart *time.Time                  `json:"scheduled_start"`
+		ScheduledEnd   *time.Time                  `json:"scheduled_end"`
+		Message        string                      `json:"message"`
+	}
+
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	incident := &models.StatusIncident{
+		Kind:           req.Kind,
+		Title:          strings.TrimSpace(req.Title),
+		Impact:         req.Impact,
+		State:          req.State,
+		Components:     req.Components,
+		ScheduledStart: req.ScheduledStart,
+		ScheduledEnd:   req.ScheduledEnd,
+		CreatedByIss:   principal.GetIss(),
+		CreatedBySub:   principal.GetSub(),
+	}
+
+	if incident.Kind == "" {
+		incident.Kind = models.StatusIncidentKindIncident
+	}
+	if incident.Impact == "" {
+		incident.Impact = models.StatusImpactNone
+	}
+	if incident.State == "" && len(incident.Kind.ValidStates()) > 0 {
+		incident.State = incident.Kind.ValidStates()[0]
+	}
+
+	if msg := validateStatusIncident(ctx, incident); msg != "" {
+		ctx.SetJSONError(http.StatusBadRequest, msg)
+		return
+	}
+
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		ctx.SetJSONError(http.StatusBadRequest, "message is required")
+		return
+	}
+
+	created, err := ctx.Storage.CreateStatusIncident(ctx, incident, message)
+	if err != nil {
+		ctx.Logger.Error("failed to create status incident",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"kind", incident.Kind,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to create incident")
+		return
+	}
+
+	ctx.Logger.Info("status incident created",
+		"admin", principal.GetUsername(),
+		"incident_id", created.ID,
+		"kind", created.Kind,
+		"impact", created.Impact,
+		"components", created.Components,
+	)
+
+	ctx.WriteJSON(http.StatusCreated, created)
+}
+
+// PATCHStatusIncident changes the title, impact, components or schedule of an incident (status:write). The
+// state changes by posting an update.
+func PATCHStatusIncident(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeStatusWrite) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	incidentID, ok := parseStatusIncidentID(ctx)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Title          *string                      `json:"title"`
+		Impact         *models.StatusIncidentImpact `json:"impact"`
+		Components     []string                     `json:"components"`
+		ScheduledStart *time.Time                   `json:"scheduled_start"`
+		ScheduledEnd   *time.Time                   `json:"scheduled_end"`
+	}
+
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	incident, err := ctx.Storage.GetStatusIncident(ctx, incidentID)
+	if err != nil {
+		ctx.Logger.Error("failed to get status incident", "error", err, "incident_id", incidentID)
+		ctx.SetJSONError(http.StatusNotFound, "Incident not found")
+		return
+	}
+
+	if req.Title != nil {
+		incident.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Impact != nil {
+		incident.Impact = *req.Impact
+	}
+	if req.Components != nil {
+		incident.Components = req.Components
+	}
+	if req.ScheduledStart != nil {
+		incident.ScheduledStart = req.ScheduledStart
+	}
+	if req.ScheduledEnd != nil {
+		incident.ScheduledEnd = req.ScheduledEnd
+	}
+
+	if msg := validateStatusIncident(ctx, incident); msg != "" {
+		ctx.SetJSONError(http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := ctx.Storage.UpdateStatusIncident(ctx, incident); err != nil {
+		ctx.Logger.Error("failed to update status incident",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"incident_id", incidentID,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to update incident")
+		return
+	}
+
+	updated, err := ctx.Storage.GetStatusIncident(ctx, incidentID)
+	if err != nil {
+		ctx.Logger.Error("failed to reload status incident", "error", err, "incident_id", incidentID)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get incident")
+		return
+	}
+
+	ctx.Logger.Info("status incident updated",
+		"admin", principal.GetUsername(),
+		"incident_id", incidentID,
+		"impact", updated.Impact,
+		"components", updated.Components,
+	)
+
+	ctx.WriteJSON(http.StatusOK, updated)
+}
+
+// POSTStatusIncidentUpdate posts an update to an incident and moves it to the update's state, which defaults
+// to the current one (status:write). Resolving an incident or completing maintenance closes it; posting an
+// open state reopens it.
+func POSTStatusIncidentUpdate(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeStatusWrite) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	incidentID, ok := parseStatusIncidentID(ctx)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		State   models.StatusIncidentState `json:"state"`
+		Message string                     `json:"message"`
+	}
+
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	incident, err := ctx.Storage.GetStatusIncident(ctx, incidentID)
+	if err != nil {
+		ctx.Logger.Error("failed to get status incident", "error", err, "incident_id", incidentID)
+		ctx.SetJSONError(http.StatusNotFound, "Incident not found")
+		return
+	}
+
+	update := &models.StatusIncidentUpdate{
+		State:        req.State,
+		Message:      strings.TrimSpace(req.Message),
+		CreatedByIss: principal.GetIss(),
+		CreatedBySub: principal.GetSub(),
+	}
+
+	if update.State == "" {
+		update.State = incident.State
+	}
+	if !slices.Contains(incident.Kind.ValidStates(), update.State) {
+		ctx.SetJSONError(http.StatusBadRequest, fmt.Sprintf("state must be one of %s", joinStates(incident.Kind.ValidStates())))
+		return
+	}
+	if update.Message == "" {
+		ctx.SetJSONError(http.StatusBadRequest, "message is required")
+		return
+	}
+
+	updated, err := ctx.Storage.AddStatusIncidentUpdate(ctx, incidentID, update)
+	if err != nil {
+		ctx.Logger.Error("failed to add status incident update",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"incident_id", incidentID,
+		)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to add incident update")
+		return
+	}
+
+	ctx.Logger.Info("status incident update posted",
+		"admin", principal.GetUsername(),
+		"incident_id", incidentID,
+		"state", update.State,
+	)
+
+	ctx.WriteJSON(http.StatusCreated, updated)
+}
+
+// DELETEStatusIncident removes an incident and its updates, e.g. one posted by mistake (status:write).
+func DELETEStatusIncident(ctx *middlewares.AppContext) {
+	principal := ctx.GetPrincipal()
+	if principal == nil {
+		ctx.SetJSONError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	if !principal.HasScope(ctx.Config, authorization.ScopeStatusWrite) {
+		ctx.SetJSONError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	incidentID, ok := parseStatusIncidentID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ctx.Storage.DeleteStatusIncident(ctx, incidentID); err != nil {
+		ctx.Logger.Error("failed to delete status incident",
+			"error", err,
+			"admin", principal.GetUsername(),
+			"incident_id", incidentID,
+		)
+		ctx.SetJSONError(http.StatusNotFound, "Incident not found")
+		return
+	}
+
+	ctx.Logger.Info("status incident deleted",
+		"admin", principal.GetUsername(),
+		"incident_id", incidentID,
+	)
+
+	ctx.Response.WriteHeader(http.StatusNoContent)
+}
+
+// getStatusIncidents loads the open incidents and those closed within history_days, writing a 500 response
+// when storage fails.
+func getStatusIncidents(ctx *middlewares.AppContext, now time.Time) ([]*models.StatusIncident, bool) {
+	since := now.AddDate(0, 0, -ctx.Config.Features.StatusPage.HistoryDays)
+
+	incidents, err := ctx.Storage.GetStatusIncidents(ctx, since)
+	if err != nil {
+		ctx.Logger.Error("failed to get status incidents", "error", err)
+		ctx.SetJSONError(http.StatusInternalServerError, "Failed to get incidents")
+		return nil, false
+	}
+
+	return incidents, true
+}
+
+// validateStatusIncident checks an incident against the configured status page, returning what is wrong
+// with it or an empty string.
+func validateStatusIncident(ctx *middlewares.AppContext, incident *models.StatusIncident) string {
+	if len(incident.Kind.ValidStates()) == 0 {
+		return "kind must be incident or maintenance"
+	}
+	if incident.Title == "" {
+		return "title is required"
+	}
+	if !slices.Contains(models.StatusIncidentImpacts, incident.Impact) {
+		return "impact must be one of none, minor, major or critical"
+	}
+	if !slices.Contains(incident.Kind.ValidStates(), incident.State) {
+		return fmt.Sprintf("state must be one of %s", joinStates(incident.Kind.ValidStates()))
+	}
+
+	if len(incident.Components) == 0 {
+		return "components requires at least one component"
+	}
+	for _, name := range incident.Components {
+		if !hasStatusPageComponent(ctx, name) {
+			return fmt.Sprintf("unknown component %q", name)
+		}
+	}
+
+	switch incident.Kind {
+	case models.StatusIncidentKindMaintenance:
+		if incident.ScheduledStart == nil || incident.ScheduledEnd == nil {
+			return "maintenance requires scheduled_start and scheduled_end"
+		}
+		if !incident.ScheduledEnd.After(*incident.ScheduledStart) {
+			return "scheduled_end must be after scheduled_start"
+		}
+	default:
+		if incident.ScheduledStart != nil || incident.ScheduledEnd != nil {
+			return "only maintenance can be scheduled"
+		}
+	}
+
+	return ""
+}
+
+func hasStatusPageComponent(ctx *middlewares.AppContext, name string) bool {
+	for _, service := range ctx.Config.Features.StatusPage.Services {
+		for _, component := range service.Components {
+			if component.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinStates(states []models.StatusIncidentState) string {
+	names := make([]string, len(states))
+	for i, state := range states {
+		names[i] = string(state)
+	}
+	return strings.Join(names, ", ")
+}
+
+// statusIncidentSummary writes the updates of an incident as plain text, newest first.
+func statusIncidentSummary(incident *models.StatusIncident) string {
+	var b strings.Builder
+	for i, update := range incident.Updates {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "%s - %s: %s", update.CreatedAt.UTC().Format(time.RFC1123), update.State, update.Message)
+	}
+	return b.String()
+}
+
+// externalBaseURL turns the configured external URL into an absolute URL without a trailing slash.
+func externalBaseURL(externalURL string) string {
+	base := strings.TrimSuffix(externalURL, "/")
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	return base
+}
+
+// parseStatusIncidentID reads the {id} path parameter, writing a 400 response when it is invalid.
+func parseStatusIncidentID(ctx *middlewares.AppContext) (int, bool) {
+	incidentID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(ctx.Request, "id")))
+	if err != nil {
+		ctx.SetJSONError(http.StatusBadRequest, "Invalid incident ID")
+		return 0, false
+	}
+
+	return incidentID, true
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Link      atomLink    `xml:"link"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/testutil"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+// withStatusPage configures a status page with a media service observed through a probe and a storage
+// service without one.
+func withStatusPage(tc *testutil.TestContext) {
+	tc.AppContext.Config.Server.ExternalURL = "status.example.com"
+	tc.AppContext.Config.Authorization.GroupScopes = map[string][]string{
+		"admin": {authorization.ScopeStatusWrite},
+	}
+	tc.AppContext.Config.Data.Probes = []config.DataProbe{{Name: "jellyfin", Type: config.DataProbeHTTP, Target: "http://jellyfin"}}
+	tc.AppContext.Config.Features.StatusPage = config.StatusPage{
+		Enabled:     true,
+		Title:       "Homelab",
+		HistoryDays: 14,
+		Services: []config.StatusPageService{
+			{Name: "Media", Components: []config.StatusPageComponent{{Name: "jellyfin", Probe: "jellyfin"}}},
+			{Name: "Storage", Components: []config.StatusPageComponent{{Name: "nas"}}},
+		},
+	}
+}
+
+func TestGETStatusPage(t *testing.T) {
+	tc := testutil.NewTestContextWithURL(t, "GET", "/api/status")
+	defer tc.Finish()
+	withStatusPage(tc)
+
+	incidents := []*models.StatusIncident{
+		{ID: 1, Kind: models.StatusIncidentKindIncident, Title: "NAS degraded", Impact: models.StatusImpactMajor, State: models.StatusIncidentIdentified, Components: []string{"nas"}},
+	}
+
+	tc.MockStorageProvider.EXPECT().GetStatusIncidents(tc.AppContext, gomock.Any()).Return(incidents, nil)
+	tc.MockCache.EXPECT().Get(tc.AppContext, "jellyfin").Return(data.CachedData{}, false)
+
+	tc.CallHandler(GETStatusPage)
+
+	tc.AssertStatus(t, 200)
+	tc.AssertJSONField(t, "title", "Homelab")
+	tc.AssertJSONField(t, "status", "partial_outage")
+
+	page := tc.GetJSONResponse(t)
+	services := page["services"].([]interface{})
+	if status := services[0].(map[string]interface{})["status"]; status != "unknown" {
+		t.Errorf("Expected a probe without a result to be unknown, got %v", status)
+	}
+	if open := page["incidents"].([]interface{}); len(open) != 1 {
+		t.Errorf("Expected 1 open incident, got %v", open)
+	}
+}
+
+func TestPOSTStatusIncident(t *testing.T) {
+	admin := &models.User{Iss: "iss", Sub: "sub", Username: "admin", Groups: []string{"admin"}}
+
+	tests := []struct {
+		name           string
+		user           *models.User
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "ShouldRequireAuthentication",
+			body:           `{}`,
+			expectedStatus: 401,
+		},
+		{
+			name:           "ShouldRequireStatusWriteScope",
+			user:           &models.User{Iss: "iss", Sub: "other", Groups: []string{"users"}},
+			body:           `{"title":"NAS down","components":["nas"],"message":"Looking into it"}`,
+			expectedStatus: 403,
+		},
+		{
+			name:           "ShouldRejectUnknownComponent",
+			user:           admin,
+			body:           `{"title":"Plex down","components":["plex"],"message":"Looking into it"}`,
+			expectedStatus: 400,
+			expectedError:  `unknown component "plex"`,
+		},
+		{
+			name:           "ShouldRejectStateOfOtherKind",
+			user:           admin,
+			body:           `{"title":"NAS down","state":"scheduled","components":["nas"],"message":"Looking into it"}`,
+			expectedStatus: 400,
+			expectedError:  "state must be one of investigating, identified, monitoring, resolved",
+		},
+		{
+			name:           "ShouldRequireMaintenanceSchedule",
+			user:           admin,
+			body:           `{"kind":"maintenance","title":"Disk swap","components":["nas"],"message":"Replacing a disk"}`,
+			expectedStatus: 400,
+			expectedError:  "maintenance requires scheduled_start and scheduled_end",
+		},
+		{
+			name:           "ShouldRequireMessage",
+			user:           admin,
+			body:           `{"title":"NAS down","components":["nas"]}`,
+			expectedStatus: 400,
+			expectedError:  "message is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := testutil.NewTestContextWithURL(t, "POST", "/api/status/incidents")
+			defer tc.Finish()
+			withStatusPage(tc)
+			tc.Request.Body = io.NopCloser(strings.NewReader(tt.body))
+
+			if tt.user != nil {
+				tc.AppContext.SetPrincipal(tt.user)
+			}
+
+			tc.CallHandler(POSTStatusIncident)
+
+			tc.AssertStatus(t, tt.expectedStatus)
+			if tt.expectedError != "" {
+				tc.AssertJSONField(t, "error", tt.expectedError)
+			}
+		})
+	}
+
+	t.Run("ShouldCreateMaintenanceWithFirstUpdate", func(t *testing.T) {
+		tc := testutil.NewTestContextWithURL(t, "POST", "/api/status/incidents")
+		defer tc.Finish()
+		withStatusPage(tc)
+		tc.Request.Body = io.NopCloser(strings.NewReader(`{
+			"kind": "maintenance",
+			"title": "Disk swap",
+			"components": ["nas"],
+			"scheduled_start": "2026-03-10T20:00:00Z",
+			"scheduled_end": "2026-03-10T22:00:00Z",
+			"message": "Replacing a disk"
+		}`))
+		tc.AppContext.SetPrincipal(admin)
+
+		tc.MockStorageProvider.EXPECT().CreateStatusIncident(tc.AppContext, gomock.Any(), "Replacing a disk").
+			DoAndReturn(func(_ any, incident *models.StatusIncident, _ string) (*models.StatusIncident, error) {
+				if incident.State != models.StatusMaintenanceScheduled || incident.Impact != models.StatusImpactNone {
+					t.Errorf("Expected scheduled maintenance without impact, got %s/%s", incident.State, incident.Impact)
+				}
+				if incident.CreatedByIss != "iss" || incident.CreatedBySub != "sub" {
+					t.Errorf("Expected the incident to be attributed to the admin, got %s/%s", incident.CreatedByIss, incident.CreatedBySub)
+				}
+				incident.ID = 7
+				return incident, nil
+			})
+
+		tc.CallHandler(POSTStatusIncident)
+
+		tc.AssertStatus(t, 201)
+		tc.AssertJSONField(t, "id", float64(7))
+		tc.AssertJSONField(t, "state", "scheduled")
+	})
+}
+
+func TestPOSTStatusIncidentUpdate(t *testing.T) {
+	admin := &models.User{Iss: "iss", Sub: "sub", Username: "admin", Groups: []string{"admin"}}
+	incident := &models.StatusIncident{ID: 3, Kind: models.StatusIncidentKindIncident, Title: "NAS down", State: models.StatusIncidentInvestigating, Components: []string{"nas"}}
+
+	t.Run("ShouldRejectInvalidState", func(t *testing.T) {
+		tc := testutil.NewTestContextWithURL(t, "POST", "/api/status/incidents/3/updates")
+		defer tc.Finish()
+		withStatusPage(tc)
+		tc.WithURLParam("id", "3")
+		tc.Request.Body = io.NopCloser(strings.NewReader(`{"state":"completed","message":"Done"}`))
+		tc.AppContext.SetPrincipal(admin)
+
+		tc.MockStorageProvider.EXPECT().GetStatusIncident(tc.AppContext, 3).Return(incident, nil)
+
+		tc.CallHandler(POSTStatusIncidentUpdate)
+
+		tc.AssertStatus(t, 400)
+	})
+
+	t.Run("ShouldResolveIncident", func(t *testing.T) {
+		tc := testutil.NewTestContextWithURL(t, "POST", "/api/status/incidents/3/updates")
+		defer tc.Finish()
+		withStatusPage(tc)
+		tc.WithURLParam("id", "3")
+		tc.Request.Body = io.NopCloser(strings.NewReader(`{"state":"resolved","message":"Disk replaced"}`))
+		tc.AppContext.SetPrincipal(admin)
+
+		resolved := *incident
+		resolved.State = models.StatusIncidentResolved
+
+		tc.MockStorageProvider.EXPECT().GetStatusIncident(tc.AppContext, 3).Return(incident, nil)
+		tc.MockStorageProvider.EXPECT().AddStatusIncidentUpdate(tc.AppContext, 3, gomock.Any()).
+			DoAndReturn(func(_ any, _ int, update *models.StatusIncidentUpdate) (*models.StatusIncident, error) {
+				if update.State != models.StatusIncidentResolved || update.Message != "Disk replaced" {
+					t.Errorf("Unexpected update %+v", update)
+				}
+				return &resolved, nil
+			})
+
+		tc.CallHandler(POSTStatusIncidentUpdate)
+
+		tc.AssertStatus(t, 201)
+		tc.AssertJSONField(t, "state", "resolved")
+	})
+}
+
+func TestGETStatusFeed(t *testing.T) {
+	tc := testutil.NewTestContextWithURL(t, "GET", "/api/status/feed")
+	defer tc.Finish()
+	withStatusPage(tc)
+
+	createdAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	incidents := []*models.StatusIncident{
+		{
+			ID: 3, Kind: models.StatusIncidentKindIncident, Title: "NAS down", State: models.StatusIncidentResolved, Components: []string{"nas"},
+			CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour),
+			Updates: []models.StatusIncidentUpdate{
+				{State: models.StatusIncidentResolved, Message: "Disk replaced", CreatedAt: createdAt.Add(time.Hour)},
+				{State: models.StatusIncidentInvestigating, Message: "Looking into it", CreatedAt: createdAt},
+			},
+		},
+		{
+			ID: 2, Kind: models.StatusIncidentKindMaintenance, Title: "Router upgrade", State: models.StatusMaintenanceCompleted,
+			CreatedAt: createdAt.Add(-48 * time.Hour), UpdatedAt: createdAt.Add(-24 * time.Hour),
+		},
+	}
+
+	tc.MockStorageProvider.EXPECT().GetStatusIncidents(tc.AppContext, gomock.Any()).Return(incidents, nil)
+
+	tc.CallHandler(GETStatusFeed)
+
+	tc.AssertStatus(t, 200)
+	tc.AssertContentType(t, "application/atom+xml; charset=utf-8")
+
+	body := tc.Response.Body.String()
+	for _, expected := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		"<id>https://status.example.com/api/status/incidents/3</id>",
+		`<link href="https://status.example.com/status/incidents/3" rel="alternate"></link>`,
+		"<title>Homelab</title>\n  <updated>2026-03-10T13:00:00Z</updated>", // the latest incident, not the time of the request
+		"<title>NAS down (resolved)</title>",
+		"<updated>2026-03-09T12:00:00Z</updated>",
+		"resolved: Disk replaced",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected feed to contain %q, got %s", expected, body)
+		}
+	}
+}
@@ -6,10 +6,14 @@ import (
 
 // ResultData represents
 type ResultData struct {
-	QueryName     string          `json:"query_name"`
-	Type          string          `json:"type"`
-	Data          json.RawMessage `json:"data,omitempty"`
-	Timestamp     int64           `json:"timestamp"`
-	RequireAuth   bool            `json:"-"`
-	RequiredGroup string          `json:"-"`
+	QueryName     string            `json:"query_name"`
+	Type          string            `json:"type"`
+	Data          json.RawMessage   `json:"data,omitempty"`
+	Variables     map[string]string `json:"variables,omitempty"`
+	Warnings      []string          `json:"warnings,omitempty"`
+	Timestamp     int64             `json:"timestamp"`
+	Stale         bool              `json:"stale"`
+	AgeSeconds    float64           `json:"age_seconds"`
+	RequireAuth   bool              `json:"-"`
+	RequiredGroup string            `json:"-"`
 }
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/services/alerting"
+	"log/slog"
+	"time"
+)
+
+// DataAlertJob evaluates alert rules on the leader, as soon as a query's cached result changes and on every
+// interval in between, so rules with a for duration fire even when the value stays the same.
+type DataAlertJob struct {
+	alerter  *alerting.Alerter
+	cache    data.Provider
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewDataAlertJob(alerter *alerting.Alerter, cache data.Provider, interval time.Duration, logger *slog.Logger) *DataAlertJob {
+	return &DataAlertJob{
+		alerter:  alerter,
+		cache:    cache,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (j *DataAlertJob) Name() string {
+	return "data_alerts"
+}
+
+func (j *DataAlertJob) RequiresLeadership() bool {
+	return true
+}
+
+func (j *DataAlertJob) Interval() time.Duration {
+	return j.interval
+}
+
+func (j *DataAlertJob) Run(ctx context.Context) error {
+	updates := j.cache.SubscribeUpdates(ctx)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	if err := j.alerter.EvaluateAll(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		j.logger.Error("initial alert evaluation failed", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case key, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			if err := j.alerter.Evaluate(ctx, key); err != nil && !errors.Is(err, context.Canceled) {
+				j.logger.Error("alert evaluation failed", "query", key, "error", err)
+			}
+		case <-ticker.C:
+			if err := j.alerter.EvaluateAll(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				j.logger.Error("alert evaluation failed", "error", err)
+			}
+		}
+	}
+}
@@ -2,25 +2,22 @@ package jobs
 
 import (
 	"context"
-	"fmt"
 	"homelab-dashboard/internal/data"
-	"homelab-dashboard/internal/middlewares"
 	"log/slog"
 	"time"
 )
 
+// DataFetchJob keeps the dashboard queries fresh on the leader. Each query is refreshed on its own TTL by the scheduler.
 type DataFetchJob struct {
-	dataService *data.Service
-	appCtx      *middlewares.AppContext
-	interval    time.Duration
+	scheduler   *data.Scheduler
+	fallbackTTL time.Duration
 	logger      *slog.Logger
 }
 
-func NewDataFetchJob(dataService *data.Service, appCtx *middlewares.AppContext, interval time.Duration, logger *slog.Logger) *DataFetchJob {
+func NewDataFetchJob(scheduler *data.Scheduler, fallbackTTL time.Duration, logger *slog.Logger) *DataFetchJob {
 	return &DataFetchJob{
-		dataService: dataService,
-		appCtx:      appCtx,
-		interval:    interval,
+		scheduler:   scheduler,
+		fallbackTTL: fallbackTTL,
 		logger:      logger,
 	}
 }
@@ -33,34 +30,20 @@ func (j *DataFetchJob) RequiresLeadership() bool {
 	return true
 }
 
+// Interval is the refresh interval of queries without a TTL; the rest follow their own TTL.
 func (j *DataFetchJob) Interval() time.Duration {
-	return j.interval
+	return j.fallbackTTL
 }
 
 func (j *DataFetchJob) Run(ctx context.Context) error {
-	if j.interval <= 0 {
-		j.logger.Error("initial query execution failed: ticker interval must not be zero")
-		return fmt.Errorf("non-positive ticker interval: %s", j.interval)
-	}
-
-	ticker := time.NewTicker(j.interval)
-	defer ticker.Stop()
+	j.logger.Debug("Starting background data fetching")
 
-	j.logger.Debug("Starting background data fetching", "interval", j.interval)
-
-	if err := j.dataService.ExecuteQueries(ctx, j.appCtx.Cache); err != nil {
-		j.logger.Error("initial data fetch failed", "error", err)
+	err := j.scheduler.Run(ctx)
+	if ctx.Err() != nil {
+		j.logger.Debug("Background data fetching canceled")
+		return ctx.Err()
 	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			j.logger.Debug("Background data fetching canceled")
-			return ctx.Err()
-		case <-ticker.C:
-			if err := j.dataService.ExecuteQueries(ctx, j.appCtx.Cache); err != nil {
-				j.logger.Error(fmt.Sprintf("Background data fetch failed, trying again in %s", j.interval.String()), "error", err)
-			}
-		}
-	}
+	j.logger.Error("background data fetching stopped", "error", err)
+	return err
 }
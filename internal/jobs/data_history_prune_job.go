@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// DataHistoryPruneJob deletes the rollups of recorded query and probe history that are past their retention.
+// History of queries and probes that were removed from the config is left alone.
+type DataHistoryPruneJob struct {
+	appCtx   *middlewares.AppContext
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewDataHistoryPruneJob(appCtx *middlewares.AppContext, interval time.Duration, logger *slog.Logger) *DataHistoryPruneJob {
+	return &DataHistoryPruneJob{
+		appCtx:   appCtx,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (j *DataHistoryPruneJob) Name() string {
+	return "data_history_prune"
+}
+
+func (j *DataHistoryPruneJob) RequiresLeadership() bool {
+	return true
+}
+
+func (j *DataHistoryPruneJob) Interval() time.Duration {
+	return j.interval
+}
+
+func (j *DataHistoryPruneJob) Run(ctx context.Context) error {
+	if j.interval <= 0 {
+		return fmt.Errorf("data history prune job interval must be positive")
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	if err := j.prune(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		j.logger.Error("initial data history prune failed", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := j.prune(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				j.logger.Error("data history prune failed", "error", err)
+			}
+		}
+	}
+}
+
+func (j *DataHistoryPruneJob) prune(ctx context.Context) error {
+	now := time.Now()
+
+	for _, query := range slices.Concat(j.appCtx.Config.Data.Queries, data.ProbeQueries(j.appCtx.Config.Data.Probes)) {
+		if !data.RecordsHistory(query) {
+			continue
+		}
+
+		for _, resolution := range models.DataHistoryResolutions {
+			retention := data.HistoryRetention(query, resolution)
+			if retention <= 0 {
+				continue
+			}
+
+			count, err := j.appCtx.Storage.DeleteDataHistoryBefore(ctx, query.Name, resolution, now.Add(-retention))
+			if err != nil {
+				return fmt.Errorf("failed to prune history of query %s: %w", query.Name, err)
+			}
+
+			if count > 0 {
+				j.logger.Info("pruned data history", "query", query.Name, "resolution", resolution, "count", count)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DataProbeJob runs the synthetic uptime probes on the leader, each on its own interval. Results are cached
+// through the data service, so they are served by /api/data and recorded into history like query results.
+type DataProbeJob struct {
+	service *data.Service
+	probes  []config.PrometheusQuery
+	logger  *slog.Logger
+}
+
+func NewDataProbeJob(service *data.Service, probes []config.DataProbe, logger *slog.Logger) *DataProbeJob {
+	return &DataProbeJob{
+		service: service,
+		probes:  data.ProbeQueries(probes),
+		logger:  logger,
+	}
+}
+
+func (j *DataProbeJob) Name() string {
+	return "data_probes"
+}
+
+func (j *DataProbeJob) RequiresLeadership() bool {
+	return true
+}
+
+// Interval is the shortest probe interval; every probe follows its own.
+func (j *DataProbeJob) Interval() time.Duration {
+	if len(j.probes) == 0 {
+		return config.DefaultDataProbe.Interval
+	}
+	return slices.MinFunc(j.probes, func(a, b config.PrometheusQuery) int { return int(a.TTL - b.TTL) }).TTL
+}
+
+func (j *DataProbeJob) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, probe := range j.probes {
+		wg.Go(func() {
+			j.runProbe(ctx, probe)
+		})
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// runProbe runs a probe right away and then on every interval until ctx is done.
+func (j *DataProbeJob) runProbe(ctx context.Context, probe config.PrometheusQuery) {
+	ticker := time.NewTicker(probe.TTL)
+	defer ticker.Stop()
+
+	for {
+		if err := j.service.ExecuteQuery(ctx, nil, probe); err != nil && ctx.Err() == nil {
+			j.logger.Error("probe failed", "probe", probe.Name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
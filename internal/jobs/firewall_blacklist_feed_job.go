@@ -0,0 +1,145 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/services/firewall"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxBlacklistFeedSize caps how much of a feed is read; Spamhaus DROP is well under 100KB.
+const maxBlacklistFeedSize = 10 << 20
+
+// FirewallBlacklistFeedJob periodically imports a threat feed into the blacklist, replacing the rules
+// from its previous import.
+type FirewallBlacklistFeedJob struct {
+	appCtx     *middlewares.AppContext
+	feed       config.FirewallBlacklistFeed
+	httpClient *http.Client
+}
+
+func NewFirewallBlacklistFeedJob(appCtx *middlewares.AppContext, feed config.FirewallBlacklistFeed) *FirewallBlacklistFeedJob {
+	return &FirewallBlacklistFeedJob{
+		appCtx: appCtx,
+		feed:   feed,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+func (j *FirewallBlacklistFeedJob) Name() string {
+	return "firewall_blacklist_feed:" + j.feed.Name
+}
+
+func (j *FirewallBlacklistFeedJob) RequiresLeadership() bool {
+	return true // Only leader should replace feed rules
+}
+
+func (j *FirewallBlacklistFeedJob) Interval() time.Duration {
+	return j.feed.Interval
+}
+
+func (j *FirewallBlacklistFeedJob) Run(ctx context.Context) error {
+	if j.feed.Interval <= 0 {
+		return fmt.Errorf("blacklist feed job interval must be positive")
+	}
+
+	ticker := time.NewTicker(j.feed.Interval)
+	defer ticker.Stop()
+
+	if err := j.importFeed(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		j.appCtx.Logger.Error("initial blacklist feed import failed", "feed", j.feed.Name, "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := j.importFeed(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				j.appCtx.Logger.Error("blacklist feed import failed", "feed", j.feed.Name, "error", err)
+			}
+		}
+	}
+}
+
+func (j *FirewallBlacklistFeedJob) importFeed(ctx context.Context) error {
+	body, err := j.openFeed(ctx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	rules, skipped, err := firewall.ParseThreatFeed(io.LimitReader(body, maxBlacklistFeedSize))
+	if err != nil {
+		return err
+	}
+
+	// An empty result almost always means an error page or a truncated download; keep the previous import.
+	if len(rules) == 0 {
+		return fmt.Errorf("feed contained no valid entries (%d lines skipped)", skipped)
+	}
+
+	systemUserIss, systemUserSub, err := j.appCtx.Storage.GetSystemUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get system user: %w", err)
+	}
+
+	var aliasUUID *string
+	if j.feed.AliasUUID != "" {
+		aliasUUID = &j.feed.AliasUUID
+	}
+
+	count, err := j.appCtx.Storage.ReplaceFeedBlacklistRules(ctx, j.feed.Name, aliasUUID, rules, systemUserIss, systemUserSub)
+	if err != nil {
+		return fmt.Errorf("failed to store feed rules: %w", err)
+	}
+
+	j.appCtx.Logger.Info("imported blacklist feed",
+		"feed", j.feed.Name,
+		"rules", count,
+		"skipped_lines", skipped,
+	)
+
+	return nil
+}
+
+func (j *FirewallBlacklistFeedJob) openFeed(ctx context.Context) (io.ReadCloser, error) {
+	if j.feed.File != "" {
+		file, err := os.Open(j.feed.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open feed file: %w", err)
+		}
+		return file, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.feed.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); strings.HasPrefix(contentType, "text/html") {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected content type %q", contentType)
+	}
+
+	return resp.Body, nil
+}
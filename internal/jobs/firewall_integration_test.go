@@ -0,0 +1,446 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/authorization"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/handlers"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/services/firewall"
+	"homelab-dashboard/internal/services/firewall/opnsensesim"
+	"homelab-dashboard/internal/storage"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	integrationAliasUUID = "c0daef37-718c-40e4-bb2b-ba5aab418d0d"
+	integrationAliasName = "Database"
+)
+
+// memoryFirewallStore keeps whitelist entries in memory and implements the storage methods the firewall
+// jobs and the handlers driven by these tests use. Any other storage call panics through the nil embedded
+// Provider.
+type memoryFirewallStore struct {
+	storage.Provider
+
+	mu      sync.Mutex
+	nextID  int
+	entries map[int]*models.FirewallIPWhitelistEntry
+	events  []models.FirewallIPWhitelistEvent
+	plans   []*models.FirewallSyncPlan
+	synced  []string       // aliases a sync was requested for
+	blocked []netip.Prefix // ranges covered by blacklist rules
+}
+
+func newMemoryFirewallStore() *memoryFirewallStore {
+	return &memoryFirewallStore{nextID: 1, entries: make(map[int]*models.FirewallIPWhitelistEntry)}
+}
+
+func (s *memoryFirewallStore) addEntry(ip string, status models.FirewallIPWhitelistStatus, expiresAt *time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.entries[id] = &models.FirewallIPWhitelistEntry{
+		ID:          id,
+		AliasName:   integrationAliasName,
+		AliasUUID:   integrationAliasUUID,
+		IPAddress:   ip + "/32",
+		Status:      status,
+		RequestedAt: time.Now(),
+		ExpiresAt:   expiresAt,
+	}
+	return id
+}
+
+func (s *memoryFirewallStore) status(id int) models.FirewallIPWhitelistStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[id].Status
+}
+
+func (s *memoryFirewallStore) eventTypes(id int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var types []string
+	for _, event := range s.events {
+		if event.WhitelistID == id {
+			types = append(types, event.EventType)
+		}
+	}
+	return types
+}
+
+func (s *memoryFirewallStore) GetSystemUser(ctx context.Context) (string, string, error) {
+	return "system", "system", nil
+}
+
+func (s *memoryFirewallStore) GetManagedAliases(ctx context.Context) ([]*models.FirewallManagedAlias, error) {
+	return []*models.FirewallManagedAlias{
+		{UUID: integrationAliasUUID, Name: integrationAliasName, AuthGroup: "users", MaxIPsPerUser: 5, MaxTotalIPs: 50},
+	}, nil
+}
+
+func (s *memoryFirewallStore) GetAliasSyncEntries(ctx context.Context, aliasUUID string) ([]*models.FirewallIPWhitelistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []*models.FirewallIPWhitelistEntry
+	for id := 1; id < s.nextID; id++ {
+		entry := s.entries[id]
+		if entry.AliasUUID == aliasUUID && (entry.Status == models.StatusRequested || entry.Status == models.StatusAdded) {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	return entries, nil
+}
+
+func (s *memoryFirewallStore) MarkIPsAsAdded(ctx context.Context, ids []int, systemUserIss, systemUserSub string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		if entry := s.entries[id]; entry.Status == models.StatusRequested {
+			entry.Status = models.StatusAdded
+			s.recordEvent(id, "added")
+		}
+	}
+	return nil
+}
+
+func (s *memoryFirewallStore) ExpireOldIPs(ctx context.Context, systemUserIss, systemUserSub string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for id, entry := range s.entries {
+		active := entry.Status == models.StatusRequested || entry.Status == models.StatusAdded || entry.Status == models.StatusScheduled
+		if active && entry.ExpiresAt != nil && !entry.ExpiresAt.After(time.Now()) {
+			entry.Status = models.StatusRemoved
+			s.recordEvent(id, "expired")
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memoryFirewallStore) GetScheduledWhitelistEntries(ctx context.Context) ([]*models.FirewallIPWhitelistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []*models.FirewallIPWhitelistEntry
+	for id := 1; id < s.nextID; id++ {
+		if entry := s.entries[id]; entry.Schedule != nil {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	return entries, nil
+}
+
+func (s *memoryFirewallStore) SetWhitelistEntryWindow(ctx context.Context, id int, open bool, systemUserIss, systemUserSub string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[id]
+	if open && entry.Status == models.StatusScheduled {
+		entry.Status = models.StatusRequested
+		s.recordEvent(id, "window_opened")
+		return true, nil
+	}
+	if !open && (entry.Status == models.StatusRequested || entry.Status == models.StatusAdded) {
+		entry.Status = models.StatusScheduled
+		s.recordEvent(id, "window_closed")
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *memoryFirewallStore) NotifyFirewallSync(ctx context.Context, aliasUUID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.synced = append(s.synced, aliasUUID)
+	return nil
+}
+
+func (s *memoryFirewallStore) GetWhitelistEntryByID(ctx context.Context, id int) (*models.FirewallIPWhitelistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil, fmt.Errorf("entry %d not found", id)
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (s *memoryFirewallStore) BlacklistIPAddress(ctx context.Context, aliasUUID, ipAddress, adminIss, adminSub, reason string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for id, entry := range s.entries {
+		if entry.AliasUUID == aliasUUID && entry.IPAddress == ipAddress && entry.Status != models.StatusBlacklistedByAdmin {
+			entry.Status = models.StatusBlacklistedByAdmin
+			s.recordEvent(id, "blacklisted_by_admin")
+			count++
+		}
+	}
+
+	prefix, err := netip.ParsePrefix(ipAddress)
+	if err != nil {
+		return 0, err
+	}
+	s.blocked = append(s.blocked, prefix)
+	return count, nil
+}
+
+func (s *memoryFirewallStore) IsIPBlacklisted(ctx context.Context, aliasUUID, ipAddress string, asn *int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addr, err := netip.ParseAddr(ipAddress)
+	if err != nil {
+		return false, err
+	}
+	for _, prefix := range s.blocked {
+		if prefix.Contains(addr) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryFirewallStore) CreateWhitelistEvent(ctx context.Context, whitelistID int, actorIss, actorSub, eventType, notes string, clientIP, userAgent *string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordEvent(whitelistID, eventType)
+	return nil
+}
+
+func (s *memoryFirewallStore) CreateFirewallSyncPlan(ctx context.Context, plan *models.FirewallSyncPlan) (*models.FirewallSyncPlan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.plans = append(s.plans, plan)
+	return plan, nil
+}
+
+func (s *memoryFirewallStore) recordEvent(id int, eventType string) {
+	s.events = append(s.events, models.FirewallIPWhitelistEvent{WhitelistID: id, EventType: eventType})
+}
+
+type firewallIntegration struct {
+	appCtx     *middlewares.AppContext
+	sim        *opnsensesim.Server
+	store      *memoryFirewallStore
+	sync       *FirewallSyncJob
+	expiration *FirewallExpirationJob
+	schedule   *FirewallScheduleJob
+}
+
+func newFirewallIntegration(t *testing.T, routerContent ...string) *firewallIntegration {
+	sim := opnsensesim.NewServer("key", "secret")
+	sim.AddAlias(integrationAliasUUID, integrationAliasName, "host", routerContent...)
+
+	server := httptest.NewServer(sim)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{Features: &config.FeaturesConfig{}}
+	cfg.Authorization.GroupScopes = map[string][]string{
+		"admins": {authorization.ScopeFirewallBlacklist},
+		"users":  {authorization.ScopeFirewallRequestOwn},
+	}
+	cfg.Features.FirewallManagement = config.FirewallManagement{
+		Enabled:         true,
+		RouterEndpoint:  server.URL,
+		RouterAPIKey:    "key",
+		RouterAPISecret: "secret",
+
+		BackgroundJobConfig: config.DefaultFirewallBackgroundJobConfig,
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := newMemoryFirewallStore()
+	appCtx := &middlewares.AppContext{Context: context.Background(), Config: cfg, Storage: store, Logger: logger}
+	routerClient := firewall.NewRouterClient(*cfg)
+
+	return &firewallIntegration{
+		appCtx:     appCtx,
+		sim:        sim,
+		store:      store,
+		sync:       NewFirewallSyncJob(appCtx, routerClient, nil, time.Minute, time.Second, logger),
+		expiration: NewFirewallExpirationJob(appCtx, time.Minute, logger),
+		schedule:   NewFirewallScheduleJob(appCtx, time.Minute, logger),
+	}
+}
+
+// call runs an API handler as principal against the integration's store and router, with the URL
+// parameters chi would have routed.
+func (env *firewallIntegration) call(handler middlewares.AppHandler, principal middlewares.Principal, method, target, body string, params map[string]string) *httptest.ResponseRecorder {
+	routeCtx := chi.NewRouteContext()
+	for key, value := range params {
+		routeCtx.URLParams.Add(key, value)
+	}
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+	rr := httptest.NewRecorder()
+
+	appCtx := *env.appCtx
+	appCtx.Context = req.Context()
+	appCtx.Request = req
+	appCtx.Response = rr
+	appCtx.SetPrincipal(principal)
+
+	handler(&appCtx)
+	return rr
+}
+
+func TestFirewallSyncFlow(t *testing.T) {
+	env := newFirewallIntegration(t, "10.0.0.1")
+	ctx := context.Background()
+
+	kept := env.store.addEntry("10.0.0.1", models.StatusAdded, nil)
+	requested := env.store.addEntry("10.0.0.2", models.StatusRequested, nil)
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, env.sim.Live(integrationAliasUUID))
+	assert.Equal(t, models.StatusAdded, env.store.status(requested))
+	assert.Equal(t, models.StatusAdded, env.store.status(kept))
+	require.Len(t, env.store.plans, 1)
+	assert.Equal(t, models.SyncPlanApplied, env.store.plans[0].Status)
+
+	// A second run has nothing to do and records nothing
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Len(t, env.store.plans, 1)
+	assert.Equal(t, 1, env.sim.Calls(opnsensesim.EndpointAliasUtilAdd))
+}
+
+func TestFirewallSyncFlowRouterFailure(t *testing.T) {
+	env := newFirewallIntegration(t)
+	ctx := context.Background()
+
+	id := env.store.addEntry("10.0.0.5", models.StatusRequested, nil)
+	env.sim.InjectFailure(opnsensesim.EndpointAliasUtilAdd, opnsensesim.Failure{Status: http.StatusServiceUnavailable, Count: 1})
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Empty(t, env.sim.Live(integrationAliasUUID))
+	assert.Contains(t, env.store.eventTypes(id), "sync_failed")
+	require.Len(t, env.store.plans, 1)
+	assert.Equal(t, models.SyncPlanFailed, env.store.plans[0].Status)
+
+	// The next run retries and succeeds
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Equal(t, []string{"10.0.0.5"}, env.sim.Live(integrationAliasUUID))
+}
+
+func TestFirewallSyncFlowConcurrentEdit(t *testing.T) {
+	env := newFirewallIntegration(t)
+	ctx := context.Background()
+
+	env.store.addEntry("10.0.0.5", models.StatusRequested, nil)
+	env.sim.AfterNext(opnsensesim.EndpointAliasUtilAdd, func() {
+		env.sim.EditAlias(integrationAliasUUID, []string{"192.0.2.77"}, nil)
+	})
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Contains(t, env.sim.Live(integrationAliasUUID), "192.0.2.77", "the concurrent edit must survive this run")
+	require.Len(t, env.store.plans, 1)
+	assert.Equal(t, models.SyncPlanFailed, env.store.plans[0].Status)
+
+	// The next run plans against the edited alias and removes the address nobody requested
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Equal(t, []string{"10.0.0.5"}, env.sim.Live(integrationAliasUUID))
+}
+
+func TestFirewallExpirationFlow(t *testing.T) {
+	env := newFirewallIntegration(t)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	expired := env.store.addEntry("10.0.0.1", models.StatusRequested, &past)
+	valid := env.store.addEntry("10.0.0.2", models.StatusRequested, &future)
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, env.sim.Live(integrationAliasUUID))
+
+	require.NoError(t, env.expiration.expireOldIPs(ctx))
+	assert.Equal(t, models.StatusRemoved, env.store.status(expired))
+	assert.Equal(t, models.StatusAdded, env.store.status(valid))
+	assert.Contains(t, env.store.eventTypes(expired), "expired")
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Equal(t, []string{"10.0.0.2"}, env.sim.Live(integrationAliasUUID))
+}
+
+func TestFirewallScheduleFlow(t *testing.T) {
+	env := newFirewallIntegration(t)
+	ctx := context.Background()
+
+	// Every minute opens a window lasting an hour, so the window is always open
+	opened := env.store.addEntry("10.0.0.1", models.StatusScheduled, nil)
+	env.store.entries[opened].Schedule = &models.AccessSchedule{Timezone: "UTC", Cron: "* * * * *", Duration: "1h"}
+
+	require.NoError(t, env.schedule.applySchedules(ctx))
+	assert.Equal(t, models.StatusRequested, env.store.status(opened))
+	assert.Equal(t, []string{integrationAliasUUID}, env.store.synced, "an opened window is synced right away")
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Equal(t, []string{"10.0.0.1"}, env.sim.Live(integrationAliasUUID))
+
+	// Nothing changes on the next check, so no further sync is requested
+	require.NoError(t, env.schedule.applySchedules(ctx))
+	assert.Len(t, env.store.synced, 1)
+}
+
+func TestFirewallBlacklistFlow(t *testing.T) {
+	env := newFirewallIntegration(t)
+	ctx := context.Background()
+	admin := &models.User{Iss: "iss", Sub: "admin", Username: "admin", Groups: []string{"admins"}}
+	user := &models.User{Iss: "iss", Sub: "user", Username: "user", Groups: []string{"users"}}
+
+	id := env.store.addEntry("198.51.100.4", models.StatusRequested, nil)
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Equal(t, []string{"198.51.100.4"}, env.sim.Live(integrationAliasUUID))
+
+	rr := env.call(handlers.DELETEBlacklistIPEntry, admin, http.MethodDelete, fmt.Sprintf("/api/firewall/entries/%d/blacklist", id),
+		`{"reason":"abuse"}`, map[string]string{"id": strconv.Itoa(id)})
+	require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	assert.Equal(t, models.StatusBlacklistedByAdmin, env.store.status(id))
+	assert.Equal(t, []string{integrationAliasUUID}, env.store.synced, "blacklisting requests a sync")
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Empty(t, env.sim.Live(integrationAliasUUID))
+	assert.Equal(t, 1, env.sim.Calls(opnsensesim.EndpointAliasUtilDelete))
+
+	// The owner cannot add the address back, so the next sync leaves the alias empty
+	rr = env.call(handlers.POSTAddIPEntry, user, http.MethodPost, "/api/firewall/entries",
+		fmt.Sprintf(`{"alias_name":%q,"ip_address":"198.51.100.4"}`, integrationAliasName), nil)
+	assert.Equal(t, http.StatusForbidden, rr.Code, rr.Body.String())
+
+	require.NoError(t, env.sync.syncAliases(ctx, nil))
+	assert.Empty(t, env.sim.Live(integrationAliasUUID))
+	assert.Equal(t, 1, env.sim.Calls(opnsensesim.EndpointAliasUtilAdd))
+}
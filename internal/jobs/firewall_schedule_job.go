@@ -0,0 +1,138 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/models"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// FirewallScheduleJob opens and closes the access windows of scheduled whitelist entries.
+// It only flips entry status, then asks the sync job to apply the changes to the affected aliases.
+type FirewallScheduleJob struct {
+	appCtx   *middlewares.AppContext
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewFirewallScheduleJob(appCtx *middlewares.AppContext, interval time.Duration, logger *slog.Logger) *FirewallScheduleJob {
+	return &FirewallScheduleJob{
+		appCtx:   appCtx,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (j *FirewallScheduleJob) Name() string {
+	return "firewall_access_schedule"
+}
+
+func (j *FirewallScheduleJob) RequiresLeadership() bool {
+	return true // Only leader should transition access windows
+}
+
+func (j *FirewallScheduleJob) Interval() time.Duration {
+	return j.interval
+}
+
+func (j *FirewallScheduleJob) Run(ctx context.Context) error {
+	if j.interval <= 0 {
+		return fmt.Errorf("firewall schedule job interval must be positive")
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	if err := j.applySchedules(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		j.logger.Error("initial access window check failed", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := j.applySchedules(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				j.logger.Error("access window check failed", "error", err)
+			}
+		}
+	}
+}
+
+func (j *FirewallScheduleJob) applySchedules(ctx context.Context) error {
+	entries, err := j.appCtx.Storage.GetScheduledWhitelistEntries(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get scheduled entries: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	systemUserIss, systemUserSub, err := j.appCtx.Storage.GetSystemUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get system user: %w", err)
+	}
+
+	now := time.Now()
+	var opened, closed int
+	var changedAliases []string
+
+	for _, entry := range entries {
+		open := entry.Schedule.IsOpen(now)
+		isScheduled := entry.Status == models.StatusScheduled
+
+		// Nothing to do while the entry already matches its window
+		if open != isScheduled {
+			continue
+		}
+
+		changed, err := j.appCtx.Storage.SetWhitelistEntryWindow(ctx, entry.ID, open, systemUserIss, systemUserSub)
+		if err != nil {
+			j.logger.Error("failed to update access window",
+				"error", err,
+				"entry_id", entry.ID,
+				"open", open,
+			)
+			continue
+		}
+
+		if !changed {
+			continue
+		}
+
+		if open {
+			opened++
+		} else {
+			closed++
+		}
+
+		if !slices.Contains(changedAliases, entry.AliasUUID) {
+			changedAliases = append(changedAliases, entry.AliasUUID)
+		}
+
+		j.logger.Debug("access window transitioned",
+			"entry_id", entry.ID,
+			"ip", entry.IPAddress,
+			"alias", entry.AliasName,
+			"open", open,
+		)
+	}
+
+	if opened > 0 || closed > 0 {
+		j.logger.Info("applied access windows", "opened", opened, "closed", closed)
+	}
+
+	// Sync now so windows open and close on time rather than at the next sync interval
+	for _, aliasUUID := range changedAliases {
+		if err := j.appCtx.Storage.NotifyFirewallSync(ctx, aliasUUID); err != nil {
+			j.logger.Warn("failed to request firewall sync", "error", err, "alias_uuid", aliasUUID)
+		}
+	}
+
+	return nil
+}
@@ -13,19 +13,28 @@ import (
 	"time"
 )
 
+// syncTriggerRetryDelay is how long to wait before listening again after losing the notification connection.
+const syncTriggerRetryDelay = 5 * time.Second
+
+// FirewallSyncJob reconciles every alias on an interval, and individual aliases on demand when a replica
+// publishes a sync request after a user adds or removes an entry.
 type FirewallSyncJob struct {
-	appCtx       *middlewares.AppContext
-	routerClient *firewall.RouterClient
-	interval     time.Duration
-	logger       *slog.Logger
+	appCtx        *middlewares.AppContext
+	routerClient  *firewall.RouterClient
+	traefikClient *firewall.TraefikClient
+	interval      time.Duration
+	debounce      time.Duration
+	logger        *slog.Logger
 }
 
-func NewFirewallSyncJob(appCtx *middlewares.AppContext, routerClient *firewall.RouterClient, interval time.Duration, logger *slog.Logger) *FirewallSyncJob {
+func NewFirewallSyncJob(appCtx *middlewares.AppContext, routerClient *firewall.RouterClient, traefikClient *firewall.TraefikClient, interval, debounce time.Duration, logger *slog.Logger) *FirewallSyncJob {
 	return &FirewallSyncJob{
-		appCtx:       appCtx,
-		routerClient: routerClient,
-		interval:     interval,
-		logger:       logger,
+		appCtx:        appCtx,
+		routerClient:  routerClient,
+		traefikClient: traefikClient,
+		interval:      interval,
+		debounce:      debounce,
+		logger:        logger,
 	}
 }
 
@@ -49,29 +58,90 @@ func (j *FirewallSyncJob) Run(ctx context.Context) error {
 	ticker := time.NewTicker(j.interval)
 	defer ticker.Stop()
 
-	if err := j.syncAllAliases(ctx); err != nil && !errors.Is(err, context.Canceled) {
+	triggers := make(chan string, 64)
+	go j.listenForTriggers(ctx, triggers)
+
+	if err := j.syncAliases(ctx, nil); err != nil && !errors.Is(err, context.Canceled) {
 		j.logger.Error("initial firewall sync failed", "error", err)
 	}
 
+	// Requests are collected for one debounce window from the first one, so a burst of changes to the
+	// same alias results in a single sync.
+	pending := make(map[string]bool)
+	var debounce *time.Timer
+	var debounceC <-chan time.Time
+
 	for {
 		select {
 		case <-ctx.Done():
+			if debounce != nil {
+				debounce.Stop()
+			}
 			return nil
+		case aliasUUID := <-triggers:
+			pending[strings.ToLower(aliasUUID)] = true
+			if debounce == nil {
+				debounce = time.NewTimer(j.debounce)
+				debounceC = debounce.C
+			}
+		case <-debounceC:
+			requested := pending
+			pending = make(map[string]bool)
+			debounce, debounceC = nil, nil
+
+			if err := j.syncAliases(ctx, requested); err != nil && !errors.Is(err, context.Canceled) {
+				j.logger.Error("on-demand firewall sync failed", "error", err)
+			}
 		case <-ticker.C:
-			if err := j.syncAllAliases(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			if err := j.syncAliases(ctx, nil); err != nil && !errors.Is(err, context.Canceled) {
 				j.logger.Error("firewall sync failed", "error", err)
 			}
 		}
 	}
 }
 
-func (j *FirewallSyncJob) syncAllAliases(ctx context.Context) error {
+// listenForTriggers forwards on-demand sync requests to triggers until ctx is cancelled, reconnecting
+// after connection errors. Requests are dropped when triggers is full; the next interval catches them.
+func (j *FirewallSyncJob) listenForTriggers(ctx context.Context, triggers chan<- string) {
+	for {
+		err := j.appCtx.Storage.ListenFirewallSync(ctx, func(aliasUUID string) {
+			select {
+			case triggers <- aliasUUID:
+			default:
+				j.logger.Warn("dropping firewall sync request, queue is full", "alias_uuid", aliasUUID)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		j.logger.Warn("firewall sync listener stopped, retrying", "error", err, "retry_in", syncTriggerRetryDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(syncTriggerRetryDelay):
+		}
+	}
+}
+
+// syncAliases syncs the aliases whose lowercased uuid is in only, or every alias when only is nil.
+func (j *FirewallSyncJob) syncAliases(ctx context.Context, only map[string]bool) error {
 	systemUserIss, systemUserSub, err := j.appCtx.Storage.GetSystemUser(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get system user: %w", err)
 	}
 
-	for _, aliasConfig := range j.appCtx.Config.Features.FirewallManagement.Aliases {
+	aliases, err := firewall.LoadAliases(ctx, j.appCtx.Storage)
+	if err != nil {
+		return err
+	}
+
+	for _, aliasConfig := range uniqueAliases(aliases) {
+		if only != nil && !only[strings.ToLower(aliasConfig.UUID)] {
+			continue
+		}
+
 		if err := j.syncAlias(ctx, &aliasConfig, systemUserIss, systemUserSub); err != nil {
 			j.logger.Error("failed to sync alias",
 				"alias_name", aliasConfig.Name,
@@ -84,124 +154,151 @@ func (j *FirewallSyncJob) syncAllAliases(ctx context.Context) error {
 	return nil
 }
 
+// uniqueAliases returns one alias per uuid, as an alias offered to several auth groups is still a single alias
+// on its backend. It is in dry-run mode if any of its auth groups is, so no group pushes changes another only
+// previews.
+func uniqueAliases(aliases []config.FirewallAliasConfig) []config.FirewallAliasConfig {
+	unique := make([]config.FirewallAliasConfig, 0, len(aliases))
+	index := make(map[string]int, len(aliases))
+
+	for _, alias := range aliases {
+		key := strings.ToLower(alias.UUID)
+		if i, seen := index[key]; seen {
+			unique[i].DryRun = unique[i].DryRun || alias.DryRun
+			continue
+		}
+		index[key] = len(unique)
+		unique = append(unique, alias)
+	}
+
+	return unique
+}
+
 func (j *FirewallSyncJob) syncAlias(ctx context.Context, aliasConfig *config.FirewallAliasConfig, systemUserIss, systemUserSub string) error {
-	currentFirewallIPs, err := j.routerClient.GetAliasIPs(ctx, aliasConfig.UUID)
+	backend, err := firewall.BackendForAlias(j.routerClient, j.traefikClient, aliasConfig)
 	if err != nil {
-		return fmt.Errorf("failed to get current firewall IPs: %w", err)
+		return err
 	}
 
-	allEntries, err := j.appCtx.Storage.GetAllWhitelistEntries(ctx)
+	currentFirewallIPs, err := backend.GetAliasIPs(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get all whitelist entries: %w", err)
+		return fmt.Errorf("failed to get current firewall IPs: %w", err)
 	}
 
-	var aliasEntries []*models.FirewallIPWhitelistEntry
-	for _, entry := range allEntries {
-		if entry.AliasUUID == aliasConfig.UUID {
-			aliasEntries = append(aliasEntries, entry)
-		}
+	aliasEntries, err := j.appCtx.Storage.GetAliasSyncEntries(ctx, aliasConfig.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to get alias whitelist entries: %w", err)
 	}
 
-	ipStatusMap := make(map[string]string) // IP -> desired status ("add" or "remove")
-	ipToEntries := make(map[string][]*models.FirewallIPWhitelistEntry)
+	plan := firewall.BuildSyncPlan(aliasConfig.Name, aliasConfig.UUID, currentFirewallIPs, aliasEntries)
 
-	// Helper function to strip CIDR notation
-	stripCIDR := func(ip string) string {
-		if idx := strings.Index(ip, "/"); idx != -1 {
-			return ip[:idx]
+	if aliasConfig.DryRun {
+		return j.recordDryRunPlan(ctx, plan)
+	}
+
+	if len(plan.PendingEntryIDs) > 0 {
+		err := j.appCtx.Storage.MarkIPsAsAdded(ctx, plan.PendingEntryIDs, systemUserIss, systemUserSub)
+		if err != nil {
+			j.logger.Error("failed to mark IPs as added",
+				"alias", aliasConfig.Name,
+				"entry_ids", plan.PendingEntryIDs,
+				"error", err,
+			)
 		}
-		return ip
 	}
 
-	for _, entry := range aliasEntries {
-		// Strip CIDR notation for comparison
-		cleanIP := stripCIDR(entry.IPAddress)
-		ipToEntries[cleanIP] = append(ipToEntries[cleanIP], entry)
+	if !plan.HasChanges() {
+		return nil
 	}
 
-	for ip, entries := range ipToEntries {
-		hasActiveEntry := false
-		pendingIDs := []int{}
+	j.logger.Info("syncing firewall alias",
+		"alias", aliasConfig.Name,
+		"ips_to_add", len(plan.IPsToAdd),
+		"ips_to_remove", len(plan.IPsToRemove),
+	)
 
-		for _, entry := range entries {
-			if entry.Status == models.StatusRequested {
-				pendingIDs = append(pendingIDs, entry.ID)
-				hasActiveEntry = true
-			} else if entry.Status == models.StatusAdded {
-				hasActiveEntry = true
-			}
-		}
+	result, err := backend.ApplyAliasChanges(ctx, plan.IPsToAdd, plan.IPsToRemove)
+	if errors.Is(err, firewall.ErrAliasModifiedConcurrently) {
+		// Someone else edited the alias; the next run plans against the new content.
+		errMsg := err.Error()
+		plan.Status = models.SyncPlanFailed
+		plan.Error = &errMsg
+		j.recordPlan(ctx, plan)
 
-		if hasActiveEntry {
-			ipStatusMap[ip] = "add"
-			if len(pendingIDs) > 0 {
-				err := j.appCtx.Storage.MarkIPsAsAdded(ctx, pendingIDs, systemUserIss, systemUserSub)
-				if err != nil {
-					j.logger.Error("failed to mark IPs as added",
-						"ip", ip,
-						"alias", aliasConfig.Name,
-						"error", err,
-					)
-				}
-			}
-		} else {
-			ipStatusMap[ip] = "remove"
+		j.logger.Warn("firewall alias changed during sync, will retry next interval",
+			"alias", aliasConfig.Name,
+			"error", err,
+		)
+		return nil
+	}
+	if err != nil {
+		errMsg := err.Error()
+		plan.Status = models.SyncPlanFailed
+		plan.Error = &errMsg
+		j.recordPlan(ctx, plan)
+
+		for _, entry := range aliasEntries {
+			_ = j.appCtx.Storage.CreateWhitelistEvent(
+				ctx,
+				entry.ID,
+				systemUserIss,
+				systemUserSub,
+				"sync_failed",
+				errMsg,
+				nil,
+				nil,
+			)
 		}
+		return fmt.Errorf("failed to update firewall alias: %w", err)
 	}
 
-	var ipsToAdd []string
-	var ipsToRemove []string
+	plan.Status = models.SyncPlanApplied
+	j.recordPlan(ctx, plan)
+
+	j.logger.Info("firewall alias synced successfully",
+		"alias", aliasConfig.Name,
+		"added", result.Added,
+		"removed", result.Removed,
+	)
+
+	return nil
+}
 
-	currentIPSet := make(map[string]bool)
-	for _, ip := range currentFirewallIPs {
-		currentIPSet[ip] = true
+// recordDryRunPlan stores a dry-run plan unless it is identical to the last plan recorded for the alias,
+// so an alias left in dry-run mode does not record the same plan on every interval.
+func (j *FirewallSyncJob) recordDryRunPlan(ctx context.Context, plan *models.FirewallSyncPlan) error {
+	plan.DryRun = true
+
+	if !plan.HasChanges() && len(plan.PendingEntryIDs) == 0 {
+		return nil
 	}
 
-	for ip, status := range ipStatusMap {
-		if status == "add" && !currentIPSet[ip] {
-			ipsToAdd = append(ipsToAdd, ip)
-		}
+	previous, err := j.appCtx.Storage.GetFirewallSyncPlans(ctx, plan.AliasUUID, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get previous sync plan: %w", err)
 	}
 
-	for ip := range currentIPSet {
-		status, exists := ipStatusMap[ip]
-		if !exists || status == "remove" {
-			ipsToRemove = append(ipsToRemove, ip)
-		}
+	if len(previous) > 0 && previous[0].DryRun && previous[0].SameChanges(plan) {
+		return nil
 	}
 
-	if len(ipsToAdd) > 0 || len(ipsToRemove) > 0 {
-		j.logger.Info("syncing firewall alias",
-			"alias", aliasConfig.Name,
-			"ips_to_add", len(ipsToAdd),
-			"ips_to_remove", len(ipsToRemove),
-		)
+	j.logger.Info("firewall alias dry run",
+		"alias", plan.AliasName,
+		"would_add", plan.IPsToAdd,
+		"would_remove", plan.IPsToRemove,
+		"pending_entries", len(plan.PendingEntryIDs),
+	)
 
-		err := j.routerClient.UpdateAlias(ctx, aliasConfig.UUID, ipsToAdd, ipsToRemove)
-		if err != nil {
-			for ip := range ipStatusMap {
-				for _, entry := range ipToEntries[ip] {
-					_ = j.appCtx.Storage.CreateWhitelistEvent(
-						ctx,
-						entry.ID,
-						systemUserIss,
-						systemUserSub,
-						"sync_failed",
-						err.Error(),
-						nil,
-						nil,
-					)
-				}
-			}
-			return fmt.Errorf("failed to update firewall alias: %w", err)
-		}
+	j.recordPlan(ctx, plan)
+	return nil
+}
 
-		j.logger.Info("firewall alias synced successfully",
-			"alias", aliasConfig.Name,
-			"added", ipsToAdd,
-			"removed", ipsToRemove,
+func (j *FirewallSyncJob) recordPlan(ctx context.Context, plan *models.FirewallSyncPlan) {
+	if _, err := j.appCtx.Storage.CreateFirewallSyncPlan(ctx, plan); err != nil {
+		j.logger.Error("failed to record firewall sync plan",
+			"alias", plan.AliasName,
+			"status", plan.Status,
+			"error", err,
 		)
 	}
-
-	return nil
 }
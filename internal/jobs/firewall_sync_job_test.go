@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"homelab-dashboard/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueAliases(t *testing.T) {
+	aliases := uniqueAliases([]config.FirewallAliasConfig{
+		{UUID: integrationAliasUUID, Name: integrationAliasName, AuthGroup: "users"},
+		{UUID: "7f93ff45-6c60-4a21-9767-3fc246f4d335", Name: "Ingress", AuthGroup: "users"},
+		{UUID: integrationAliasUUID, Name: integrationAliasName, AuthGroup: "admins", DryRun: true},
+	})
+
+	require.Len(t, aliases, 2, "an alias offered to several groups is synced once")
+	assert.Equal(t, integrationAliasUUID, aliases[0].UUID)
+	assert.True(t, aliases[0].DryRun, "an alias is dry-run when any of its groups is")
+	assert.False(t, aliases[1].DryRun)
+}
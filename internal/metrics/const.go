@@ -16,3 +16,10 @@ const (
 )
 
 const DataSourceTypeMimir = "mimir"
+
+const (
+	DataSourceTypeLoki       = "loki"
+	DataSourceTypeHTTPJSON   = "http_json"
+	DataSourceTypeKubernetes = "kubernetes"
+	DataSourceTypeProbe      = "probe"
+)
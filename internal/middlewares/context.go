@@ -6,6 +6,7 @@ import (
 	"homelab-dashboard/internal/config"
 	"homelab-dashboard/internal/data"
 	"homelab-dashboard/internal/services/certificate"
+	"homelab-dashboard/internal/services/firewall"
 	"homelab-dashboard/internal/storage"
 	"log/slog"
 	"net/http"
@@ -18,8 +19,12 @@ type AppContext struct {
 	SessionManager     SessionProvider
 	OIDCProvider       OIDCProvider
 	Cache              data.Provider
+	DataService        *data.Service // runs templated queries on demand
 	Storage            storage.Provider
 	CertificateManager certificate.Provider
+	RouterClient       *firewall.RouterClient
+	TraefikClient      *firewall.TraefikClient // nil unless an alias is backed by a Traefik middleware
+	GeoIP              *firewall.GeoIPResolver
 
 	principal Principal
 
@@ -41,8 +46,12 @@ func AppContextMiddleware(baseCtx *AppContext) func(http.Handler) http.Handler {
 				SessionManager:     baseCtx.SessionManager,
 				OIDCProvider:       baseCtx.OIDCProvider,
 				Cache:              baseCtx.Cache,
+				DataService:        baseCtx.DataService,
 				Storage:            baseCtx.Storage,
 				CertificateManager: baseCtx.CertificateManager,
+				RouterClient:       baseCtx.RouterClient,
+				TraefikClient:      baseCtx.TraefikClient,
+				GeoIP:              baseCtx.GeoIP,
 				principal:          baseCtx.principal,
 				Request:            r,
 				Response:           w,
@@ -86,7 +95,7 @@ func (ctx *AppContext) Redirect(url string, status int) {
 	http.Redirect(ctx.Response, ctx.Request, url, status)
 }
 
-func NewAppContext(ctx context.Context, cfg *config.Config, logger *slog.Logger, cache data.Provider, sessionManager SessionProvider, oidcProvider OIDCProvider, storage storage.Provider, certificates certificate.Provider) *AppContext {
+func NewAppContext(ctx context.Context, cfg *config.Config, logger *slog.Logger, cache data.Provider, dataService *data.Service, sessionManager SessionProvider, oidcProvider OIDCProvider, storage storage.Provider, certificates certificate.Provider, routerClient *firewall.RouterClient, traefikClient *firewall.TraefikClient, geoIP *firewall.GeoIPResolver) *AppContext {
 	return &AppContext{
 		Context:            ctx,
 		Config:             cfg,
@@ -94,8 +103,12 @@ func NewAppContext(ctx context.Context, cfg *config.Config, logger *slog.Logger,
 		SessionManager:     sessionManager,
 		OIDCProvider:       oidcProvider,
 		Cache:              cache,
+		DataService:        dataService,
 		Storage:            storage,
 		CertificateManager: certificates,
+		RouterClient:       routerClient,
+		TraefikClient:      traefikClient,
+		GeoIP:              geoIP,
 		principal:          nil,
 	}
 }
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"net/http"
+	"slices"
+)
+
+// SkipForEventStreams applies mw to every request except those for the given streaming endpoints, such as
+// Server-Sent Events or large downloads, which stay open by design and would otherwise be cut off by request
+// timeouts. Only the listed paths are exempt, whatever the request's Accept header says.
+func SkipForEventStreams(mw func(http.Handler) http.Handler, streamPaths ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && slices.Contains(streamPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSkipForEventStreams(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		accept        string
+		expectApplied bool
+	}{
+		{"stream endpoint", http.MethodGet, "/api/data/stream", "text/event-stream", false},
+		{"other endpoint asking for an event stream", http.MethodGet, "/api/data", "text/event-stream", true},
+		{"regular request", http.MethodGet, "/api/queries", "application/json", true},
+		{"other method on stream path", http.MethodPost, "/api/data/stream", "", true},
+		{"second stream endpoint", http.MethodGet, "/api/firewall/events/export", "text/csv", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied := false
+			mw := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					applied = true
+					next.ServeHTTP(w, r)
+				})
+			}
+
+			handler := SkipForEventStreams(mw, "/api/data/stream", "/api/firewall/events/export")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if applied != tt.expectApplied {
+				t.Errorf("Expected middleware applied=%v, got %v", tt.expectApplied, applied)
+			}
+		})
+	}
+}
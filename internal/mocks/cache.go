@@ -113,6 +113,20 @@ func (mr *MockCacheProviderMockRecorder) ListAll(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockCacheProvider)(nil).ListAll), ctx)
 }
 
+// PublishUpdate mocks base method.
+func (m *MockCacheProvider) PublishUpdate(ctx context.Context, queryName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishUpdate", ctx, queryName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishUpdate indicates an expected call of PublishUpdate.
+func (mr *MockCacheProviderMockRecorder) PublishUpdate(ctx, queryName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishUpdate", reflect.TypeOf((*MockCacheProvider)(nil).PublishUpdate), ctx, queryName)
+}
+
 // Set mocks base method.
 func (m *MockCacheProvider) Set(ctx context.Context, queryName string, arg2 data.CachedData) {
 	m.ctrl.T.Helper()
@@ -152,3 +166,47 @@ func (mr *MockCacheProviderMockRecorder) Size(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Size", reflect.TypeOf((*MockCacheProvider)(nil).Size), ctx)
 }
+
+// SlidingWindowAllow mocks base method.
+func (m *MockCacheProvider) SlidingWindowAllow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlidingWindowAllow", ctx, key, limit, window)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SlidingWindowAllow indicates an expected call of SlidingWindowAllow.
+func (mr *MockCacheProviderMockRecorder) SlidingWindowAllow(ctx, key, limit, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlidingWindowAllow", reflect.TypeOf((*MockCacheProvider)(nil).SlidingWindowAllow), ctx, key, limit, window)
+}
+
+// SlidingWindowRelease mocks base method.
+func (m *MockCacheProvider) SlidingWindowRelease(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlidingWindowRelease", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SlidingWindowRelease indicates an expected call of SlidingWindowRelease.
+func (mr *MockCacheProviderMockRecorder) SlidingWindowRelease(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlidingWindowRelease", reflect.TypeOf((*MockCacheProvider)(nil).SlidingWindowRelease), ctx, key)
+}
+
+// SubscribeUpdates mocks base method.
+func (m *MockCacheProvider) SubscribeUpdates(ctx context.Context) <-chan string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeUpdates", ctx)
+	ret0, _ := ret[0].(<-chan string)
+	return ret0
+}
+
+// SubscribeUpdates indicates an expected call of SubscribeUpdates.
+func (mr *MockCacheProviderMockRecorder) SubscribeUpdates(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeUpdates", reflect.TypeOf((*MockCacheProvider)(nil).SubscribeUpdates), ctx)
+}
@@ -47,18 +47,33 @@ func (m *MockStorageProvider) EXPECT() *MockStorageProviderMockRecorder {
 }
 
 // AddIPToWhitelist mocks base method.
-func (m *MockStorageProvider) AddIPToWhitelist(ctx context.Context, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description string, expiresAt *time.Time, clientIP, userAgent *string) (*models.FirewallIPWhitelistEntry, error) {
+func (m *MockStorageProvider) AddIPToWhitelist(ctx context.Context, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description string, expiresAt *time.Time, clientIP, userAgent *string, enrichment, clientEnrichment models.IPEnrichment, schedule *models.AccessSchedule) (*models.FirewallIPWhitelistEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddIPToWhitelist", ctx, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description, expiresAt, clientIP, userAgent)
+	ret := m.ctrl.Call(m, "AddIPToWhitelist", ctx, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description, expiresAt, clientIP, userAgent, enrichment, clientEnrichment, schedule)
 	ret0, _ := ret[0].(*models.FirewallIPWhitelistEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddIPToWhitelist indicates an expected call of AddIPToWhitelist.
-func (mr *MockStorageProviderMockRecorder) AddIPToWhitelist(ctx, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description, expiresAt, clientIP, userAgent any) *gomock.Call {
+func (mr *MockStorageProviderMockRecorder) AddIPToWhitelist(ctx, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description, expiresAt, clientIP, userAgent, enrichment, clientEnrichment, schedule any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIPToWhitelist", reflect.TypeOf((*MockStorageProvider)(nil).AddIPToWhitelist), ctx, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description, expiresAt, clientIP, userAgent)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIPToWhitelist", reflect.TypeOf((*MockStorageProvider)(nil).AddIPToWhitelist), ctx, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description, expiresAt, clientIP, userAgent, enrichment, clientEnrichment, schedule)
+}
+
+// AddStatusIncidentUpdate mocks base method.
+func (m *MockStorageProvider) AddStatusIncidentUpdate(ctx context.Context, incidentID int, update *models.StatusIncidentUpdate) (*models.StatusIncident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddStatusIncidentUpdate", ctx, incidentID, update)
+	ret0, _ := ret[0].(*models.StatusIncident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddStatusIncidentUpdate indicates an expected call of AddStatusIncidentUpdate.
+func (mr *MockStorageProviderMockRecorder) AddStatusIncidentUpdate(ctx, incidentID, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStatusIncidentUpdate", reflect.TypeOf((*MockStorageProvider)(nil).AddStatusIncidentUpdate), ctx, incidentID, update)
 }
 
 // BlacklistIP mocks base method.
@@ -132,6 +147,37 @@ func (mr *MockStorageProviderMockRecorder) CountUserActiveIPs(ctx, ownerIss, own
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserActiveIPs", reflect.TypeOf((*MockStorageProvider)(nil).CountUserActiveIPs), ctx, ownerIss, ownerSub, aliasUUID)
 }
 
+// CountUserDistinctNetworks mocks base method.
+func (m *MockStorageProvider) CountUserDistinctNetworks(ctx context.Context, ownerIss, ownerSub string, window time.Duration) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserDistinctNetworks", ctx, ownerIss, ownerSub, window)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountUserDistinctNetworks indicates an expected call of CountUserDistinctNetworks.
+func (mr *MockStorageProviderMockRecorder) CountUserDistinctNetworks(ctx, ownerIss, ownerSub, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserDistinctNetworks", reflect.TypeOf((*MockStorageProvider)(nil).CountUserDistinctNetworks), ctx, ownerIss, ownerSub, window)
+}
+
+// CreateBlacklistRule mocks base method.
+func (m *MockStorageProvider) CreateBlacklistRule(ctx context.Context, rule *models.FirewallBlacklistRule) (*models.FirewallBlacklistRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlacklistRule", ctx, rule)
+	ret0, _ := ret[0].(*models.FirewallBlacklistRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBlacklistRule indicates an expected call of CreateBlacklistRule.
+func (mr *MockStorageProviderMockRecorder) CreateBlacklistRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlacklistRule", reflect.TypeOf((*MockStorageProvider)(nil).CreateBlacklistRule), ctx, rule)
+}
+
 // CreateCertificateRequest mocks base method.
 func (m *MockStorageProvider) CreateCertificateRequest(ctx context.Context, sub, iss, commonName, status, message string, dnsNames, organizationalUnits []string, validityDays int) (*models.CertificateRequest, error) {
 	m.ctrl.T.Helper()
@@ -147,6 +193,51 @@ func (mr *MockStorageProviderMockRecorder) CreateCertificateRequest(ctx, sub, is
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCertificateRequest", reflect.TypeOf((*MockStorageProvider)(nil).CreateCertificateRequest), ctx, sub, iss, commonName, status, message, dnsNames, organizationalUnits, validityDays)
 }
 
+// CreateDataAlert mocks base method.
+func (m *MockStorageProvider) CreateDataAlert(ctx context.Context, alert *models.DataAlert) (*models.DataAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataAlert", ctx, alert)
+	ret0, _ := ret[0].(*models.DataAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDataAlert indicates an expected call of CreateDataAlert.
+func (mr *MockStorageProviderMockRecorder) CreateDataAlert(ctx, alert any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataAlert", reflect.TypeOf((*MockStorageProvider)(nil).CreateDataAlert), ctx, alert)
+}
+
+// CreateFirewallSyncPlan mocks base method.
+func (m *MockStorageProvider) CreateFirewallSyncPlan(ctx context.Context, plan *models.FirewallSyncPlan) (*models.FirewallSyncPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFirewallSyncPlan", ctx, plan)
+	ret0, _ := ret[0].(*models.FirewallSyncPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFirewallSyncPlan indicates an expected call of CreateFirewallSyncPlan.
+func (mr *MockStorageProviderMockRecorder) CreateFirewallSyncPlan(ctx, plan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFirewallSyncPlan", reflect.TypeOf((*MockStorageProvider)(nil).CreateFirewallSyncPlan), ctx, plan)
+}
+
+// CreateManagedAlias mocks base method.
+func (m *MockStorageProvider) CreateManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) (*models.FirewallManagedAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateManagedAlias", ctx, alias)
+	ret0, _ := ret[0].(*models.FirewallManagedAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateManagedAlias indicates an expected call of CreateManagedAlias.
+func (mr *MockStorageProviderMockRecorder) CreateManagedAlias(ctx, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateManagedAlias", reflect.TypeOf((*MockStorageProvider)(nil).CreateManagedAlias), ctx, alias)
+}
+
 // CreateServiceAccount mocks base method.
 func (m *MockStorageProvider) CreateServiceAccount(ctx context.Context, serviceAccount *models.ServiceAccount) (*models.ServiceAccount, error) {
 	m.ctrl.T.Helper()
@@ -162,6 +253,21 @@ func (mr *MockStorageProviderMockRecorder) CreateServiceAccount(ctx, serviceAcco
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockStorageProvider)(nil).CreateServiceAccount), ctx, serviceAccount)
 }
 
+// CreateStatusIncident mocks base method.
+func (m *MockStorageProvider) CreateStatusIncident(ctx context.Context, incident *models.StatusIncident, message string) (*models.StatusIncident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStatusIncident", ctx, incident, message)
+	ret0, _ := ret[0].(*models.StatusIncident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStatusIncident indicates an expected call of CreateStatusIncident.
+func (mr *MockStorageProviderMockRecorder) CreateStatusIncident(ctx, incident, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStatusIncident", reflect.TypeOf((*MockStorageProvider)(nil).CreateStatusIncident), ctx, incident, message)
+}
+
 // CreateUser mocks base method.
 func (m *MockStorageProvider) CreateUser(ctx context.Context, sub, iss, username, displayName, email string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -191,6 +297,35 @@ func (mr *MockStorageProviderMockRecorder) CreateWhitelistEvent(ctx, whitelistID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWhitelistEvent", reflect.TypeOf((*MockStorageProvider)(nil).CreateWhitelistEvent), ctx, whitelistID, actorIss, actorSub, eventType, notes, clientIP, userAgent)
 }
 
+// DeleteDataAlert mocks base method.
+func (m *MockStorageProvider) DeleteDataAlert(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDataAlert", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDataAlert indicates an expected call of DeleteDataAlert.
+func (mr *MockStorageProviderMockRecorder) DeleteDataAlert(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDataAlert", reflect.TypeOf((*MockStorageProvider)(nil).DeleteDataAlert), ctx, id)
+}
+
+// DeleteDataHistoryBefore mocks base method.
+func (m *MockStorageProvider) DeleteDataHistoryBefore(ctx context.Context, queryName string, resolution models.DataHistoryResolution, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDataHistoryBefore", ctx, queryName, resolution, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDataHistoryBefore indicates an expected call of DeleteDataHistoryBefore.
+func (mr *MockStorageProviderMockRecorder) DeleteDataHistoryBefore(ctx, queryName, resolution, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDataHistoryBefore", reflect.TypeOf((*MockStorageProvider)(nil).DeleteDataHistoryBefore), ctx, queryName, resolution, before)
+}
+
 // DeleteIssuedCertificate mocks base method.
 func (m *MockStorageProvider) DeleteIssuedCertificate(ctx context.Context, identifier string) error {
 	m.ctrl.T.Helper()
@@ -205,6 +340,20 @@ func (mr *MockStorageProviderMockRecorder) DeleteIssuedCertificate(ctx, identifi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIssuedCertificate", reflect.TypeOf((*MockStorageProvider)(nil).DeleteIssuedCertificate), ctx, identifier)
 }
 
+// DeleteManagedAlias mocks base method.
+func (m *MockStorageProvider) DeleteManagedAlias(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteManagedAlias", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteManagedAlias indicates an expected call of DeleteManagedAlias.
+func (mr *MockStorageProviderMockRecorder) DeleteManagedAlias(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteManagedAlias", reflect.TypeOf((*MockStorageProvider)(nil).DeleteManagedAlias), ctx, id)
+}
+
 // DeleteServiceAccount mocks base method.
 func (m *MockStorageProvider) DeleteServiceAccount(ctx context.Context, iss, sub string) error {
 	m.ctrl.T.Helper()
@@ -219,6 +368,20 @@ func (mr *MockStorageProviderMockRecorder) DeleteServiceAccount(ctx, iss, sub an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServiceAccount", reflect.TypeOf((*MockStorageProvider)(nil).DeleteServiceAccount), ctx, iss, sub)
 }
 
+// DeleteStatusIncident mocks base method.
+func (m *MockStorageProvider) DeleteStatusIncident(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStatusIncident", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStatusIncident indicates an expected call of DeleteStatusIncident.
+func (mr *MockStorageProviderMockRecorder) DeleteStatusIncident(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStatusIncident", reflect.TypeOf((*MockStorageProvider)(nil).DeleteStatusIncident), ctx, id)
+}
+
 // DisableServiceAccount mocks base method.
 func (m *MockStorageProvider) DisableServiceAccount(ctx context.Context, iss, sub string) error {
 	m.ctrl.T.Helper()
@@ -276,6 +439,51 @@ func (mr *MockStorageProviderMockRecorder) ExpireOldIPs(ctx, systemUserIss, syst
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireOldIPs", reflect.TypeOf((*MockStorageProvider)(nil).ExpireOldIPs), ctx, systemUserIss, systemUserSub)
 }
 
+// ExtendWhitelistEntry mocks base method.
+func (m *MockStorageProvider) ExtendWhitelistEntry(ctx context.Context, id, previousCount int, expiresAt time.Time, actorIss, actorSub, notes string, clientIP, userAgent *string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendWhitelistEntry", ctx, id, previousCount, expiresAt, actorIss, actorSub, notes, clientIP, userAgent)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtendWhitelistEntry indicates an expected call of ExtendWhitelistEntry.
+func (mr *MockStorageProviderMockRecorder) ExtendWhitelistEntry(ctx, id, previousCount, expiresAt, actorIss, actorSub, notes, clientIP, userAgent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendWhitelistEntry", reflect.TypeOf((*MockStorageProvider)(nil).ExtendWhitelistEntry), ctx, id, previousCount, expiresAt, actorIss, actorSub, notes, clientIP, userAgent)
+}
+
+// GetActiveDataAlerts mocks base method.
+func (m *MockStorageProvider) GetActiveDataAlerts(ctx context.Context) ([]*models.DataAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveDataAlerts", ctx)
+	ret0, _ := ret[0].([]*models.DataAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveDataAlerts indicates an expected call of GetActiveDataAlerts.
+func (mr *MockStorageProviderMockRecorder) GetActiveDataAlerts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveDataAlerts", reflect.TypeOf((*MockStorageProvider)(nil).GetActiveDataAlerts), ctx)
+}
+
+// GetAliasSyncEntries mocks base method.
+func (m *MockStorageProvider) GetAliasSyncEntries(ctx context.Context, aliasUUID string) ([]*models.FirewallIPWhitelistEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAliasSyncEntries", ctx, aliasUUID)
+	ret0, _ := ret[0].([]*models.FirewallIPWhitelistEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAliasSyncEntries indicates an expected call of GetAliasSyncEntries.
+func (mr *MockStorageProviderMockRecorder) GetAliasSyncEntries(ctx, aliasUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAliasSyncEntries", reflect.TypeOf((*MockStorageProvider)(nil).GetAliasSyncEntries), ctx, aliasUUID)
+}
+
 // GetAllWhitelistEntries mocks base method.
 func (m *MockStorageProvider) GetAllWhitelistEntries(ctx context.Context) ([]*models.FirewallIPWhitelistEntry, error) {
 	m.ctrl.T.Helper()
@@ -306,6 +514,36 @@ func (mr *MockStorageProviderMockRecorder) GetApprovedCertificateRequests(ctx an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApprovedCertificateRequests", reflect.TypeOf((*MockStorageProvider)(nil).GetApprovedCertificateRequests), ctx)
 }
 
+// GetBlacklistRuleByID mocks base method.
+func (m *MockStorageProvider) GetBlacklistRuleByID(ctx context.Context, id int) (*models.FirewallBlacklistRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlacklistRuleByID", ctx, id)
+	ret0, _ := ret[0].(*models.FirewallBlacklistRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlacklistRuleByID indicates an expected call of GetBlacklistRuleByID.
+func (mr *MockStorageProviderMockRecorder) GetBlacklistRuleByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlacklistRuleByID", reflect.TypeOf((*MockStorageProvider)(nil).GetBlacklistRuleByID), ctx, id)
+}
+
+// GetBlacklistRules mocks base method.
+func (m *MockStorageProvider) GetBlacklistRules(ctx context.Context, aliasUUID string, includeInactive bool) ([]*models.FirewallBlacklistRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlacklistRules", ctx, aliasUUID, includeInactive)
+	ret0, _ := ret[0].([]*models.FirewallBlacklistRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlacklistRules indicates an expected call of GetBlacklistRules.
+func (mr *MockStorageProviderMockRecorder) GetBlacklistRules(ctx, aliasUUID, includeInactive any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlacklistRules", reflect.TypeOf((*MockStorageProvider)(nil).GetBlacklistRules), ctx, aliasUUID, includeInactive)
+}
+
 // GetCertificateAuthority mocks base method.
 func (m *MockStorageProvider) GetCertificateAuthority(ctx context.Context) (*utils.CertificateData, utils.KeyAlgorithm, error) {
 	m.ctrl.T.Helper()
@@ -397,6 +635,21 @@ func (mr *MockStorageProviderMockRecorder) GetCertificateRequestsPaginated(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCertificateRequestsPaginated", reflect.TypeOf((*MockStorageProvider)(nil).GetCertificateRequestsPaginated), ctx, params)
 }
 
+// GetDataHistory mocks base method.
+func (m *MockStorageProvider) GetDataHistory(ctx context.Context, queryName, variables string, resolution models.DataHistoryResolution, start, end time.Time) ([]models.DataHistoryPoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataHistory", ctx, queryName, variables, resolution, start, end)
+	ret0, _ := ret[0].([]models.DataHistoryPoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataHistory indicates an expected call of GetDataHistory.
+func (mr *MockStorageProviderMockRecorder) GetDataHistory(ctx, queryName, variables, resolution, start, end any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataHistory", reflect.TypeOf((*MockStorageProvider)(nil).GetDataHistory), ctx, queryName, variables, resolution, start, end)
+}
+
 // GetEncryptionValidation mocks base method.
 func (m *MockStorageProvider) GetEncryptionValidation(ctx context.Context) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -412,6 +665,21 @@ func (mr *MockStorageProviderMockRecorder) GetEncryptionValidation(ctx any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEncryptionValidation", reflect.TypeOf((*MockStorageProvider)(nil).GetEncryptionValidation), ctx)
 }
 
+// GetFirewallSyncPlans mocks base method.
+func (m *MockStorageProvider) GetFirewallSyncPlans(ctx context.Context, aliasUUID string, limit int) ([]*models.FirewallSyncPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirewallSyncPlans", ctx, aliasUUID, limit)
+	ret0, _ := ret[0].([]*models.FirewallSyncPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirewallSyncPlans indicates an expected call of GetFirewallSyncPlans.
+func (mr *MockStorageProviderMockRecorder) GetFirewallSyncPlans(ctx, aliasUUID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirewallSyncPlans", reflect.TypeOf((*MockStorageProvider)(nil).GetFirewallSyncPlans), ctx, aliasUUID, limit)
+}
+
 // GetIssuedCertificateByIdentifier mocks base method.
 func (m *MockStorageProvider) GetIssuedCertificateByIdentifier(ctx context.Context, identifier string) ([]byte, []byte, []byte, error) {
 	m.ctrl.T.Helper()
@@ -429,6 +697,36 @@ func (mr *MockStorageProviderMockRecorder) GetIssuedCertificateByIdentifier(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIssuedCertificateByIdentifier", reflect.TypeOf((*MockStorageProvider)(nil).GetIssuedCertificateByIdentifier), ctx, identifier)
 }
 
+// GetManagedAliasByID mocks base method.
+func (m *MockStorageProvider) GetManagedAliasByID(ctx context.Context, id int) (*models.FirewallManagedAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManagedAliasByID", ctx, id)
+	ret0, _ := ret[0].(*models.FirewallManagedAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManagedAliasByID indicates an expected call of GetManagedAliasByID.
+func (mr *MockStorageProviderMockRecorder) GetManagedAliasByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManagedAliasByID", reflect.TypeOf((*MockStorageProvider)(nil).GetManagedAliasByID), ctx, id)
+}
+
+// GetManagedAliases mocks base method.
+func (m *MockStorageProvider) GetManagedAliases(ctx context.Context) ([]*models.FirewallManagedAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManagedAliases", ctx)
+	ret0, _ := ret[0].([]*models.FirewallManagedAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManagedAliases indicates an expected call of GetManagedAliases.
+func (mr *MockStorageProviderMockRecorder) GetManagedAliases(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManagedAliases", reflect.TypeOf((*MockStorageProvider)(nil).GetManagedAliases), ctx)
+}
+
 // GetPendingCertificateRequests mocks base method.
 func (m *MockStorageProvider) GetPendingCertificateRequests(ctx context.Context) ([]*models.CertificateRequest, error) {
 	m.ctrl.T.Helper()
@@ -488,6 +786,21 @@ func (mr *MockStorageProviderMockRecorder) GetRecentCertificateDownloadLogs(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentCertificateDownloadLogs", reflect.TypeOf((*MockStorageProvider)(nil).GetRecentCertificateDownloadLogs), ctx, limit)
 }
 
+// GetScheduledWhitelistEntries mocks base method.
+func (m *MockStorageProvider) GetScheduledWhitelistEntries(ctx context.Context) ([]*models.FirewallIPWhitelistEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledWhitelistEntries", ctx)
+	ret0, _ := ret[0].([]*models.FirewallIPWhitelistEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledWhitelistEntries indicates an expected call of GetScheduledWhitelistEntries.
+func (mr *MockStorageProviderMockRecorder) GetScheduledWhitelistEntries(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledWhitelistEntries", reflect.TypeOf((*MockStorageProvider)(nil).GetScheduledWhitelistEntries), ctx)
+}
+
 // GetServiceAccountByID mocks base method.
 func (m *MockStorageProvider) GetServiceAccountByID(ctx context.Context, iss, sub string) (*models.ServiceAccount, error) {
 	m.ctrl.T.Helper()
@@ -533,6 +846,36 @@ func (mr *MockStorageProviderMockRecorder) GetServiceAccountsByCreator(ctx, iss,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceAccountsByCreator", reflect.TypeOf((*MockStorageProvider)(nil).GetServiceAccountsByCreator), ctx, iss, sub)
 }
 
+// GetStatusIncident mocks base method.
+func (m *MockStorageProvider) GetStatusIncident(ctx context.Context, id int) (*models.StatusIncident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusIncident", ctx, id)
+	ret0, _ := ret[0].(*models.StatusIncident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusIncident indicates an expected call of GetStatusIncident.
+func (mr *MockStorageProviderMockRecorder) GetStatusIncident(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusIncident", reflect.TypeOf((*MockStorageProvider)(nil).GetStatusIncident), ctx, id)
+}
+
+// GetStatusIncidents mocks base method.
+func (m *MockStorageProvider) GetStatusIncidents(ctx context.Context, since time.Time) ([]*models.StatusIncident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusIncidents", ctx, since)
+	ret0, _ := ret[0].([]*models.StatusIncident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusIncidents indicates an expected call of GetStatusIncidents.
+func (mr *MockStorageProviderMockRecorder) GetStatusIncidents(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusIncidents", reflect.TypeOf((*MockStorageProvider)(nil).GetStatusIncidents), ctx, since)
+}
+
 // GetSystemUser mocks base method.
 func (m *MockStorageProvider) GetSystemUser(ctx context.Context) (string, string, error) {
 	m.ctrl.T.Helper()
@@ -609,6 +952,21 @@ func (mr *MockStorageProviderMockRecorder) GetWhitelistEventsByEntry(ctx, whitel
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWhitelistEventsByEntry", reflect.TypeOf((*MockStorageProvider)(nil).GetWhitelistEventsByEntry), ctx, whitelistID)
 }
 
+// ImportManagedAlias mocks base method.
+func (m *MockStorageProvider) ImportManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportManagedAlias", ctx, alias)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportManagedAlias indicates an expected call of ImportManagedAlias.
+func (mr *MockStorageProviderMockRecorder) ImportManagedAlias(ctx, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportManagedAlias", reflect.TypeOf((*MockStorageProvider)(nil).ImportManagedAlias), ctx, alias)
+}
+
 // InsertAuditLogCertificateDownload mocks base method.
 func (m *MockStorageProvider) InsertAuditLogCertificateDownload(ctx context.Context, certId int, sub, iss, ipAddress, rawUserAgent string, userAgent uasurfer.UserAgent) (*models.CertificateDownload, error) {
 	m.ctrl.T.Helper()
@@ -653,18 +1011,32 @@ func (mr *MockStorageProviderMockRecorder) InsertIssuedCertificate(ctx, identifi
 }
 
 // IsIPBlacklisted mocks base method.
-func (m *MockStorageProvider) IsIPBlacklisted(ctx context.Context, aliasName, ipAddress string) (bool, error) {
+func (m *MockStorageProvider) IsIPBlacklisted(ctx context.Context, aliasUUID, ipAddress string, asn *int64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsIPBlacklisted", ctx, aliasName, ipAddress)
+	ret := m.ctrl.Call(m, "IsIPBlacklisted", ctx, aliasUUID, ipAddress, asn)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // IsIPBlacklisted indicates an expected call of IsIPBlacklisted.
-func (mr *MockStorageProviderMockRecorder) IsIPBlacklisted(ctx, aliasName, ipAddress any) *gomock.Call {
+func (mr *MockStorageProviderMockRecorder) IsIPBlacklisted(ctx, aliasUUID, ipAddress, asn any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIPBlacklisted", reflect.TypeOf((*MockStorageProvider)(nil).IsIPBlacklisted), ctx, aliasName, ipAddress)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIPBlacklisted", reflect.TypeOf((*MockStorageProvider)(nil).IsIPBlacklisted), ctx, aliasUUID, ipAddress, asn)
+}
+
+// ListenFirewallSync mocks base method.
+func (m *MockStorageProvider) ListenFirewallSync(ctx context.Context, handler func(string)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListenFirewallSync", ctx, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenFirewallSync indicates an expected call of ListenFirewallSync.
+func (mr *MockStorageProviderMockRecorder) ListenFirewallSync(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenFirewallSync", reflect.TypeOf((*MockStorageProvider)(nil).ListenFirewallSync), ctx, handler)
 }
 
 // MarkIPsAsAdded mocks base method.
@@ -681,6 +1053,20 @@ func (mr *MockStorageProviderMockRecorder) MarkIPsAsAdded(ctx, ids, systemUserIs
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkIPsAsAdded", reflect.TypeOf((*MockStorageProvider)(nil).MarkIPsAsAdded), ctx, ids, systemUserIss, systemUserSub)
 }
 
+// NotifyFirewallSync mocks base method.
+func (m *MockStorageProvider) NotifyFirewallSync(ctx context.Context, aliasUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyFirewallSync", ctx, aliasUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyFirewallSync indicates an expected call of NotifyFirewallSync.
+func (mr *MockStorageProviderMockRecorder) NotifyFirewallSync(ctx, aliasUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyFirewallSync", reflect.TypeOf((*MockStorageProvider)(nil).NotifyFirewallSync), ctx, aliasUUID)
+}
+
 // PauseServiceAccount mocks base method.
 func (m *MockStorageProvider) PauseServiceAccount(ctx context.Context, iss, sub string) error {
 	m.ctrl.T.Helper()
@@ -709,6 +1095,49 @@ func (mr *MockStorageProviderMockRecorder) Ping(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStorageProvider)(nil).Ping), ctx)
 }
 
+// RecordDataHistory mocks base method.
+func (m *MockStorageProvider) RecordDataHistory(ctx context.Context, samples []models.DataHistorySample) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDataHistory", ctx, samples)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDataHistory indicates an expected call of RecordDataHistory.
+func (mr *MockStorageProviderMockRecorder) RecordDataHistory(ctx, samples any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDataHistory", reflect.TypeOf((*MockStorageProvider)(nil).RecordDataHistory), ctx, samples)
+}
+
+// RemoveAliasEntries mocks base method.
+func (m *MockStorageProvider) RemoveAliasEntries(ctx context.Context, aliasUUID, adminIss, adminSub, reason string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAliasEntries", ctx, aliasUUID, adminIss, adminSub, reason)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveAliasEntries indicates an expected call of RemoveAliasEntries.
+func (mr *MockStorageProviderMockRecorder) RemoveAliasEntries(ctx, aliasUUID, adminIss, adminSub, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAliasEntries", reflect.TypeOf((*MockStorageProvider)(nil).RemoveAliasEntries), ctx, aliasUUID, adminIss, adminSub, reason)
+}
+
+// RemoveBlacklistRule mocks base method.
+func (m *MockStorageProvider) RemoveBlacklistRule(ctx context.Context, id int, removerIss, removerSub string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveBlacklistRule", ctx, id, removerIss, removerSub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveBlacklistRule indicates an expected call of RemoveBlacklistRule.
+func (mr *MockStorageProviderMockRecorder) RemoveBlacklistRule(ctx, id, removerIss, removerSub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveBlacklistRule", reflect.TypeOf((*MockStorageProvider)(nil).RemoveBlacklistRule), ctx, id, removerIss, removerSub)
+}
+
 // RemoveIPFromWhitelist mocks base method.
 func (m *MockStorageProvider) RemoveIPFromWhitelist(ctx context.Context, id int, ownerIss, ownerSub string, clientIP, userAgent *string) error {
 	m.ctrl.T.Helper()
@@ -723,6 +1152,21 @@ func (mr *MockStorageProviderMockRecorder) RemoveIPFromWhitelist(ctx, id, ownerI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveIPFromWhitelist", reflect.TypeOf((*MockStorageProvider)(nil).RemoveIPFromWhitelist), ctx, id, ownerIss, ownerSub, clientIP, userAgent)
 }
 
+// ReplaceFeedBlacklistRules mocks base method.
+func (m *MockStorageProvider) ReplaceFeedBlacklistRules(ctx context.Context, feedName string, aliasUUID *string, rules []*models.FirewallBlacklistRule, creatorIss, creatorSub string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceFeedBlacklistRules", ctx, feedName, aliasUUID, rules, creatorIss, creatorSub)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceFeedBlacklistRules indicates an expected call of ReplaceFeedBlacklistRules.
+func (mr *MockStorageProviderMockRecorder) ReplaceFeedBlacklistRules(ctx, feedName, aliasUUID, rules, creatorIss, creatorSub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceFeedBlacklistRules", reflect.TypeOf((*MockStorageProvider)(nil).ReplaceFeedBlacklistRules), ctx, feedName, aliasUUID, rules, creatorIss, creatorSub)
+}
+
 // RunMigrations mocks base method.
 func (m *MockStorageProvider) RunMigrations(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -737,6 +1181,21 @@ func (mr *MockStorageProviderMockRecorder) RunMigrations(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunMigrations", reflect.TypeOf((*MockStorageProvider)(nil).RunMigrations), ctx)
 }
 
+// SearchWhitelistEvents mocks base method.
+func (m *MockStorageProvider) SearchWhitelistEvents(ctx context.Context, filter models.FirewallEventFilter, params models.PaginationParams) (*models.PaginatedFirewallEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchWhitelistEvents", ctx, filter, params)
+	ret0, _ := ret[0].(*models.PaginatedFirewallEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchWhitelistEvents indicates an expected call of SearchWhitelistEvents.
+func (mr *MockStorageProviderMockRecorder) SearchWhitelistEvents(ctx, filter, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchWhitelistEvents", reflect.TypeOf((*MockStorageProvider)(nil).SearchWhitelistEvents), ctx, filter, params)
+}
+
 // SetEncryptionValidation mocks base method.
 func (m *MockStorageProvider) SetEncryptionValidation(ctx context.Context, validationData []byte) error {
 	m.ctrl.T.Helper()
@@ -751,6 +1210,35 @@ func (mr *MockStorageProviderMockRecorder) SetEncryptionValidation(ctx, validati
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEncryptionValidation", reflect.TypeOf((*MockStorageProvider)(nil).SetEncryptionValidation), ctx, validationData)
 }
 
+// SetWhitelistEntryWindow mocks base method.
+func (m *MockStorageProvider) SetWhitelistEntryWindow(ctx context.Context, id int, open bool, systemUserIss, systemUserSub string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWhitelistEntryWindow", ctx, id, open, systemUserIss, systemUserSub)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWhitelistEntryWindow indicates an expected call of SetWhitelistEntryWindow.
+func (mr *MockStorageProviderMockRecorder) SetWhitelistEntryWindow(ctx, id, open, systemUserIss, systemUserSub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWhitelistEntryWindow", reflect.TypeOf((*MockStorageProvider)(nil).SetWhitelistEntryWindow), ctx, id, open, systemUserIss, systemUserSub)
+}
+
+// StreamWhitelistEvents mocks base method.
+func (m *MockStorageProvider) StreamWhitelistEvents(ctx context.Context, filter models.FirewallEventFilter, fn func(*models.FirewallAuditEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamWhitelistEvents", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamWhitelistEvents indicates an expected call of StreamWhitelistEvents.
+func (mr *MockStorageProviderMockRecorder) StreamWhitelistEvents(ctx, filter, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamWhitelistEvents", reflect.TypeOf((*MockStorageProvider)(nil).StreamWhitelistEvents), ctx, filter, fn)
+}
+
 // UnpauseServiceAccount mocks base method.
 func (m *MockStorageProvider) UnpauseServiceAccount(ctx context.Context, iss, sub string) error {
 	m.ctrl.T.Helper()
@@ -765,6 +1253,20 @@ func (mr *MockStorageProviderMockRecorder) UnpauseServiceAccount(ctx, iss, sub a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpauseServiceAccount", reflect.TypeOf((*MockStorageProvider)(nil).UnpauseServiceAccount), ctx, iss, sub)
 }
 
+// UpdateBlacklistRule mocks base method.
+func (m *MockStorageProvider) UpdateBlacklistRule(ctx context.Context, id int, reason *string, expiresAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBlacklistRule", ctx, id, reason, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBlacklistRule indicates an expected call of UpdateBlacklistRule.
+func (mr *MockStorageProviderMockRecorder) UpdateBlacklistRule(ctx, id, reason, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBlacklistRule", reflect.TypeOf((*MockStorageProvider)(nil).UpdateBlacklistRule), ctx, id, reason, expiresAt)
+}
+
 // UpdateCertificateMetadata mocks base method.
 func (m *MockStorageProvider) UpdateCertificateMetadata(ctx context.Context, requestID int, identifier string, metadata map[string]any) error {
 	m.ctrl.T.Helper()
@@ -807,6 +1309,48 @@ func (mr *MockStorageProviderMockRecorder) UpdateCertificateRequestStatus(ctx, r
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCertificateRequestStatus", reflect.TypeOf((*MockStorageProvider)(nil).UpdateCertificateRequestStatus), ctx, requestId, newStatus, reviewerIss, reviewerSub, notes)
 }
 
+// UpdateDataAlert mocks base method.
+func (m *MockStorageProvider) UpdateDataAlert(ctx context.Context, alert *models.DataAlert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDataAlert", ctx, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDataAlert indicates an expected call of UpdateDataAlert.
+func (mr *MockStorageProviderMockRecorder) UpdateDataAlert(ctx, alert any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDataAlert", reflect.TypeOf((*MockStorageProvider)(nil).UpdateDataAlert), ctx, alert)
+}
+
+// UpdateManagedAlias mocks base method.
+func (m *MockStorageProvider) UpdateManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateManagedAlias", ctx, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateManagedAlias indicates an expected call of UpdateManagedAlias.
+func (mr *MockStorageProviderMockRecorder) UpdateManagedAlias(ctx, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateManagedAlias", reflect.TypeOf((*MockStorageProvider)(nil).UpdateManagedAlias), ctx, alias)
+}
+
+// UpdateStatusIncident mocks base method.
+func (m *MockStorageProvider) UpdateStatusIncident(ctx context.Context, incident *models.StatusIncident) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatusIncident", ctx, incident)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatusIncident indicates an expected call of UpdateStatusIncident.
+func (mr *MockStorageProviderMockRecorder) UpdateStatusIncident(ctx, incident any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusIncident", reflect.TypeOf((*MockStorageProvider)(nil).UpdateStatusIncident), ctx, incident)
+}
+
 // UpsertUser mocks base method.
 func (m *MockStorageProvider) UpsertUser(ctx context.Context, sub, iss, username, displayName, email string, groups []string) (*models.User, error) {
 	m.ctrl.T.Helper()
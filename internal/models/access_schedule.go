@@ -0,0 +1,152 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxScheduleWindow bounds cron-based windows; anything longer is better expressed as a plain expiry.
+const maxScheduleWindow = 7 * 24 * time.Hour
+
+// AccessSchedule describes the recurring windows in which a whitelist entry is applied to the router.
+// Either Cron+Duration (a window opens at every cron activation and stays open for Duration) or
+// Weekdays+Start+End (a daily window on the listed days; End before Start spans midnight) must be set.
+type AccessSchedule struct {
+	Timezone string `json:"timezone,omitempty"` // IANA name, defaults to UTC
+
+	Cron     string `json:"cron,omitempty"`     // standard 5-field expression or descriptor such as @daily
+	Duration string `json:"duration,omitempty"` // Go duration, e.g. "2h30m"
+
+	Weekdays []string `json:"weekdays,omitempty"` // mon, tue, ... (full names accepted)
+	Start    string   `json:"start,omitempty"`    // HH:MM
+	End      string   `json:"end,omitempty"`      // HH:MM
+}
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Validate checks the schedule is well-formed and fills in the default timezone.
+func (s *AccessSchedule) Validate() error {
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", s.Timezone)
+	}
+
+	hasCron := s.Cron != ""
+	hasWeekly := len(s.Weekdays) > 0 || s.Start != "" || s.End != ""
+	if hasCron == hasWeekly {
+		return fmt.Errorf("schedule must set either cron and duration, or weekdays, start and end")
+	}
+
+	if hasCron {
+		if _, err := cron.ParseStandard(s.Cron); err != nil {
+			return fmt.Errorf("invalid cron expression: %w", err)
+		}
+		duration, err := time.ParseDuration(s.Duration)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("schedule duration must be a positive duration such as \"2h\"")
+		}
+		if duration > maxScheduleWindow {
+			return fmt.Errorf("schedule duration cannot exceed %s", maxScheduleWindow)
+		}
+		return nil
+	}
+
+	if len(s.Weekdays) == 0 {
+		return fmt.Errorf("schedule weekdays are required")
+	}
+	for i, day := range s.Weekdays {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if _, ok := scheduleWeekdays[day]; !ok {
+			return fmt.Errorf("invalid weekday %q", s.Weekdays[i])
+		}
+		s.Weekdays[i] = day
+	}
+
+	start, err := parseClock(s.Start)
+	if err != nil {
+		return fmt.Errorf("invalid schedule start: %w", err)
+	}
+	end, err := parseClock(s.End)
+	if err != nil {
+		return fmt.Errorf("invalid schedule end: %w", err)
+	}
+	if start == end {
+		return fmt.Errorf("schedule start and end cannot be equal")
+	}
+
+	return nil
+}
+
+// IsOpen reports whether now falls inside one of the schedule's windows.
+// The schedule is assumed to have passed Validate; malformed schedules are treated as closed.
+func (s *AccessSchedule) IsOpen(now time.Time) bool {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return false
+	}
+	now = now.In(loc)
+
+	if s.Cron != "" {
+		schedule, err := cron.ParseStandard(s.Cron)
+		if err != nil {
+			return false
+		}
+		duration, err := time.ParseDuration(s.Duration)
+		if err != nil {
+			return false
+		}
+		// Open when the schedule fired within the last Duration, i.e. in (now-duration, now].
+		return !schedule.Next(now.Add(-duration)).After(now)
+	}
+
+	start, err := parseClock(s.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(s.End)
+	if err != nil {
+		return false
+	}
+
+	clock := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute + time.Duration(now.Second())*time.Second
+	today := now.Weekday()
+	yesterday := (today + 6) % 7
+
+	if start < end {
+		return s.hasWeekday(today) && clock >= start && clock < end
+	}
+
+	// Window spans midnight: opens on a listed day and closes the following morning.
+	return (s.hasWeekday(today) && clock >= start) || (s.hasWeekday(yesterday) && clock < end)
+}
+
+func (s *AccessSchedule) hasWeekday(day time.Weekday) bool {
+	for _, name := range s.Weekdays {
+		if d, ok := scheduleWeekdays[strings.ToLower(name)]; ok && d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses "HH:MM" into an offset from midnight.
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not in HH:MM format", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
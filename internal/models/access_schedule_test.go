@@ -0,0 +1,124 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    AccessSchedule
+		expectError bool
+	}{
+		{
+			name:     "cron window",
+			schedule: AccessSchedule{Cron: "0 22 * * 6", Duration: "4h"},
+		},
+		{
+			name:     "weekday window",
+			schedule: AccessSchedule{Timezone: "Europe/Berlin", Weekdays: []string{"Mon", "friday"}, Start: "09:00", End: "17:30"},
+		},
+		{
+			name:        "both modes",
+			schedule:    AccessSchedule{Cron: "@daily", Duration: "1h", Weekdays: []string{"mon"}, Start: "09:00", End: "10:00"},
+			expectError: true,
+		},
+		{
+			name:        "neither mode",
+			schedule:    AccessSchedule{Timezone: "UTC"},
+			expectError: true,
+		},
+		{
+			name:        "bad timezone",
+			schedule:    AccessSchedule{Timezone: "Mars/Olympus", Cron: "@daily", Duration: "1h"},
+			expectError: true,
+		},
+		{
+			name:        "bad cron",
+			schedule:    AccessSchedule{Cron: "every day", Duration: "1h"},
+			expectError: true,
+		},
+		{
+			name:        "cron without duration",
+			schedule:    AccessSchedule{Cron: "@daily"},
+			expectError: true,
+		},
+		{
+			name:        "cron window longer than a week",
+			schedule:    AccessSchedule{Cron: "@monthly", Duration: "200h"},
+			expectError: true,
+		},
+		{
+			name:        "unknown weekday",
+			schedule:    AccessSchedule{Weekdays: []string{"someday"}, Start: "09:00", End: "17:00"},
+			expectError: true,
+		},
+		{
+			name:        "bad clock",
+			schedule:    AccessSchedule{Weekdays: []string{"mon"}, Start: "9am", End: "17:00"},
+			expectError: true,
+		},
+		{
+			name:        "empty window",
+			schedule:    AccessSchedule{Weekdays: []string{"mon"}, Start: "09:00", End: "09:00"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, tt.schedule.Timezone)
+		})
+	}
+}
+
+func TestAccessSchedule_IsOpen(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 2024-06-03 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.June, day, hour, minute, 0, 0, berlin)
+	}
+
+	workingHours := AccessSchedule{Timezone: "Europe/Berlin", Weekdays: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}
+	overnight := AccessSchedule{Timezone: "Europe/Berlin", Weekdays: []string{"sat"}, Start: "22:00", End: "02:00"}
+	weeklyCron := AccessSchedule{Timezone: "Europe/Berlin", Cron: "0 22 * * 6", Duration: "4h"}
+
+	tests := []struct {
+		name     string
+		schedule AccessSchedule
+		now      time.Time
+		expected bool
+	}{
+		{"inside working hours", workingHours, at(3, 10, 0), true},
+		{"start is inclusive", workingHours, at(3, 9, 0), true},
+		{"end is exclusive", workingHours, at(3, 17, 0), false},
+		{"weekend", workingHours, at(8, 10, 0), false},
+		{"evaluated in schedule timezone", workingHours, time.Date(2024, time.June, 3, 7, 30, 0, 0, time.UTC), true},
+		{"overnight before midnight", overnight, at(8, 23, 0), true},
+		{"overnight after midnight", overnight, at(9, 1, 0), true},
+		{"overnight closed next morning", overnight, at(9, 3, 0), false},
+		{"overnight not opened on other days", overnight, at(7, 23, 0), false},
+		{"cron window open", weeklyCron, at(9, 1, 30), true},
+		{"cron window at activation", weeklyCron, at(8, 22, 0), true},
+		{"cron window closed", weeklyCron, at(9, 2, 0), false},
+		{"cron window not yet open", weeklyCron, at(8, 21, 59), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.schedule.IsOpen(tt.now))
+		})
+	}
+}
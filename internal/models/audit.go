@@ -19,3 +19,36 @@ type CertificateDownload struct {
 	DeviceType           string
 	DownloadedAt         time.Time
 }
+
+// FirewallEventTypes lists every event type recorded in firewall_whitelist_events.
+var FirewallEventTypes = []string{
+	"requested", "added", "removed", "removed_by_admin", "blacklisted_by_admin", "expired", "sync_failed",
+	"window_opened", "window_closed", "extended", "abuse_suspected",
+}
+
+// FirewallEventFilter narrows a search over firewall whitelist events. Zero values match everything.
+type FirewallEventFilter struct {
+	Actor      string        // actor username or sub
+	EventTypes []string      // any of these types
+	ClientNet  *netip.Prefix // client IP within this network; a single address is a /32 or /128
+	AliasUUID  string
+	Since      *time.Time // inclusive
+	Until      *time.Time // exclusive
+}
+
+// FirewallAuditEvent is a whitelist event together with the entry it belongs to.
+type FirewallAuditEvent struct {
+	FirewallIPWhitelistEvent
+	AliasName string `json:"alias_name"`
+	AliasUUID string `json:"alias_uuid"`
+	IPAddress string `json:"ip_address"`
+}
+
+// PaginatedFirewallEvents holds one page of a firewall event search, newest first.
+type PaginatedFirewallEvents struct {
+	Events  []*FirewallAuditEvent `json:"events"`
+	Total   int                   `json:"total"`
+	Limit   int                   `json:"limit"`
+	Offset  int                   `json:"offset"`
+	HasMore bool                  `json:"has_more"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// DataAlertState is where an alert is in its lifecycle. An alert is pending while its rule's condition holds
+// for less than the rule's for duration, then fires until the condition stops holding.
+type DataAlertState string
+
+const (
+	DataAlertPending  DataAlertState = "pending"
+	DataAlertFiring   DataAlertState = "firing"
+	DataAlertResolved DataAlertState = "resolved"
+)
+
+// DataAlert is an alert raised by an alert rule for one series of a query result.
+type DataAlert struct {
+	ID       int               `json:"id"`
+	RuleName string            `json:"rule_name"`
+	QueryKey string            `json:"query_key"` // cache key of the evaluated result, e.g. "pods?namespace=media"
+	Series   string            `json:"series"`    // the series' labels in their canonical form
+	Labels   map[string]string `json:"labels"`
+	Severity string            `json:"severity"`
+
+	State DataAlertState `json:"state"`
+	Value float64        `json:"value"` // most recent value the rule was evaluated against
+
+	ActiveSince     time.Time  `json:"active_since"`
+	FiredAt         *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	LastEvaluatedAt time.Time  `json:"last_evaluated_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// DataHistoryResolution is the bucket width of one rollup of a query's recorded history.
+type DataHistoryResolution string
+
+const (
+	DataHistoryFiveMinutes DataHistoryResolution = "5m"
+	DataHistoryHourly      DataHistoryResolution = "1h"
+	DataHistoryDaily       DataHistoryResolution = "1d"
+)
+
+// DataHistoryResolutions lists every rollup, finest first.
+var DataHistoryResolutions = []DataHistoryResolution{DataHistoryFiveMinutes, DataHistoryHourly, DataHistoryDaily}
+
+// Duration returns the width of the resolution's buckets, or 0 for an unknown resolution.
+func (r DataHistoryResolution) Duration() time.Duration {
+	switch r {
+	case DataHistoryFiveMinutes:
+		return 5 * time.Minute
+	case DataHistoryHourly:
+		return time.Hour
+	case DataHistoryDaily:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Bucket returns the start of the bucket t falls into. Buckets are aligned to UTC.
+func (r DataHistoryResolution) Bucket(t time.Time) time.Time {
+	return t.UTC().Truncate(r.Duration())
+}
+
+// DataHistorySample is the value of one series of a query result at the time it was recorded.
+type DataHistorySample struct {
+	QueryName  string
+	Variables  string // encoded selection of a templated query, e.g. "namespace=media", empty otherwise
+	Series     string // the series' labels in their canonical form, e.g. {instance="nas:9100"}
+	Labels     map[string]string
+	Value      float64
+	RecordedAt time.Time
+}
+
+// DataHistoryPoint is one bucket of a rollup of a series' recorded values.
+type DataHistoryPoint struct {
+	Series      string
+	Labels      map[string]string
+	Bucket      time.Time
+	SampleCount int
+	Avg         float64
+	Min         float64
+	Max         float64
+	Last        float64
+}
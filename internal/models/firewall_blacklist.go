@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type FirewallBlacklistRule struct {
+	ID int `json:"id"`
+
+	AliasUUID *string `json:"alias_uuid,omitempty"` // nil = applies to every alias
+
+	Value      string  `json:"value"`
+	RangeStart *string `json:"range_start,omitempty"`
+	RangeEnd   *string `json:"range_end,omitempty"`
+	ASN        *int64  `json:"asn,omitempty"`
+
+	Source   FirewallBlacklistSource `json:"source"`
+	FeedName *string                 `json:"feed_name,omitempty"`
+	Reason   *string                 `json:"reason,omitempty"`
+
+	CreatedByIss string     `json:"created_by_iss"`
+	CreatedBySub string     `json:"created_by_sub"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+
+	RemovedAt    *time.Time `json:"removed_at,omitempty"`
+	RemovedByIss *string    `json:"removed_by_iss,omitempty"`
+	RemovedBySub *string    `json:"removed_by_sub,omitempty"`
+}
+
+// IsActive reports whether the rule has neither been lifted nor expired at the given time.
+func (r *FirewallBlacklistRule) IsActive(now time.Time) bool {
+	if r.RemovedAt != nil {
+		return false
+	}
+	return r.ExpiresAt == nil || r.ExpiresAt.After(now)
+}
+
+type FirewallBlacklistSource string
+
+const (
+	BlacklistSourceManual FirewallBlacklistSource = "manual"
+	BlacklistSourceEntry  FirewallBlacklistSource = "entry"
+	BlacklistSourceFeed   FirewallBlacklistSource = "feed"
+)
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// FirewallManagedAlias is a router alias an admin has enabled for self-service whitelisting at runtime.
+// It carries the same settings as an alias in features.firewall_management.aliases.
+type FirewallManagedAlias struct {
+	ID int `json:"id"`
+
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	MaxIPsPerUser     int    `json:"max_ips_per_user"`
+	MaxTotalIPs       int    `json:"max_total_ips"`
+	DefaultTTLSeconds *int64 `json:"default_ttl_seconds,omitempty"` // nil = no expiration
+	AuthGroup         string `json:"auth_group"`
+	DryRun            bool   `json:"dry_run"`
+
+	// Set when the alias manages a Traefik Middleware's ipAllowList instead of an OPNsense alias
+	TraefikNamespace string `json:"traefik_namespace,omitempty"`
+	TraefikName      string `json:"traefik_name,omitempty"`
+
+	MaxLifetimeSeconds *int64 `json:"max_lifetime_seconds,omitempty"` // nil = no lifetime cap
+	MaxExtensions      int    `json:"max_extensions"`
+
+	AllowedCountries []string `json:"allowed_countries"`
+	DeniedCountries  []string `json:"denied_countries"`
+	AllowedASNs      []int64  `json:"allowed_asns"`
+	DeniedASNs       []int64  `json:"denied_asns"`
+
+	CreatedByIss string    `json:"created_by_iss"`
+	CreatedBySub string    `json:"created_by_sub"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
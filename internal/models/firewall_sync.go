@@ -0,0 +1,48 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// FirewallSyncPlan is the set of changes a firewall sync computed for a single alias.
+type FirewallSyncPlan struct {
+	ID        int    `json:"id"`
+	AliasName string `json:"alias_name"`
+	AliasUUID string `json:"alias_uuid"`
+
+	DryRun bool `json:"dry_run"`
+
+	IPsToAdd        []string `json:"ips_to_add"`
+	IPsToRemove     []string `json:"ips_to_remove"`
+	PendingEntryIDs []int    `json:"pending_entry_ids"`
+
+	Status FirewallSyncPlanStatus `json:"status"`
+	Error  *string                `json:"error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FirewallSyncPlanStatus string
+
+const (
+	SyncPlanPlanned FirewallSyncPlanStatus = "planned"
+	SyncPlanApplied FirewallSyncPlanStatus = "applied"
+	SyncPlanFailed  FirewallSyncPlanStatus = "failed"
+)
+
+// HasChanges reports whether applying the plan would modify the alias on the router.
+func (p *FirewallSyncPlan) HasChanges() bool {
+	return len(p.IPsToAdd) > 0 || len(p.IPsToRemove) > 0
+}
+
+// SameChanges reports whether two plans would make identical changes to the alias.
+func (p *FirewallSyncPlan) SameChanges(other *FirewallSyncPlan) bool {
+	if other == nil {
+		return false
+	}
+
+	return slices.Equal(p.IPsToAdd, other.IPsToAdd) &&
+		slices.Equal(p.IPsToRemove, other.IPsToRemove) &&
+		slices.Equal(p.PendingEntryIDs, other.PendingEntryIDs)
+}
@@ -19,18 +19,26 @@ type FirewallIPWhitelistEntry struct {
 
 	Description string `json:"description"`
 
-	Status FirewallIPWhitelistStatus `json:"status"`
+	IPEnrichment
+
+	Status   FirewallIPWhitelistStatus `json:"status"`
+	Schedule *AccessSchedule           `json:"schedule,omitempty"` // nil = applied continuously
 
 	RequestedAt time.Time  `json:"requested_at"`
 	AddedAt     *time.Time `json:"added_at,omitempty"`
 	RemovedAt   *time.Time `json:"removed_at,omitempty"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 
+	ExtensionCount int `json:"extension_count"` // times the owner has extended ExpiresAt
+
 	RemovedByIss  *string `json:"removed_by_iss,omitempty"`
 	RemovedBySub  *string `json:"removed_by_sub,omitempty"`
 	RemovalReason *string `json:"removal_reason,omitempty"`
 	
 	Events []FirewallIPWhitelistEvent `json:"events"`
+
+	// EstimatedApplyAt is set in API responses after a change that is waiting for a firewall sync; it is not stored.
+	EstimatedApplyAt *time.Time `json:"estimated_apply_at,omitempty"`
 }
 
 type FirewallIPWhitelistEvent struct {
@@ -48,14 +56,26 @@ type FirewallIPWhitelistEvent struct {
 	ClientIP  *string `json:"client_ip,omitempty"`
 	UserAgent *string `json:"user_agent,omitempty"`
 
+	ClientCountryCode    *string `json:"client_country_code,omitempty"`
+	ClientASN            *int64  `json:"client_asn,omitempty"`
+	ClientASOrganization *string `json:"client_as_organization,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// IPEnrichment holds the GeoIP details known about an address; fields are nil when no database covers them.
+type IPEnrichment struct {
+	CountryCode    *string `json:"country_code,omitempty"`
+	ASN            *int64  `json:"asn,omitempty"`
+	ASOrganization *string `json:"as_organization,omitempty"`
+}
+
 type FirewallIPWhitelistStatus string
 
 const (
 	StatusAdded              FirewallIPWhitelistStatus = "added"
 	StatusRequested          FirewallIPWhitelistStatus = "requested"
+	StatusScheduled          FirewallIPWhitelistStatus = "scheduled" // active, but outside its access window
 	StatusRemoved            FirewallIPWhitelistStatus = "removed"
 	StatusRemovedByAdmin     FirewallIPWhitelistStatus = "removed_by_admin"
 	StatusBlacklistedByAdmin FirewallIPWhitelistStatus = "blacklisted_by_admin"
@@ -3,22 +3,30 @@ package models
 import (
 	"homelab-dashboard/internal/config"
 	"slices"
+	"strings"
 	"time"
 )
 
 type ServiceAccount struct {
-	Sub            string     `json:"sub"`
-	Iss            string     `json:"iss"`
-	Name           string     `json:"name"`
-	LookupId       string     `json:"lookup_id"`
-	SecretHash     string     `json:"secret_hash"`
-	TokenExpiresAt time.Time  `json:"token_expires_at"`
-	Scopes         []string   `json:"scopes"`
-	IsDisabled     bool       `json:"is_disabled"`
-	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
-	CreatedBySub   string     `json:"created_by_sub"`
-	CreatedByIss   string     `json:"created_by_iss"`
-	CreatedAt      time.Time  `json:"created_at"`
+	Sub            string               `json:"sub"`
+	Iss            string               `json:"iss"`
+	Name           string               `json:"name"`
+	LookupId       string               `json:"lookup_id"`
+	SecretHash     string               `json:"secret_hash"`
+	TokenExpiresAt time.Time            `json:"token_expires_at"`
+	Scopes         []string             `json:"scopes"`
+	FirewallGrants []FirewallAliasGrant `json:"firewall_grants"`
+	IsDisabled     bool                 `json:"is_disabled"`
+	DeletedAt      *time.Time           `json:"deleted_at,omitempty"`
+	CreatedBySub   string               `json:"created_by_sub"`
+	CreatedByIss   string               `json:"created_by_iss"`
+	CreatedAt      time.Time            `json:"created_at"`
+}
+
+// FirewallAliasGrant lets a service account use one firewall alias as if it were a member of AuthGroup.
+type FirewallAliasGrant struct {
+	AliasUUID string `json:"alias_uuid"`
+	AuthGroup string `json:"auth_group"`
 }
 
 func (s ServiceAccount) GetIss() string {
@@ -41,10 +49,18 @@ func (s ServiceAccount) GetEmail() string {
 	return ""
 }
 
+// GetGroups is always empty; firewall access is granted per alias through FirewallGrants instead.
 func (s ServiceAccount) GetGroups() []string {
 	return []string{}
 }
 
+// HasFirewallGrant reports whether the account was granted the alias as offered to authGroup.
+func (s ServiceAccount) HasFirewallGrant(aliasUUID, authGroup string) bool {
+	return slices.ContainsFunc(s.FirewallGrants, func(grant FirewallAliasGrant) bool {
+		return strings.EqualFold(grant.AliasUUID, aliasUUID) && grant.AuthGroup == authGroup
+	})
+}
+
 func (s ServiceAccount) GetScopes(cfg *config.Config) []string {
 	return s.Scopes
 }
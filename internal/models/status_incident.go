@@ -0,0 +1,113 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// StatusIncidentKind tells unplanned incidents apart from scheduled maintenance.
+type StatusIncidentKind string
+
+const (
+	StatusIncidentKindIncident    StatusIncidentKind = "incident"
+	StatusIncidentKindMaintenance StatusIncidentKind = "maintenance"
+)
+
+// StatusIncidentState is where an incident or maintenance window stands. Incidents go from investigating to
+// resolved, maintenance from scheduled to completed.
+type StatusIncidentState string
+
+const (
+	StatusIncidentInvestigating StatusIncidentState = "investigating"
+	StatusIncidentIdentified    StatusIncidentState = "identified"
+	StatusIncidentMonitoring    StatusIncidentState = "monitoring"
+	StatusIncidentResolved      StatusIncidentState = "resolved"
+
+	StatusMaintenanceScheduled  StatusIncidentState = "scheduled"
+	StatusMaintenanceInProgress StatusIncidentState = "in_progress"
+	StatusMaintenanceCompleted  StatusIncidentState = "completed"
+)
+
+// ValidStates returns the states an incident of the kind can be in.
+func (k StatusIncidentKind) ValidStates() []StatusIncidentState {
+	switch k {
+	case StatusIncidentKindIncident:
+		return []StatusIncidentState{StatusIncidentInvestigating, StatusIncidentIdentified, StatusIncidentMonitoring, StatusIncidentResolved}
+	case StatusIncidentKindMaintenance:
+		return []StatusIncidentState{StatusMaintenanceScheduled, StatusMaintenanceInProgress, StatusMaintenanceCompleted}
+	default:
+		return nil
+	}
+}
+
+// IsClosed reports whether the state ends an incident or maintenance window.
+func (s StatusIncidentState) IsClosed() bool {
+	return s == StatusIncidentResolved || s == StatusMaintenanceCompleted
+}
+
+// StatusIncidentImpact is how badly an incident affects its components.
+type StatusIncidentImpact string
+
+const (
+	StatusImpactNone     StatusIncidentImpact = "none"
+	StatusImpactMinor    StatusIncidentImpact = "minor"
+	StatusImpactMajor    StatusIncidentImpact = "major"
+	StatusImpactCritical StatusIncidentImpact = "critical"
+)
+
+// StatusIncidentImpacts lists every impact, least severe first.
+var StatusIncidentImpacts = []StatusIncidentImpact{StatusImpactNone, StatusImpactMinor, StatusImpactMajor, StatusImpactCritical}
+
+// StatusIncident is an incident or maintenance window on the status page, with the updates posted to it.
+type StatusIncident struct {
+	ID         int                  `json:"id"`
+	Kind       StatusIncidentKind   `json:"kind"`
+	Title      string               `json:"title"`
+	Impact     StatusIncidentImpact `json:"impact"`
+	State      StatusIncidentState  `json:"state"`
+	Components []string             `json:"components"` // names of the affected status page components
+
+	ScheduledStart *time.Time `json:"scheduled_start,omitempty"` // maintenance only
+	ScheduledEnd   *time.Time `json:"scheduled_end,omitempty"`
+
+	CreatedByIss string     `json:"-"`
+	CreatedBySub string     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+
+	Updates []StatusIncidentUpdate `json:"updates"` // newest first
+}
+
+// IsOpen reports whether the incident has not been resolved or completed.
+func (i *StatusIncident) IsOpen() bool {
+	return !i.State.IsClosed()
+}
+
+// InMaintenance reports whether a maintenance window is under way: started by hand, or within its schedule
+// and not completed early.
+func (i *StatusIncident) InMaintenance(now time.Time) bool {
+	if i.Kind != StatusIncidentKindMaintenance || !i.IsOpen() {
+		return false
+	}
+	if i.State == StatusMaintenanceInProgress {
+		return true
+	}
+	return i.ScheduledStart != nil && i.ScheduledEnd != nil && !now.Before(*i.ScheduledStart) && now.Before(*i.ScheduledEnd)
+}
+
+// Affects reports whether the incident affects the named component.
+func (i *StatusIncident) Affects(component string) bool {
+	return slices.Contains(i.Components, component)
+}
+
+// StatusIncidentUpdate is a message posted to an incident, along with the state it moved the incident to.
+type StatusIncidentUpdate struct {
+	ID           int                 `json:"id"`
+	IncidentID   int                 `json:"-"`
+	State        StatusIncidentState `json:"state"`
+	Message      string              `json:"message"`
+	CreatedByIss string              `json:"-"`
+	CreatedBySub string              `json:"-"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
@@ -21,7 +21,8 @@ func setupRouter(ctx *middlewares.AppContext) *chi.Mux {
 	//r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middlewares.MetricsMiddleware)
-	r.Use(middleware.Timeout(60 * time.Second))
+	// The event export streams its file after writing 200, a timeout there would silently truncate the download
+	r.Use(middlewares.SkipForEventStreams(middleware.Timeout(60*time.Second), "/api/data/stream", "/api/firewall/events/export"))
 
 	r.Use(ctx.SessionManager.LoadAndSave)
 
@@ -95,22 +96,63 @@ func setupRouter(ctx *middlewares.AppContext) *chi.Mux {
 			})
 		}
 
-		//firewall management does not support service accounts until groups have been implemented for service accounts
+		// Service accounts reach firewall aliases through the grants they were created with.
 		if ctx.Config.Storage.Enabled && ctx.Config.Features.FirewallManagement.Enabled {
 			r.Route("/firewall", func(r chi.Router) {
 				r.Group(func(r chi.Router) {
-					r.Use(middlewares.RequireCookieAuth)
+					r.Use(middlewares.RequireAuth)
 					r.Get("/aliases", ctx.HandlerFunc(handlers.GETAvailableAliases))
 					r.Get("/entries", ctx.HandlerFunc(handlers.GETUserEntries))
 					r.Post("/entries", ctx.HandlerFunc(handlers.POSTAddIPEntry))
 					r.Delete("/entries/{id}", ctx.HandlerFunc(handlers.DELETERemoveIPEntry))
+					r.Patch("/entries/{id}/extend", ctx.HandlerFunc(handlers.PATCHExtendIPEntry))
 					r.Delete("/entries/{id}/blacklist", ctx.HandlerFunc(handlers.DELETEBlacklistIPEntry))
+					r.Get("/aliases/{uuid}/preview", ctx.HandlerFunc(handlers.GETFirewallSyncPreview))
+					r.Get("/aliases/{uuid}/plans", ctx.HandlerFunc(handlers.GETFirewallSyncPlans))
+					r.Get("/events", ctx.HandlerFunc(handlers.GETFirewallEvents))
+					r.Get("/events/export", ctx.HandlerFunc(handlers.GETFirewallEventsExport))
+					r.Get("/blacklist", ctx.HandlerFunc(handlers.GETBlacklistRules))
+					r.Post("/blacklist", ctx.HandlerFunc(handlers.POSTBlacklistRule))
+					r.Get("/blacklist/{id}", ctx.HandlerFunc(handlers.GETBlacklistRule))
+					r.Patch("/blacklist/{id}", ctx.HandlerFunc(handlers.PATCHBlacklistRule))
+					r.Delete("/blacklist/{id}", ctx.HandlerFunc(handlers.DELETEBlacklistRule))
+					r.Get("/router/aliases", ctx.HandlerFunc(handlers.GETRouterAliases))
+					r.Get("/managed-aliases", ctx.HandlerFunc(handlers.GETManagedAliases))
+					r.Post("/managed-aliases", ctx.HandlerFunc(handlers.POSTManagedAlias))
+					r.Put("/managed-aliases/{id}", ctx.HandlerFunc(handlers.PUTManagedAlias))
+					r.Delete("/managed-aliases/{id}", ctx.HandlerFunc(handlers.DELETEManagedAlias))
+				})
+			})
+		}
+
+		// The status page is public, posting incidents and maintenance requires status:write
+		if ctx.Config.Storage.Enabled && ctx.Config.Features.StatusPage.Enabled {
+			r.Route("/status", func(r chi.Router) {
+				r.Get("/", ctx.HandlerFunc(handlers.GETStatusPage))
+				r.Get("/feed", ctx.HandlerFunc(handlers.GETStatusFeed))
+				r.Get("/incidents/{id}", ctx.HandlerFunc(handlers.GETStatusIncident))
+
+				r.Group(func(r chi.Router) {
+					r.Use(middlewares.RequireAuth)
+					r.Post("/incidents", ctx.HandlerFunc(handlers.POSTStatusIncident))
+					r.Patch("/incidents/{id}", ctx.HandlerFunc(handlers.PATCHStatusIncident))
+					r.Post("/incidents/{id}/updates", ctx.HandlerFunc(handlers.POSTStatusIncidentUpdate))
+					r.Delete("/incidents/{id}", ctx.HandlerFunc(handlers.DELETEStatusIncident))
 				})
 			})
 		}
 
 		r.Get("/queries", ctx.HandlerFunc(handlers.GetQueriesGET))
 		r.Get("/data", ctx.HandlerFunc(handlers.GetMetricsGET))
+		r.Get("/data/stream", ctx.HandlerFunc(handlers.GetDataStreamGET))
+		r.Get("/data/status", ctx.HandlerFunc(handlers.GetDataStatusGET))
+		r.Get("/data/variables", ctx.HandlerFunc(handlers.GetDataVariablesGET))
+		r.Get("/data/{query}/history", ctx.HandlerFunc(handlers.GetDataHistoryGET))
+		r.Get("/alerts", ctx.HandlerFunc(handlers.GetAlertsGET))
+		r.Group(func(r chi.Router) {
+			r.Use(middlewares.RequireAuth)
+			r.Get("/data/{query}/range", ctx.HandlerFunc(handlers.GetDataRangeGET))
+		})
 
 		r.Route("/v1", func(r chi.Router) {
 			r.Get("/health", ctx.HandlerFunc(handlers.HandlerHealth))
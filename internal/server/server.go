@@ -11,6 +11,7 @@ import (
 	"homelab-dashboard/internal/jobs"
 	"homelab-dashboard/internal/metrics"
 	"homelab-dashboard/internal/middlewares"
+	"homelab-dashboard/internal/services/alerting"
 	"homelab-dashboard/internal/services/certificate"
 	"homelab-dashboard/internal/services/firewall"
 	"homelab-dashboard/internal/storage"
@@ -133,6 +134,10 @@ func New(cfg *config.Config) (*Server, error) {
 		logger.Debug("Database Migrations Completed")
 
 		database = dbProvider
+
+		if cfg.Data.History.Enabled {
+			dataService.WithHistory(database)
+		}
 	}
 
 	var certProvider certificate.Provider
@@ -164,14 +169,67 @@ func New(cfg *config.Config) (*Server, error) {
 		}
 	}
 
-	appCtx := middlewares.NewAppContext(ctx, cfg, logger, cache, sessionManager, oidcProvider, database, certProvider)
+	var routerClient *firewall.RouterClient
+	var traefikClient *firewall.TraefikClient
+	var geoIP *firewall.GeoIPResolver
+	if cfg.Features.FirewallManagement.Enabled {
+		// Create router client for firewall communication
+		routerClient = firewall.NewRouterClient(*cfg)
+
+		if cfg.Features.FirewallManagement.Kubernetes != nil {
+			traefikClient, err = firewall.NewTraefikClientFromConfig(cfg.Features.FirewallManagement.Kubernetes)
+			if err != nil {
+				logger.Error("failed to create traefik middleware client", "error", err)
+				cancel()
+				return nil, err
+			}
+			logger.Debug("Traefik Middleware Client Initialized")
+		}
+
+		if err := validateFirewallAliases(ctx, cfg, database, routerClient, traefikClient, logger); err != nil {
+			logger.Error("firewall alias validation failed", "error", err)
+			cancel()
+			return nil, err
+		}
+
+		if cfg.Features.FirewallManagement.GeoIP != nil {
+			geoIP, err = firewall.NewGeoIPResolver(*cfg.Features.FirewallManagement.GeoIP)
+			if err != nil {
+				logger.Error("failed to open geoip databases", "error", err)
+				cancel()
+				return nil, err
+			}
+			logger.Debug("GeoIP Resolver Initialized")
+		}
+
+		warnUnmatchedASNRules(ctx, cfg, database, logger)
+	}
+
+	appCtx := middlewares.NewAppContext(ctx, cfg, logger, cache, dataService, sessionManager, oidcProvider, database, certProvider, routerClient, traefikClient, geoIP)
 
 	jobManager := jobs.NewJobManager(election, logger)
 
-	interval := calculateFetchInterval(cfg, cfg.Data.FallbackFetchInterval)
-	dataFetchJob := jobs.NewDataFetchJob(dataService, appCtx, interval, logger)
+	scheduler := data.NewScheduler(dataService, cache, cfg.Data.Scheduler, cfg.Data.FallbackFetchInterval, logger)
+	dataFetchJob := jobs.NewDataFetchJob(scheduler, cfg.Data.FallbackFetchInterval, logger)
 	jobManager.Register(dataFetchJob)
 
+	if len(data.ProbeQueries(cfg.Data.Probes)) > 0 && cache != nil {
+		jobManager.Register(jobs.NewDataProbeJob(dataService, cfg.Data.Probes, logger))
+	}
+
+	if cfg.Data.History.Enabled && database != nil {
+		jobManager.Register(jobs.NewDataHistoryPruneJob(appCtx, cfg.Data.History.PruneInterval, logger))
+	}
+
+	if cfg.Data.Alerts != nil && len(cfg.Data.Alerts.Rules) > 0 && database != nil && cache != nil {
+		alerter, err := alerting.NewAlerter(cfg.Data.Alerts, database, cache, logger)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to set up alerting: %w", err)
+		}
+		jobManager.Register(jobs.NewDataAlertJob(alerter, cache, cfg.Data.Alerts.EvaluationInterval, logger))
+	}
+
 	if cfg.Features.MTLSManagement.Enabled {
 		certificateCreationJob := jobs.NewCertificateCreationJob(appCtx, cfg.Features.MTLSManagement.BackgroundJobConfig.ApprovedCertificatePollingInterval)
 		jobManager.Register(certificateCreationJob)
@@ -181,14 +239,13 @@ func New(cfg *config.Config) (*Server, error) {
 	}
 
 	if cfg.Features.FirewallManagement.Enabled {
-		// Create router client for firewall communication
-		routerClient := firewall.NewRouterClient(*cfg)
-
 		// Register firewall sync job
 		firewallSyncJob := jobs.NewFirewallSyncJob(
 			appCtx,
 			routerClient,
+			traefikClient,
 			cfg.Features.FirewallManagement.BackgroundJobConfig.SyncInterval,
+			cfg.Features.FirewallManagement.BackgroundJobConfig.SyncDebounce,
 			logger,
 		)
 		jobManager.Register(firewallSyncJob)
@@ -201,9 +258,24 @@ func New(cfg *config.Config) (*Server, error) {
 		)
 		jobManager.Register(firewallExpirationJob)
 
+		// Register access window job
+		firewallScheduleJob := jobs.NewFirewallScheduleJob(
+			appCtx,
+			cfg.Features.FirewallManagement.BackgroundJobConfig.ScheduleInterval,
+			logger,
+		)
+		jobManager.Register(firewallScheduleJob)
+
+		// Register one import job per threat feed
+		for _, feed := range cfg.Features.FirewallManagement.BlacklistFeeds {
+			jobManager.Register(jobs.NewFirewallBlacklistFeedJob(appCtx, feed))
+		}
+
 		logger.Info("firewall management jobs registered",
 			"sync_interval", cfg.Features.FirewallManagement.BackgroundJobConfig.SyncInterval,
 			"expiration_interval", cfg.Features.FirewallManagement.BackgroundJobConfig.ExpirationInterval,
+			"schedule_interval", cfg.Features.FirewallManagement.BackgroundJobConfig.ScheduleInterval,
+			"blacklist_feeds", len(cfg.Features.FirewallManagement.BlacklistFeeds),
 		)
 	}
 
@@ -300,50 +372,119 @@ func (s *Server) Start() error {
 		}
 	}
 
+	if s.appCtx.GeoIP != nil {
+		s.appCtx.GeoIP.Close()
+	}
+
 	s.logger.Info("Server Existed")
 	return nil
 }
 
 func setupDataService(cfg *config.Config, logger *slog.Logger) (*data.Service, data.Provider, error) {
-	mimirClient, err := data.NewMimirClient(
-		cfg.Data.PrometheusURL,
-		cfg.Data.BasicAuth.Username,
-		cfg.Data.BasicAuth.Password,
-	)
-
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create new mimir client: %w", err)
-	}
-
 	cache, err := data.NewCacheProvider(cfg, logger)
 	if err != nil {
 		logger.Error("error setting up cache provider", "error", err)
 	}
-	return data.NewService(mimirClient, cache, logger, cfg.Data.Queries), cache, nil
-}
 
-// calculateFetchInterval determines how often the background data fetching should happen, based completely on the shortest configured ttl, falling back to the default if there are no ttl configured.
-func calculateFetchInterval(cfg *config.Config, defaultInterval time.Duration) time.Duration {
-	var minTTL time.Duration
-	found := false
+	sources := map[string]data.DataSource{
+		config.DataSourceHTTPJSON: data.NewHTTPJSONSource(),
+	}
 
-	for _, q := range cfg.Data.Queries {
-		if q.Disabled || q.TTL <= 0 {
-			continue
+	if len(cfg.Data.Backends) > 0 {
+		clients := make(map[string]*data.MimirClient, len(cfg.Data.Backends))
+		for _, backend := range cfg.Data.Backends {
+			mimirClient, err := data.NewMimirClient(backend, logger)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create new mimir client: %w", err)
+			}
+			mimirClient.WithCircuitBreaker(data.NewCircuitBreaker(data.BackendCircuitName(backend.Name), cfg.Data.CircuitBreaker, cache, logger))
+			clients[backend.Name] = mimirClient
 		}
+		sources[config.DataSourcePrometheus] = data.NewPrometheusSource(clients)
+	}
+
+	if cfg.Data.Loki != nil {
+		sources[config.DataSourceLoki] = data.NewLokiSource(cfg.Data.Loki)
+	}
 
-		if !found || q.TTL < minTTL {
-			minTTL = q.TTL
-			found = true
+	if len(cfg.Data.Probes) > 0 {
+		probeSource, err := data.NewProbeSource(cfg.Data.Probes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create probe data source: %w", err)
+		}
+		sources[config.DataSourceProbe] = probeSource
+	}
+
+	if cfg.Data.Kubernetes != nil {
+		kubernetesSource, err := data.NewKubernetesSourceFromConfig(cfg.Data.Kubernetes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create kubernetes data source: %w", err)
 		}
+		sources[config.DataSourceKubernetes] = kubernetesSource
 	}
 
-	if !found {
-		minTTL = defaultInterval
+	service := data.NewService(sources, cache, logger, cfg.Data.Queries).
+		WithVariables(cfg.Data.Variables, cfg.Data.OnDemand).
+		WithProbes(cfg.Data.Probes)
+	return service, cache, nil
+}
+
+// warnUnmatchedASNRules logs active ASN blacklist rules when no ASN database is configured, as they never match.
+func warnUnmatchedASNRules(ctx context.Context, cfg *config.Config, database storage.Provider, logger *slog.Logger) {
+	if geoIP := cfg.Features.FirewallManagement.GeoIP; geoIP != nil && geoIP.ASNDatabase != "" {
+		return
 	}
 
-	if minTTL < time.Second*30 {
-		minTTL = time.Second * 30
+	rules, err := database.GetBlacklistRules(ctx, "", false)
+	if err != nil {
+		logger.Warn("could not check blacklist rules for ASN rules", "error", err)
+		return
 	}
-	return minTTL
+
+	count := 0
+	for _, rule := range rules {
+		if rule.ASN != nil {
+			count++
+		}
+	}
+
+	if count > 0 {
+		logger.Warn("ASN blacklist rules never match without features.firewall_management.geoip.asn_database", "rules", count)
+	}
+}
+
+// validateFirewallAliases imports the aliases of the config file, then checks every managed alias still exists
+// on the router or in the cluster and can hold whitelist entries. Problems are only logged: a stale alias must
+// not keep the dashboard down, as its admin API is where the alias is fixed or deleted, and the sync job skips
+// an alias whose backend rejects it.
+func validateFirewallAliases(ctx context.Context, cfg *config.Config, database storage.Provider, routerClient *firewall.RouterClient, traefikClient *firewall.TraefikClient, logger *slog.Logger) error {
+	imported, err := firewall.ImportConfigAliases(ctx, cfg, database)
+	if err != nil {
+		return err
+	}
+	if imported > 0 {
+		logger.Info("imported firewall aliases from the config file; manage them through /api/firewall/managed-aliases from now on", "aliases", imported)
+	}
+
+	aliases, err := firewall.LoadAliases(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	validateCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	err = firewall.ValidateAliasBackends(validateCtx, routerClient, traefikClient, aliases)
+	if err == nil {
+		logger.Debug("Firewall aliases validated", "aliases", len(aliases))
+		return nil
+	}
+
+	if errors.Is(err, firewall.ErrAliasNotFound) || errors.Is(err, firewall.ErrUnsupportedAliasType) || errors.Is(err, firewall.ErrBackendNotConfigured) {
+		logger.Error("some firewall aliases cannot be synced; fix or delete them through /api/firewall/managed-aliases", "error", err)
+		return nil
+	}
+
+	logger.Warn("could not validate firewall aliases against their backends", "error", err)
+	return nil
 }
@@ -0,0 +1,293 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/storage"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// notifyTimeout bounds how long one receiver may take to deliver a notification, so a slow receiver cannot hold
+// up evaluation.
+const notifyTimeout = 10 * time.Second
+
+// Alerter evaluates alert rules against the cached results of their queries, keeps the resulting alerts in
+// storage and notifies receivers when an alert fires or resolves.
+type Alerter struct {
+	rules     []rule
+	receivers map[string]Receiver
+	storage   storage.Provider
+	cache     data.Provider
+	logger    *slog.Logger
+}
+
+// rule is an alert rule with its condition parsed.
+type rule struct {
+	config.DataAlertRule
+	condition data.Condition
+}
+
+// evaluation is what evaluating a rule against one result changes: alerts to create, update and delete, and
+// the notifications to send once those changes are saved.
+type evaluation struct {
+	create        []*models.DataAlert
+	update        []*models.DataAlert
+	delete        []int
+	notifications []Notification
+}
+
+func NewAlerter(alerts *config.DataAlerts, storage storage.Provider, cache data.Provider, logger *slog.Logger) (*Alerter, error) {
+	rules := make([]rule, 0, len(alerts.Rules))
+	for _, r := range alerts.Rules {
+		condition, err := data.ParseCondition(r.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %s: %w", r.Name, err)
+		}
+		rules = append(rules, rule{DataAlertRule: r, condition: condition})
+	}
+
+	return &Alerter{
+		rules:     rules,
+		receivers: NewReceivers(alerts),
+		storage:   storage,
+		cache:     cache,
+		logger:    logger,
+	}, nil
+}
+
+// EvaluateAll evaluates every rule against every cached result of its query, then retires the alerts no rule
+// will evaluate again.
+func (a *Alerter) EvaluateAll(ctx context.Context) error {
+	active, err := a.storage.GetActiveDataAlerts(ctx)
+	if err != nil {
+		return err
+	}
+
+	cachedKeys := a.cache.ListAll(ctx)
+	for _, key := range cachedKeys {
+		if err := a.evaluate(ctx, key, active); err != nil {
+			return err
+		}
+	}
+
+	return a.retireOrphans(ctx, active, cachedKeys)
+}
+
+// Evaluate evaluates the rules of the query a fresh result was cached for.
+func (a *Alerter) Evaluate(ctx context.Context, queryKey string) error {
+	active, err := a.storage.GetActiveDataAlerts(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.evaluate(ctx, queryKey, active)
+}
+
+func (a *Alerter) evaluate(ctx context.Context, queryKey string, active []*models.DataAlert) error {
+	queryName := data.CacheKeyQueryName(queryKey)
+
+	for _, r := range a.rules {
+		if r.Query != queryName {
+			continue
+		}
+
+		cached, ok := a.cache.Get(ctx, queryKey)
+		if !ok {
+			continue
+		}
+
+		// A stale result says nothing about the present, so alerts keep their state until fresh data arrives
+		now := time.Now()
+		if cached.IsStale(now) {
+			continue
+		}
+
+		values, err := data.LatestValues(cached)
+		if err != nil {
+			a.logger.Warn("failed to read query result for alert rule", "rule", r.Name, "query", queryKey, "error", err)
+			continue
+		}
+
+		var ruleAlerts []*models.DataAlert
+		for _, alert := range active {
+			if alert.RuleName == r.Name && alert.QueryKey == queryKey {
+				ruleAlerts = append(ruleAlerts, alert)
+			}
+		}
+
+		if err := a.apply(ctx, r, evaluateRule(r, queryKey, values, ruleAlerts, now)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retireOrphans drops or resolves the active alerts whose rule is no longer configured, now watches another
+// query, or whose query result is no longer cached. Alerts of a rule that was removed resolve without notifying,
+// as there is no rule left to say who to tell.
+func (a *Alerter) retireOrphans(ctx context.Context, active []*models.DataAlert, cachedKeys []string) error {
+	now := time.Now()
+
+	for _, r := range a.rules {
+		var result evaluation
+		for _, alert := range active {
+			if alert.RuleName == r.Name && alert.State != models.DataAlertResolved &&
+				(data.CacheKeyQueryName(alert.QueryKey) != r.Query || !slices.Contains(cachedKeys, alert.QueryKey)) {
+				retireAlert(r, alert, now, &result)
+			}
+		}
+		if err := a.apply(ctx, r, result); err != nil {
+			return err
+		}
+	}
+
+	var removed evaluation
+	for _, alert := range active {
+		configured := slices.ContainsFunc(a.rules, func(r rule) bool { return r.Name == alert.RuleName })
+		if !configured {
+			a.logger.Info("retiring alert of removed rule", "rule", alert.RuleName, "query", alert.QueryKey, "labels", alert.Labels)
+			retireAlert(rule{}, alert, now, &removed)
+		}
+	}
+	removed.notifications = nil
+
+	return a.apply(ctx, rule{}, removed)
+}
+
+// apply saves an evaluation and then sends its notifications, so a failed save never notifies twice.
+func (a *Alerter) apply(ctx context.Context, r rule, result evaluation) error {
+	for _, alert := range result.create {
+		if _, err := a.storage.CreateDataAlert(ctx, alert); err != nil {
+			return err
+		}
+	}
+	for _, alert := range result.update {
+		if err := a.storage.UpdateDataAlert(ctx, alert); err != nil {
+			return err
+		}
+	}
+	for _, id := range result.delete {
+		if err := a.storage.DeleteDataAlert(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	for _, notification := range result.notifications {
+		a.logger.Info("alert "+string(notification.State), "rule", notification.Rule, "query", notification.Query, "labels", notification.Labels, "value", notification.Value)
+
+		for name, receiver := range a.receivers {
+			if len(r.Receivers) > 0 && !slices.Contains(r.Receivers, name) {
+				continue
+			}
+			notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+			err := receiver.Notify(notifyCtx, notification)
+			cancel()
+			if err != nil {
+				a.logger.Error("failed to send alert notification", "rule", notification.Rule, "receiver", name, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// evaluateRule compares each series of a result with a rule's condition. A series that meets it gets a pending
+// alert, which fires once the condition has held for the rule's for duration. Alerts whose series no longer
+// meets the condition, or is gone from the result, are resolved if they fired and dropped if they were pending.
+func evaluateRule(r rule, queryKey string, values map[string]data.SeriesValue, active []*models.DataAlert, now time.Time) evaluation {
+	var result evaluation
+
+	bySeries := make(map[string]*models.DataAlert, len(active))
+	for _, alert := range active {
+		bySeries[alert.Series] = alert
+	}
+
+	for series, sample := range values {
+		if !r.condition.Matches(sample.Value) {
+			continue
+		}
+
+		alert, exists := bySeries[series]
+		if !exists {
+			alert = &models.DataAlert{
+				RuleName:    r.Name,
+				QueryKey:    queryKey,
+				Series:      series,
+				Labels:      sample.Labels,
+				Severity:    r.Severity,
+				State:       models.DataAlertPending,
+				ActiveSince: now,
+			}
+		}
+
+		alert.Value = sample.Value
+		alert.LastEvaluatedAt = now
+
+		if alert.State == models.DataAlertPending && now.Sub(alert.ActiveSince) >= r.For {
+			firedAt := now
+			alert.State = models.DataAlertFiring
+			alert.FiredAt = &firedAt
+			result.notifications = append(result.notifications, newNotification(r, alert))
+		}
+
+		if exists {
+			result.update = append(result.update, alert)
+		} else {
+			result.create = append(result.create, alert)
+		}
+	}
+
+	for _, alert := range active {
+		sample, ok := values[alert.Series]
+		if ok && r.condition.Matches(sample.Value) {
+			continue
+		}
+		if ok {
+			alert.Value = sample.Value
+		}
+
+		retireAlert(r, alert, now, &result)
+	}
+
+	return result
+}
+
+// retireAlert drops an alert that was still pending, or resolves one that fired and notifies its receivers.
+func retireAlert(r rule, alert *models.DataAlert, now time.Time, result *evaluation) {
+	if alert.State == models.DataAlertPending {
+		result.delete = append(result.delete, alert.ID)
+		return
+	}
+
+	resolvedAt := now
+	alert.State = models.DataAlertResolved
+	alert.ResolvedAt = &resolvedAt
+	alert.LastEvaluatedAt = now
+
+	result.update = append(result.update, alert)
+	result.notifications = append(result.notifications, newNotification(r, alert))
+}
+
+func newNotification(r rule, alert *models.DataAlert) Notification {
+	notification := Notification{
+		Rule:       r.Name,
+		State:      alert.State,
+		Severity:   r.Severity,
+		Summary:    r.Summary,
+		Condition:  r.condition.String(),
+		Query:      alert.QueryKey,
+		Labels:     alert.Labels,
+		Value:      alert.Value,
+		ResolvedAt: alert.ResolvedAt,
+	}
+	if alert.FiredAt != nil {
+		notification.FiredAt = *alert.FiredAt
+	}
+	return notification
+}
@@ -0,0 +1,202 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/mocks"
+	"homelab-dashboard/internal/models"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEvaluateRule(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	r := rule{
+		DataAlertRule: config.DataAlertRule{Name: "node_not_ready", Query: "node_status", For: 5 * time.Minute, Severity: config.DataAlertSeverityCritical},
+		condition:     data.Condition{Operator: "<", Threshold: 1},
+	}
+
+	values := map[string]data.SeriesValue{
+		`{node="a"}`: {Labels: map[string]string{"node": "a"}, Value: 0},
+		`{node="b"}`: {Labels: map[string]string{"node": "b"}, Value: 1},
+	}
+
+	t.Run("ShouldCreatePendingAlert", func(t *testing.T) {
+		result := evaluateRule(r, "node_status", values, nil, now)
+
+		require.Len(t, result.create, 1)
+		assert.Equal(t, models.DataAlertPending, result.create[0].State)
+		assert.Equal(t, `{node="a"}`, result.create[0].Series)
+		assert.Empty(t, result.notifications, "a pending alert should not notify")
+	})
+
+	t.Run("ShouldFireOnceConditionHeldForDuration", func(t *testing.T) {
+		pending := &models.DataAlert{ID: 1, RuleName: r.Name, QueryKey: "node_status", Series: `{node="a"}`, State: models.DataAlertPending, ActiveSince: now.Add(-5 * time.Minute)}
+		result := evaluateRule(r, "node_status", values, []*models.DataAlert{pending}, now)
+
+		require.Len(t, result.update, 1)
+		assert.Equal(t, models.DataAlertFiring, result.update[0].State)
+		require.Len(t, result.notifications, 1)
+		assert.Equal(t, models.DataAlertFiring, result.notifications[0].State)
+		assert.Equal(t, "< 1", result.notifications[0].Condition)
+	})
+
+	t.Run("ShouldFireImmediatelyWithoutForDuration", func(t *testing.T) {
+		immediate := r
+		immediate.For = 0
+		result := evaluateRule(immediate, "node_status", values, nil, now)
+
+		require.Len(t, result.create, 1)
+		assert.Equal(t, models.DataAlertFiring, result.create[0].State)
+		assert.Len(t, result.notifications, 1)
+	})
+
+	t.Run("ShouldDropPendingAlertThatCleared", func(t *testing.T) {
+		pending := &models.DataAlert{ID: 2, RuleName: r.Name, QueryKey: "node_status", Series: `{node="b"}`, State: models.DataAlertPending, ActiveSince: now.Add(-time.Minute)}
+		result := evaluateRule(r, "node_status", values, []*models.DataAlert{pending}, now)
+
+		assert.Equal(t, []int{2}, result.delete)
+		assert.Len(t, result.notifications, 0)
+	})
+
+	t.Run("ShouldResolveFiringAlertWhoseSeriesIsGone", func(t *testing.T) {
+		firedAt := now.Add(-time.Hour)
+		firing := &models.DataAlert{ID: 3, RuleName: r.Name, QueryKey: "node_status", Series: `{node="c"}`, State: models.DataAlertFiring, ActiveSince: firedAt, FiredAt: &firedAt}
+		result := evaluateRule(r, "node_status", values, []*models.DataAlert{firing}, now)
+
+		require.Len(t, result.notifications, 1)
+		assert.Equal(t, models.DataAlertResolved, result.notifications[0].State)
+		assert.Equal(t, firedAt, result.notifications[0].FiredAt)
+		require.NotNil(t, firing.ResolvedAt)
+		assert.Equal(t, now, *firing.ResolvedAt)
+	})
+}
+
+// recordingReceiver keeps the notifications it is sent.
+type recordingReceiver struct {
+	notifications []Notification
+}
+
+func (r *recordingReceiver) Notify(_ context.Context, notification Notification) error {
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+func TestEvaluateAllRetiresOrphanedAlerts(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStorageProvider(ctrl)
+
+	cache, _ := data.NewMemCache(&config.Config{}, logger)
+	vector, err := json.Marshal(model.Vector{{Metric: model.Metric{"node": "a"}, Value: 1}})
+	require.NoError(t, err)
+	cache.Set(ctx, "node_status", data.CachedData{Name: "node_status", ValueType: "vector", JSONBytes: vector, Timestamp: time.Now(), ExpiresAt: time.Now().Add(time.Minute)})
+
+	alerter, err := NewAlerter(&config.DataAlerts{Rules: []config.DataAlertRule{
+		{Name: "node_not_ready", Query: "node_status", Condition: "< 1"},
+	}}, store, cache, logger)
+	require.NoError(t, err)
+	receiver := &recordingReceiver{}
+	alerter.receivers = map[string]Receiver{"test": receiver}
+
+	firedAt := time.Now().Add(-time.Hour)
+	uncached := &models.DataAlert{ID: 1, RuleName: "node_not_ready", QueryKey: "node_status?node=b", Series: `{node="b"}`, State: models.DataAlertFiring, ActiveSince: firedAt, FiredAt: &firedAt}
+	removedPending := &models.DataAlert{ID: 2, RuleName: "disk_full", QueryKey: "disk_usage", Series: "{}", State: models.DataAlertPending, ActiveSince: firedAt}
+	removedFiring := &models.DataAlert{ID: 3, RuleName: "disk_full", QueryKey: "disk_usage", Series: `{mountpoint="/"}`, State: models.DataAlertFiring, ActiveSince: firedAt, FiredAt: &firedAt}
+
+	store.EXPECT().GetActiveDataAlerts(ctx).Return([]*models.DataAlert{uncached, removedPending, removedFiring}, nil)
+	store.EXPECT().UpdateDataAlert(ctx, uncached).Return(nil)
+	store.EXPECT().DeleteDataAlert(ctx, 2).Return(nil)
+	store.EXPECT().UpdateDataAlert(ctx, removedFiring).Return(nil)
+
+	require.NoError(t, alerter.EvaluateAll(ctx))
+
+	assert.Equal(t, models.DataAlertResolved, uncached.State)
+	assert.Equal(t, models.DataAlertResolved, removedFiring.State)
+	require.Len(t, receiver.notifications, 1, "only the alert whose rule is still configured notifies")
+	assert.Equal(t, "node_not_ready", receiver.notifications[0].Rule)
+	assert.Equal(t, models.DataAlertResolved, receiver.notifications[0].State)
+}
+
+func TestWebhookReceiver(t *testing.T) {
+	var received Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	receiver := NewWebhookReceiver(server.URL, map[string]string{"Authorization": "Bearer token"})
+	notification := Notification{Rule: "disk_full", State: models.DataAlertFiring, Severity: "warning", Labels: map[string]string{"mountpoint": "/"}, Value: 95}
+
+	require.NoError(t, receiver.Notify(context.Background(), notification))
+	assert.Equal(t, "disk_full", received.Rule)
+	assert.Equal(t, 95.0, received.Value)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	assert.Error(t, NewWebhookReceiver(failing.URL, nil).Notify(context.Background(), notification))
+}
+
+func TestEmailReceiverHonoursContext(t *testing.T) {
+	// A mail server that accepts connections but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	receiver := NewEmailReceiver(&config.DataAlertSMTP{Host: addr.IP.String(), Port: addr.Port, From: "dashboard@example.com"}, []string{"ops@example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.Error(t, receiver.Notify(ctx, Notification{Rule: "disk_full", State: models.DataAlertFiring}))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestEmailMessage(t *testing.T) {
+	resolvedAt := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	message := string(emailMessage("dashboard@example.com", []string{"ops@example.com"}, Notification{
+		Rule:       "disk_full",
+		State:      models.DataAlertResolved,
+		Severity:   "warning",
+		Summary:    "Root filesystem is almost full",
+		Condition:  "> 90",
+		Query:      "disk_usage",
+		Value:      80,
+		FiredAt:    resolvedAt.Add(-time.Hour),
+		ResolvedAt: &resolvedAt,
+	}))
+
+	assert.Contains(t, message, "Subject: [RESOLVED] disk_full (warning)\r\n")
+	assert.Contains(t, message, "Query: disk_usage > 90\r\n")
+	assert.Contains(t, message, "Resolved at: 2026-03-10T13:00:00Z\r\n")
+}
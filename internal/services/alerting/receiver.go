@@ -0,0 +1,200 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"net"
+	"net/http"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notification tells receivers that an alert started firing or resolved.
+type Notification struct {
+	Rule       string                `json:"rule"`
+	State      models.DataAlertState `json:"state"`
+	Severity   string                `json:"severity"`
+	Summary    string                `json:"summary,omitempty"`
+	Condition  string                `json:"condition"`
+	Query      string                `json:"query"`
+	Labels     map[string]string     `json:"labels"`
+	Value      float64               `json:"value"`
+	FiredAt    time.Time             `json:"fired_at"`
+	ResolvedAt *time.Time            `json:"resolved_at,omitempty"`
+}
+
+// Receiver delivers notifications somewhere a person will see them.
+type Receiver interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NewReceivers builds the receivers configured for alerts, keyed by name.
+func NewReceivers(alerts *config.DataAlerts) map[string]Receiver {
+	receivers := make(map[string]Receiver, len(alerts.Receivers))
+
+	for _, receiver := range alerts.Receivers {
+		switch receiver.Type {
+		case config.DataAlertReceiverWebhook:
+			receivers[receiver.Name] = NewWebhookReceiver(receiver.URL, receiver.Headers)
+		case config.DataAlertReceiverEmail:
+			receivers[receiver.Name] = NewEmailReceiver(alerts.SMTP, receiver.To)
+		}
+	}
+
+	return receivers
+}
+
+// WebhookReceiver posts each notification as JSON.
+type WebhookReceiver struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func NewWebhookReceiver(url string, headers map[string]string) *WebhookReceiver {
+	return &WebhookReceiver{url: url, headers: headers, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *WebhookReceiver) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// EmailReceiver mails each notification to a list of recipients.
+type EmailReceiver struct {
+	smtp *config.DataAlertSMTP
+	to   []string
+}
+
+func NewEmailReceiver(smtp *config.DataAlertSMTP, to []string) *EmailReceiver {
+	return &EmailReceiver{smtp: smtp, to: to}
+}
+
+func (e *EmailReceiver) Notify(ctx context.Context, notification Notification) error {
+	address := net.JoinHostPort(e.smtp.Host, strconv.Itoa(e.smtp.Port))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mail server: %w", err)
+	}
+
+	// net/smtp never looks at ctx, so the connection carries its deadline and is cut short if it is cancelled
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, e.smtp.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if err := e.send(client, emailMessage(e.smtp.From, e.to, notification)); err != nil {
+		return fmt.Errorf("failed to send alert email: %w", err)
+	}
+
+	return nil
+}
+
+// send delivers a message over an open SMTP session the way smtp.SendMail does, upgrading to TLS when the
+// server offers it.
+func (e *EmailReceiver) send(client *smtp.Client, message []byte) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.smtp.Host}); err != nil {
+			return err
+		}
+	}
+
+	if e.smtp.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.smtp.Username, e.smtp.Password, e.smtp.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(e.smtp.From); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// emailMessage formats a notification as a plain text email.
+func emailMessage(from string, to []string, notification Notification) []byte {
+	subject := fmt.Sprintf("[%s] %s (%s)", strings.ToUpper(string(notification.State)), notification.Rule, notification.Severity)
+
+	var body strings.Builder
+	if notification.Summary != "" {
+		fmt.Fprintf(&body, "%s\r\n\r\n", notification.Summary)
+	}
+	fmt.Fprintf(&body, "Rule: %s\r\n", notification.Rule)
+	fmt.Fprintf(&body, "Query: %s %s\r\n", notification.Query, notification.Condition)
+	fmt.Fprintf(&body, "Value: %g\r\n", notification.Value)
+
+	names := make([]string, 0, len(notification.Labels))
+	for name := range notification.Labels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(&body, "Label %s: %s\r\n", name, notification.Labels[name])
+	}
+
+	fmt.Fprintf(&body, "Firing since: %s\r\n", notification.FiredAt.UTC().Format(time.RFC3339))
+	if notification.ResolvedAt != nil {
+		fmt.Fprintf(&body, "Resolved at: %s\r\n", notification.ResolvedAt.UTC().Format(time.RFC3339))
+	}
+
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		from, strings.Join(to, ", "), subject, body.String()))
+}
@@ -7,9 +7,8 @@ import (
 	"fmt"
 	"homelab-dashboard/internal/config"
 	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/utils"
 	"log/slog"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,7 +20,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // KubernetesCertificateProvider wraps the Kubernetes and cert-manager clients
@@ -63,28 +61,15 @@ func NewKubernetesClient(ctx context.Context, cfg *config.Config, logger *slog.L
 		return nil, fmt.Errorf("kubernetes namespace configuration is missing")
 	}
 
-	var restConfig *rest.Config
-	var err error
-
 	if k8sCfg.InCluster {
 		logger.Info("using in-cluster Kubernetes configuration")
-		restConfig, err = rest.InClusterConfig()
-		if err != nil {
-			return nil, fmt.Errorf("failed to create in-cluster config: %w", err)
-		}
 	} else {
-		kubeconfig := k8sCfg.Kubeconfig
-		if kubeconfig == "" {
-			if home := homeDir(); home != "" {
-				kubeconfig = filepath.Join(home, ".kube", "config")
-			}
-		}
+		logger.Debug("Using Kubeconfig File", "path", k8sCfg.Kubeconfig)
+	}
 
-		logger.Debug("Using Kubeconfig File", "path", kubeconfig)
-		restConfig, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build config from kubeconfig: %w", err)
-		}
+	restConfig, err := utils.KubernetesRestConfig(k8sCfg)
+	if err != nil {
+		return nil, err
 	}
 
 	clientset, err := kubernetes.NewForConfig(restConfig)
@@ -114,13 +99,6 @@ func NewKubernetesClient(ctx context.Context, cfg *config.Config, logger *slog.L
 	}, nil
 }
 
-func homeDir() string {
-	if h := os.Getenv("HOME"); h != "" {
-		return h
-	}
-	return os.Getenv("USERPROFILE")
-}
-
 // CreateCertificateFromRequest creates a cert-manager Certificate resource from a CertificateRequest
 func (c *KubernetesCertificateProvider) CreateCertificateFromRequest(ctx context.Context, request *models.CertificateRequest) (string, map[string]interface{}, error) {
 	certName := GenerateCertificateName(request.OwnerSub, request.OwnerIss, request.RequestedAt)
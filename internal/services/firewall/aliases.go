@@ -0,0 +1,164 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/storage"
+	"strings"
+	"time"
+)
+
+// LoadAliases returns every alias the dashboard manages. Aliases live in the database only; those declared in
+// the config file are copied there once by ImportConfigAliases.
+func LoadAliases(ctx context.Context, store storage.Provider) ([]config.FirewallAliasConfig, error) {
+	managed, err := store.GetManagedAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load managed aliases: %w", err)
+	}
+
+	aliases := make([]config.FirewallAliasConfig, 0, len(managed))
+	for _, alias := range managed {
+		aliases = append(aliases, ManagedAliasToConfig(alias))
+	}
+
+	return aliases, nil
+}
+
+// ImportConfigAliases copies the aliases declared in the config file into the database, attributed to the
+// system user, and returns how many were imported. Each uuid and auth group is imported once: changes made
+// through the admin API are never overwritten by the file, and an alias deleted there stays deleted.
+func ImportConfigAliases(ctx context.Context, cfg *config.Config, store storage.Provider) (int, error) {
+	imported := 0
+	for _, alias := range cfg.Features.FirewallManagement.Aliases {
+		record := ConfigAliasToManaged(alias)
+		record.CreatedByIss = cfg.Server.ExternalURL
+		record.CreatedBySub = storage.SystemSub
+
+		created, err := store.ImportManagedAlias(ctx, record)
+		if err != nil {
+			return imported, fmt.Errorf("failed to import alias %s (%s): %w", alias.Name, alias.UUID, err)
+		}
+		if created {
+			imported++
+		}
+	}
+
+	return imported, nil
+}
+
+// ConfigAliasToManaged converts an alias declared in the config file to its database representation.
+func ConfigAliasToManaged(alias config.FirewallAliasConfig) *models.FirewallManagedAlias {
+	seconds := func(d *time.Duration) *int64 {
+		if d == nil {
+			return nil
+		}
+		s := int64(d.Seconds())
+		return &s
+	}
+
+	managed := &models.FirewallManagedAlias{
+		UUID:               strings.ToLower(alias.UUID),
+		Name:               alias.Name,
+		Description:        alias.Description,
+		MaxIPsPerUser:      alias.MaxIPsPerUser,
+		MaxTotalIPs:        alias.MaxTotalIPs,
+		DefaultTTLSeconds:  seconds(alias.DefaultTTL),
+		AuthGroup:          alias.AuthGroup,
+		DryRun:             alias.DryRun,
+		MaxLifetimeSeconds: seconds(alias.MaxLifetime),
+		MaxExtensions:      alias.MaxExtensions,
+		AllowedCountries:   alias.AllowedCountries,
+		DeniedCountries:    alias.DeniedCountries,
+		AllowedASNs:        alias.AllowedASNs,
+		DeniedASNs:         alias.DeniedASNs,
+	}
+
+	if alias.Traefik != nil {
+		managed.TraefikNamespace = alias.Traefik.Namespace
+		managed.TraefikName = alias.Traefik.Name
+	}
+
+	return managed
+}
+
+// ManagedAliasToConfig converts a database alias to the config representation used by handlers and jobs.
+func ManagedAliasToConfig(alias *models.FirewallManagedAlias) config.FirewallAliasConfig {
+	var ttl *time.Duration
+	if alias.DefaultTTLSeconds != nil {
+		d := time.Duration(*alias.DefaultTTLSeconds) * time.Second
+		ttl = &d
+	}
+
+	var maxLifetime *time.Duration
+	if alias.MaxLifetimeSeconds != nil {
+		d := time.Duration(*alias.MaxLifetimeSeconds) * time.Second
+		maxLifetime = &d
+	}
+
+	var traefik *config.FirewallTraefikMiddleware
+	if alias.TraefikName != "" {
+		traefik = &config.FirewallTraefikMiddleware{Namespace: alias.TraefikNamespace, Name: alias.TraefikName}
+	}
+
+	return config.FirewallAliasConfig{
+		Name:             alias.Name,
+		UUID:             alias.UUID,
+		Description:      alias.Description,
+		MaxIPsPerUser:    alias.MaxIPsPerUser,
+		MaxTotalIPs:      alias.MaxTotalIPs,
+		DefaultTTL:       ttl,
+		AuthGroup:        alias.AuthGroup,
+		DryRun:           alias.DryRun,
+		Traefik:          traefik,
+		MaxLifetime:      maxLifetime,
+		MaxExtensions:    alias.MaxExtensions,
+		AllowedCountries: alias.AllowedCountries,
+		DeniedCountries:  alias.DeniedCountries,
+		AllowedASNs:      alias.AllowedASNs,
+		DeniedASNs:       alias.DeniedASNs,
+	}
+}
+
+// FindAliasByUUID returns the first alias with the given uuid, or nil if none matches.
+func FindAliasByUUID(aliases []config.FirewallAliasConfig, uuid string) *config.FirewallAliasConfig {
+	uuid = strings.TrimSpace(uuid)
+	if uuid == "" {
+		return nil
+	}
+
+	for i := range aliases {
+		if strings.EqualFold(aliases[i].UUID, uuid) {
+			return &aliases[i]
+		}
+	}
+
+	return nil
+}
+
+// ValidateAliasBackends checks each alias still exists on the router or in the cluster and can hold
+// whitelist entries. All problems are reported together; use errors.Is with ErrAliasNotFound,
+// ErrUnsupportedAliasType or ErrBackendNotConfigured to tell configuration mistakes apart from a backend
+// being unreachable.
+func ValidateAliasBackends(ctx context.Context, router *RouterClient, traefik *TraefikClient, aliases []config.FirewallAliasConfig) error {
+	var errs []error
+	checked := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		// The same alias may be offered to several auth groups; it only needs checking once.
+		if checked[strings.ToLower(alias.UUID)] {
+			continue
+		}
+		checked[strings.ToLower(alias.UUID)] = true
+
+		backend, err := BackendForAlias(router, traefik, &alias)
+		if err == nil {
+			err = backend.Validate(ctx)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("alias %s (%s): %w", alias.Name, alias.UUID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,167 @@
+package firewall_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/mocks"
+	"homelab-dashboard/internal/models"
+	"homelab-dashboard/internal/services/firewall"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testHostAliasUUID    = "c0daef37-718c-40e4-bb2b-ba5aab418d0d"
+	testNetworkAliasUUID = "7f93ff45-6c60-4a21-9767-3fc246f4d335"
+	testURLAliasUUID     = "0b0c7d52-3b39-4cf1-9f5d-0a4bba0ad5b4"
+	testMissingAliasUUID = "5a1e4c3e-1d7b-4c43-9a2e-5a3e0d1f9e77"
+)
+
+func TestLoadAliases(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStorageProvider(ctrl)
+
+	ttl := int64(7200)
+	store.EXPECT().GetManagedAliases(gomock.Any()).Return([]*models.FirewallManagedAlias{
+		{UUID: testHostAliasUUID, Name: "Database", AuthGroup: "developers", MaxIPsPerUser: 2, MaxTotalIPs: 10, DefaultTTLSeconds: &ttl},
+		{UUID: testNetworkAliasUUID, Name: "Ingress", AuthGroup: "admins", TraefikNamespace: "traefik", TraefikName: "home-allowlist"},
+	}, nil)
+
+	aliases, err := firewall.LoadAliases(context.Background(), store)
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+
+	assert.Equal(t, "developers", aliases[0].AuthGroup)
+	assert.Equal(t, 2, aliases[0].MaxIPsPerUser)
+	require.NotNil(t, aliases[0].DefaultTTL)
+	assert.Equal(t, 2*time.Hour, *aliases[0].DefaultTTL)
+	assert.Nil(t, aliases[0].Traefik)
+
+	require.NotNil(t, aliases[1].Traefik)
+	assert.Equal(t, config.FirewallTraefikMiddleware{Namespace: "traefik", Name: "home-allowlist"}, *aliases[1].Traefik)
+}
+
+func TestLoadAliasesStorageError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStorageProvider(ctrl)
+	store.EXPECT().GetManagedAliases(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+	_, err := firewall.LoadAliases(context.Background(), store)
+	assert.Error(t, err)
+}
+
+func TestImportConfigAliases(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStorageProvider(ctrl)
+
+	ttl := 2 * time.Hour
+	cfg := &config.Config{Server: config.ServerConfig{ExternalURL: "https://dashboard.example.com"}, Features: &config.FeaturesConfig{}}
+	cfg.Features.FirewallManagement.Aliases = []config.FirewallAliasConfig{
+		{UUID: strings.ToUpper(testHostAliasUUID), Name: "Database", AuthGroup: "admins", MaxIPsPerUser: 1, MaxTotalIPs: 1},
+		{UUID: testNetworkAliasUUID, Name: "Ingress", AuthGroup: "admins", MaxIPsPerUser: 3, MaxTotalIPs: 50, DefaultTTL: &ttl,
+			Traefik: &config.FirewallTraefikMiddleware{Namespace: "traefik", Name: "home-allowlist"}},
+	}
+
+	// The first alias was imported before, and may since have been changed or deleted through the admin API
+	store.EXPECT().ImportManagedAlias(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, alias *models.FirewallManagedAlias) (bool, error) {
+			assert.Equal(t, testHostAliasUUID, alias.UUID)
+			return false, nil
+		})
+	store.EXPECT().ImportManagedAlias(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, alias *models.FirewallManagedAlias) (bool, error) {
+			assert.Equal(t, testNetworkAliasUUID, alias.UUID)
+			assert.Equal(t, "traefik", alias.TraefikNamespace)
+			assert.Equal(t, "home-allowlist", alias.TraefikName)
+			require.NotNil(t, alias.DefaultTTLSeconds)
+			assert.Equal(t, int64(7200), *alias.DefaultTTLSeconds)
+			assert.Equal(t, "https://dashboard.example.com", alias.CreatedByIss)
+			assert.Equal(t, "system", alias.CreatedBySub)
+			return true, nil
+		})
+
+	imported, err := firewall.ImportConfigAliases(context.Background(), cfg, store)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+}
+
+func TestFindAliasByUUID(t *testing.T) {
+	aliases := []config.FirewallAliasConfig{
+		{UUID: testHostAliasUUID, Name: "Database"},
+		{UUID: testNetworkAliasUUID, Name: "VPNUsers"},
+	}
+
+	alias := firewall.FindAliasByUUID(aliases, " "+strings.ToUpper(testNetworkAliasUUID)+" ")
+	require.NotNil(t, alias)
+	assert.Equal(t, "VPNUsers", alias.Name)
+
+	assert.Nil(t, firewall.FindAliasByUUID(aliases, testMissingAliasUUID))
+	assert.Nil(t, firewall.FindAliasByUUID(aliases, ""))
+}
+
+func TestIsManageableAliasType(t *testing.T) {
+	assert.True(t, firewall.IsManageableAliasType("host"))
+	assert.True(t, firewall.IsManageableAliasType("network"))
+	assert.True(t, firewall.IsManageableAliasType("Host(s)"))
+	assert.True(t, firewall.IsManageableAliasType("Network(s)"))
+	assert.False(t, firewall.IsManageableAliasType("urltable"))
+	assert.False(t, firewall.IsManageableAliasType("geoip"))
+	assert.False(t, firewall.IsManageableAliasType(""))
+}
+
+func TestValidateAliasBackends(t *testing.T) {
+	types := map[string]string{
+		testHostAliasUUID:    "host",
+		testNetworkAliasUUID: "network",
+		testURLAliasUUID:     "urltable",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uuid := strings.TrimPrefix(r.URL.Path, "/api/firewall/alias/get_item/")
+		aliasType, ok := types[uuid]
+		if !ok {
+			// OPNsense returns an empty template for unknown uuids
+			fmt.Fprint(w, `{"alias":{"name":"","type":{"host":{"value":"Host(s)","selected":1}}}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"alias":{"name":"alias_%s","type":{"%s":{"value":"","selected":1}}}}`, aliasType, aliasType)
+	}))
+	defer server.Close()
+
+	cfg := config.Config{Features: &config.FeaturesConfig{}}
+	cfg.Features.FirewallManagement.RouterEndpoint = server.URL + "/"
+	client := firewall.NewRouterClient(cfg)
+
+	t.Run("host and network aliases pass", func(t *testing.T) {
+		err := firewall.ValidateAliasBackends(context.Background(), client, nil, []config.FirewallAliasConfig{
+			{UUID: testHostAliasUUID},
+			{UUID: testNetworkAliasUUID},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing alias is reported", func(t *testing.T) {
+		err := firewall.ValidateAliasBackends(context.Background(), client, nil, []config.FirewallAliasConfig{
+			{UUID: testHostAliasUUID},
+			{UUID: testMissingAliasUUID},
+		})
+		assert.ErrorIs(t, err, firewall.ErrAliasNotFound)
+		assert.NotErrorIs(t, err, firewall.ErrUnsupportedAliasType)
+	})
+
+	t.Run("unsupported alias type is reported", func(t *testing.T) {
+		err := firewall.ValidateAliasBackends(context.Background(), client, nil, []config.FirewallAliasConfig{
+			{UUID: testURLAliasUUID},
+		})
+		assert.ErrorIs(t, err, firewall.ErrUnsupportedAliasType)
+	})
+}
@@ -0,0 +1,70 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"homelab-dashboard/internal/config"
+)
+
+var ErrBackendNotConfigured = errors.New("firewall backend is not configured")
+
+// AliasBackend is where an alias's whitelist is enforced: an OPNsense alias or a Traefik middleware.
+type AliasBackend interface {
+	// GetAliasIPs returns the addresses currently applied.
+	GetAliasIPs(ctx context.Context) ([]string, error)
+	// ApplyAliasChanges adds and removes addresses, failing with ErrAliasModifiedConcurrently when
+	// the content changed underneath it.
+	ApplyAliasChanges(ctx context.Context, add, remove []string) (*AliasChangeResult, error)
+	// Validate checks the alias exists and can hold whitelist entries.
+	Validate(ctx context.Context) error
+}
+
+// BackendForAlias returns the backend enforcing alias. Either client may be nil when no alias uses it.
+func BackendForAlias(router *RouterClient, traefik *TraefikClient, alias *config.FirewallAliasConfig) (AliasBackend, error) {
+	if alias.Traefik != nil {
+		if traefik == nil {
+			return nil, ErrBackendNotConfigured
+		}
+		return &traefikAliasBackend{client: traefik, middleware: *alias.Traefik}, nil
+	}
+
+	if router == nil {
+		return nil, ErrBackendNotConfigured
+	}
+	return &routerAliasBackend{client: router, aliasUUID: alias.UUID}, nil
+}
+
+type routerAliasBackend struct {
+	client    *RouterClient
+	aliasUUID string
+}
+
+func (b *routerAliasBackend) GetAliasIPs(ctx context.Context) ([]string, error) {
+	return b.client.GetAliasIPs(ctx, b.aliasUUID)
+}
+
+func (b *routerAliasBackend) ApplyAliasChanges(ctx context.Context, add, remove []string) (*AliasChangeResult, error) {
+	return b.client.ApplyAliasChanges(ctx, b.aliasUUID, add, remove)
+}
+
+func (b *routerAliasBackend) Validate(ctx context.Context) error {
+	_, err := b.client.ValidateAlias(ctx, b.aliasUUID)
+	return err
+}
+
+type traefikAliasBackend struct {
+	client     *TraefikClient
+	middleware config.FirewallTraefikMiddleware
+}
+
+func (b *traefikAliasBackend) GetAliasIPs(ctx context.Context) ([]string, error) {
+	return b.client.GetSourceRange(ctx, b.middleware)
+}
+
+func (b *traefikAliasBackend) ApplyAliasChanges(ctx context.Context, add, remove []string) (*AliasChangeResult, error) {
+	return b.client.ApplySourceRangeChanges(ctx, b.middleware, add, remove)
+}
+
+func (b *traefikAliasBackend) Validate(ctx context.Context) error {
+	return b.client.ValidateMiddleware(ctx, b.middleware)
+}
@@ -0,0 +1,175 @@
+package firewall
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"homelab-dashboard/internal/models"
+	"io"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ParseBlacklistValue parses a single IP, a CIDR block, a start-end range or an
+// autonomous system number ("AS64496") into an unsaved blacklist rule.
+// Only the target fields (Value, RangeStart, RangeEnd, ASN) are populated.
+func ParseBlacklistValue(value string) (*models.FirewallBlacklistRule, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("blacklist value is empty")
+	}
+
+	if len(value) > 2 && strings.EqualFold(value[:2], "AS") {
+		asn, err := strconv.ParseInt(value[2:], 10, 64)
+		if err != nil || asn <= 0 || asn > math.MaxUint32 {
+			return nil, fmt.Errorf("invalid AS number %q", value)
+		}
+		return &models.FirewallBlacklistRule{
+			Value: fmt.Sprintf("AS%d", asn),
+			ASN:   &asn,
+		}, nil
+	}
+
+	var start, end netip.Addr
+	var normalized string
+
+	switch {
+	case strings.Contains(value, "/"):
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", value, err)
+		}
+		prefix = prefix.Masked()
+		start, end = prefix.Addr(), lastAddr(prefix)
+		normalized = prefix.String()
+
+	case strings.Contains(value, "-"):
+		startStr, endStr, _ := strings.Cut(value, "-")
+		var err error
+		if start, err = parseHostAddr(startStr); err != nil {
+			return nil, err
+		}
+		if end, err = parseHostAddr(endStr); err != nil {
+			return nil, err
+		}
+		if start.Is4() != end.Is4() {
+			return nil, fmt.Errorf("range %q mixes IPv4 and IPv6 addresses", value)
+		}
+		if end.Less(start) {
+			return nil, fmt.Errorf("range %q ends before it starts", value)
+		}
+		normalized = start.String() + "-" + end.String()
+
+	default:
+		addr, err := parseHostAddr(value)
+		if err != nil {
+			return nil, err
+		}
+		start, end = addr, addr
+		normalized = addr.String()
+	}
+
+	startStr, endStr := start.String(), end.String()
+	return &models.FirewallBlacklistRule{
+		Value:      normalized,
+		RangeStart: &startStr,
+		RangeEnd:   &endStr,
+	}, nil
+}
+
+// threatFeedJSONLine covers the JSON-lines variants of the Spamhaus DROP and ASN-DROP lists.
+type threatFeedJSONLine struct {
+	Type   string `json:"type"`
+	CIDR   string `json:"cidr"`
+	SBLID  string `json:"sblid"`
+	ASN    int64  `json:"asn"`
+	ASName string `json:"asname"`
+}
+
+// ParseThreatFeed reads a plain-text threat feed such as Spamhaus DROP ("192.0.2.0/24 ; SBL123")
+// or ASN-DROP ("AS64496 ; XX | Example"). Text after ';' is kept as the rule reason, lines
+// starting with ';' or '#' are comments, and JSON-lines feeds are accepted as well.
+// Lines that cannot be parsed are counted in skipped rather than failing the whole feed.
+func ParseThreatFeed(r io.Reader) (rules []*models.FirewallBlacklistRule, skipped int, err error) {
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var value, reason string
+		if strings.HasPrefix(line, "{") {
+			var entry threatFeedJSONLine
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				skipped++
+				continue
+			}
+			if entry.Type == "metadata" {
+				continue
+			}
+			switch {
+			case entry.CIDR != "":
+				value, reason = entry.CIDR, entry.SBLID
+			case entry.ASN > 0:
+				value, reason = fmt.Sprintf("AS%d", entry.ASN), entry.ASName
+			default:
+				skipped++
+				continue
+			}
+		} else {
+			value, reason, _ = strings.Cut(line, ";")
+			value, _, _ = strings.Cut(value, "#")
+			if fields := strings.Fields(value); len(fields) > 0 {
+				value = fields[0]
+			}
+		}
+
+		rule, err := ParseBlacklistValue(value)
+		if err != nil {
+			skipped++
+			continue
+		}
+
+		if seen[rule.Value] {
+			continue
+		}
+		seen[rule.Value] = true
+
+		if reason = strings.TrimSpace(reason); reason != "" {
+			rule.Reason = &reason
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, skipped, fmt.Errorf("failed to read threat feed: %w", err)
+	}
+
+	return rules, skipped, nil
+}
+
+func parseHostAddr(s string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid IP address %q: %w", s, err)
+	}
+	if addr.Zone() != "" {
+		return netip.Addr{}, fmt.Errorf("IP address %q must not include a zone", s)
+	}
+	return addr.Unmap(), nil
+}
+
+// lastAddr returns the highest address contained in a masked prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(bytes)*8; i++ {
+		bytes[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
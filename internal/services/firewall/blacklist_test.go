@@ -0,0 +1,128 @@
+package firewall
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlacklistValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedValue string
+		expectedStart string
+		expectedEnd   string
+		expectedASN   int64
+		expectError   bool
+	}{
+		{
+			name:          "single IPv4 address",
+			value:         " 192.0.2.10 ",
+			expectedValue: "192.0.2.10",
+			expectedStart: "192.0.2.10",
+			expectedEnd:   "192.0.2.10",
+		},
+		{
+			name:          "IPv4-mapped IPv6 address is unmapped",
+			value:         "::ffff:192.0.2.10",
+			expectedValue: "192.0.2.10",
+			expectedStart: "192.0.2.10",
+			expectedEnd:   "192.0.2.10",
+		},
+		{
+			name:          "CIDR block is masked",
+			value:         "198.51.100.77/24",
+			expectedValue: "198.51.100.0/24",
+			expectedStart: "198.51.100.0",
+			expectedEnd:   "198.51.100.255",
+		},
+		{
+			name:          "IPv6 CIDR block",
+			value:         "2001:db8::/32",
+			expectedValue: "2001:db8::/32",
+			expectedStart: "2001:db8::",
+			expectedEnd:   "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff",
+		},
+		{
+			name:          "start-end range",
+			value:         "203.0.113.10 - 203.0.113.20",
+			expectedValue: "203.0.113.10-203.0.113.20",
+			expectedStart: "203.0.113.10",
+			expectedEnd:   "203.0.113.20",
+		},
+		{
+			name:          "AS number",
+			value:         "as64496",
+			expectedValue: "AS64496",
+			expectedASN:   64496,
+		},
+		{name: "empty value", value: "  ", expectError: true},
+		{name: "garbage", value: "not-an-ip", expectError: true},
+		{name: "reversed range", value: "203.0.113.20-203.0.113.10", expectError: true},
+		{name: "mixed family range", value: "203.0.113.1-2001:db8::1", expectError: true},
+		{name: "AS number out of range", value: "AS4294967296", expectError: true},
+		{name: "zoned address", value: "fe80::1%eth0", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseBlacklistValue(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedValue, rule.Value)
+
+			if tt.expectedASN != 0 {
+				require.NotNil(t, rule.ASN)
+				assert.Equal(t, tt.expectedASN, *rule.ASN)
+				assert.Nil(t, rule.RangeStart)
+				assert.Nil(t, rule.RangeEnd)
+				return
+			}
+
+			assert.Nil(t, rule.ASN)
+			require.NotNil(t, rule.RangeStart)
+			require.NotNil(t, rule.RangeEnd)
+			assert.Equal(t, tt.expectedStart, *rule.RangeStart)
+			assert.Equal(t, tt.expectedEnd, *rule.RangeEnd)
+		})
+	}
+}
+
+func TestParseThreatFeed(t *testing.T) {
+	feed := strings.Join([]string{
+		"; Spamhaus DROP List 2024/01/01",
+		"; Last-Modified: Mon, 01 Jan 2024 00:00:00 GMT",
+		"1.10.16.0/20 ; SBL256894",
+		"2.56.192.0/22 ; SBL459831",
+		"1.10.16.0/20 ; SBL256894",
+		"# hash comment",
+		"",
+		"AS64496 ; XX | Example Networks",
+		"this is not valid",
+		`{"cidr":"5.134.128.0/19","sblid":"SBL270738","rir":"ripencc"}`,
+		`{"asn":64497,"rir":"arin","domain":"example.net","cc":"US","asname":"EXAMPLE-AS"}`,
+		`{"type":"metadata","timestamp":1700000000,"size":2,"records":2}`,
+	}, "\n")
+
+	rules, skipped, err := ParseThreatFeed(strings.NewReader(feed))
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+
+	values := make([]string, 0, len(rules))
+	reasons := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		values = append(values, rule.Value)
+		require.NotNil(t, rule.Reason)
+		reasons = append(reasons, *rule.Reason)
+	}
+
+	assert.Equal(t, []string{"1.10.16.0/20", "2.56.192.0/22", "AS64496", "5.134.128.0/19", "AS64497"}, values)
+	assert.Equal(t, []string{"SBL256894", "SBL459831", "XX | Example Networks", "SBL270738", "EXAMPLE-AS"}, reasons)
+}
@@ -0,0 +1,138 @@
+package firewall
+
+import (
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoIPResolver looks up country and ASN details in local MaxMind-format (MMDB) databases.
+// Either database may be omitted, in which case the corresponding fields are left empty.
+type GeoIPResolver struct {
+	countryDB *maxminddb.Reader
+	asnDB     *maxminddb.Reader
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+type asnRecord struct {
+	AutonomousSystemNumber       uint   `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// NewGeoIPResolver opens the databases configured under features.firewall_management.geoip.
+func NewGeoIPResolver(cfg config.FirewallGeoIPConfig) (*GeoIPResolver, error) {
+	resolver := &GeoIPResolver{}
+
+	if cfg.CountryDatabase != "" {
+		reader, err := maxminddb.Open(cfg.CountryDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open country database: %w", err)
+		}
+		resolver.countryDB = reader
+	}
+
+	if cfg.ASNDatabase != "" {
+		reader, err := maxminddb.Open(cfg.ASNDatabase)
+		if err != nil {
+			resolver.Close()
+			return nil, fmt.Errorf("failed to open ASN database: %w", err)
+		}
+		resolver.asnDB = reader
+	}
+
+	return resolver, nil
+}
+
+// Lookup returns whatever enrichment the configured databases know about ip.
+// Addresses missing from a database (e.g. private ranges) yield empty fields, not an error.
+func (r *GeoIPResolver) Lookup(ip net.IP) (models.IPEnrichment, error) {
+	var enrichment models.IPEnrichment
+
+	if r.countryDB != nil {
+		var record countryRecord
+		if err := r.countryDB.Lookup(ip, &record); err != nil {
+			return enrichment, fmt.Errorf("country lookup failed: %w", err)
+		}
+
+		country := record.Country.ISOCode
+		if country == "" {
+			country = record.RegisteredCountry.ISOCode
+		}
+		if country != "" {
+			enrichment.CountryCode = &country
+		}
+	}
+
+	if r.asnDB != nil {
+		var record asnRecord
+		if err := r.asnDB.Lookup(ip, &record); err != nil {
+			return enrichment, fmt.Errorf("ASN lookup failed: %w", err)
+		}
+
+		if record.AutonomousSystemNumber != 0 {
+			asn := int64(record.AutonomousSystemNumber)
+			enrichment.ASN = &asn
+		}
+		if record.AutonomousSystemOrganization != "" {
+			organization := record.AutonomousSystemOrganization
+			enrichment.ASOrganization = &organization
+		}
+	}
+
+	return enrichment, nil
+}
+
+func (r *GeoIPResolver) Close() {
+	if r.countryDB != nil {
+		_ = r.countryDB.Close()
+	}
+	if r.asnDB != nil {
+		_ = r.asnDB.Close()
+	}
+}
+
+// CheckAliasGeoPolicy applies an alias's country and ASN allow/deny lists to an enriched address.
+// Deny lists win over allow lists, and an address whose country or ASN is unknown never satisfies an allow list.
+func CheckAliasGeoPolicy(alias *config.FirewallAliasConfig, enrichment models.IPEnrichment) error {
+	country := ""
+	if enrichment.CountryCode != nil {
+		country = strings.ToUpper(*enrichment.CountryCode)
+	}
+
+	if country != "" && slices.Contains(alias.DeniedCountries, country) {
+		return fmt.Errorf("addresses located in %s are not allowed for this alias", country)
+	}
+
+	if len(alias.AllowedCountries) > 0 && !slices.Contains(alias.AllowedCountries, country) {
+		if country == "" {
+			return fmt.Errorf("the location of this address could not be determined and this alias only allows specific countries")
+		}
+		return fmt.Errorf("addresses located in %s are not allowed for this alias", country)
+	}
+
+	if enrichment.ASN != nil && slices.Contains(alias.DeniedASNs, *enrichment.ASN) {
+		return fmt.Errorf("addresses announced by AS%d are not allowed for this alias", *enrichment.ASN)
+	}
+
+	if len(alias.AllowedASNs) > 0 && (enrichment.ASN == nil || !slices.Contains(alias.AllowedASNs, *enrichment.ASN)) {
+		if enrichment.ASN == nil {
+			return fmt.Errorf("the network of this address could not be determined and this alias only allows specific networks")
+		}
+		return fmt.Errorf("addresses announced by AS%d are not allowed for this alias", *enrichment.ASN)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package firewall
+
+import (
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAliasGeoPolicy(t *testing.T) {
+	country := func(code string) *string { return &code }
+	asn := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name        string
+		alias       config.FirewallAliasConfig
+		enrichment  models.IPEnrichment
+		expectError bool
+	}{
+		{
+			name:       "no policy allows unknown address",
+			alias:      config.FirewallAliasConfig{},
+			enrichment: models.IPEnrichment{},
+		},
+		{
+			name:       "allowed country passes",
+			alias:      config.FirewallAliasConfig{AllowedCountries: []string{"US", "CA"}},
+			enrichment: models.IPEnrichment{CountryCode: country("CA")},
+		},
+		{
+			name:        "country outside allow list is rejected",
+			alias:       config.FirewallAliasConfig{AllowedCountries: []string{"US"}},
+			enrichment:  models.IPEnrichment{CountryCode: country("DE")},
+			expectError: true,
+		},
+		{
+			name:        "unknown country does not satisfy allow list",
+			alias:       config.FirewallAliasConfig{AllowedCountries: []string{"US"}},
+			enrichment:  models.IPEnrichment{},
+			expectError: true,
+		},
+		{
+			name:        "denied country is rejected",
+			alias:       config.FirewallAliasConfig{DeniedCountries: []string{"RU"}},
+			enrichment:  models.IPEnrichment{CountryCode: country("ru")},
+			expectError: true,
+		},
+		{
+			name:       "unknown country passes deny list",
+			alias:      config.FirewallAliasConfig{DeniedCountries: []string{"RU"}},
+			enrichment: models.IPEnrichment{},
+		},
+		{
+			name: "deny list wins over allow list",
+			alias: config.FirewallAliasConfig{
+				AllowedCountries: []string{"US"},
+				DeniedASNs:       []int64{14061},
+			},
+			enrichment:  models.IPEnrichment{CountryCode: country("US"), ASN: asn(14061)},
+			expectError: true,
+		},
+		{
+			name:       "allowed ASN passes",
+			alias:      config.FirewallAliasConfig{AllowedASNs: []int64{7922}},
+			enrichment: models.IPEnrichment{ASN: asn(7922)},
+		},
+		{
+			name:        "unknown ASN does not satisfy allow list",
+			alias:       config.FirewallAliasConfig{AllowedASNs: []int64{7922}},
+			enrichment:  models.IPEnrichment{CountryCode: country("US")},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAliasGeoPolicy(&tt.alias, tt.enrichment)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
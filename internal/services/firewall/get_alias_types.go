@@ -91,3 +91,29 @@ func getSelected(options map[string]SelectOption) string {
 	}
 	return ""
 }
+
+// AliasSearchResponse is the paged result of /api/firewall/alias/search_item
+type AliasSearchResponse struct {
+	Rows     []AliasSearchRow `json:"rows"`
+	RowCount int              `json:"rowCount"`
+	Total    int              `json:"total"`
+	Current  int              `json:"current"`
+}
+
+// AliasSearchRow summarises a single alias in a search result
+type AliasSearchRow struct {
+	UUID        string `json:"uuid"`
+	Enabled     string `json:"enabled"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Content     string `json:"content"`
+}
+
+// IsManageableAliasType reports whether entries can be written to an alias of the given type.
+// Only host and network aliases hold plain addresses; the others are generated by the router.
+// Search results report display names such as "Host(s)", so those are accepted too.
+func IsManageableAliasType(aliasType string) bool {
+	aliasType = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(aliasType)), "(s)")
+	return aliasType == "host" || aliasType == "network"
+}
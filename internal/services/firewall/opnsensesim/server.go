@@ -0,0 +1,389 @@
+// Package opnsensesim is an in-process stand-in for the OPNsense alias API used by firewall.RouterClient.
+// It keeps the stored alias definitions and the live firewall tables apart the way OPNsense does: set_item
+// only changes the stored definition until reconfigure applies it, while alias_util changes both at once.
+package opnsensesim
+
+import (
+	"encoding/json"
+	"homelab-dashboard/internal/services/firewall"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint identifies an alias API call, for failure injection, hooks and call counts.
+type Endpoint string
+
+const (
+	EndpointGetItem         Endpoint = "get_item"
+	EndpointSetItem         Endpoint = "set_item"
+	EndpointSearchItem      Endpoint = "search_item"
+	EndpointReconfigure     Endpoint = "reconfigure"
+	EndpointAliasUtilAdd    Endpoint = "alias_util_add"
+	EndpointAliasUtilDelete Endpoint = "alias_util_delete"
+)
+
+// Failure makes calls to an endpoint answer with Status and Body instead of being handled.
+// Count limits how many calls fail; zero fails every call until the failure is cleared.
+type Failure struct {
+	Status int
+	Body   string
+	Count  int
+}
+
+type alias struct {
+	uuid        string
+	name        string
+	aliasType   string
+	description string
+	content     []string // stored definition, as returned by get_item
+	live        []string // what the firewall is currently enforcing
+}
+
+// Server serves the OPNsense alias endpoints. The zero value is not usable; create one with NewServer.
+type Server struct {
+	apiKey    string
+	apiSecret string
+
+	mu       sync.Mutex
+	aliases  map[string]*alias // keyed by lowercase uuid
+	latency  time.Duration
+	failures map[Endpoint]*Failure
+	hooks    map[Endpoint][]func()
+	calls    map[Endpoint]int
+}
+
+// NewServer creates an empty simulator. Requests must authenticate with apiKey and apiSecret as basic auth
+// credentials, like the real API; leaving both empty accepts any request.
+func NewServer(apiKey, apiSecret string) *Server {
+	return &Server{
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		aliases:   make(map[string]*alias),
+		failures:  make(map[Endpoint]*Failure),
+		hooks:     make(map[Endpoint][]func()),
+		calls:     make(map[Endpoint]int),
+	}
+}
+
+// AddAlias defines an alias whose content is already applied to the live table.
+// aliasType is an OPNsense type key such as "host", "network" or "urltable".
+func (s *Server) AddAlias(uuid, name, aliasType string, content ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.aliases[strings.ToLower(uuid)] = &alias{
+		uuid:      uuid,
+		name:      name,
+		aliasType: aliasType,
+		content:   slices.Clone(content),
+		live:      slices.Clone(content),
+	}
+}
+
+// EditAlias changes an alias the way an administrator using the OPNsense UI would: the stored definition
+// is updated and applied straight away. Use it from a hook to simulate a concurrent edit.
+func (s *Server) EditAlias(uuid string, add, remove []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.aliases[strings.ToLower(uuid)]
+	if !ok {
+		return
+	}
+
+	a.content = applyEdit(a.content, add, remove)
+	a.live = slices.Clone(a.content)
+}
+
+// Content returns the stored definition of an alias, sorted, or nil when the alias does not exist.
+func (s *Server) Content(uuid string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a, ok := s.aliases[strings.ToLower(uuid)]; ok {
+		return sorted(a.content)
+	}
+	return nil
+}
+
+// Live returns the addresses the firewall is enforcing for an alias, sorted.
+func (s *Server) Live(uuid string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a, ok := s.aliases[strings.ToLower(uuid)]; ok {
+		return sorted(a.live)
+	}
+	return nil
+}
+
+// SetLatency delays every response by d, or until the request is cancelled.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// InjectFailure makes calls to endpoint fail as described by failure, replacing any earlier failure.
+func (s *Server) InjectFailure(endpoint Endpoint, failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if failure.Status == 0 {
+		failure.Status = http.StatusInternalServerError
+	}
+	s.failures[endpoint] = &failure
+}
+
+// ClearFailures removes every injected failure.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = make(map[Endpoint]*Failure)
+}
+
+// AfterNext runs fn once, after the next successful call to endpoint has been handled and before its
+// response is written. Hooks run without the simulator locked, so they may call EditAlias.
+func (s *Server) AfterNext(endpoint Endpoint, fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks[endpoint] = append(s.hooks[endpoint], fn)
+}
+
+// Calls returns how many requests reached endpoint, including failed ones.
+func (s *Server) Calls(endpoint Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[endpoint]
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.apiKey != "" || s.apiSecret != "" {
+		key, secret, ok := r.BasicAuth()
+		if !ok || key != s.apiKey || secret != s.apiSecret {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"status": http.StatusUnauthorized, "message": "Authentication Failed"})
+			return
+		}
+	}
+
+	endpoint, arg, ok := route(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls[endpoint]++
+	latency := s.latency
+	failure := s.takeFailure(endpoint)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if failure != nil {
+		w.WriteHeader(failure.Status)
+		_, _ = w.Write([]byte(failure.Body))
+		return
+	}
+
+	status, body := s.handle(endpoint, arg, r)
+
+	s.mu.Lock()
+	hooks := s.hooks[endpoint]
+	delete(s.hooks, endpoint)
+	s.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	writeJSON(w, status, body)
+}
+
+// takeFailure returns the failure to answer a call to endpoint with, if any. Callers hold s.mu.
+func (s *Server) takeFailure(endpoint Endpoint) *Failure {
+	failure, ok := s.failures[endpoint]
+	if !ok {
+		return nil
+	}
+
+	if failure.Count > 0 {
+		failure.Count--
+		if failure.Count == 0 {
+			delete(s.failures, endpoint)
+		}
+	}
+
+	return &Failure{Status: failure.Status, Body: failure.Body}
+}
+
+func (s *Server) handle(endpoint Endpoint, arg string, r *http.Request) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch endpoint {
+	case EndpointGetItem:
+		a, ok := s.aliases[strings.ToLower(arg)]
+		if !ok {
+			// OPNsense answers unknown uuids with an empty template.
+			return http.StatusOK, firewall.AliasGetResponse{}
+		}
+		return http.StatusOK, firewall.AliasGetResponse{Alias: a.detail()}
+
+	case EndpointSearchItem:
+		rows := make([]firewall.AliasSearchRow, 0, len(s.aliases))
+		for _, a := range s.aliases {
+			rows = append(rows, firewall.AliasSearchRow{
+				UUID:        a.uuid,
+				Enabled:     "1",
+				Name:        a.name,
+				Type:        a.aliasType,
+				Description: a.description,
+				Content:     strings.Join(a.content, ","),
+			})
+		}
+		slices.SortFunc(rows, func(x, y firewall.AliasSearchRow) int { return strings.Compare(x.Name, y.Name) })
+		return http.StatusOK, firewall.AliasSearchResponse{Rows: rows, RowCount: len(rows), Total: len(rows), Current: 1}
+
+	case EndpointSetItem:
+		a, ok := s.aliases[strings.ToLower(arg)]
+		if !ok {
+			return http.StatusOK, map[string]string{"result": "failed"}
+		}
+
+		var req firewall.AliasSetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return http.StatusBadRequest, map[string]string{"result": "failed"}
+		}
+
+		a.content = nil
+		for _, line := range strings.Split(req.Alias.Content, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				a.content = append(a.content, line)
+			}
+		}
+		if req.Alias.Name != "" {
+			a.name = req.Alias.Name
+		}
+		if req.Alias.Type != "" {
+			a.aliasType = req.Alias.Type
+		}
+		a.description = req.Alias.Description
+		return http.StatusOK, map[string]string{"result": "saved"}
+
+	case EndpointReconfigure:
+		for _, a := range s.aliases {
+			a.live = slices.Clone(a.content)
+		}
+		return http.StatusOK, map[string]string{"status": "ok"}
+
+	case EndpointAliasUtilAdd, EndpointAliasUtilDelete:
+		a := s.aliasByName(arg)
+		if a == nil {
+			return http.StatusOK, firewall.AliasUtilResponse{Status: "failed"}
+		}
+
+		var req firewall.AliasUtilRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Address == "" {
+			return http.StatusOK, firewall.AliasUtilResponse{Status: "failed"}
+		}
+
+		if endpoint == EndpointAliasUtilAdd {
+			a.content = applyEdit(a.content, []string{req.Address}, nil)
+			a.live = applyEdit(a.live, []string{req.Address}, nil)
+		} else {
+			a.content = applyEdit(a.content, nil, []string{req.Address})
+			a.live = applyEdit(a.live, nil, []string{req.Address})
+		}
+		return http.StatusOK, firewall.AliasUtilResponse{Status: "done"}
+	}
+
+	return http.StatusNotFound, nil
+}
+
+// aliasByName finds an alias by its name, which is how alias_util addresses them. Callers hold s.mu.
+func (s *Server) aliasByName(name string) *alias {
+	for _, a := range s.aliases {
+		if a.name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func (a *alias) detail() firewall.AliasDetail {
+	content := make(map[string]firewall.ContentItem, len(a.content))
+	for _, value := range a.content {
+		content[value] = firewall.ContentItem{Value: value, Selected: 1}
+	}
+
+	return firewall.AliasDetail{
+		Enabled:     "1",
+		Name:        a.name,
+		Type:        map[string]firewall.SelectOption{a.aliasType: {Value: a.aliasType, Selected: 1}},
+		Content:     content,
+		Description: a.description,
+	}
+}
+
+// route maps a request to the endpoint it calls and the uuid or alias name in its path.
+func route(r *http.Request) (Endpoint, string, bool) {
+	path := r.URL.Path
+
+	for _, candidate := range []struct {
+		prefix   string
+		method   string
+		endpoint Endpoint
+	}{
+		{"/api/firewall/alias/get_item/", http.MethodGet, EndpointGetItem},
+		{"/api/firewall/alias/set_item/", http.MethodPost, EndpointSetItem},
+		{"/api/firewall/alias_util/add/", http.MethodPost, EndpointAliasUtilAdd},
+		{"/api/firewall/alias_util/delete/", http.MethodPost, EndpointAliasUtilDelete},
+	} {
+		if arg, ok := strings.CutPrefix(path, candidate.prefix); ok && arg != "" && r.Method == candidate.method {
+			return candidate.endpoint, arg, true
+		}
+	}
+
+	switch {
+	case path == "/api/firewall/alias/search_item" && r.Method == http.MethodGet:
+		return EndpointSearchItem, "", true
+	case path == "/api/firewall/alias/reconfigure" && r.Method == http.MethodPost:
+		return EndpointReconfigure, "", true
+	}
+
+	return "", "", false
+}
+
+func applyEdit(content, add, remove []string) []string {
+	result := slices.DeleteFunc(slices.Clone(content), func(value string) bool {
+		return slices.Contains(remove, value) || slices.Contains(remove, firewall.StripCIDR(value))
+	})
+	for _, value := range add {
+		if !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func sorted(values []string) []string {
+	result := slices.Clone(values)
+	slices.Sort(result)
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
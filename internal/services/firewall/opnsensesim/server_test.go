@@ -0,0 +1,118 @@
+package opnsensesim_test
+
+import (
+	"context"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/services/firewall"
+	"homelab-dashboard/internal/services/firewall/opnsensesim"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAliasUUID = "c0daef37-718c-40e4-bb2b-ba5aab418d0d"
+	testAPIKey    = "key"
+	testAPISecret = "secret"
+)
+
+func newSimulatedRouter(t *testing.T, aliasType string, content ...string) (*opnsensesim.Server, *firewall.RouterClient) {
+	sim := opnsensesim.NewServer(testAPIKey, testAPISecret)
+	sim.AddAlias(testAliasUUID, "Database", aliasType, content...)
+
+	server := httptest.NewServer(sim)
+	t.Cleanup(server.Close)
+
+	cfg := config.Config{Features: &config.FeaturesConfig{}}
+	cfg.Features.FirewallManagement.RouterEndpoint = server.URL
+	cfg.Features.FirewallManagement.RouterAPIKey = testAPIKey
+	cfg.Features.FirewallManagement.RouterAPISecret = testAPISecret
+	return sim, firewall.NewRouterClient(cfg)
+}
+
+func TestRouterClientAgainstSimulator(t *testing.T) {
+	sim, client := newSimulatedRouter(t, "host", "10.0.0.1", "10.0.0.2")
+
+	alias, err := client.ValidateAlias(context.Background(), testAliasUUID)
+	require.NoError(t, err)
+	assert.Equal(t, "Database", alias.Name)
+
+	result, err := client.ApplyAliasChanges(context.Background(), testAliasUUID, []string{"10.0.0.3"}, []string{"10.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.3"}, result.Added)
+
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3"}, sim.Content(testAliasUUID))
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3"}, sim.Live(testAliasUUID))
+	assert.Zero(t, sim.Calls(opnsensesim.EndpointReconfigure))
+}
+
+func TestSimulatorRejectsUnsupportedAliasType(t *testing.T) {
+	sim, client := newSimulatedRouter(t, "external", "10.0.0.1")
+
+	_, err := client.ApplyAliasChanges(context.Background(), testAliasUUID, []string{"10.0.0.2"}, nil)
+	assert.ErrorIs(t, err, firewall.ErrUnsupportedAliasType)
+	assert.Equal(t, []string{"10.0.0.1"}, sim.Live(testAliasUUID))
+	assert.Zero(t, sim.Calls(opnsensesim.EndpointAliasUtilAdd))
+}
+
+func TestSimulatorConcurrentEdit(t *testing.T) {
+	sim, client := newSimulatedRouter(t, "host", "10.0.0.1")
+	sim.AfterNext(opnsensesim.EndpointAliasUtilAdd, func() {
+		sim.EditAlias(testAliasUUID, []string{"192.0.2.10"}, nil)
+	})
+
+	_, err := client.ApplyAliasChanges(context.Background(), testAliasUUID, []string{"10.0.0.2"}, nil)
+	assert.ErrorIs(t, err, firewall.ErrAliasModifiedConcurrently)
+	assert.Contains(t, sim.Content(testAliasUUID), "192.0.2.10")
+}
+
+func TestSimulatorFailureInjection(t *testing.T) {
+	sim, client := newSimulatedRouter(t, "host", "10.0.0.1")
+	sim.InjectFailure(opnsensesim.EndpointAliasUtilAdd, opnsensesim.Failure{Status: http.StatusBadGateway, Count: 1})
+
+	_, err := client.ApplyAliasChanges(context.Background(), testAliasUUID, []string{"10.0.0.2"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+	assert.Equal(t, []string{"10.0.0.1"}, sim.Content(testAliasUUID))
+
+	// The failure only applied to one call
+	_, err = client.ApplyAliasChanges(context.Background(), testAliasUUID, []string{"10.0.0.2"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sim.Calls(opnsensesim.EndpointAliasUtilAdd))
+}
+
+func TestSimulatorLatency(t *testing.T) {
+	sim, client := newSimulatedRouter(t, "host")
+	sim.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetAliasIPs(ctx, testAliasUUID)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSimulatorRejectsBadCredentials(t *testing.T) {
+	sim := opnsensesim.NewServer(testAPIKey, testAPISecret)
+	server := httptest.NewServer(sim)
+	t.Cleanup(server.Close)
+
+	cfg := config.Config{Features: &config.FeaturesConfig{}}
+	cfg.Features.FirewallManagement.RouterEndpoint = server.URL
+	cfg.Features.FirewallManagement.RouterAPIKey = "wrong"
+
+	_, err := firewall.NewRouterClient(cfg).ListAliases(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestSimulatorUnknownAlias(t *testing.T) {
+	_, client := newSimulatedRouter(t, "host")
+
+	_, err := client.GetAlias(context.Background(), "7f93ff45-6c60-4a21-9767-3fc246f4d335")
+	assert.ErrorIs(t, err, firewall.ErrAliasNotFound)
+}
@@ -0,0 +1,76 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"time"
+)
+
+// RateLimitAction names the whitelist change a rate limit applies to.
+type RateLimitAction string
+
+const (
+	RateLimitCreate RateLimitAction = "create"
+	RateLimitDelete RateLimitAction = "delete"
+)
+
+// CheckRateLimit records a create or delete by a user on an alias and reports whether it stays within the
+// configured budget. When it does not, the returned duration is how long until the next attempt can succeed.
+// A change that is allowed but then fails should be given back with ReleaseRateLimit.
+func CheckRateLimit(ctx context.Context, cache data.Provider, limits *config.FirewallRateLimitConfig, action RateLimitAction, aliasUUID, ownerIss, ownerSub string) (bool, time.Duration, error) {
+	limit := rateLimitFor(limits, action)
+	if limit == 0 {
+		return true, 0, nil
+	}
+
+	return cache.SlidingWindowAllow(ctx, rateLimitKey(action, aliasUUID, ownerIss, ownerSub), limit, limits.Window)
+}
+
+// ReleaseRateLimit gives back the budget taken by CheckRateLimit for a change that did not go through, so
+// rejected requests do not count against the user.
+func ReleaseRateLimit(ctx context.Context, cache data.Provider, limits *config.FirewallRateLimitConfig, action RateLimitAction, aliasUUID, ownerIss, ownerSub string) error {
+	if rateLimitFor(limits, action) == 0 {
+		return nil
+	}
+
+	return cache.SlidingWindowRelease(ctx, rateLimitKey(action, aliasUUID, ownerIss, ownerSub))
+}
+
+// rateLimitFor returns the budget for action, zero when it is not limited.
+func rateLimitFor(limits *config.FirewallRateLimitConfig, action RateLimitAction) int {
+	if limits == nil {
+		return 0
+	}
+
+	if action == RateLimitDelete {
+		return limits.DeletesPerWindow
+	}
+	return limits.CreatesPerWindow
+}
+
+func rateLimitKey(action RateLimitAction, aliasUUID, ownerIss, ownerSub string) string {
+	return fmt.Sprintf("ratelimit:firewall:%s:%s:%s|%s", action, aliasUUID, ownerIss, ownerSub)
+}
+
+// ExceedsNetworkRotation reports whether a user's recent entries span more networks than the detector allows.
+func ExceedsNetworkRotation(detection *config.FirewallAbuseDetectionConfig, distinctASNs, distinctCountries int) bool {
+	if detection == nil {
+		return false
+	}
+
+	if detection.MaxDistinctASNs > 0 && distinctASNs > detection.MaxDistinctASNs {
+		return true
+	}
+
+	return detection.MaxDistinctCountries > 0 && distinctCountries > detection.MaxDistinctCountries
+}
+
+// ShouldReportNetworkRotation claims the single network rotation report allowed per user per detection window,
+// so a user who keeps rotating does not flood the audit log with one event per entry.
+func ShouldReportNetworkRotation(ctx context.Context, cache data.Provider, detection *config.FirewallAbuseDetectionConfig, ownerIss, ownerSub string) (bool, error) {
+	key := fmt.Sprintf("abuse:firewall:network_rotation:%s|%s", ownerIss, ownerSub)
+	allowed, _, err := cache.SlidingWindowAllow(ctx, key, 1, detection.Window)
+	return allowed, err
+}
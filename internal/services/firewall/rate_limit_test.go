@@ -0,0 +1,107 @@
+package firewall
+
+import (
+	"context"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRateLimit(t *testing.T) {
+	cache, err := data.NewMemCache(&config.Config{}, slog.Default())
+	require.NoError(t, err)
+
+	limits := &config.FirewallRateLimitConfig{CreatesPerWindow: 2, DeletesPerWindow: 1, Window: time.Hour}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := CheckRateLimit(ctx, cache, limits, RateLimitCreate, testAliasUUID, "iss", "alice")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := CheckRateLimit(ctx, cache, limits, RateLimitCreate, testAliasUUID, "iss", "alice")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, 59*time.Minute)
+
+	// Deletes, other users and other aliases are counted separately
+	allowed, _, _ = CheckRateLimit(ctx, cache, limits, RateLimitDelete, testAliasUUID, "iss", "alice")
+	assert.True(t, allowed)
+	allowed, _, _ = CheckRateLimit(ctx, cache, limits, RateLimitCreate, testAliasUUID, "iss", "bob")
+	assert.True(t, allowed)
+	allowed, _, _ = CheckRateLimit(ctx, cache, limits, RateLimitCreate, "other-alias", "iss", "alice")
+	assert.True(t, allowed)
+
+	allowed, _, err = CheckRateLimit(ctx, cache, nil, RateLimitCreate, testAliasUUID, "iss", "alice")
+	require.NoError(t, err)
+	assert.True(t, allowed, "no limits configured")
+
+	unlimited := &config.FirewallRateLimitConfig{CreatesPerWindow: 0, DeletesPerWindow: 1, Window: time.Hour}
+	for i := 0; i < 5; i++ {
+		allowed, _, err = CheckRateLimit(ctx, cache, unlimited, RateLimitCreate, testAliasUUID, "iss", "carol")
+		require.NoError(t, err)
+		assert.True(t, allowed, "a zero limit disables the check")
+	}
+}
+
+func TestReleaseRateLimit(t *testing.T) {
+	cache, err := data.NewMemCache(&config.Config{}, slog.Default())
+	require.NoError(t, err)
+
+	limits := &config.FirewallRateLimitConfig{CreatesPerWindow: 1, DeletesPerWindow: 1, Window: time.Hour}
+	ctx := context.Background()
+
+	allowed, _, err := CheckRateLimit(ctx, cache, limits, RateLimitCreate, testAliasUUID, "iss", "alice")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	require.NoError(t, ReleaseRateLimit(ctx, cache, limits, RateLimitCreate, testAliasUUID, "iss", "alice"))
+
+	allowed, _, err = CheckRateLimit(ctx, cache, limits, RateLimitCreate, testAliasUUID, "iss", "alice")
+	require.NoError(t, err)
+	assert.True(t, allowed, "a released attempt does not count")
+}
+
+func TestExceedsNetworkRotation(t *testing.T) {
+	tests := []struct {
+		name      string
+		detection *config.FirewallAbuseDetectionConfig
+		asns      int
+		countries int
+		expected  bool
+	}{
+		{"disabled", nil, 50, 50, false},
+		{"within limits", &config.FirewallAbuseDetectionConfig{MaxDistinctASNs: 3, MaxDistinctCountries: 2}, 3, 2, false},
+		{"too many ASNs", &config.FirewallAbuseDetectionConfig{MaxDistinctASNs: 3, MaxDistinctCountries: 2}, 4, 1, true},
+		{"too many countries", &config.FirewallAbuseDetectionConfig{MaxDistinctASNs: 3, MaxDistinctCountries: 2}, 1, 3, true},
+		{"country check disabled", &config.FirewallAbuseDetectionConfig{MaxDistinctASNs: 3}, 1, 30, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExceedsNetworkRotation(tt.detection, tt.asns, tt.countries))
+		})
+	}
+}
+
+func TestShouldReportNetworkRotationOncePerWindow(t *testing.T) {
+	cache, err := data.NewMemCache(&config.Config{}, slog.Default())
+	require.NoError(t, err)
+
+	detection := &config.FirewallAbuseDetectionConfig{MaxDistinctASNs: 3, Window: time.Hour}
+	ctx := context.Background()
+
+	report, err := ShouldReportNetworkRotation(ctx, cache, detection, "iss", "alice")
+	require.NoError(t, err)
+	assert.True(t, report)
+
+	report, err = ShouldReportNetworkRotation(ctx, cache, detection, "iss", "alice")
+	require.NoError(t, err)
+	assert.False(t, report)
+}
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"homelab-dashboard/internal/config"
 	"io"
+	"maps"
 	"net/http"
-	"strings"
+	"slices"
 	"time"
 )
 
@@ -36,12 +38,34 @@ func (c *RouterClient) normalizeEndpoint() string {
 
 const (
 	fmtGETAliasByUUIDPath = "/api/firewall/alias/get_item/%s"
-	fmtSETAliasByUUIDPath = "/api/firewall/alias/set_item/%s"
-	reconfigurePath       = "/api/firewall/alias/reconfigure"
+	searchAliasesPath     = "/api/firewall/alias/search_item"
+
+	fmtAliasUtilAddPath    = "/api/firewall/alias_util/add/%s"
+	fmtAliasUtilDeletePath = "/api/firewall/alias_util/delete/%s"
+)
+
+var (
+	ErrAliasNotFound        = errors.New("alias not found on router")
+	ErrUnsupportedAliasType = errors.New("alias type is not supported")
+
+	ErrAliasModifiedConcurrently = errors.New("alias was modified concurrently")
 )
 
 // GetAliasIPs retrieves current IPs from an OPNsense alias
 func (c *RouterClient) GetAliasIPs(ctx context.Context, aliasUUID string) ([]string, error) {
+	alias, err := c.GetAlias(ctx, aliasUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := alias.GetSelectedIPs()
+
+	return ips, nil
+}
+
+// GetAlias retrieves the full definition of an OPNsense alias.
+// OPNsense answers unknown UUIDs with an empty template rather than a 404, so both are reported as ErrAliasNotFound.
+func (c *RouterClient) GetAlias(ctx context.Context, aliasUUID string) (*AliasDetail, error) {
 	url := c.normalizeEndpoint() + fmt.Sprintf(fmtGETAliasByUUIDPath, aliasUUID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -60,6 +84,10 @@ func (c *RouterClient) GetAliasIPs(ctx context.Context, aliasUUID string) ([]str
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrAliasNotFound
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
@@ -70,138 +98,188 @@ func (c *RouterClient) GetAliasIPs(ctx context.Context, aliasUUID string) ([]str
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	ips := aliasResp.Alias.GetSelectedIPs()
+	if aliasResp.Alias.Name == "" {
+		return nil, ErrAliasNotFound
+	}
 
-	return ips, nil
+	return &aliasResp.Alias, nil
 }
 
-// UpdateAlias updates an OPNsense alias with new IPs
-func (c *RouterClient) UpdateAlias(ctx context.Context, aliasUUID string, ipsToAdd, ipsToRemove []string) error {
-	currentIPs, err := c.GetAliasIPs(ctx, aliasUUID)
+// ListAliases returns every alias defined on the router, including ones the dashboard cannot manage.
+func (c *RouterClient) ListAliases(ctx context.Context) ([]AliasSearchRow, error) {
+	url := c.normalizeEndpoint() + searchAliasesPath
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get current alias state: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	ipMap := make(map[string]bool)
+	req.SetBasicAuth(
+		c.config.Features.FirewallManagement.RouterAPIKey,
+		c.config.Features.FirewallManagement.RouterAPISecret,
+	)
 
-	for _, ip := range currentIPs {
-		ipMap[ip] = true
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	defer resp.Body.Close()
 
-	for _, ip := range ipsToRemove {
-		delete(ipMap, ip)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
 	}
 
-	for _, ip := range ipsToAdd {
-		ipMap[ip] = true
+	var searchResp AliasSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	var newIPs []string
-	for ip := range ipMap {
-		newIPs = append(newIPs, ip)
-	}
+	return searchResp.Rows, nil
+}
 
-	url := c.normalizeEndpoint() + fmt.Sprintf(fmtGETAliasByUUIDPath, aliasUUID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// ValidateAlias checks that aliasUUID exists on the router and is an alias type the dashboard can manage.
+func (c *RouterClient) ValidateAlias(ctx context.Context, aliasUUID string) (*AliasDetail, error) {
+	alias, err := c.GetAlias(ctx, aliasUUID)
 	if err != nil {
-		return fmt.Errorf("failed to create get request: %w", err)
+		return nil, err
 	}
-	req.SetBasicAuth(
-		c.config.Features.FirewallManagement.RouterAPIKey,
-		c.config.Features.FirewallManagement.RouterAPISecret,
-	)
 
-	resp, err := c.httpClient.Do(req)
+	if aliasType := getSelected(alias.Type); !IsManageableAliasType(aliasType) {
+		return nil, fmt.Errorf("%w: alias %q is of type %q", ErrUnsupportedAliasType, alias.Name, aliasType)
+	}
+
+	return alias, nil
+}
+
+// ApplyAliasChanges adds and removes addresses on an OPNsense alias, skipping any that are already in the
+// desired state. Addresses are changed one at a time through alias_util, which updates the live table and
+// the stored alias together, so only host and network aliases are supported; other types are rejected with
+// ErrUnsupportedAliasType. Nothing is written when there is nothing to change.
+//
+// The alias is read again after the write and compared with the content read before it plus the
+// changes made. A mismatch means someone else edited the alias in the meantime and is reported as
+// ErrAliasModifiedConcurrently so the caller can recompute from fresh state instead of clobbering it.
+func (c *RouterClient) ApplyAliasChanges(ctx context.Context, aliasUUID string, ipsToAdd, ipsToRemove []string) (*AliasChangeResult, error) {
+	before, err := c.GetAlias(ctx, aliasUUID)
 	if err != nil {
-		return fmt.Errorf("get request failed: %w", err)
+		return nil, fmt.Errorf("failed to get current alias state: %w", err)
+	}
+	if aliasType := getSelected(before.Type); !IsManageableAliasType(aliasType) {
+		return nil, fmt.Errorf("%w: alias %q is of type %q", ErrUnsupportedAliasType, before.Name, aliasType)
 	}
-	defer resp.Body.Close()
 
-	var fullAlias AliasGetResponse
-	if err := json.NewDecoder(resp.Body).Decode(&fullAlias); err != nil {
-		return fmt.Errorf("failed to decode alias: %w", err)
+	current := make(map[string]bool)
+	for _, ip := range before.GetSelectedIPs() {
+		current[ip] = true
 	}
 
-	// 4. Build set request with newline-separated IPs (strip CIDR notation)
-	content := ""
-	for i, ip := range newIPs {
-		if i > 0 {
-			content += "\n"
+	result := &AliasChangeResult{Added: []string{}, Removed: []string{}}
+	for _, ip := range ipsToAdd {
+		if ip = StripCIDR(ip); !current[ip] && !slices.Contains(result.Added, ip) {
+			result.Added = append(result.Added, ip)
 		}
-		// Strip CIDR notation if present (e.g., "192.168.1.1/32" -> "192.168.1.1")
-		cleanIP := ip
-		if idx := strings.Index(ip, "/"); idx != -1 {
-			cleanIP = ip[:idx]
+	}
+	for _, ip := range ipsToRemove {
+		if ip = StripCIDR(ip); current[ip] && !slices.Contains(result.Removed, ip) {
+			result.Removed = append(result.Removed, ip)
 		}
-		content += cleanIP
-	}
-
-	setReq := AliasSetRequest{
-		Alias: AliasSetBody{
-			Enabled:        fullAlias.Alias.Enabled,
-			Name:           fullAlias.Alias.Name,
-			Type:           getSelected(fullAlias.Alias.Type),
-			Proto:          getSelected(fullAlias.Alias.Proto),
-			Categories:     getSelected(fullAlias.Alias.Categories),
-			UpdateFreq:     fullAlias.Alias.UpdateFreq,
-			Content:        content,
-			PathExpression: fullAlias.Alias.PathExpression,
-			AuthType:       getSelected(fullAlias.Alias.AuthType),
-			Username:       fullAlias.Alias.Username,
-			Password:       fullAlias.Alias.Password,
-			Interface:      getSelected(fullAlias.Alias.Interface),
-			Counters:       fullAlias.Alias.Counters,
-			Description:    fullAlias.Alias.Description,
-		},
 	}
 
-	setURL := c.normalizeEndpoint() + fmt.Sprintf(fmtSETAliasByUUIDPath, aliasUUID)
-	jsonBody, err := json.Marshal(setReq)
-	if err != nil {
-		return fmt.Errorf("failed to marshal set request: %w", err)
+	if !result.Changed() {
+		return result, nil
 	}
 
-	setHTTPReq, err := http.NewRequestWithContext(ctx, "POST", setURL, bytes.NewReader(jsonBody))
-	if err != nil {
-		return fmt.Errorf("failed to create set request: %w", err)
+	if err := c.applyAliasUtilChanges(ctx, before.Name, result.Added, result.Removed); err != nil {
+		return result, err
 	}
-	setHTTPReq.SetBasicAuth(
-		c.config.Features.FirewallManagement.RouterAPIKey,
-		c.config.Features.FirewallManagement.RouterAPISecret,
-	)
-	setHTTPReq.Header.Set("Content-Type", "application/json")
 
-	setResp, err := c.httpClient.Do(setHTTPReq)
+	after, err := c.GetAlias(ctx, aliasUUID)
 	if err != nil {
-		return fmt.Errorf("set request failed: %w", err)
+		return result, fmt.Errorf("failed to verify alias state: %w", err)
+	}
+
+	expected := maps.Clone(current)
+	for _, ip := range result.Added {
+		expected[ip] = true
+	}
+	for _, ip := range result.Removed {
+		delete(expected, ip)
+	}
+
+	if !sameIPSet(expected, after.GetSelectedIPs()) {
+		return result, fmt.Errorf("%w: %s changed on the router during the update", ErrAliasModifiedConcurrently, before.Name)
+	}
+
+	return result, nil
+}
+
+// applyAliasUtilChanges adds and removes single addresses by alias name.
+func (c *RouterClient) applyAliasUtilChanges(ctx context.Context, aliasName string, ipsToAdd, ipsToRemove []string) error {
+	for _, ip := range ipsToAdd {
+		if err := c.aliasUtil(ctx, fmtAliasUtilAddPath, aliasName, ip); err != nil {
+			return fmt.Errorf("failed to add %s: %w", ip, err)
+		}
 	}
-	defer setResp.Body.Close()
 
-	if setResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(setResp.Body)
-		return fmt.Errorf("set request returned status %d: %s", setResp.StatusCode, string(body))
+	for _, ip := range ipsToRemove {
+		if err := c.aliasUtil(ctx, fmtAliasUtilDeletePath, aliasName, ip); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", ip, err)
+		}
 	}
 
-	reconfigURL := c.normalizeEndpoint() + reconfigurePath
-	reconfigReq, err := http.NewRequestWithContext(ctx, "POST", reconfigURL, nil)
+	return nil
+}
+
+func (c *RouterClient) aliasUtil(ctx context.Context, pathFormat, aliasName, address string) error {
+	url := c.normalizeEndpoint() + fmt.Sprintf(pathFormat, aliasName)
+
+	jsonBody, err := json.Marshal(AliasUtilRequest{Address: address})
 	if err != nil {
-		return fmt.Errorf("failed to create reconfigure request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
-	reconfigReq.SetBasicAuth(
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(
 		c.config.Features.FirewallManagement.RouterAPIKey,
 		c.config.Features.FirewallManagement.RouterAPISecret,
 	)
+	req.Header.Set("Content-Type", "application/json")
 
-	reconfigResp, err := c.httpClient.Do(reconfigReq)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("reconfigure request failed: %w", err)
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
 	}
-	defer reconfigResp.Body.Close()
 
-	if reconfigResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(reconfigResp.Body)
-		return fmt.Errorf("reconfigure returned status %d: %s", reconfigResp.StatusCode, string(body))
+	var utilResp AliasUtilResponse
+	if err := json.NewDecoder(resp.Body).Decode(&utilResp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if utilResp.Status != "done" {
+		return fmt.Errorf("router returned status %q", utilResp.Status)
 	}
 
 	return nil
 }
+
+// sameIPSet reports whether ips contains exactly the addresses in set.
+func sameIPSet(set map[string]bool, ips []string) bool {
+	seen := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		if !set[ip] {
+			return false
+		}
+		seen[ip] = true
+	}
+	return len(seen) == len(set)
+}
@@ -0,0 +1,123 @@
+package firewall
+
+import (
+	"context"
+	"encoding/json"
+	"homelab-dashboard/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAliasUUID = "c0daef37-718c-40e4-bb2b-ba5aab418d0d"
+
+// fakeAliasRouter serves just enough of the OPNsense alias API for a single alias.
+type fakeAliasRouter struct {
+	mu        sync.Mutex
+	name      string
+	aliasType string
+	content   []string
+	calls     map[string]int
+	afterUtil func(f *fakeAliasRouter) // runs after every alias_util call, e.g. to simulate a concurrent edit
+}
+
+func (f *fakeAliasRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/api/firewall/alias/get_item/"):
+		f.calls["get"]++
+		content := make(map[string]ContentItem)
+		for _, value := range f.content {
+			content[value] = ContentItem{Value: value, Selected: 1}
+		}
+		_ = json.NewEncoder(w).Encode(AliasGetResponse{Alias: AliasDetail{
+			Name:    f.name,
+			Type:    map[string]SelectOption{f.aliasType: {Selected: 1}},
+			Content: content,
+		}})
+	case strings.HasPrefix(path, "/api/firewall/alias_util/"):
+		var req AliasUtilRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(path, "/add/") {
+			f.calls["add"]++
+			f.content = append(f.content, req.Address)
+		} else {
+			f.calls["delete"]++
+			f.content = slices.DeleteFunc(f.content, func(v string) bool { return v == req.Address })
+		}
+		if f.afterUtil != nil {
+			f.afterUtil(f)
+		}
+		_, _ = w.Write([]byte(`{"status":"done"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeAliasRouter(t *testing.T, aliasType string, content ...string) (*fakeAliasRouter, *RouterClient) {
+	fake := &fakeAliasRouter{name: "Database", aliasType: aliasType, content: content, calls: map[string]int{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg := config.Config{Features: &config.FeaturesConfig{}}
+	cfg.Features.FirewallManagement.RouterEndpoint = server.URL
+	return fake, NewRouterClient(cfg)
+}
+
+func TestApplyAliasChangesIncremental(t *testing.T) {
+	fake, client := newFakeAliasRouter(t, "host", "10.0.0.1", "10.0.0.2")
+
+	result, err := client.ApplyAliasChanges(context.Background(), testAliasUUID,
+		[]string{"10.0.0.3", "10.0.0.1"}, // 10.0.0.1 is already present
+		[]string{"10.0.0.2", "10.0.0.9"}, // 10.0.0.9 is already absent
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"10.0.0.3"}, result.Added)
+	assert.Equal(t, []string{"10.0.0.2"}, result.Removed)
+
+	assert.ElementsMatch(t, []string{"10.0.0.1", "10.0.0.3"}, fake.content)
+	assert.Equal(t, 1, fake.calls["add"])
+	assert.Equal(t, 1, fake.calls["delete"])
+}
+
+func TestApplyAliasChangesNoop(t *testing.T) {
+	fake, client := newFakeAliasRouter(t, "host", "10.0.0.1")
+
+	result, err := client.ApplyAliasChanges(context.Background(), testAliasUUID, []string{"10.0.0.1/32"}, []string{"10.0.0.5"})
+	require.NoError(t, err)
+
+	assert.False(t, result.Changed())
+	assert.Equal(t, 1, fake.calls["get"], "only the initial read should hit the router")
+	assert.Zero(t, fake.calls["add"]+fake.calls["delete"])
+}
+
+func TestApplyAliasChangesDetectsConcurrentEdit(t *testing.T) {
+	fake, client := newFakeAliasRouter(t, "host", "10.0.0.1")
+	fake.afterUtil = func(f *fakeAliasRouter) {
+		f.content = append(f.content, "192.0.2.10")
+		f.afterUtil = nil
+	}
+
+	_, err := client.ApplyAliasChanges(context.Background(), testAliasUUID, []string{"10.0.0.2"}, nil)
+	assert.ErrorIs(t, err, ErrAliasModifiedConcurrently)
+	assert.Contains(t, fake.content, "192.0.2.10", "the concurrent edit must not be overwritten")
+}
+
+func TestApplyAliasChangesRejectsUnsupportedType(t *testing.T) {
+	fake, client := newFakeAliasRouter(t, "external", "10.0.0.1", "10.0.0.2")
+
+	_, err := client.ApplyAliasChanges(context.Background(), testAliasUUID, []string{"10.0.0.3"}, []string{"10.0.0.1"})
+	assert.ErrorIs(t, err, ErrUnsupportedAliasType)
+	assert.ElementsMatch(t, []string{"10.0.0.1", "10.0.0.2"}, fake.content)
+	assert.Zero(t, fake.calls["add"]+fake.calls["delete"])
+}
@@ -24,3 +24,24 @@ type AliasSetBody struct {
 	Counters       string `json:"counters"`
 	Description    string `json:"description"`
 }
+
+// AliasUtilRequest is the POST body for alias_util/add and alias_util/delete
+type AliasUtilRequest struct {
+	Address string `json:"address"`
+}
+
+// AliasUtilResponse reports the outcome of an alias_util call; "done" on success
+type AliasUtilResponse struct {
+	Status string `json:"status"`
+}
+
+// AliasChangeResult describes what ApplyAliasChanges changed on the router
+type AliasChangeResult struct {
+	Added   []string
+	Removed []string
+}
+
+// Changed reports whether any address was added or removed
+func (r *AliasChangeResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0
+}
@@ -0,0 +1,73 @@
+package firewall
+
+import (
+	"homelab-dashboard/internal/models"
+	"slices"
+	"strings"
+)
+
+// BuildSyncPlan computes the changes needed to make an alias match its whitelist entries.
+// currentIPs is the alias content as reported by the router, entries are all whitelist entries for the alias.
+// The plan is deterministic so plans computed at different times can be compared.
+func BuildSyncPlan(aliasName, aliasUUID string, currentIPs []string, entries []*models.FirewallIPWhitelistEntry) *models.FirewallSyncPlan {
+	desired := make(map[string]bool) // IP -> should be present on the router
+	var pendingIDs []int
+
+	for _, entry := range entries {
+		ip := StripCIDR(entry.IPAddress)
+
+		switch entry.Status {
+		case models.StatusRequested:
+			pendingIDs = append(pendingIDs, entry.ID)
+			desired[ip] = true
+		case models.StatusAdded:
+			desired[ip] = true
+		default:
+			if _, exists := desired[ip]; !exists {
+				desired[ip] = false
+			}
+		}
+	}
+
+	currentIPSet := make(map[string]bool)
+	for _, ip := range currentIPs {
+		currentIPSet[ip] = true
+	}
+
+	plan := &models.FirewallSyncPlan{
+		AliasName:       aliasName,
+		AliasUUID:       aliasUUID,
+		IPsToAdd:        []string{},
+		IPsToRemove:     []string{},
+		PendingEntryIDs: []int{},
+		Status:          models.SyncPlanPlanned,
+	}
+
+	for ip, present := range desired {
+		if present && !currentIPSet[ip] {
+			plan.IPsToAdd = append(plan.IPsToAdd, ip)
+		}
+	}
+
+	for ip := range currentIPSet {
+		if !desired[ip] {
+			plan.IPsToRemove = append(plan.IPsToRemove, ip)
+		}
+	}
+
+	plan.PendingEntryIDs = append(plan.PendingEntryIDs, pendingIDs...)
+
+	slices.Sort(plan.IPsToAdd)
+	slices.Sort(plan.IPsToRemove)
+	slices.Sort(plan.PendingEntryIDs)
+
+	return plan
+}
+
+// StripCIDR removes CIDR notation from an address (e.g., "192.168.1.1/32" -> "192.168.1.1").
+func StripCIDR(ip string) string {
+	if idx := strings.Index(ip, "/"); idx != -1 {
+		return ip[:idx]
+	}
+	return ip
+}
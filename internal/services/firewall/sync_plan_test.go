@@ -0,0 +1,118 @@
+package firewall
+
+import (
+	"homelab-dashboard/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSyncPlan(t *testing.T) {
+	tests := []struct {
+		name            string
+		currentIPs      []string
+		entries         []*models.FirewallIPWhitelistEntry
+		expectedAdd     []string
+		expectedRemove  []string
+		expectedPending []int
+	}{
+		{
+			name:       "requested entry is added and marked pending",
+			currentIPs: []string{},
+			entries: []*models.FirewallIPWhitelistEntry{
+				{ID: 1, IPAddress: "10.0.0.1/32", Status: models.StatusRequested},
+			},
+			expectedAdd:     []string{"10.0.0.1"},
+			expectedRemove:  []string{},
+			expectedPending: []int{1},
+		},
+		{
+			name:       "added entry already on router makes no changes",
+			currentIPs: []string{"10.0.0.1"},
+			entries: []*models.FirewallIPWhitelistEntry{
+				{ID: 1, IPAddress: "10.0.0.1", Status: models.StatusAdded},
+			},
+			expectedAdd:     []string{},
+			expectedRemove:  []string{},
+			expectedPending: []int{},
+		},
+		{
+			name:       "removed entry is removed from router",
+			currentIPs: []string{"10.0.0.1", "10.0.0.2"},
+			entries: []*models.FirewallIPWhitelistEntry{
+				{ID: 1, IPAddress: "10.0.0.1", Status: models.StatusRemoved},
+				{ID: 2, IPAddress: "10.0.0.2", Status: models.StatusAdded},
+			},
+			expectedAdd:     []string{},
+			expectedRemove:  []string{"10.0.0.1"},
+			expectedPending: []int{},
+		},
+		{
+			name:       "scheduled entry outside its window is removed from router",
+			currentIPs: []string{"10.0.0.1"},
+			entries: []*models.FirewallIPWhitelistEntry{
+				{ID: 1, IPAddress: "10.0.0.1", Status: models.StatusScheduled},
+			},
+			expectedAdd:     []string{},
+			expectedRemove:  []string{"10.0.0.1"},
+			expectedPending: []int{},
+		},
+		{
+			name:       "active entry wins over removed entry for the same ip",
+			currentIPs: []string{"10.0.0.1"},
+			entries: []*models.FirewallIPWhitelistEntry{
+				{ID: 1, IPAddress: "10.0.0.1", Status: models.StatusRemoved},
+				{ID: 2, IPAddress: "10.0.0.1", Status: models.StatusAdded},
+			},
+			expectedAdd:     []string{},
+			expectedRemove:  []string{},
+			expectedPending: []int{},
+		},
+		{
+			name:            "unmanaged router ip is removed",
+			currentIPs:      []string{"10.0.0.9"},
+			entries:         nil,
+			expectedAdd:     []string{},
+			expectedRemove:  []string{"10.0.0.9"},
+			expectedPending: []int{},
+		},
+		{
+			name:       "results are sorted",
+			currentIPs: []string{"10.0.0.9", "10.0.0.8"},
+			entries: []*models.FirewallIPWhitelistEntry{
+				{ID: 5, IPAddress: "10.0.0.3", Status: models.StatusRequested},
+				{ID: 4, IPAddress: "10.0.0.2", Status: models.StatusRequested},
+			},
+			expectedAdd:     []string{"10.0.0.2", "10.0.0.3"},
+			expectedRemove:  []string{"10.0.0.8", "10.0.0.9"},
+			expectedPending: []int{4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := BuildSyncPlan("alias", "uuid", tt.currentIPs, tt.entries)
+
+			assert.Equal(t, tt.expectedAdd, plan.IPsToAdd)
+			assert.Equal(t, tt.expectedRemove, plan.IPsToRemove)
+			assert.Equal(t, tt.expectedPending, plan.PendingEntryIDs)
+			assert.Equal(t, models.SyncPlanPlanned, plan.Status)
+		})
+	}
+}
+
+func TestFirewallSyncPlan_SameChanges(t *testing.T) {
+	a := BuildSyncPlan("alias", "uuid", []string{"10.0.0.9"}, []*models.FirewallIPWhitelistEntry{
+		{ID: 1, IPAddress: "10.0.0.1", Status: models.StatusRequested},
+	})
+	b := BuildSyncPlan("alias", "uuid", []string{"10.0.0.9"}, []*models.FirewallIPWhitelistEntry{
+		{ID: 1, IPAddress: "10.0.0.1", Status: models.StatusRequested},
+	})
+	c := BuildSyncPlan("alias", "uuid", []string{}, []*models.FirewallIPWhitelistEntry{
+		{ID: 1, IPAddress: "10.0.0.1", Status: models.StatusRequested},
+	})
+
+	assert.True(t, a.SameChanges(b))
+	assert.False(t, a.SameChanges(c))
+	assert.False(t, a.SameChanges(nil))
+}
@@ -0,0 +1,19 @@
+package firewall
+
+import (
+	"homelab-dashboard/internal/config"
+	"time"
+)
+
+// syncApplyMargin covers the router round trips of a single alias sync.
+const syncApplyMargin = 5 * time.Second
+
+// EstimateApplyTime predicts when a change to an alias reaches the router. When an on-demand sync was
+// requested the leader applies it after its debounce window; otherwise the change waits for the next
+// scheduled sync, which is at most one interval away.
+func EstimateApplyTime(jobConfig *config.FirewallBackgroundJobConfig, triggered bool, now time.Time) time.Time {
+	if triggered {
+		return now.Add(jobConfig.SyncDebounce + syncApplyMargin)
+	}
+	return now.Add(jobConfig.SyncInterval)
+}
@@ -0,0 +1,20 @@
+package firewall
+
+import (
+	"homelab-dashboard/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateApplyTime(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	jobConfig := &config.FirewallBackgroundJobConfig{
+		SyncInterval: 5 * time.Minute,
+		SyncDebounce: 2 * time.Second,
+	}
+
+	assert.Equal(t, now.Add(7*time.Second), EstimateApplyTime(jobConfig, true, now))
+	assert.Equal(t, now.Add(5*time.Minute), EstimateApplyTime(jobConfig, false, now))
+}
@@ -0,0 +1,160 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/utils"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// TraefikMiddlewareGVR is the Traefik v3 Middleware custom resource.
+var TraefikMiddlewareGVR = schema.GroupVersionResource{
+	Group:    "traefik.io",
+	Version:  "v1alpha1",
+	Resource: "middlewares",
+}
+
+// traefikDenyAllSourceRange stands in for an empty allow list. Traefik rejects an ipAllowList without
+// any source range, which would take every route using the middleware offline instead of denying access.
+const traefikDenyAllSourceRange = "255.255.255.255/32"
+
+var traefikSourceRangePath = []string{"spec", "ipAllowList", "sourceRange"}
+
+// TraefikClient manages the ipAllowList.sourceRange of Traefik Middleware resources through the
+// Kubernetes dynamic client, so the Traefik CRDs do not need to be compiled in.
+type TraefikClient struct {
+	client dynamic.Interface
+}
+
+func NewTraefikClient(client dynamic.Interface) *TraefikClient {
+	return &TraefikClient{client: client}
+}
+
+// NewTraefikClientFromConfig connects to the cluster configured under features.firewall_management.kubernetes.
+func NewTraefikClientFromConfig(cfg *config.KubernetesConfig) (*TraefikClient, error) {
+	restConfig, err := utils.KubernetesRestConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes dynamic client: %w", err)
+	}
+
+	return NewTraefikClient(client), nil
+}
+
+// GetSourceRange returns the addresses currently allowed by a middleware.
+// A middleware without an ipAllowList is reported as ErrUnsupportedAliasType.
+func (c *TraefikClient) GetSourceRange(ctx context.Context, middleware config.FirewallTraefikMiddleware) ([]string, error) {
+	obj, err := c.getMiddleware(ctx, middleware)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceRange, err := readSourceRange(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(sourceRange, func(value string) bool {
+		return value == traefikDenyAllSourceRange
+	}), nil
+}
+
+// ApplySourceRangeChanges adds and removes addresses from a middleware's allow list. The update carries
+// the resourceVersion that was read, so a concurrent edit makes it fail with ErrAliasModifiedConcurrently
+// rather than silently overwriting the other change.
+func (c *TraefikClient) ApplySourceRangeChanges(ctx context.Context, middleware config.FirewallTraefikMiddleware, add, remove []string) (*AliasChangeResult, error) {
+	obj, err := c.getMiddleware(ctx, middleware)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := readSourceRange(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AliasChangeResult{}
+	updated := make([]string, 0, len(current)+len(add))
+
+	for _, value := range current {
+		if value == traefikDenyAllSourceRange {
+			continue
+		}
+		if slices.Contains(remove, value) {
+			result.Removed = append(result.Removed, value)
+			continue
+		}
+		updated = append(updated, value)
+	}
+
+	for _, value := range add {
+		if !slices.Contains(updated, value) {
+			updated = append(updated, value)
+			result.Added = append(result.Added, value)
+		}
+	}
+
+	if !result.Changed() {
+		return result, nil
+	}
+
+	if len(updated) == 0 {
+		updated = []string{traefikDenyAllSourceRange}
+	}
+
+	if err := unstructured.SetNestedStringSlice(obj.Object, updated, traefikSourceRangePath...); err != nil {
+		return nil, fmt.Errorf("failed to set source range: %w", err)
+	}
+
+	_, err = c.client.Resource(TraefikMiddlewareGVR).Namespace(middleware.Namespace).Update(ctx, obj, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrAliasModifiedConcurrently, middleware.Namespace, middleware.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update middleware %s/%s: %w", middleware.Namespace, middleware.Name, err)
+	}
+
+	return result, nil
+}
+
+// ValidateMiddleware checks the middleware exists and is an ipAllowList middleware.
+func (c *TraefikClient) ValidateMiddleware(ctx context.Context, middleware config.FirewallTraefikMiddleware) error {
+	_, err := c.GetSourceRange(ctx, middleware)
+	return err
+}
+
+func (c *TraefikClient) getMiddleware(ctx context.Context, middleware config.FirewallTraefikMiddleware) (*unstructured.Unstructured, error) {
+	obj, err := c.client.Resource(TraefikMiddlewareGVR).Namespace(middleware.Namespace).Get(ctx, middleware.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: middleware %s/%s", ErrAliasNotFound, middleware.Namespace, middleware.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get middleware %s/%s: %w", middleware.Namespace, middleware.Name, err)
+	}
+
+	return obj, nil
+}
+
+func readSourceRange(obj *unstructured.Unstructured) ([]string, error) {
+	if _, found, _ := unstructured.NestedMap(obj.Object, "spec", "ipAllowList"); !found {
+		return nil, fmt.Errorf("%w: middleware %s/%s has no ipAllowList", ErrUnsupportedAliasType, obj.GetNamespace(), obj.GetName())
+	}
+
+	sourceRange, _, err := unstructured.NestedStringSlice(obj.Object, traefikSourceRangePath...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source range of middleware %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return sourceRange, nil
+}
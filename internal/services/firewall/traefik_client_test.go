@@ -0,0 +1,146 @@
+package firewall
+
+import (
+	"context"
+	"homelab-dashboard/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var testMiddleware = config.FirewallTraefikMiddleware{Namespace: "ingress", Name: "home-allowlist"}
+
+func newTestMiddleware(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "traefik.io/v1alpha1",
+		"kind":       "Middleware",
+		"metadata": map[string]interface{}{
+			"namespace": testMiddleware.Namespace,
+			"name":      name,
+		},
+		"spec": spec,
+	}}
+}
+
+func newAllowListMiddleware(sourceRange ...interface{}) *unstructured.Unstructured {
+	return newTestMiddleware(testMiddleware.Name, map[string]interface{}{
+		"ipAllowList": map[string]interface{}{
+			"sourceRange": sourceRange,
+		},
+	})
+}
+
+func newFakeTraefikClient(objects ...runtime.Object) (*TraefikClient, *dynamicfake.FakeDynamicClient) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{TraefikMiddlewareGVR: "MiddlewareList"}, objects...)
+	return NewTraefikClient(client), client
+}
+
+func storedSourceRange(t *testing.T, client *dynamicfake.FakeDynamicClient) []string {
+	obj, err := client.Resource(TraefikMiddlewareGVR).Namespace(testMiddleware.Namespace).Get(context.Background(), testMiddleware.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	sourceRange, _, err := unstructured.NestedStringSlice(obj.Object, "spec", "ipAllowList", "sourceRange")
+	require.NoError(t, err)
+	return sourceRange
+}
+
+func TestTraefikClientGetSourceRange(t *testing.T) {
+	client, _ := newFakeTraefikClient(newAllowListMiddleware("192.0.2.1", "198.51.100.0/24"))
+
+	sourceRange, err := client.GetSourceRange(context.Background(), testMiddleware)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.1", "198.51.100.0/24"}, sourceRange)
+}
+
+func TestTraefikClientApplySourceRangeChanges(t *testing.T) {
+	client, fake := newFakeTraefikClient(newAllowListMiddleware("192.0.2.1", "192.0.2.2"))
+
+	result, err := client.ApplySourceRangeChanges(context.Background(), testMiddleware,
+		[]string{"192.0.2.3", "192.0.2.1"}, []string{"192.0.2.2", "192.0.2.9"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"192.0.2.3"}, result.Added)
+	assert.Equal(t, []string{"192.0.2.2"}, result.Removed)
+	assert.Equal(t, []string{"192.0.2.1", "192.0.2.3"}, storedSourceRange(t, fake))
+}
+
+func TestTraefikClientKeepsMiddlewareValidWhenEmpty(t *testing.T) {
+	client, fake := newFakeTraefikClient(newAllowListMiddleware("192.0.2.1"))
+
+	_, err := client.ApplySourceRangeChanges(context.Background(), testMiddleware, nil, []string{"192.0.2.1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{traefikDenyAllSourceRange}, storedSourceRange(t, fake))
+
+	sourceRange, err := client.GetSourceRange(context.Background(), testMiddleware)
+	require.NoError(t, err)
+	assert.Empty(t, sourceRange)
+
+	result, err := client.ApplySourceRangeChanges(context.Background(), testMiddleware, []string{"192.0.2.5"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.5"}, result.Added)
+	assert.Equal(t, []string{"192.0.2.5"}, storedSourceRange(t, fake))
+}
+
+func TestTraefikClientSkipsNoOpUpdates(t *testing.T) {
+	client, fake := newFakeTraefikClient(newAllowListMiddleware("192.0.2.1"))
+
+	result, err := client.ApplySourceRangeChanges(context.Background(), testMiddleware, []string{"192.0.2.1"}, []string{"192.0.2.7"})
+	require.NoError(t, err)
+	assert.False(t, result.Changed())
+
+	for _, action := range fake.Actions() {
+		assert.NotEqual(t, "update", action.GetVerb())
+	}
+}
+
+func TestTraefikClientValidateMiddleware(t *testing.T) {
+	other := newTestMiddleware("strip-prefix", map[string]interface{}{
+		"stripPrefix": map[string]interface{}{"prefixes": []interface{}{"/api"}},
+	})
+	client, _ := newFakeTraefikClient(newAllowListMiddleware(), other)
+
+	assert.NoError(t, client.ValidateMiddleware(context.Background(), testMiddleware))
+
+	err := client.ValidateMiddleware(context.Background(), config.FirewallTraefikMiddleware{Namespace: "ingress", Name: "strip-prefix"})
+	assert.ErrorIs(t, err, ErrUnsupportedAliasType)
+
+	err = client.ValidateMiddleware(context.Background(), config.FirewallTraefikMiddleware{Namespace: "ingress", Name: "missing"})
+	assert.ErrorIs(t, err, ErrAliasNotFound)
+}
+
+func TestTraefikClientReportsConcurrentModification(t *testing.T) {
+	client, fake := newFakeTraefikClient(newAllowListMiddleware("192.0.2.1"))
+	fake.PrependReactor("update", "middlewares", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(TraefikMiddlewareGVR.GroupResource(), testMiddleware.Name, nil)
+	})
+
+	_, err := client.ApplySourceRangeChanges(context.Background(), testMiddleware, []string{"192.0.2.2"}, nil)
+	assert.ErrorIs(t, err, ErrAliasModifiedConcurrently)
+}
+
+func TestBackendForAlias(t *testing.T) {
+	traefik, _ := newFakeTraefikClient(newAllowListMiddleware("192.0.2.1"))
+	alias := &config.FirewallAliasConfig{UUID: testAliasUUID, Traefik: &testMiddleware}
+
+	_, err := BackendForAlias(nil, nil, alias)
+	assert.ErrorIs(t, err, ErrBackendNotConfigured)
+
+	backend, err := BackendForAlias(nil, traefik, alias)
+	require.NoError(t, err)
+
+	ips, err := backend.GetAliasIPs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.1"}, ips)
+
+	_, err = BackendForAlias(nil, traefik, &config.FirewallAliasConfig{UUID: testAliasUUID})
+	assert.ErrorIs(t, err, ErrBackendNotConfigured)
+}
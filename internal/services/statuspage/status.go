@@ -0,0 +1,278 @@
+package statuspage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/models"
+	"slices"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Status is the state shown for a component, a service or the whole page.
+type Status string
+
+// Statuses from least to most severe; a service or page shows the most severe status of its parts.
+const (
+	StatusOperational         Status = "operational"
+	StatusUnknown             Status = "unknown" // observed component without a fresh result
+	StatusUnderMaintenance    Status = "under_maintenance"
+	StatusDegradedPerformance Status = "degraded_performance"
+	StatusPartialOutage       Status = "partial_outage"
+	StatusMajorOutage         Status = "major_outage"
+)
+
+var severity = []Status{
+	StatusOperational,
+	StatusUnknown,
+	StatusUnderMaintenance,
+	StatusDegradedPerformance,
+	StatusPartialOutage,
+	StatusMajorOutage,
+}
+
+// Worst returns the most severe of the given statuses, or operational when there are none.
+func Worst(statuses ...Status) Status {
+	worst := StatusOperational
+	for _, status := range statuses {
+		if slices.Index(severity, status) > slices.Index(severity, worst) {
+			worst = status
+		}
+	}
+	return worst
+}
+
+// impactStatus is the status an open incident puts its components in.
+func impactStatus(impact models.StatusIncidentImpact) Status {
+	switch impact {
+	case models.StatusImpactMinor:
+		return StatusDegradedPerformance
+	case models.StatusImpactMajor:
+		return StatusPartialOutage
+	case models.StatusImpactCritical:
+		return StatusMajorOutage
+	default:
+		return StatusOperational
+	}
+}
+
+// Page is the public status page.
+type Page struct {
+	Title       string                   `json:"title"`
+	Status      Status                   `json:"status"`
+	Services    []Service                `json:"services"`
+	Incidents   []*models.StatusIncident `json:"incidents"`   // open incidents
+	Maintenance []*models.StatusIncident `json:"maintenance"` // maintenance windows that are under way or upcoming
+	History     []*models.StatusIncident `json:"history"`     // incidents and maintenance closed within history_days
+	UpdatedAt   time.Time                `json:"updated_at"`
+}
+
+// Service is a service on the status page with the status of its components.
+type Service struct {
+	Name       string      `json:"name"`
+	Status     Status      `json:"status"`
+	Components []Component `json:"components"`
+}
+
+// Component is a part of a service on the status page.
+type Component struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Status      Status `json:"status"`
+}
+
+// Builder puts the status page together from the cached results of the probes and queries its components
+// are observed through, and from the incidents kept in storage.
+type Builder struct {
+	page       *config.StatusPage
+	cache      data.Provider
+	keys       map[string][]string // cache keys observed per component
+	probes     map[string]bool     // components observed through a probe
+	conditions map[string]data.Condition
+}
+
+// NewBuilder resolves the components of the configured status page to the cache keys of their probes and
+// queries. A query with template variables is observed through every selection the scheduler precomputes.
+func NewBuilder(cfg *config.Config, cache data.Provider) (*Builder, error) {
+	b := &Builder{
+		page:       &cfg.Features.StatusPage,
+		cache:      cache,
+		keys:       make(map[string][]string),
+		probes:     make(map[string]bool),
+		conditions: make(map[string]data.Condition),
+	}
+
+	precomputed := data.PrecomputedQueries(cfg.Data.Queries, cfg.Data.Variables)
+
+	for _, service := range b.page.Services {
+		for _, component := range service.Components {
+			switch {
+			case component.Probe != "":
+				b.keys[component.Name] = []string{component.Probe}
+				b.probes[component.Name] = true
+			case component.Query != "":
+				condition, err := data.ParseCondition(component.Condition)
+				if err != nil {
+					return nil, fmt.Errorf("status page component %s: %w", component.Name, err)
+				}
+				b.conditions[component.Name] = condition
+
+				for _, query := range precomputed {
+					if query.Name == component.Query {
+						b.keys[component.Name] = append(b.keys[component.Name], data.QueryCacheKey(query))
+					}
+				}
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// Build returns the status page as of now. Incidents are those returned by storage: open ones and those
+// closed within history_days.
+func (b *Builder) Build(ctx context.Context, incidents []*models.StatusIncident, now time.Time) *Page {
+	page := &Page{
+		Title:       b.page.Title,
+		Services:    make([]Service, 0, len(b.page.Services)),
+		Incidents:   []*models.StatusIncident{},
+		Maintenance: []*models.StatusIncident{},
+		History:     []*models.StatusIncident{},
+		UpdatedAt:   now,
+	}
+
+	for _, incident := range incidents {
+		switch {
+		case !incident.IsOpen():
+			page.History = append(page.History, incident)
+		case incident.Kind == models.StatusIncidentKindMaintenance:
+			page.Maintenance = append(page.Maintenance, incident)
+		default:
+			page.Incidents = append(page.Incidents, incident)
+		}
+	}
+
+	var serviceStatuses []Status
+	for _, service := range b.page.Services {
+		s := Service{Name: service.Name, Components: make([]Component, 0, len(service.Components))}
+
+		var componentStatuses []Status
+		for _, component := range service.Components {
+			status := b.componentStatus(ctx, component.Name, incidents, now)
+			s.Components = append(s.Components, Component{Name: component.Name, Description: component.Description, Status: status})
+			componentStatuses = append(componentStatuses, status)
+		}
+
+		s.Status = Worst(componentStatuses...)
+		page.Services = append(page.Services, s)
+		serviceStatuses = append(serviceStatuses, s.Status)
+	}
+	page.Status = Worst(serviceStatuses...)
+
+	return page
+}
+
+// componentStatus combines what is observed of a component with the incidents posted for it. Maintenance
+// under way explains any outage, so it takes precedence; open incidents otherwise raise the status to their
+// impact.
+func (b *Builder) componentStatus(ctx context.Context, name string, incidents []*models.StatusIncident, now time.Time) Status {
+	status := b.observedStatus(ctx, name, now)
+
+	for _, incident := range incidents {
+		if !incident.Affects(name) {
+			continue
+		}
+		if incident.InMaintenance(now) {
+			return StatusUnderMaintenance
+		}
+		if incident.IsOpen() && incident.Kind == models.StatusIncidentKindIncident {
+			status = Worst(status, impactStatus(incident.Impact))
+		}
+	}
+
+	return status
+}
+
+// observedStatus reads the status of a component from the cached results of its probe or query. Components
+// without either are operational unless an incident says otherwise.
+func (b *Builder) observedStatus(ctx context.Context, name string, now time.Time) Status {
+	if _, query := b.conditions[name]; !query && !b.probes[name] {
+		return StatusOperational
+	}
+
+	keys := b.keys[name]
+	if b.cache == nil || len(keys) == 0 {
+		return StatusUnknown
+	}
+
+	var checked, failing int
+	for _, key := range keys {
+		cached, ok := b.cache.Get(ctx, key)
+		if !ok || cached.IsStale(now) {
+			continue
+		}
+
+		var results []bool
+		var err error
+		if b.probes[name] {
+			results, err = probeResults(cached)
+		} else {
+			results, err = b.queryResults(name, cached)
+		}
+		if err != nil {
+			continue
+		}
+
+		for _, ok := range results {
+			checked++
+			if !ok {
+				failing++
+			}
+		}
+	}
+
+	switch {
+	case checked == 0:
+		return StatusUnknown
+	case failing == 0:
+		return StatusOperational
+	case failing == checked:
+		return StatusMajorOutage
+	default:
+		return StatusPartialOutage
+	}
+}
+
+// probeResults reads whether a probe succeeded from its cached result.
+func probeResults(cached data.CachedData) ([]bool, error) {
+	var vector model.Vector
+	if err := json.Unmarshal(cached.JSONBytes, &vector); err != nil {
+		return nil, fmt.Errorf("failed to decode probe result: %w", err)
+	}
+
+	for _, sample := range vector {
+		if sample.Metric[model.MetricNameLabel] == data.ProbeSuccessMetric {
+			return []bool{sample.Value == 1}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("probe result has no %s sample", data.ProbeSuccessMetric)
+}
+
+// queryResults reads which series of a cached query result pass, i.e. do not meet the failure condition.
+func (b *Builder) queryResults(name string, cached data.CachedData) ([]bool, error) {
+	values, err := data.LatestValues(cached)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]bool, 0, len(values))
+	for _, value := range values {
+		results = append(results, !b.conditions[name].Matches(value.Value))
+	}
+	return results, nil
+}
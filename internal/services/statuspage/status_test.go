@@ -0,0 +1,124 @@
+package statuspage
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"homelab-dashboard/internal/config"
+	"homelab-dashboard/internal/data"
+	"homelab-dashboard/internal/models"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cacheVector caches a vector result under key, fresh as of now.
+func cacheVector(t *testing.T, cache data.Provider, key string, vector model.Vector, now time.Time) {
+	t.Helper()
+
+	jsonBytes, err := json.Marshal(vector)
+	require.NoError(t, err)
+	cache.Set(context.Background(), key, data.CachedData{Name: key, ValueType: "vector", JSONBytes: jsonBytes, Timestamp: now, ExpiresAt: now.Add(time.Minute)})
+}
+
+func sample(name string, labels model.LabelSet, value float64) *model.Sample {
+	metric := model.Metric{model.MetricNameLabel: model.LabelValue(name)}
+	for label, labelValue := range labels {
+		metric[label] = labelValue
+	}
+	return &model.Sample{Metric: metric, Value: model.SampleValue(value)}
+}
+
+func TestWorst(t *testing.T) {
+	assert.Equal(t, StatusOperational, Worst())
+	assert.Equal(t, StatusUnknown, Worst(StatusOperational, StatusUnknown))
+	assert.Equal(t, StatusMajorOutage, Worst(StatusPartialOutage, StatusMajorOutage, StatusUnderMaintenance))
+}
+
+func TestBuilder_Build(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	cfg := &config.Config{
+		Data: config.DataConfig{
+			Queries: []config.PrometheusQuery{
+				{Name: "node_ready", Query: `kube_node_status_condition{condition="Ready",status="true"}`},
+			},
+		},
+		Features: &config.FeaturesConfig{
+			StatusPage: config.StatusPage{
+				Title: "Homelab",
+				Services: []config.StatusPageService{
+					{Name: "Media", Components: []config.StatusPageComponent{
+						{Name: "jellyfin", Probe: "jellyfin"},
+						{Name: "jellyseerr", Probe: "jellyseerr"},
+					}},
+					{Name: "Cluster", Components: []config.StatusPageComponent{
+						{Name: "nodes", Query: "node_ready", Condition: "< 1"},
+						{Name: "storage"},
+					}},
+				},
+			},
+		},
+	}
+
+	newBuilder := func(t *testing.T) *Builder {
+		cache, _ := data.NewMemCache(&config.Config{}, logger)
+		cacheVector(t, cache, "jellyfin", model.Vector{sample(data.ProbeSuccessMetric, nil, 1), sample(data.ProbeDurationMetric, nil, 0.2)}, now)
+		cacheVector(t, cache, "node_ready", model.Vector{
+			sample("kube_node_status_condition", model.LabelSet{"node": "a"}, 1),
+			sample("kube_node_status_condition", model.LabelSet{"node": "b"}, 0),
+		}, now)
+
+		builder, err := NewBuilder(cfg, cache)
+		require.NoError(t, err)
+		return builder
+	}
+
+	t.Run("ShouldReadComponentsFromCachedResults", func(t *testing.T) {
+		page := newBuilder(t).Build(ctx, nil, now)
+
+		assert.Equal(t, "Homelab", page.Title)
+		require.Len(t, page.Services, 2)
+		assert.Equal(t, StatusOperational, page.Services[0].Components[0].Status)
+		assert.Equal(t, StatusUnknown, page.Services[0].Components[1].Status, "a probe without a result is unknown")
+		assert.Equal(t, StatusUnknown, page.Services[0].Status)
+		assert.Equal(t, StatusPartialOutage, page.Services[1].Components[0].Status, "one of two nodes is failing")
+		assert.Equal(t, StatusOperational, page.Services[1].Components[1].Status)
+		assert.Equal(t, StatusPartialOutage, page.Status)
+	})
+
+	t.Run("ShouldTreatStaleResultsAsUnknown", func(t *testing.T) {
+		page := newBuilder(t).Build(ctx, nil, now.Add(time.Hour))
+
+		assert.Equal(t, StatusUnknown, page.Services[0].Components[0].Status)
+		assert.Equal(t, StatusUnknown, page.Services[1].Components[0].Status)
+	})
+
+	t.Run("ShouldApplyIncidentsAndMaintenance", func(t *testing.T) {
+		start, end := now.Add(-time.Hour), now.Add(time.Hour)
+		incidents := []*models.StatusIncident{
+			{ID: 1, Kind: models.StatusIncidentKindIncident, Impact: models.StatusImpactCritical, State: models.StatusIncidentIdentified, Components: []string{"storage"}},
+			{ID: 2, Kind: models.StatusIncidentKindIncident, Impact: models.StatusImpactMinor, State: models.StatusIncidentResolved, Components: []string{"jellyfin"}},
+			{ID: 3, Kind: models.StatusIncidentKindMaintenance, State: models.StatusMaintenanceScheduled, Components: []string{"nodes"}, ScheduledStart: &start, ScheduledEnd: &end},
+			{ID: 4, Kind: models.StatusIncidentKindMaintenance, State: models.StatusMaintenanceScheduled, Components: []string{"jellyfin"}, ScheduledStart: &end, ScheduledEnd: &end},
+		}
+
+		page := newBuilder(t).Build(ctx, incidents, now)
+
+		assert.Equal(t, StatusOperational, page.Services[0].Components[0].Status, "resolved incidents and upcoming maintenance do not count")
+		assert.Equal(t, StatusUnderMaintenance, page.Services[1].Components[0].Status)
+		assert.Equal(t, StatusMajorOutage, page.Services[1].Components[1].Status)
+		assert.Equal(t, StatusMajorOutage, page.Status)
+
+		assert.Equal(t, []*models.StatusIncident{incidents[0]}, page.Incidents)
+		assert.Equal(t, []*models.StatusIncident{incidents[2], incidents[3]}, page.Maintenance)
+		assert.Equal(t, []*models.StatusIncident{incidents[1]}, page.History)
+	})
+}
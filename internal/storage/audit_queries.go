@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"homelab-dashboard/internal/models"
 	"homelab-dashboard/internal/utils"
+	"strings"
 
 	"github.com/avct/uasurfer"
 	"github.com/jackc/pgx/v5"
@@ -114,14 +115,16 @@ func (p *DatabaseProvider) GetRecentCertificateDownloadLogs(ctx context.Context,
 	return downloads, nil
 }
 
+// insertWhitelistEventQuery records an audit event for a whitelist entry; it is shared by the queries that
+// change an entry and its audit trail in one transaction.
+const insertWhitelistEventQuery = `
+	INSERT INTO firewall_whitelist_events (whitelist_id, actor_iss, actor_sub, event_type, notes, client_ip, user_agent)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
 // CreateWhitelistEvent creates an audit event for a whitelist entry
 func (p *DatabaseProvider) CreateWhitelistEvent(ctx context.Context, whitelistID int, actorIss, actorSub, eventType, notes string, clientIP, userAgent *string) error {
-	query := `
-        INSERT INTO firewall_whitelist_events (whitelist_id, actor_iss, actor_sub, event_type, notes, client_ip, user_agent)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
-    `
-
-	_, err := p.pool.Exec(ctx, query, whitelistID, actorIss, actorSub, eventType, notes, clientIP, userAgent)
+	_, err := p.pool.Exec(ctx, insertWhitelistEventQuery, whitelistID, actorIss, actorSub, eventType, notes, clientIP, userAgent)
 	if err != nil {
 		return fmt.Errorf("failed to create whitelist event: %w", err)
 	}
@@ -133,7 +136,7 @@ func (p *DatabaseProvider) CreateWhitelistEvent(ctx context.Context, whitelistID
 func (p *DatabaseProvider) GetWhitelistEventsByEntry(ctx context.Context, whitelistID int) ([]*models.FirewallIPWhitelistEvent, error) {
 	query := `
         SELECT fwe.id, fwe.whitelist_id, fwe.actor_iss, fwe.actor_sub, fwe.event_type, fwe.notes, fwe.client_ip::text,
-               fwe.user_agent, fwe.created_at,
+               fwe.user_agent, fwe.created_at, fwe.client_country_code, fwe.client_asn, fwe.client_as_organization,
                COALESCE(actor.username, sa_creator.username) as actor_username,
                COALESCE(actor.display_name, sa_creator.display_name) as actor_display_name
         FROM firewall_whitelist_events fwe
@@ -163,6 +166,9 @@ func (p *DatabaseProvider) GetWhitelistEventsByEntry(ctx context.Context, whitel
 			&event.ClientIP,
 			&event.UserAgent,
 			&event.CreatedAt,
+			&event.ClientCountryCode,
+			&event.ClientASN,
+			&event.ClientASOrganization,
 			&event.ActorUsername,
 			&event.ActorDisplayName,
 		)
@@ -178,3 +184,164 @@ func (p *DatabaseProvider) GetWhitelistEventsByEntry(ctx context.Context, whitel
 
 	return events, nil
 }
+
+// whitelistEventSearchFrom joins events to their entry and resolve actors the same way GetWhitelistEventsByEntry does,
+// so service account actions are attributed to the account's creator.
+const whitelistEventSearchFrom = `
+        FROM firewall_whitelist_events fwe
+        JOIN firewall_ip_whitelist_entries fwl ON fwe.whitelist_id = fwl.id
+        LEFT JOIN users actor ON fwe.actor_iss = actor.iss AND fwe.actor_sub = actor.sub
+        LEFT JOIN service_accounts sa ON fwe.actor_iss = sa.iss AND fwe.actor_sub = sa.sub
+        LEFT JOIN users sa_creator ON sa.created_by_iss = sa_creator.iss AND sa.created_by_sub = sa_creator.sub
+`
+
+const whitelistEventSearchColumns = `
+        SELECT fwe.id, fwe.whitelist_id, fwe.actor_iss, fwe.actor_sub, fwe.event_type, fwe.notes, fwe.client_ip::text,
+               fwe.user_agent, fwe.created_at, fwe.client_country_code, fwe.client_asn, fwe.client_as_organization,
+               COALESCE(actor.username, sa_creator.username, '') as actor_username,
+               COALESCE(actor.display_name, sa_creator.display_name, '') as actor_display_name,
+               fwl.alias_name, fwl.alias_uuid, fwl.ip_address::text
+`
+
+// buildWhitelistEventFilter turns a filter into a WHERE clause and its arguments, numbered from $1.
+func buildWhitelistEventFilter(filter models.FirewallEventFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Actor != "" {
+		add("(fwe.actor_sub = $%[1]d OR COALESCE(actor.username, sa_creator.username) = $%[1]d)", filter.Actor)
+	}
+	if len(filter.EventTypes) > 0 {
+		add("fwe.event_type = ANY($%d)", filter.EventTypes)
+	}
+	if filter.ClientNet != nil {
+		add("fwe.client_ip <<= $%d::inet", filter.ClientNet.String())
+	}
+	if filter.AliasUUID != "" {
+		add("fwl.alias_uuid = $%d", filter.AliasUUID)
+	}
+	if filter.Since != nil {
+		add("fwe.created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		add("fwe.created_at < $%d", *filter.Until)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// SearchWhitelistEvents returns one page of the events matching filter across all entries, newest first.
+func (p *DatabaseProvider) SearchWhitelistEvents(ctx context.Context, filter models.FirewallEventFilter, params models.PaginationParams) (*models.PaginatedFirewallEvents, error) {
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+	if params.Limit > 500 {
+		params.Limit = 500
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	where, args := buildWhitelistEventFilter(filter)
+
+	var total int
+	if err := p.pool.QueryRow(ctx, "SELECT COUNT(*) "+whitelistEventSearchFrom+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count whitelist events: %w", err)
+	}
+
+	query := whitelistEventSearchColumns + whitelistEventSearchFrom + where +
+		fmt.Sprintf(" ORDER BY fwe.created_at DESC, fwe.id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+
+	rows, err := p.pool.Query(ctx, query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search whitelist events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.FirewallAuditEvent{}
+	for rows.Next() {
+		event, err := scanFirewallAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate whitelist events: %w", err)
+	}
+
+	return &models.PaginatedFirewallEvents{
+		Events:  events,
+		Total:   total,
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+		HasMore: params.Offset+len(events) < total,
+	}, nil
+}
+
+// StreamWhitelistEvents calls fn for every event matching filter, newest first, without holding the result in memory.
+// Iteration stops at the first error returned by fn.
+func (p *DatabaseProvider) StreamWhitelistEvents(ctx context.Context, filter models.FirewallEventFilter, fn func(*models.FirewallAuditEvent) error) error {
+	where, args := buildWhitelistEventFilter(filter)
+	query := whitelistEventSearchColumns + whitelistEventSearchFrom + where + " ORDER BY fwe.created_at DESC, fwe.id DESC"
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to search whitelist events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanFirewallAuditEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate whitelist events: %w", err)
+	}
+
+	return nil
+}
+
+func scanFirewallAuditEvent(rows pgx.Rows) (*models.FirewallAuditEvent, error) {
+	var event models.FirewallAuditEvent
+	err := rows.Scan(
+		&event.ID,
+		&event.WhitelistID,
+		&event.ActorISS,
+		&event.ActorSub,
+		&event.EventType,
+		&event.Notes,
+		&event.ClientIP,
+		&event.UserAgent,
+		&event.CreatedAt,
+		&event.ClientCountryCode,
+		&event.ClientASN,
+		&event.ClientASOrganization,
+		&event.ActorUsername,
+		&event.ActorDisplayName,
+		&event.AliasName,
+		&event.AliasUUID,
+		&event.IPAddress,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan whitelist event: %w", err)
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"homelab-dashboard/internal/models"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestBuildWhitelistEventFilter(t *testing.T) {
+	where, args := buildWhitelistEventFilter(models.FirewallEventFilter{})
+	if where != "" || len(args) != 0 {
+		t.Errorf("Expected no conditions for an empty filter, got %q with %d args", where, len(args))
+	}
+
+	network := netip.MustParsePrefix("192.0.2.0/24")
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args = buildWhitelistEventFilter(models.FirewallEventFilter{
+		Actor:      "alice",
+		EventTypes: []string{"added"},
+		ClientNet:  &network,
+		Since:      &since,
+	})
+
+	expected := "WHERE (fwe.actor_sub = $1 OR COALESCE(actor.username, sa_creator.username) = $1)" +
+		" AND fwe.event_type = ANY($2) AND fwe.client_ip <<= $3::inet AND fwe.created_at >= $4"
+	if where != expected {
+		t.Errorf("Unexpected WHERE clause:\n got: %s\nwant: %s", where, expected)
+	}
+
+	if len(args) != 4 {
+		t.Fatalf("Expected 4 args, got %d", len(args))
+	}
+	if args[2] != "192.0.2.0/24" {
+		t.Errorf("Expected client network argument 192.0.2.0/24, got %v", args[2])
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/models"
+)
+
+// CreateDataAlert records a new pending or firing alert.
+func (p *DatabaseProvider) CreateDataAlert(ctx context.Context, alert *models.DataAlert) (*models.DataAlert, error) {
+	query := `
+		INSERT INTO data_alerts (rule_name, query_key, series, labels, severity, state, value, active_since, fired_at, last_evaluated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	err := p.pool.QueryRow(ctx, query,
+		alert.RuleName,
+		alert.QueryKey,
+		alert.Series,
+		alert.Labels,
+		alert.Severity,
+		string(alert.State),
+		alert.Value,
+		alert.ActiveSince,
+		alert.FiredAt,
+		alert.LastEvaluatedAt,
+	).Scan(&alert.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// GetActiveDataAlerts returns every pending and firing alert, oldest first.
+func (p *DatabaseProvider) GetActiveDataAlerts(ctx context.Context) ([]*models.DataAlert, error) {
+	query := `
+		SELECT id, rule_name, query_key, series, labels, severity, state, value, active_since, fired_at, resolved_at, last_evaluated_at
+		FROM data_alerts
+		WHERE state <> 'resolved'
+		ORDER BY active_since, id
+	`
+
+	rows, err := p.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active data alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*models.DataAlert
+	for rows.Next() {
+		var alert models.DataAlert
+		err := rows.Scan(
+			&alert.ID,
+			&alert.RuleName,
+			&alert.QueryKey,
+			&alert.Series,
+			&alert.Labels,
+			&alert.Severity,
+			&alert.State,
+			&alert.Value,
+			&alert.ActiveSince,
+			&alert.FiredAt,
+			&alert.ResolvedAt,
+			&alert.LastEvaluatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data alert: %w", err)
+		}
+		alerts = append(alerts, &alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate data alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// UpdateDataAlert saves an alert's state, latest value and timestamps.
+func (p *DatabaseProvider) UpdateDataAlert(ctx context.Context, alert *models.DataAlert) error {
+	query := `
+		UPDATE data_alerts
+		SET state = $2, value = $3, fired_at = $4, resolved_at = $5, last_evaluated_at = $6
+		WHERE id = $1
+	`
+
+	_, err := p.pool.Exec(ctx, query,
+		alert.ID,
+		string(alert.State),
+		alert.Value,
+		alert.FiredAt,
+		alert.ResolvedAt,
+		alert.LastEvaluatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update data alert: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDataAlert removes an alert, used for pending alerts whose condition cleared before they fired.
+func (p *DatabaseProvider) DeleteDataAlert(ctx context.Context, id int) error {
+	if _, err := p.pool.Exec(ctx, `DELETE FROM data_alerts WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete data alert: %w", err)
+	}
+
+	return nil
+}
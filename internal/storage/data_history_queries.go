@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RecordDataHistory adds samples to every rollup of their query's history. Each bucket keeps the count, sum,
+// minimum and maximum of the samples that fell into it, along with the most recent one.
+func (p *DatabaseProvider) RecordDataHistory(ctx context.Context, samples []models.DataHistorySample) error {
+	query := `
+		INSERT INTO data_query_history (query_name, variables, resolution, bucket, series, labels, sample_count, sum, min, max, last, last_recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 1, $7, $7, $7, $7, $8)
+		ON CONFLICT (query_name, variables, resolution, bucket, series) DO UPDATE SET
+			sample_count = data_query_history.sample_count + 1,
+			sum = data_query_history.sum + EXCLUDED.sum,
+			min = LEAST(data_query_history.min, EXCLUDED.min),
+			max = GREATEST(data_query_history.max, EXCLUDED.max),
+			last = CASE WHEN EXCLUDED.last_recorded_at >= data_query_history.last_recorded_at THEN EXCLUDED.last ELSE data_query_history.last END,
+			last_recorded_at = GREATEST(data_query_history.last_recorded_at, EXCLUDED.last_recorded_at)
+	`
+
+	batch := &pgx.Batch{}
+	for _, sample := range samples {
+		for _, resolution := range models.DataHistoryResolutions {
+			batch.Queue(query,
+				sample.QueryName,
+				sample.Variables,
+				string(resolution),
+				resolution.Bucket(sample.RecordedAt),
+				sample.Series,
+				sample.Labels,
+				sample.Value,
+				sample.RecordedAt,
+			)
+		}
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	if err := p.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to record data history: %w", err)
+	}
+
+	return nil
+}
+
+// GetDataHistory returns a rollup of a query's history between start and end, ordered by series and bucket.
+func (p *DatabaseProvider) GetDataHistory(ctx context.Context, queryName, variables string, resolution models.DataHistoryResolution, start, end time.Time) ([]models.DataHistoryPoint, error) {
+	query := `
+		SELECT series, labels, bucket, sample_count, sum / sample_count, min, max, last
+		FROM data_query_history
+		WHERE query_name = $1 AND variables = $2 AND resolution = $3 AND bucket >= $4 AND bucket <= $5
+		ORDER BY series, bucket
+	`
+
+	rows, err := p.pool.Query(ctx, query, queryName, variables, string(resolution), resolution.Bucket(start), end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data history: %w", err)
+	}
+	defer rows.Close()
+
+	var points []models.DataHistoryPoint
+	for rows.Next() {
+		var point models.DataHistoryPoint
+		err := rows.Scan(
+			&point.Series,
+			&point.Labels,
+			&point.Bucket,
+			&point.SampleCount,
+			&point.Avg,
+			&point.Min,
+			&point.Max,
+			&point.Last,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data history point: %w", err)
+		}
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate data history: %w", err)
+	}
+
+	return points, nil
+}
+
+// DeleteDataHistoryBefore deletes the buckets of one rollup of a query's history that started before the given
+// time, and returns how many were deleted.
+func (p *DatabaseProvider) DeleteDataHistoryBefore(ctx context.Context, queryName string, resolution models.DataHistoryResolution, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM data_query_history
+		WHERE query_name = $1 AND resolution = $2 AND bucket < $3
+	`
+
+	result, err := p.pool.Exec(ctx, query, queryName, string(resolution), before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete data history: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const blacklistRuleColumns = `
+	id, alias_uuid::text, value, host(range_start), host(range_end), asn, source, feed_name, reason,
+	created_by_iss, created_by_sub, created_at, expires_at, removed_at, removed_by_iss, removed_by_sub
+`
+
+// CreateBlacklistRule inserts a blacklist rule for a single IP, range or ASN.
+func (p *DatabaseProvider) CreateBlacklistRule(ctx context.Context, rule *models.FirewallBlacklistRule) (*models.FirewallBlacklistRule, error) {
+	query := `
+		INSERT INTO firewall_blacklist_rules (alias_uuid, value, range_start, range_end, asn, source, feed_name, reason, created_by_iss, created_by_sub, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	var id int
+	err := p.pool.QueryRow(ctx, query,
+		rule.AliasUUID,
+		rule.Value,
+		rule.RangeStart,
+		rule.RangeEnd,
+		rule.ASN,
+		string(rule.Source),
+		rule.FeedName,
+		rule.Reason,
+		rule.CreatedByIss,
+		rule.CreatedBySub,
+		rule.ExpiresAt,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blacklist rule: %w", err)
+	}
+
+	return p.GetBlacklistRuleByID(ctx, id)
+}
+
+// GetBlacklistRuleByID returns a single blacklist rule, including lifted and expired ones.
+func (p *DatabaseProvider) GetBlacklistRuleByID(ctx context.Context, id int) (*models.FirewallBlacklistRule, error) {
+	query := `SELECT ` + blacklistRuleColumns + ` FROM firewall_blacklist_rules WHERE id = $1`
+
+	rule, err := scanBlacklistRule(p.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("blacklist rule not found")
+		}
+		return nil, fmt.Errorf("failed to get blacklist rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetBlacklistRules lists blacklist rules that apply to an alias (including global rules), or every
+// rule when aliasUUID is empty. Lifted and expired rules are only returned when includeInactive is set.
+func (p *DatabaseProvider) GetBlacklistRules(ctx context.Context, aliasUUID string, includeInactive bool) ([]*models.FirewallBlacklistRule, error) {
+	query := `
+		SELECT ` + blacklistRuleColumns + `
+		FROM firewall_blacklist_rules
+		WHERE ($1 = '' OR alias_uuid IS NULL OR alias_uuid::text = $1)
+		  AND ($2 OR (removed_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())))
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := p.pool.Query(ctx, query, aliasUUID, includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blacklist rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.FirewallBlacklistRule
+	for rows.Next() {
+		rule, err := scanBlacklistRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blacklist rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate blacklist rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateBlacklistRule replaces the reason and expiry of an active blacklist rule.
+func (p *DatabaseProvider) UpdateBlacklistRule(ctx context.Context, id int, reason *string, expiresAt *time.Time) error {
+	query := `
+		UPDATE firewall_blacklist_rules
+		SET reason = $2, expires_at = $3
+		WHERE id = $1 AND removed_at IS NULL
+	`
+
+	result, err := p.pool.Exec(ctx, query, id, reason, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to update blacklist rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("blacklist rule not found")
+	}
+
+	return nil
+}
+
+// RemoveBlacklistRule lifts a blacklist rule. The row is kept for auditing, and lifted feed rules
+// are not re-created by later imports of the same feed.
+func (p *DatabaseProvider) RemoveBlacklistRule(ctx context.Context, id int, removerIss, removerSub string) error {
+	query := `
+		UPDATE firewall_blacklist_rules
+		SET removed_at = NOW(), removed_by_iss = $2, removed_by_sub = $3
+		WHERE id = $1 AND removed_at IS NULL
+	`
+
+	result, err := p.pool.Exec(ctx, query, id, removerIss, removerSub)
+	if err != nil {
+		return fmt.Errorf("failed to remove blacklist rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("blacklist rule not found")
+	}
+
+	return nil
+}
+
+// ReplaceFeedBlacklistRules atomically swaps the active rules of a threat feed for a freshly imported set.
+// The previous rules are expired rather than deleted so their history stays auditable. Values an admin has
+// lifted from this feed are skipped. Returns the number of rules inserted.
+func (p *DatabaseProvider) ReplaceFeedBlacklistRules(ctx context.Context, feedName string, aliasUUID *string, rules []*models.FirewallBlacklistRule, creatorIss, creatorSub string) (int, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	expireQuery := `
+		UPDATE firewall_blacklist_rules
+		SET expires_at = NOW()
+		WHERE source = 'feed' AND feed_name = $1 AND removed_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+	`
+	if _, err := tx.Exec(ctx, expireQuery, feedName); err != nil {
+		return 0, fmt.Errorf("failed to expire previous feed rules: %w", err)
+	}
+
+	values := make([]string, len(rules))
+	starts := make([]*string, len(rules))
+	ends := make([]*string, len(rules))
+	asns := make([]*int64, len(rules))
+	reasons := make([]*string, len(rules))
+	for i, rule := range rules {
+		values[i] = rule.Value
+		starts[i] = rule.RangeStart
+		ends[i] = rule.RangeEnd
+		asns[i] = rule.ASN
+		reasons[i] = rule.Reason
+	}
+
+	insertQuery := `
+		INSERT INTO firewall_blacklist_rules (alias_uuid, value, range_start, range_end, asn, source, feed_name, reason, created_by_iss, created_by_sub)
+		SELECT $2::uuid, v.value, v.range_start::inet, v.range_end::inet, v.asn, 'feed', $1, v.reason, $3, $4
+		FROM unnest($5::text[], $6::text[], $7::text[], $8::bigint[], $9::text[]) AS v(value, range_start, range_end, asn, reason)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM firewall_blacklist_rules lifted
+			WHERE lifted.source = 'feed'
+			  AND lifted.feed_name = $1
+			  AND lifted.removed_at IS NOT NULL
+			  AND lifted.value = v.value
+		)
+	`
+	result, err := tx.Exec(ctx, insertQuery, feedName, aliasUUID, creatorIss, creatorSub, values, starts, ends, asns, reasons)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert feed rules: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// IsIPBlacklisted checks whether any active blacklist rule for the alias (or a global rule) covers the IP.
+// ASN rules are only consulted when the caller knows the ASN announcing the address.
+func (p *DatabaseProvider) IsIPBlacklisted(ctx context.Context, aliasUUID, ipAddress string, asn *int64) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM firewall_blacklist_rules
+			WHERE (alias_uuid IS NULL OR alias_uuid = $1)
+			  AND removed_at IS NULL
+			  AND (expires_at IS NULL OR expires_at > NOW())
+			  AND (
+			      $2::inet BETWEEN range_start AND range_end
+			      OR (asn IS NOT NULL AND asn = $3)
+			  )
+		)
+	`
+
+	var exists bool
+	err := p.pool.QueryRow(ctx, query, aliasUUID, ipAddress, asn).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if IP is blacklisted: %w", err)
+	}
+
+	return exists, nil
+}
+
+func scanBlacklistRule(row pgx.Row) (*models.FirewallBlacklistRule, error) {
+	var rule models.FirewallBlacklistRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.AliasUUID,
+		&rule.Value,
+		&rule.RangeStart,
+		&rule.RangeEnd,
+		&rule.ASN,
+		&rule.Source,
+		&rule.FeedName,
+		&rule.Reason,
+		&rule.CreatedByIss,
+		&rule.CreatedBySub,
+		&rule.CreatedAt,
+		&rule.ExpiresAt,
+		&rule.RemovedAt,
+		&rule.RemovedByIss,
+		&rule.RemovedBySub,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
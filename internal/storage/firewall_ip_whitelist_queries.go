@@ -13,7 +13,9 @@ import (
 // AddIPToWhitelist adds a firewall ip whitelist entry.
 // This function uses a transaction to atomically check limits and insert the entry,
 // preventing race conditions where multiple concurrent requests could exceed limits.
-func (p *DatabaseProvider) AddIPToWhitelist(ctx context.Context, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description string, expiresAt *time.Time, clientIP, userAgent *string) (*models.FirewallIPWhitelistEntry, error) {
+// The enrichment describes ipAddress, clientEnrichment describes clientIP and is stored on the requested event.
+// Entries with a schedule start as 'scheduled' when their access window is currently closed.
+func (p *DatabaseProvider) AddIPToWhitelist(ctx context.Context, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description string, expiresAt *time.Time, clientIP, userAgent *string, enrichment, clientEnrichment models.IPEnrichment, schedule *models.AccessSchedule) (*models.FirewallIPWhitelistEntry, error) {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -25,7 +27,7 @@ func (p *DatabaseProvider) AddIPToWhitelist(ctx context.Context, ownerIss, owner
 		SELECT COUNT(*)
 		FROM firewall_ip_whitelist_entries
 		WHERE owner_iss = $1 AND owner_sub = $2 AND alias_uuid = $3
-		  AND status IN ('requested', 'added')
+		  AND status IN ('requested', 'added', 'scheduled')
 	`
 	var userCount int
 	err = tx.QueryRow(ctx, userCountQuery, ownerIss, ownerSub, aliasUUID).Scan(&userCount)
@@ -37,7 +39,7 @@ func (p *DatabaseProvider) AddIPToWhitelist(ctx context.Context, ownerIss, owner
 		SELECT COUNT(*)
 		FROM firewall_ip_whitelist_entries
 		WHERE alias_uuid = $1
-		  AND status IN ('requested', 'added')
+		  AND status IN ('requested', 'added', 'scheduled')
 	`
 	var totalCount int
 	err = tx.QueryRow(ctx, totalCountQuery, aliasUUID).Scan(&totalCount)
@@ -49,7 +51,7 @@ func (p *DatabaseProvider) AddIPToWhitelist(ctx context.Context, ownerIss, owner
 		SELECT id
 		FROM firewall_ip_whitelist_entries
 		WHERE owner_iss = $1 AND owner_sub = $2 AND alias_uuid = $3 AND ip_address = $4
-		  AND status IN ('requested', 'added')
+		  AND status IN ('requested', 'added', 'scheduled')
 		LIMIT 1
 	`
 	var existingID int
@@ -63,22 +65,31 @@ func (p *DatabaseProvider) AddIPToWhitelist(ctx context.Context, ownerIss, owner
 	}
 
 	insertQuery := `
-		INSERT INTO firewall_ip_whitelist_entries (owner_iss, owner_sub, alias_name, alias_uuid, ip_address, description, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO firewall_ip_whitelist_entries (owner_iss, owner_sub, alias_name, alias_uuid, ip_address, description, expires_at,
+		                                           country_code, asn, as_organization, schedule, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id
 	`
+	status := models.StatusRequested
+	if schedule != nil && !schedule.IsOpen(time.Now()) {
+		status = models.StatusScheduled
+	}
+
 	var recordId int
 	err = tx.QueryRow(ctx, insertQuery,
-		ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description, expiresAt).Scan(&recordId)
+		ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description, expiresAt,
+		enrichment.CountryCode, enrichment.ASN, enrichment.ASOrganization, schedule, string(status)).Scan(&recordId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add IP to whitelist: %w", err)
 	}
 
 	eventQuery := `
-		INSERT INTO firewall_whitelist_events (whitelist_id, actor_iss, actor_sub, event_type, notes, client_ip, user_agent)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO firewall_whitelist_events (whitelist_id, actor_iss, actor_sub, event_type, notes, client_ip, user_agent,
+		                                       client_country_code, client_asn, client_as_organization)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
-	_, err = tx.Exec(ctx, eventQuery, recordId, ownerIss, ownerSub, "requested", nil, clientIP, userAgent)
+	_, err = tx.Exec(ctx, eventQuery, recordId, ownerIss, ownerSub, "requested", nil, clientIP, userAgent,
+		clientEnrichment.CountryCode, clientEnrichment.ASN, clientEnrichment.ASOrganization)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create requested event: %w", err)
 	}
@@ -94,7 +105,8 @@ func (p *DatabaseProvider) AddIPToWhitelist(ctx context.Context, ownerIss, owner
 func (p *DatabaseProvider) GetWhitelistEntryByID(ctx context.Context, id int) (*models.FirewallIPWhitelistEntry, error) {
 	query := `
         SELECT id, owner_iss, owner_sub, alias_name, alias_uuid, ip_address::text, ip_version, description, status, 
-               requested_at, added_at, removed_at, expires_at, removed_by_iss, removed_by_sub, removal_reason
+               requested_at, added_at, removed_at, expires_at, removed_by_iss, removed_by_sub, removal_reason,
+               country_code, asn, as_organization, schedule, extension_count
         FROM firewall_ip_whitelist_entries
         WHERE id = $1
     `
@@ -117,6 +129,11 @@ func (p *DatabaseProvider) GetWhitelistEntryByID(ctx context.Context, id int) (*
 		&whitelistEntry.RemovedByIss,
 		&whitelistEntry.RemovedBySub,
 		&whitelistEntry.RemovalReason,
+		&whitelistEntry.CountryCode,
+		&whitelistEntry.ASN,
+		&whitelistEntry.ASOrganization,
+		&whitelistEntry.Schedule,
+		&whitelistEntry.ExtensionCount,
 	)
 
 	if err != nil {
@@ -147,6 +164,7 @@ func (p *DatabaseProvider) GetUserWhitelistEntries(ctx context.Context, ownerIss
 			fiwe.ip_address::text, fiwe.ip_version, fiwe.description, fiwe.status,
 			fiwe.requested_at, fiwe.added_at, fiwe.removed_at, fiwe.expires_at,
 			fiwe.removed_by_iss, fiwe.removed_by_sub, fiwe.removal_reason,
+			fiwe.country_code, fiwe.asn, fiwe.as_organization, fiwe.schedule, fiwe.extension_count,
 			owner.username as owner_username,
 			owner.display_name as owner_display_name,
 			fwe.id as event_id,
@@ -158,6 +176,9 @@ func (p *DatabaseProvider) GetUserWhitelistEntries(ctx context.Context, ownerIss
 			fwe.client_ip::text as event_client_ip,
 			fwe.user_agent as event_user_agent,
 			fwe.created_at as event_created_at,
+			fwe.client_country_code as event_client_country_code,
+			fwe.client_asn as event_client_asn,
+			fwe.client_as_organization as event_client_as_organization,
 			COALESCE(event_actor.username, sa_creator.username) as event_actor_username,
 			COALESCE(event_actor.display_name, sa_creator.display_name) as event_actor_display_name
 		FROM firewall_ip_whitelist_entries fiwe
@@ -188,11 +209,17 @@ func (p *DatabaseProvider) GetUserWhitelistEntries(ctx context.Context, ownerIss
 			requestedAt                                         time.Time
 			addedAt, removedAt, expiresAt                       *time.Time
 			removedByIss, removedBySub, removalReason           *string
+			countryCode, asOrganization                         *string
+			asn                                                 *int64
+			schedule                                            *models.AccessSchedule
+			extensionCount                                      int
 			eventID, eventWhitelistID                           *int
 			eventActorIss, eventActorSub, eventType, eventNotes *string
 			eventClientIP, eventUserAgent                       *string
 			eventCreatedAt                                      *time.Time
 			eventActorUsername, eventActorDisplay               *string
+			eventClientCountryCode, eventClientASOrganization   *string
+			eventClientASN                                      *int64
 		)
 
 		err := rows.Scan(
@@ -200,10 +227,12 @@ func (p *DatabaseProvider) GetUserWhitelistEntries(ctx context.Context, ownerIss
 			&ipAddress, &ipVersion, &description, &status,
 			&requestedAt, &addedAt, &removedAt, &expiresAt,
 			&removedByIss, &removedBySub, &removalReason,
+			&countryCode, &asn, &asOrganization, &schedule, &extensionCount,
 			&ownerUsername, &ownerDisplayName,
 			&eventID, &eventWhitelistID,
 			&eventActorIss, &eventActorSub, &eventType, &eventNotes,
 			&eventClientIP, &eventUserAgent, &eventCreatedAt,
+			&eventClientCountryCode, &eventClientASN, &eventClientASOrganization,
 			&eventActorUsername, &eventActorDisplay,
 		)
 		if err != nil {
@@ -224,6 +253,8 @@ func (p *DatabaseProvider) GetUserWhitelistEntries(ctx context.Context, ownerIss
 				IPVersion:        ipVersion,
 				Description:      description,
 				Status:           models.FirewallIPWhitelistStatus(status),
+				Schedule:         schedule,
+				ExtensionCount:   extensionCount,
 				RequestedAt:      requestedAt,
 				AddedAt:          addedAt,
 				RemovedAt:        removedAt,
@@ -231,7 +262,12 @@ func (p *DatabaseProvider) GetUserWhitelistEntries(ctx context.Context, ownerIss
 				RemovedByIss:     removedByIss,
 				RemovedBySub:     removedBySub,
 				RemovalReason:    removalReason,
-				Events:           []models.FirewallIPWhitelistEvent{},
+				IPEnrichment: models.IPEnrichment{
+					CountryCode:    countryCode,
+					ASN:            asn,
+					ASOrganization: asOrganization,
+				},
+				Events: []models.FirewallIPWhitelistEvent{},
 			}
 			entriesMap[entryID] = entry
 			entryOrder = append(entryOrder, entryID)
@@ -250,6 +286,10 @@ func (p *DatabaseProvider) GetUserWhitelistEntries(ctx context.Context, ownerIss
 				CreatedAt:        *eventCreatedAt,
 				ActorUsername:    *eventActorUsername,
 				ActorDisplayName: *eventActorDisplay,
+
+				ClientCountryCode:    eventClientCountryCode,
+				ClientASN:            eventClientASN,
+				ClientASOrganization: eventClientASOrganization,
 			}
 			entry.Events = append(entry.Events, event)
 		}
@@ -277,6 +317,7 @@ func (p *DatabaseProvider) GetAllWhitelistEntries(ctx context.Context) ([]*model
 			fiwe.ip_address::text, fiwe.ip_version, fiwe.description, fiwe.status,
 			fiwe.requested_at, fiwe.added_at, fiwe.removed_at, fiwe.expires_at,
 			fiwe.removed_by_iss, fiwe.removed_by_sub, fiwe.removal_reason,
+			fiwe.country_code, fiwe.asn, fiwe.as_organization, fiwe.schedule, fiwe.extension_count,
 			owner.username as owner_username,
 			owner.display_name as owner_display_name,
 			fwe.id as event_id,
@@ -288,6 +329,9 @@ func (p *DatabaseProvider) GetAllWhitelistEntries(ctx context.Context) ([]*model
 			fwe.client_ip::text as event_client_ip,
 			fwe.user_agent as event_user_agent,
 			fwe.created_at as event_created_at,
+			fwe.client_country_code as event_client_country_code,
+			fwe.client_asn as event_client_asn,
+			fwe.client_as_organization as event_client_as_organization,
 			COALESCE(event_actor.username, sa_creator.username) as event_actor_username,
 			COALESCE(event_actor.display_name, sa_creator.display_name) as event_actor_display_name
 		FROM firewall_ip_whitelist_entries fiwe
@@ -317,11 +361,17 @@ func (p *DatabaseProvider) GetAllWhitelistEntries(ctx context.Context) ([]*model
 			requestedAt                                         time.Time
 			addedAt, removedAt, expiresAt                       *time.Time
 			removedByIss, removedBySub, removalReason           *string
+			countryCode, asOrganization                         *string
+			asn                                                 *int64
+			schedule                                            *models.AccessSchedule
+			extensionCount                                      int
 			eventID, eventWhitelistID                           *int
 			eventActorIss, eventActorSub, eventType, eventNotes *string
 			eventClientIP, eventUserAgent                       *string
 			eventCreatedAt                                      *time.Time
 			eventActorUsername, eventActorDisplay               *string
+			eventClientCountryCode, eventClientASOrganization   *string
+			eventClientASN                                      *int64
 		)
 
 		err := rows.Scan(
@@ -329,10 +379,12 @@ func (p *DatabaseProvider) GetAllWhitelistEntries(ctx context.Context) ([]*model
 			&ipAddress, &ipVersion, &description, &status,
 			&requestedAt, &addedAt, &removedAt, &expiresAt,
 			&removedByIss, &removedBySub, &removalReason,
+			&countryCode, &asn, &asOrganization, &schedule, &extensionCount,
 			&ownerUsername, &ownerDisplayName,
 			&eventID, &eventWhitelistID,
 			&eventActorIss, &eventActorSub, &eventType, &eventNotes,
 			&eventClientIP, &eventUserAgent, &eventCreatedAt,
+			&eventClientCountryCode, &eventClientASN, &eventClientASOrganization,
 			&eventActorUsername, &eventActorDisplay,
 		)
 		if err != nil {
@@ -353,6 +405,8 @@ func (p *DatabaseProvider) GetAllWhitelistEntries(ctx context.Context) ([]*model
 				IPVersion:        ipVersion,
 				Description:      description,
 				Status:           models.FirewallIPWhitelistStatus(status),
+				Schedule:         schedule,
+				ExtensionCount:   extensionCount,
 				RequestedAt:      requestedAt,
 				AddedAt:          addedAt,
 				RemovedAt:        removedAt,
@@ -360,7 +414,12 @@ func (p *DatabaseProvider) GetAllWhitelistEntries(ctx context.Context) ([]*model
 				RemovedByIss:     removedByIss,
 				RemovedBySub:     removedBySub,
 				RemovalReason:    removalReason,
-				Events:           []models.FirewallIPWhitelistEvent{},
+				IPEnrichment: models.IPEnrichment{
+					CountryCode:    countryCode,
+					ASN:            asn,
+					ASOrganization: asOrganization,
+				},
+				Events: []models.FirewallIPWhitelistEvent{},
 			}
 			entriesMap[entryID] = entry
 			entryOrder = append(entryOrder, entryID)
@@ -379,6 +438,10 @@ func (p *DatabaseProvider) GetAllWhitelistEntries(ctx context.Context) ([]*model
 				CreatedAt:        *eventCreatedAt,
 				ActorUsername:    *eventActorUsername,
 				ActorDisplayName: *eventActorDisplay,
+
+				ClientCountryCode:    eventClientCountryCode,
+				ClientASN:            eventClientASN,
+				ClientASOrganization: eventClientASOrganization,
 			}
 			entry.Events = append(entry.Events, event)
 		}
@@ -421,8 +484,61 @@ func (p *DatabaseProvider) RemoveIPFromWhitelist(ctx context.Context, id int, ow
 	return nil
 }
 
-// BlacklistIP blacklists an IP address (prevents re-adding)
+// ExtendWhitelistEntry moves the expiry of an active entry and records an 'extended' event in one transaction.
+// The update only applies while the entry still has previousCount extensions, so concurrent extensions cannot
+// both succeed; false is returned when the entry changed or is no longer active.
+func (p *DatabaseProvider) ExtendWhitelistEntry(ctx context.Context, id, previousCount int, expiresAt time.Time, actorIss, actorSub, notes string, clientIP, userAgent *string) (bool, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        UPDATE firewall_ip_whitelist_entries
+        SET expires_at = $2, extension_count = extension_count + 1
+        WHERE id = $1
+          AND extension_count = $3
+          AND status IN ('requested', 'added', 'scheduled')
+          AND expires_at > NOW()
+    `
+
+	result, err := tx.Exec(ctx, query, id, expiresAt, previousCount)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend whitelist entry: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, insertWhitelistEventQuery, id, actorIss, actorSub, "extended", notes, clientIP, userAgent)
+	if err != nil {
+		return false, fmt.Errorf("failed to create extension event: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// insertEntryBlacklistRuleQuery records a single-IP blacklist rule for an IP blacklisted through a whitelist entry.
+const insertEntryBlacklistRuleQuery = `
+	INSERT INTO firewall_blacklist_rules (alias_uuid, value, range_start, range_end, source, reason, created_by_iss, created_by_sub)
+	VALUES ($1, host($2::inet), $2::inet, $2::inet, 'entry', NULLIF($3, ''), $4, $5)
+`
+
+// BlacklistIP blacklists an IP address (prevents re-adding). The entry, its blacklist rule and its audit event
+// are written in one transaction.
 func (p *DatabaseProvider) BlacklistIP(ctx context.Context, id int, adminIss, adminSub, reason string) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
         UPDATE firewall_ip_whitelist_entries
         SET status = 'blacklisted_by_admin',
@@ -431,22 +547,32 @@ func (p *DatabaseProvider) BlacklistIP(ctx context.Context, id int, adminIss, ad
             removed_by_sub = $3,
             removal_reason = $4
         WHERE id = $1
+        RETURNING alias_uuid::text, ip_address::text
     `
 
-	result, err := p.pool.Exec(ctx, query, id, adminIss, adminSub, reason)
+	var aliasUUID, ipAddress string
+	err = tx.QueryRow(ctx, query, id, adminIss, adminSub, reason).Scan(&aliasUUID, &ipAddress)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("whitelist entry not found")
+		}
 		return fmt.Errorf("failed to blacklist IP: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("whitelist entry not found")
+	_, err = tx.Exec(ctx, insertEntryBlacklistRuleQuery, aliasUUID, ipAddress, reason, adminIss, adminSub)
+	if err != nil {
+		return fmt.Errorf("failed to create blacklist rule: %w", err)
 	}
 
-	err = p.CreateWhitelistEvent(ctx, id, adminIss, adminSub, "blacklisted_by_admin", reason, nil, nil)
+	_, err = tx.Exec(ctx, insertWhitelistEventQuery, id, adminIss, adminSub, "blacklisted_by_admin", reason, nil, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create blacklist event: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -507,13 +633,15 @@ func (p *DatabaseProvider) BlacklistIPAddress(ctx context.Context, aliasUUID, ip
 		return 0, fmt.Errorf("failed to update entries to blacklisted: %w", err)
 	}
 
+	// Record the blacklist as a rule so IsIPBlacklisted sees it and it can be lifted later
+	_, err = tx.Exec(ctx, insertEntryBlacklistRuleQuery, aliasUUID, ipAddress, reason, adminIss, adminSub)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create blacklist rule: %w", err)
+	}
+
 	// Create audit events for each blacklisted entry
 	for _, id := range entryIDs {
-		eventQuery := `
-			INSERT INTO firewall_whitelist_events (whitelist_id, actor_iss, actor_sub, event_type, notes, client_ip, user_agent)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
-		`
-		_, err = tx.Exec(ctx, eventQuery, id, adminIss, adminSub, "blacklisted_by_admin", reason, nil, nil)
+		_, err = tx.Exec(ctx, insertWhitelistEventQuery, id, adminIss, adminSub, "blacklisted_by_admin", reason, nil, nil)
 		if err != nil {
 			return 0, fmt.Errorf("failed to create blacklist event for entry %d: %w", id, err)
 		}
@@ -526,31 +654,67 @@ func (p *DatabaseProvider) BlacklistIPAddress(ctx context.Context, aliasUUID, ip
 	return len(entryIDs), nil
 }
 
-// IsIPBlacklisted checks if an IP is blacklisted for an alias
-func (p *DatabaseProvider) IsIPBlacklisted(ctx context.Context, aliasUUID, ipAddress string) (bool, error) {
-	query := `
-        SELECT EXISTS(
-            SELECT 1 FROM firewall_ip_whitelist_entries
-            WHERE alias_uuid = $1 
-              AND ip_address = $2 
-              AND status = 'blacklisted_by_admin'
-        )
-    `
+// RemoveAliasEntries removes every active entry of an alias on behalf of an admin, recording a
+// 'removed_by_admin' event for each. Returns the count of entries removed.
+func (p *DatabaseProvider) RemoveAliasEntries(ctx context.Context, aliasUUID, adminIss, adminSub, reason string) (int, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updateQuery := `
+		UPDATE firewall_ip_whitelist_entries
+		SET status = 'removed_by_admin',
+		    removed_at = NOW(),
+		    removed_by_iss = $2,
+		    removed_by_sub = $3,
+		    removal_reason = $4
+		WHERE alias_uuid = $1
+		  AND status IN ('requested', 'added', 'scheduled')
+		RETURNING id
+	`
 
-	var exists bool
-	err := p.pool.QueryRow(ctx, query, aliasUUID, ipAddress).Scan(&exists)
+	rows, err := tx.Query(ctx, updateQuery, aliasUUID, adminIss, adminSub, reason)
 	if err != nil {
-		return false, fmt.Errorf("failed to check if IP is blacklisted: %w", err)
+		return 0, fmt.Errorf("failed to remove alias entries: %w", err)
 	}
 
-	return exists, nil
+	var entryIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan entry ID: %w", err)
+		}
+		entryIDs = append(entryIDs, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate removed entries: %w", err)
+	}
+
+	for _, id := range entryIDs {
+		_, err = tx.Exec(ctx, insertWhitelistEventQuery, id, adminIss, adminSub, "removed_by_admin", reason, nil, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create removal event for entry %d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(entryIDs), nil
 }
 
 // GetPendingIPs gets all IPs that need to be added to the firewall for a specific alias
 func (p *DatabaseProvider) GetPendingIPs(ctx context.Context, aliasUUID string) ([]*models.FirewallIPWhitelistEntry, error) {
 	query := `
         SELECT id, owner_iss, owner_sub, alias_name, alias_uuid, ip_address::text, ip_version, description, status, 
-               requested_at, added_at, removed_at, expires_at, removed_by_iss, removed_by_sub, removal_reason
+               requested_at, added_at, removed_at, expires_at, removed_by_iss, removed_by_sub, removal_reason,
+               country_code, asn, as_organization, schedule, extension_count
         FROM firewall_ip_whitelist_entries
         WHERE alias_uuid = $1 
           AND status = 'requested'
@@ -584,6 +748,11 @@ func (p *DatabaseProvider) GetPendingIPs(ctx context.Context, aliasUUID string)
 			&entry.RemovedByIss,
 			&entry.RemovedBySub,
 			&entry.RemovalReason,
+			&entry.CountryCode,
+			&entry.ASN,
+			&entry.ASOrganization,
+			&entry.Schedule,
+			&entry.ExtensionCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan pending IP: %w", err)
@@ -599,6 +768,65 @@ func (p *DatabaseProvider) GetPendingIPs(ctx context.Context, aliasUUID string)
 	return entries, nil
 }
 
+// GetAliasSyncEntries gets the entries of a single alias that should be present on the firewall,
+// i.e. requested and added ones. Events are not loaded.
+func (p *DatabaseProvider) GetAliasSyncEntries(ctx context.Context, aliasUUID string) ([]*models.FirewallIPWhitelistEntry, error) {
+	query := `
+        SELECT id, owner_iss, owner_sub, alias_name, alias_uuid, ip_address::text, ip_version, description, status,
+               requested_at, added_at, removed_at, expires_at, removed_by_iss, removed_by_sub, removal_reason,
+               country_code, asn, as_organization, schedule, extension_count
+        FROM firewall_ip_whitelist_entries
+        WHERE alias_uuid = $1
+          AND status IN ('requested', 'added')
+        ORDER BY requested_at ASC
+    `
+
+	rows, err := p.pool.Query(ctx, query, aliasUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alias sync entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.FirewallIPWhitelistEntry
+	for rows.Next() {
+		var entry models.FirewallIPWhitelistEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OwnerIss,
+			&entry.OwnerSub,
+			&entry.AliasName,
+			&entry.AliasUUID,
+			&entry.IPAddress,
+			&entry.IPVersion,
+			&entry.Description,
+			&entry.Status,
+			&entry.RequestedAt,
+			&entry.AddedAt,
+			&entry.RemovedAt,
+			&entry.ExpiresAt,
+			&entry.RemovedByIss,
+			&entry.RemovedBySub,
+			&entry.RemovalReason,
+			&entry.CountryCode,
+			&entry.ASN,
+			&entry.ASOrganization,
+			&entry.Schedule,
+			&entry.ExtensionCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alias sync entry: %w", err)
+		}
+		entry.Events = []models.FirewallIPWhitelistEvent{}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate alias sync entries: %w", err)
+	}
+
+	return entries, nil
+}
+
 // MarkIPsAsAdded marks IPs as successfully added to the firewall
 func (p *DatabaseProvider) MarkIPsAsAdded(ctx context.Context, ids []int, systemUserIss, systemUserSub string) error {
 	query := `
@@ -622,12 +850,121 @@ func (p *DatabaseProvider) MarkIPsAsAdded(ctx context.Context, ids []int, system
 	return nil
 }
 
+// GetScheduledWhitelistEntries returns active, unexpired entries that have an access schedule
+func (p *DatabaseProvider) GetScheduledWhitelistEntries(ctx context.Context) ([]*models.FirewallIPWhitelistEntry, error) {
+	query := `
+        SELECT id, owner_iss, owner_sub, alias_name, alias_uuid, ip_address::text, ip_version, description, status,
+               requested_at, added_at, removed_at, expires_at, removed_by_iss, removed_by_sub, removal_reason,
+               country_code, asn, as_organization, schedule, extension_count
+        FROM firewall_ip_whitelist_entries
+        WHERE schedule IS NOT NULL
+          AND status IN ('requested', 'added', 'scheduled')
+          AND (expires_at IS NULL OR expires_at > NOW())
+        ORDER BY id ASC
+    `
+
+	rows, err := p.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.FirewallIPWhitelistEntry
+	for rows.Next() {
+		var entry models.FirewallIPWhitelistEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OwnerIss,
+			&entry.OwnerSub,
+			&entry.AliasName,
+			&entry.AliasUUID,
+			&entry.IPAddress,
+			&entry.IPVersion,
+			&entry.Description,
+			&entry.Status,
+			&entry.RequestedAt,
+			&entry.AddedAt,
+			&entry.RemovedAt,
+			&entry.ExpiresAt,
+			&entry.RemovedByIss,
+			&entry.RemovedBySub,
+			&entry.RemovalReason,
+			&entry.CountryCode,
+			&entry.ASN,
+			&entry.ASOrganization,
+			&entry.Schedule,
+			&entry.ExtensionCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled entry: %w", err)
+		}
+		entry.Events = []models.FirewallIPWhitelistEvent{}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate scheduled entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// SetWhitelistEntryWindow moves a scheduled entry in or out of its access window. Opening re-queues the entry
+// as 'requested' so the sync job adds it; closing parks it as 'scheduled' so the sync job removes it.
+// Returns false, without recording an event, when the entry was already on that side of the window.
+func (p *DatabaseProvider) SetWhitelistEntryWindow(ctx context.Context, id int, open bool, systemUserIss, systemUserSub string) (bool, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updateQuery := `
+        UPDATE firewall_ip_whitelist_entries
+        SET status = 'scheduled'
+        WHERE id = $1 AND schedule IS NOT NULL AND status IN ('requested', 'added')
+    `
+	eventType := "window_closed"
+	if open {
+		updateQuery = `
+            UPDATE firewall_ip_whitelist_entries
+            SET status = 'requested'
+            WHERE id = $1 AND status = 'scheduled'
+        `
+		eventType = "window_opened"
+	}
+
+	result, err := tx.Exec(ctx, updateQuery, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update access window: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	eventQuery := `
+		INSERT INTO firewall_whitelist_events (whitelist_id, actor_iss, actor_sub, event_type, notes, client_ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = tx.Exec(ctx, eventQuery, id, systemUserIss, systemUserSub, eventType, nil, nil, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create %s event: %w", eventType, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
 // ExpireOldIPs marks expired IPs as removed and returns the count of expired IPs
 func (p *DatabaseProvider) ExpireOldIPs(ctx context.Context, systemUserIss, systemUserSub string) (int, error) {
 	selectQuery := `
         SELECT id
         FROM firewall_ip_whitelist_entries
-        WHERE status IN ('requested', 'added')
+        WHERE status IN ('requested', 'added', 'scheduled')
           AND expires_at IS NOT NULL
           AND expires_at <= NOW()
     `
@@ -682,7 +1019,7 @@ func (p *DatabaseProvider) CountUserActiveIPs(ctx context.Context, ownerIss, own
         SELECT COUNT(*) 
         FROM firewall_ip_whitelist_entries
         WHERE owner_iss = $1 AND owner_sub = $2 AND alias_uuid = $3 
-          AND status IN ('requested', 'added')
+          AND status IN ('requested', 'added', 'scheduled')
     `
 
 	var count int
@@ -700,7 +1037,7 @@ func (p *DatabaseProvider) CountTotalActiveIPs(ctx context.Context, aliasUUID st
         SELECT COUNT(*) 
         FROM firewall_ip_whitelist_entries
         WHERE alias_uuid = $1 
-          AND status IN ('requested', 'added')
+          AND status IN ('requested', 'added', 'scheduled')
     `
 
 	var count int
@@ -711,3 +1048,21 @@ func (p *DatabaseProvider) CountTotalActiveIPs(ctx context.Context, aliasUUID st
 
 	return count, nil
 }
+
+// CountUserDistinctNetworks counts the distinct ASNs and countries of the entries a user requested within the last window,
+// across every alias. Entries without enrichment are not counted.
+func (p *DatabaseProvider) CountUserDistinctNetworks(ctx context.Context, ownerIss, ownerSub string, window time.Duration) (int, int, error) {
+	query := `
+        SELECT COUNT(DISTINCT asn), COUNT(DISTINCT country_code)
+        FROM firewall_ip_whitelist_entries
+        WHERE owner_iss = $1 AND owner_sub = $2 AND requested_at >= NOW() - $3 * INTERVAL '1 second'
+    `
+
+	var asns, countries int
+	err := p.pool.QueryRow(ctx, query, ownerIss, ownerSub, int64(window.Seconds())).Scan(&asns, &countries)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count user distinct networks: %w", err)
+	}
+
+	return asns, countries, nil
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrManagedAliasExists is returned when an alias is already enabled for the same auth group.
+var ErrManagedAliasExists = errors.New("alias is already managed for this auth group")
+
+const managedAliasColumns = `
+	id, alias_uuid::text, name, description, max_ips_per_user, max_total_ips, default_ttl_seconds, auth_group, dry_run,
+	max_lifetime_seconds, max_extensions, allowed_countries, denied_countries, allowed_asns, denied_asns,
+	traefik_namespace, traefik_name, created_by_iss, created_by_sub, created_at, updated_at
+`
+
+// GetManagedAliases lists every alias enabled through the admin API, ordered by name.
+func (p *DatabaseProvider) GetManagedAliases(ctx context.Context) ([]*models.FirewallManagedAlias, error) {
+	query := `SELECT ` + managedAliasColumns + ` FROM firewall_managed_aliases ORDER BY name, auth_group`
+
+	rows, err := p.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []*models.FirewallManagedAlias
+	for rows.Next() {
+		alias, err := scanManagedAlias(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan managed alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate managed aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// GetManagedAliasByID returns a single managed alias.
+func (p *DatabaseProvider) GetManagedAliasByID(ctx context.Context, id int) (*models.FirewallManagedAlias, error) {
+	query := `SELECT ` + managedAliasColumns + ` FROM firewall_managed_aliases WHERE id = $1`
+
+	alias, err := scanManagedAlias(p.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("managed alias not found")
+		}
+		return nil, fmt.Errorf("failed to get managed alias: %w", err)
+	}
+
+	return alias, nil
+}
+
+const insertManagedAliasQuery = `
+	INSERT INTO firewall_managed_aliases (
+		alias_uuid, name, description, max_ips_per_user, max_total_ips, default_ttl_seconds, auth_group, dry_run,
+		max_lifetime_seconds, max_extensions, allowed_countries, denied_countries, allowed_asns, denied_asns,
+		traefik_namespace, traefik_name, created_by_iss, created_by_sub
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+`
+
+func managedAliasInsertArgs(alias *models.FirewallManagedAlias) []any {
+	return []any{
+		alias.UUID,
+		alias.Name,
+		alias.Description,
+		alias.MaxIPsPerUser,
+		alias.MaxTotalIPs,
+		alias.DefaultTTLSeconds,
+		alias.AuthGroup,
+		alias.DryRun,
+		alias.MaxLifetimeSeconds,
+		alias.MaxExtensions,
+		nonNilStrings(alias.AllowedCountries),
+		nonNilStrings(alias.DeniedCountries),
+		nonNilInt64s(alias.AllowedASNs),
+		nonNilInt64s(alias.DeniedASNs),
+		alias.TraefikNamespace,
+		alias.TraefikName,
+		alias.CreatedByIss,
+		alias.CreatedBySub,
+	}
+}
+
+// CreateManagedAlias enables a router alias for an auth group.
+func (p *DatabaseProvider) CreateManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) (*models.FirewallManagedAlias, error) {
+	var id int
+	err := p.pool.QueryRow(ctx, insertManagedAliasQuery+` RETURNING id`, managedAliasInsertArgs(alias)...).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrManagedAliasExists
+		}
+		return nil, fmt.Errorf("failed to create managed alias: %w", err)
+	}
+
+	return p.GetManagedAliasByID(ctx, id)
+}
+
+// ImportManagedAlias stores an alias declared in the config file, unless the same uuid and auth group was
+// imported before or is already managed. It reports whether the alias was created.
+func (p *DatabaseProvider) ImportManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) (bool, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO firewall_alias_imports (alias_uuid, auth_group)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, alias.UUID, alias.AuthGroup)
+	if err != nil {
+		return false, fmt.Errorf("failed to record alias import: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	// An alias enabled through the admin API before its first import keeps the admin's settings
+	result, err = tx.Exec(ctx, insertManagedAliasQuery+` ON CONFLICT (alias_uuid, auth_group) DO NOTHING`, managedAliasInsertArgs(alias)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to import managed alias: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// UpdateManagedAlias replaces the limits and policies of a managed alias. The alias uuid and its Traefik
+// middleware cannot change.
+func (p *DatabaseProvider) UpdateManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) error {
+	query := `
+		UPDATE firewall_managed_aliases
+		SET name = $2,
+		    description = $3,
+		    max_ips_per_user = $4,
+		    max_total_ips = $5,
+		    default_ttl_seconds = $6,
+		    auth_group = $7,
+		    dry_run = $8,
+		    max_lifetime_seconds = $9,
+		    max_extensions = $10,
+		    allowed_countries = $11,
+		    denied_countries = $12,
+		    allowed_asns = $13,
+		    denied_asns = $14,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := p.pool.Exec(ctx, query,
+		alias.ID,
+		alias.Name,
+		alias.Description,
+		alias.MaxIPsPerUser,
+		alias.MaxTotalIPs,
+		alias.DefaultTTLSeconds,
+		alias.AuthGroup,
+		alias.DryRun,
+		alias.MaxLifetimeSeconds,
+		alias.MaxExtensions,
+		nonNilStrings(alias.AllowedCountries),
+		nonNilStrings(alias.DeniedCountries),
+		nonNilInt64s(alias.AllowedASNs),
+		nonNilInt64s(alias.DeniedASNs),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrManagedAliasExists
+		}
+		return fmt.Errorf("failed to update managed alias: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("managed alias not found")
+	}
+
+	return nil
+}
+
+// DeleteManagedAlias stops managing an alias. Its whitelist entries are left untouched, see RemoveAliasEntries.
+func (p *DatabaseProvider) DeleteManagedAlias(ctx context.Context, id int) error {
+	result, err := p.pool.Exec(ctx, `DELETE FROM firewall_managed_aliases WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete managed alias: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("managed alias not found")
+	}
+
+	return nil
+}
+
+func scanManagedAlias(row pgx.Row) (*models.FirewallManagedAlias, error) {
+	var alias models.FirewallManagedAlias
+	err := row.Scan(
+		&alias.ID,
+		&alias.UUID,
+		&alias.Name,
+		&alias.Description,
+		&alias.MaxIPsPerUser,
+		&alias.MaxTotalIPs,
+		&alias.DefaultTTLSeconds,
+		&alias.AuthGroup,
+		&alias.DryRun,
+		&alias.MaxLifetimeSeconds,
+		&alias.MaxExtensions,
+		&alias.AllowedCountries,
+		&alias.DeniedCountries,
+		&alias.AllowedASNs,
+		&alias.DeniedASNs,
+		&alias.TraefikNamespace,
+		&alias.TraefikName,
+		&alias.CreatedByIss,
+		&alias.CreatedBySub,
+		&alias.CreatedAt,
+		&alias.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func nonNilInt64s(values []int64) []int64 {
+	if values == nil {
+		return []int64{}
+	}
+	return values
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// firewallSyncChannel is the Postgres NOTIFY channel carrying alias uuids that need syncing now.
+const firewallSyncChannel = "firewall_sync"
+
+// NotifyFirewallSync asks whichever replica is leading to sync an alias without waiting for the next interval.
+func (p *DatabaseProvider) NotifyFirewallSync(ctx context.Context, aliasUUID string) error {
+	if _, err := p.pool.Exec(ctx, `SELECT pg_notify($1, $2)`, firewallSyncChannel, aliasUUID); err != nil {
+		return fmt.Errorf("failed to notify firewall sync: %w", err)
+	}
+	return nil
+}
+
+// ListenFirewallSync calls handler with the alias uuid of every sync request until ctx is cancelled or
+// the connection is lost. It holds a dedicated connection from the pool while listening.
+func (p *DatabaseProvider) ListenFirewallSync(ctx context.Context, handler func(aliasUUID string)) error {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	// The session keeps LISTEN active, so close it rather than hand it back to the pool.
+	defer conn.Conn().Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+firewallSyncChannel); err != nil {
+		return fmt.Errorf("failed to listen for firewall sync requests: %w", err)
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to wait for firewall sync request: %w", err)
+		}
+		handler(notification.Payload)
+	}
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"homelab-dashboard/internal/models"
+)
+
+// CreateFirewallSyncPlan records a plan computed by the firewall sync job.
+func (p *DatabaseProvider) CreateFirewallSyncPlan(ctx context.Context, plan *models.FirewallSyncPlan) (*models.FirewallSyncPlan, error) {
+	query := `
+		INSERT INTO firewall_sync_plans (alias_name, alias_uuid, dry_run, ips_to_add, ips_to_remove, pending_entry_ids, status, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := p.pool.QueryRow(ctx, query,
+		plan.AliasName,
+		plan.AliasUUID,
+		plan.DryRun,
+		plan.IPsToAdd,
+		plan.IPsToRemove,
+		plan.PendingEntryIDs,
+		string(plan.Status),
+		plan.Error,
+	).Scan(&plan.ID, &plan.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firewall sync plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// GetFirewallSyncPlans returns the most recent sync plans for an alias, newest first.
+func (p *DatabaseProvider) GetFirewallSyncPlans(ctx context.Context, aliasUUID string, limit int) ([]*models.FirewallSyncPlan, error) {
+	query := `
+		SELECT id, alias_name, alias_uuid, dry_run, ips_to_add, ips_to_remove, pending_entry_ids, status, error, created_at
+		FROM firewall_sync_plans
+		WHERE alias_uuid = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := p.pool.Query(ctx, query, aliasUUID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get firewall sync plans: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []*models.FirewallSyncPlan
+	for rows.Next() {
+		var plan models.FirewallSyncPlan
+		err := rows.Scan(
+			&plan.ID,
+			&plan.AliasName,
+			&plan.AliasUUID,
+			&plan.DryRun,
+			&plan.IPsToAdd,
+			&plan.IPsToRemove,
+			&plan.PendingEntryIDs,
+			&plan.Status,
+			&plan.Error,
+			&plan.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan firewall sync plan: %w", err)
+		}
+		plans = append(plans, &plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate firewall sync plans: %w", err)
+	}
+
+	return plans, nil
+}
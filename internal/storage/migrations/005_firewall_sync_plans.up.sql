@@ -0,0 +1,21 @@
+CREATE TABLE firewall_sync_plans (
+    id SERIAL PRIMARY KEY,
+
+    alias_name TEXT NOT NULL,
+    alias_uuid UUID NOT NULL,
+
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+
+    ips_to_add TEXT[] NOT NULL DEFAULT '{}',
+    ips_to_remove TEXT[] NOT NULL DEFAULT '{}',
+    pending_entry_ids INTEGER[] NOT NULL DEFAULT '{}',
+
+    status TEXT NOT NULL DEFAULT 'planned',
+    error TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_sync_plan_status CHECK (status IN ('planned', 'applied', 'failed'))
+);
+
+CREATE INDEX idx_sync_plans_alias_created ON firewall_sync_plans(alias_uuid, created_at DESC);
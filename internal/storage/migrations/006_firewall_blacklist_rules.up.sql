@@ -0,0 +1,55 @@
+CREATE TABLE firewall_blacklist_rules (
+    id SERIAL PRIMARY KEY,
+
+    -- NULL applies the rule to every alias
+    alias_uuid UUID,
+
+    -- Normalized notation the rule was created from (IP, CIDR, start-end range or ASN)
+    value TEXT NOT NULL,
+
+    range_start INET,
+    range_end INET,
+    asn BIGINT,
+
+    source TEXT NOT NULL DEFAULT 'manual',
+    feed_name TEXT,
+    reason TEXT,
+
+    created_by_iss TEXT NOT NULL,
+    created_by_sub TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP,
+
+    removed_at TIMESTAMP,
+    removed_by_iss TEXT,
+    removed_by_sub TEXT,
+
+    CONSTRAINT blacklist_creator_not_empty CHECK (created_by_iss != '' AND created_by_sub != ''),
+    CONSTRAINT blacklist_remover_not_empty CHECK (
+        (removed_by_iss IS NULL AND removed_by_sub IS NULL) OR
+        (removed_by_iss != '' AND removed_by_sub != '')
+    ),
+    CONSTRAINT valid_blacklist_target CHECK (
+        (range_start IS NOT NULL AND range_end IS NOT NULL AND asn IS NULL
+            AND family(range_start) = family(range_end) AND range_start <= range_end) OR
+        (range_start IS NULL AND range_end IS NULL AND asn IS NOT NULL)
+    ),
+    CONSTRAINT valid_blacklist_source CHECK (source IN ('manual', 'entry', 'feed')),
+    CONSTRAINT valid_blacklist_feed_name CHECK ((source = 'feed') = (feed_name IS NOT NULL)),
+    CONSTRAINT valid_blacklist_expiration CHECK (expires_at IS NULL OR expires_at > created_at)
+);
+
+CREATE INDEX idx_blacklist_active_ranges ON firewall_blacklist_rules(range_start, range_end) WHERE removed_at IS NULL AND range_start IS NOT NULL;
+CREATE INDEX idx_blacklist_active_asns ON firewall_blacklist_rules(asn) WHERE removed_at IS NULL AND asn IS NOT NULL;
+CREATE INDEX idx_blacklist_alias_uuid ON firewall_blacklist_rules(alias_uuid);
+CREATE INDEX idx_blacklist_feed ON firewall_blacklist_rules(feed_name, value) WHERE source = 'feed';
+
+-- Carry over IPs blacklisted through whitelist entries so the rules table is the single source of truth
+INSERT INTO firewall_blacklist_rules (alias_uuid, value, range_start, range_end, source, reason, created_by_iss, created_by_sub, created_at)
+SELECT DISTINCT ON (alias_uuid, ip_address)
+    alias_uuid, host(ip_address), ip_address, ip_address, 'entry', removal_reason, removed_by_iss, removed_by_sub, COALESCE(removed_at, NOW())
+FROM firewall_ip_whitelist_entries
+WHERE status = 'blacklisted_by_admin'
+  AND removed_by_iss IS NOT NULL
+  AND removed_by_sub IS NOT NULL
+ORDER BY alias_uuid, ip_address, removed_at DESC;
@@ -0,0 +1,12 @@
+ALTER TABLE firewall_ip_whitelist_entries
+ADD COLUMN country_code TEXT,
+ADD COLUMN asn BIGINT,
+ADD COLUMN as_organization TEXT;
+
+ALTER TABLE firewall_whitelist_events
+ADD COLUMN client_country_code TEXT,
+ADD COLUMN client_asn BIGINT,
+ADD COLUMN client_as_organization TEXT;
+
+CREATE INDEX idx_whitelist_country ON firewall_ip_whitelist_entries(country_code);
+CREATE INDEX idx_whitelist_asn ON firewall_ip_whitelist_entries(asn);
@@ -0,0 +1,30 @@
+ALTER TABLE firewall_ip_whitelist_entries
+ADD COLUMN schedule JSONB;
+
+-- 'scheduled' entries are active but outside their access window, so they are kept off the router
+ALTER TABLE firewall_ip_whitelist_entries
+DROP CONSTRAINT valid_status;
+
+ALTER TABLE firewall_ip_whitelist_entries
+ADD CONSTRAINT valid_status CHECK (status IN ('requested', 'added', 'scheduled', 'removed', 'removed_by_admin', 'blacklisted_by_admin'));
+
+ALTER TABLE firewall_ip_whitelist_entries
+ADD CONSTRAINT schedule_required_when_scheduled CHECK (status != 'scheduled' OR schedule IS NOT NULL);
+
+DROP INDEX idx_whitelist_duplicate_ips;
+CREATE INDEX idx_whitelist_duplicate_ips ON firewall_ip_whitelist_entries(alias_uuid, ip_address, status) WHERE status IN ('requested', 'added', 'scheduled');
+
+DROP INDEX idx_unique_active_ip_per_user;
+CREATE UNIQUE INDEX idx_unique_active_ip_per_user ON firewall_ip_whitelist_entries(alias_uuid, ip_address, owner_iss, owner_sub)
+WHERE status IN ('requested', 'added', 'scheduled');
+
+CREATE INDEX idx_whitelist_scheduled ON firewall_ip_whitelist_entries(status) WHERE schedule IS NOT NULL;
+
+ALTER TABLE firewall_whitelist_events
+DROP CONSTRAINT valid_event_type;
+
+ALTER TABLE firewall_whitelist_events
+ADD CONSTRAINT valid_event_type CHECK (event_type IN (
+    'requested', 'added', 'removed', 'removed_by_admin', 'blacklisted_by_admin', 'expired', 'sync_failed',
+    'window_opened', 'window_closed'
+));
@@ -0,0 +1,31 @@
+-- Router aliases enabled at runtime through the admin API. Aliases declared in the YAML config
+-- are still honoured and take precedence over rows here with the same uuid and auth group.
+CREATE TABLE firewall_managed_aliases (
+    id SERIAL PRIMARY KEY,
+
+    alias_uuid UUID NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+
+    max_ips_per_user INTEGER NOT NULL,
+    max_total_ips INTEGER NOT NULL,
+    default_ttl_seconds BIGINT, -- NULL = no expiration
+    auth_group TEXT NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+
+    allowed_countries TEXT[] NOT NULL DEFAULT '{}',
+    denied_countries TEXT[] NOT NULL DEFAULT '{}',
+    allowed_asns BIGINT[] NOT NULL DEFAULT '{}',
+    denied_asns BIGINT[] NOT NULL DEFAULT '{}',
+
+    created_by_iss TEXT NOT NULL,
+    created_by_sub TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT managed_alias_creator_not_empty CHECK (created_by_iss != '' AND created_by_sub != ''),
+    CONSTRAINT managed_alias_name_not_empty CHECK (name != '' AND auth_group != ''),
+    CONSTRAINT valid_managed_alias_limits CHECK (max_ips_per_user > 0 AND max_total_ips >= max_ips_per_user),
+    CONSTRAINT valid_managed_alias_ttl CHECK (default_ttl_seconds IS NULL OR default_ttl_seconds >= 3600),
+    CONSTRAINT unique_managed_alias_group UNIQUE (alias_uuid, auth_group)
+);
@@ -0,0 +1,22 @@
+ALTER TABLE firewall_ip_whitelist_entries
+ADD COLUMN extension_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE firewall_ip_whitelist_entries
+ADD CONSTRAINT valid_extension_count CHECK (extension_count >= 0);
+
+-- Extension limits for aliases enabled through the admin API; NULL lifetime = unlimited
+ALTER TABLE firewall_managed_aliases
+ADD COLUMN max_lifetime_seconds BIGINT,
+ADD COLUMN max_extensions INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE firewall_managed_aliases
+ADD CONSTRAINT valid_managed_alias_extensions CHECK (max_extensions >= 0 AND (max_lifetime_seconds IS NULL OR max_lifetime_seconds >= 3600));
+
+ALTER TABLE firewall_whitelist_events
+DROP CONSTRAINT valid_event_type;
+
+ALTER TABLE firewall_whitelist_events
+ADD CONSTRAINT valid_event_type CHECK (event_type IN (
+    'requested', 'added', 'removed', 'removed_by_admin', 'blacklisted_by_admin', 'expired', 'sync_failed',
+    'window_opened', 'window_closed', 'extended'
+));
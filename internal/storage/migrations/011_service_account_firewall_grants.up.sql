@@ -0,0 +1,11 @@
+-- Aliases a service account may manage entries in. Each grant stands in for membership of the alias's
+-- auth group, limited to that one alias, and is only honoured while the creator is still in the group.
+CREATE TABLE service_account_firewall_grants(
+    owner_iss TEXT NOT NULL,
+    owner_sub TEXT NOT NULL,
+    alias_uuid TEXT NOT NULL,
+    auth_group TEXT NOT NULL,
+
+    PRIMARY KEY (owner_iss, owner_sub, alias_uuid, auth_group),
+    FOREIGN KEY (owner_iss, owner_sub) REFERENCES service_accounts(iss, sub) ON DELETE CASCADE
+);
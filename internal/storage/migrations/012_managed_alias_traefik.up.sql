@@ -0,0 +1,4 @@
+-- Managed aliases can be applied to a Traefik Middleware's ipAllowList instead of an OPNsense alias.
+ALTER TABLE firewall_managed_aliases
+ADD COLUMN traefik_namespace TEXT NOT NULL DEFAULT '',
+ADD COLUMN traefik_name TEXT NOT NULL DEFAULT '';
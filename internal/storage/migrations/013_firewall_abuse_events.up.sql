@@ -0,0 +1,11 @@
+ALTER TABLE firewall_whitelist_events
+DROP CONSTRAINT valid_event_type;
+
+ALTER TABLE firewall_whitelist_events
+ADD CONSTRAINT valid_event_type CHECK (event_type IN (
+    'requested', 'added', 'removed', 'removed_by_admin', 'blacklisted_by_admin', 'expired', 'sync_failed',
+    'window_opened', 'window_closed', 'extended', 'abuse_suspected'
+));
+
+-- Speeds up the per-user network rotation check
+CREATE INDEX idx_whitelist_owner_requested ON firewall_ip_whitelist_entries(owner_iss, owner_sub, requested_at);
@@ -0,0 +1,2 @@
+-- Supports the audit log search's client IP / CIDR filter (client_ip <<= network)
+CREATE INDEX idx_whitelist_events_client_ip ON firewall_whitelist_events USING gist (client_ip inet_ops);
@@ -0,0 +1,18 @@
+-- Rollups of dashboard query results, kept after the TSDB's retention has rolled over
+CREATE TABLE data_query_history (
+    query_name TEXT NOT NULL,
+    variables TEXT NOT NULL DEFAULT '',
+    resolution TEXT NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    series TEXT NOT NULL,
+    labels JSONB NOT NULL DEFAULT '{}',
+    sample_count INTEGER NOT NULL,
+    sum DOUBLE PRECISION NOT NULL,
+    min DOUBLE PRECISION NOT NULL,
+    max DOUBLE PRECISION NOT NULL,
+    last DOUBLE PRECISION NOT NULL,
+    last_recorded_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (query_name, variables, resolution, bucket, series),
+    CONSTRAINT valid_resolution CHECK (resolution IN ('5m', '1h', '1d'))
+);
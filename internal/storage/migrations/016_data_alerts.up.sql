@@ -0,0 +1,22 @@
+CREATE TABLE data_alerts (
+    id SERIAL PRIMARY KEY,
+
+    rule_name TEXT NOT NULL,
+    query_key TEXT NOT NULL,
+    series TEXT NOT NULL,
+    labels JSONB NOT NULL DEFAULT '{}',
+    severity TEXT NOT NULL,
+
+    state TEXT NOT NULL DEFAULT 'pending',
+    value DOUBLE PRECISION NOT NULL,
+
+    active_since TIMESTAMPTZ NOT NULL,
+    fired_at TIMESTAMPTZ,
+    resolved_at TIMESTAMPTZ,
+    last_evaluated_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT valid_alert_state CHECK (state IN ('pending', 'firing', 'resolved'))
+);
+
+-- A rule has at most one active alert per series; resolved alerts are kept as history
+CREATE UNIQUE INDEX idx_data_alerts_active ON data_alerts(rule_name, query_key, series) WHERE state <> 'resolved';
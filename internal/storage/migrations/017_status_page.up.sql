@@ -0,0 +1,44 @@
+-- Incidents and maintenance windows of the public status page
+CREATE TABLE status_incidents (
+    id SERIAL PRIMARY KEY,
+
+    kind TEXT NOT NULL,
+    title TEXT NOT NULL,
+    impact TEXT NOT NULL DEFAULT 'none',
+    state TEXT NOT NULL,
+    components TEXT[] NOT NULL DEFAULT '{}',
+
+    scheduled_start TIMESTAMPTZ,
+    scheduled_end TIMESTAMPTZ,
+
+    created_by_iss TEXT NOT NULL,
+    created_by_sub TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+
+    CONSTRAINT valid_incident_kind CHECK (kind IN ('incident', 'maintenance')),
+    CONSTRAINT valid_incident_impact CHECK (impact IN ('none', 'minor', 'major', 'critical')),
+    CONSTRAINT valid_incident_state CHECK (
+        (kind = 'incident' AND state IN ('investigating', 'identified', 'monitoring', 'resolved')) OR
+        (kind = 'maintenance' AND state IN ('scheduled', 'in_progress', 'completed'))
+    ),
+    CONSTRAINT valid_maintenance_window CHECK (
+        kind <> 'maintenance' OR (scheduled_start IS NOT NULL AND scheduled_end > scheduled_start)
+    )
+);
+
+CREATE INDEX idx_status_incidents_open ON status_incidents(created_at) WHERE resolved_at IS NULL;
+CREATE INDEX idx_status_incidents_resolved_at ON status_incidents(resolved_at);
+
+CREATE TABLE status_incident_updates (
+    id SERIAL PRIMARY KEY,
+    incident_id INTEGER NOT NULL REFERENCES status_incidents(id) ON DELETE CASCADE,
+    state TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_by_iss TEXT NOT NULL,
+    created_by_sub TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_status_incident_updates_incident ON status_incident_updates(incident_id, created_at);
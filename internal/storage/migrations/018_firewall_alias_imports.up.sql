@@ -0,0 +1,13 @@
+-- Config file aliases already copied into firewall_managed_aliases. Each uuid and auth group is imported once,
+-- so an alias deleted through the admin API does not come back on the next start.
+CREATE TABLE firewall_alias_imports (
+    alias_uuid UUID NOT NULL,
+    auth_group TEXT NOT NULL,
+    imported_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (alias_uuid, auth_group)
+);
+
+-- Aliases stored before imports were recorded may have come from the config file
+INSERT INTO firewall_alias_imports (alias_uuid, auth_group)
+SELECT alias_uuid, auth_group FROM firewall_managed_aliases;
@@ -40,6 +40,17 @@ func (p *DatabaseProvider) CreateServiceAccount(ctx context.Context, serviceAcco
 		}
 	}
 
+	grantsQuery := `
+        INSERT INTO service_account_firewall_grants (owner_sub, owner_iss, alias_uuid, auth_group)
+        VALUES ($1, $2, $3, $4)
+    `
+	for _, grant := range serviceAccount.FirewallGrants {
+		_, err := tx.Exec(ctx, grantsQuery, serviceAccount.Sub, serviceAccount.Iss, grant.AliasUUID, grant.AuthGroup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert firewall grant %s/%s: %w", grant.AliasUUID, grant.AuthGroup, err)
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -106,6 +117,15 @@ func (p *DatabaseProvider) GetServiceAccountByID(ctx context.Context, iss, sub s
 		serviceAccount.Scopes = []string{}
 	}
 
+	grants, err := p.getServiceAccountFirewallGrants(ctx, []string{serviceAccount.Iss}, []string{serviceAccount.Sub})
+	if err != nil {
+		return nil, err
+	}
+	serviceAccount.FirewallGrants = grants[serviceAccount.Iss+":"+serviceAccount.Sub]
+	if serviceAccount.FirewallGrants == nil {
+		serviceAccount.FirewallGrants = []models.FirewallAliasGrant{}
+	}
+
 	return &serviceAccount, nil
 }
 
@@ -167,6 +187,15 @@ func (p *DatabaseProvider) GetServiceAccountByLookupId(ctx context.Context, look
 		serviceAccount.Scopes = []string{}
 	}
 
+	grants, err := p.getServiceAccountFirewallGrants(ctx, []string{serviceAccount.Iss}, []string{serviceAccount.Sub})
+	if err != nil {
+		return nil, err
+	}
+	serviceAccount.FirewallGrants = grants[serviceAccount.Iss+":"+serviceAccount.Sub]
+	if serviceAccount.FirewallGrants == nil {
+		serviceAccount.FirewallGrants = []models.FirewallAliasGrant{}
+	}
+
 	return &serviceAccount, nil
 }
 
@@ -211,6 +240,7 @@ func (p *DatabaseProvider) GetServiceAccountsByCreator(ctx context.Context, iss,
 			return nil, fmt.Errorf("failed to scan service account: %w", err)
 		}
 		serviceAccount.Scopes = []string{}
+		serviceAccount.FirewallGrants = []models.FirewallAliasGrant{}
 		serviceAccounts = append(serviceAccounts, &serviceAccount)
 		issValues = append(issValues, serviceAccount.Iss)
 		subValues = append(subValues, serviceAccount.Sub)
@@ -252,9 +282,58 @@ func (p *DatabaseProvider) GetServiceAccountsByCreator(ctx context.Context, iss,
 		return nil, fmt.Errorf("failed to iterate scopes: %w", err)
 	}
 
+	grants, err := p.getServiceAccountFirewallGrants(ctx, issValues, subValues)
+	if err != nil {
+		return nil, err
+	}
+	for key, accountGrants := range grants {
+		if sa, ok := saMap[key]; ok {
+			sa.FirewallGrants = accountGrants
+		}
+	}
+
 	return serviceAccounts, nil
 }
 
+// getServiceAccountFirewallGrants returns the firewall grants of the given accounts keyed by "iss:sub".
+// Grants for an auth group the creating user has since left are dropped, so an account never has more
+// firewall access than its creator had at their last login.
+func (p *DatabaseProvider) getServiceAccountFirewallGrants(ctx context.Context, issValues, subValues []string) (map[string][]models.FirewallAliasGrant, error) {
+	query := `
+       SELECT g.owner_iss, g.owner_sub, g.alias_uuid, g.auth_group
+       FROM service_account_firewall_grants g
+       JOIN service_accounts sa ON sa.iss = g.owner_iss AND sa.sub = g.owner_sub
+       JOIN user_groups ug ON ug.owner_iss = sa.created_by_iss
+           AND ug.owner_sub = sa.created_by_sub
+           AND ug.group_name = g.auth_group
+       WHERE (g.owner_iss, g.owner_sub) IN (SELECT UNNEST($1::text[]), UNNEST($2::text[]))
+       ORDER BY g.owner_iss, g.owner_sub, g.alias_uuid, g.auth_group`
+
+	rows, err := p.pool.Query(ctx, query, issValues, subValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get firewall grants for service accounts: %w", err)
+	}
+	defer rows.Close()
+
+	grants := make(map[string][]models.FirewallAliasGrant)
+	for rows.Next() {
+		var ownerIss, ownerSub string
+		var grant models.FirewallAliasGrant
+		if err := rows.Scan(&ownerIss, &ownerSub, &grant.AliasUUID, &grant.AuthGroup); err != nil {
+			return nil, fmt.Errorf("failed to scan firewall grant: %w", err)
+		}
+
+		key := ownerIss + ":" + ownerSub
+		grants[key] = append(grants[key], grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate firewall grants: %w", err)
+	}
+
+	return grants, nil
+}
+
 func (p *DatabaseProvider) PauseServiceAccount(ctx context.Context, iss, sub string) error {
 	query := `
        UPDATE service_accounts
@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homelab-dashboard/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const statusIncidentColumns = `
+	id, kind, title, impact, state, components, scheduled_start, scheduled_end,
+	created_by_iss, created_by_sub, created_at, updated_at, resolved_at
+`
+
+// CreateStatusIncident inserts an incident or maintenance window together with its first update.
+func (p *DatabaseProvider) CreateStatusIncident(ctx context.Context, incident *models.StatusIncident, message string) (*models.StatusIncident, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO status_incidents (kind, title, impact, state, components, scheduled_start, scheduled_end, created_by_iss, created_by_sub, resolved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $10 THEN NOW() END)
+		RETURNING id
+	`
+
+	var id int
+	err = tx.QueryRow(ctx, query,
+		string(incident.Kind),
+		incident.Title,
+		string(incident.Impact),
+		string(incident.State),
+		incident.Components,
+		incident.ScheduledStart,
+		incident.ScheduledEnd,
+		incident.CreatedByIss,
+		incident.CreatedBySub,
+		incident.State.IsClosed(),
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status incident: %w", err)
+	}
+
+	updateQuery := `
+		INSERT INTO status_incident_updates (incident_id, state, message, created_by_iss, created_by_sub)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err = tx.Exec(ctx, updateQuery, id, string(incident.State), message, incident.CreatedByIss, incident.CreatedBySub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status incident update: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit status incident: %w", err)
+	}
+
+	return p.GetStatusIncident(ctx, id)
+}
+
+// AddStatusIncidentUpdate posts an update to an incident and moves the incident to the update's state.
+// Moving to resolved or completed stamps resolved_at, reopening clears it.
+func (p *DatabaseProvider) AddStatusIncidentUpdate(ctx context.Context, incidentID int, update *models.StatusIncidentUpdate) (*models.StatusIncident, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE status_incidents
+		SET state = $2,
+		    updated_at = NOW(),
+		    resolved_at = CASE WHEN $3 THEN COALESCE(resolved_at, NOW()) END
+		WHERE id = $1
+	`
+
+	result, err := tx.Exec(ctx, query, incidentID, string(update.State), update.State.IsClosed())
+	if err != nil {
+		return nil, fmt.Errorf("failed to update status incident state: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return nil, fmt.Errorf("status incident not found")
+	}
+
+	updateQuery := `
+		INSERT INTO status_incident_updates (incident_id, state, message, created_by_iss, created_by_sub)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err = tx.Exec(ctx, updateQuery, incidentID, string(update.State), update.Message, update.CreatedByIss, update.CreatedBySub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status incident update: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit status incident update: %w", err)
+	}
+
+	return p.GetStatusIncident(ctx, incidentID)
+}
+
+// GetStatusIncident returns a single incident with its updates, newest first.
+func (p *DatabaseProvider) GetStatusIncident(ctx context.Context, id int) (*models.StatusIncident, error) {
+	query := `SELECT ` + statusIncidentColumns + ` FROM status_incidents WHERE id = $1`
+
+	incident, err := scanStatusIncident(p.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("status incident not found")
+		}
+		return nil, fmt.Errorf("failed to get status incident: %w", err)
+	}
+
+	if err := p.attachStatusIncidentUpdates(ctx, []*models.StatusIncident{incident}); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// GetStatusIncidents returns every open incident and maintenance window, plus those closed since the given
+// time, newest first and with their updates.
+func (p *DatabaseProvider) GetStatusIncidents(ctx context.Context, since time.Time) ([]*models.StatusIncident, error) {
+	query := `
+		SELECT ` + statusIncidentColumns + `
+		FROM status_incidents
+		WHERE resolved_at IS NULL OR resolved_at >= $1
+		ORDER BY COALESCE(scheduled_start, created_at) DESC, id DESC
+	`
+
+	rows, err := p.pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []*models.StatusIncident
+	for rows.Next() {
+		incident, err := scanStatusIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status incident: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate status incidents: %w", err)
+	}
+
+	if err := p.attachStatusIncidentUpdates(ctx, incidents); err != nil {
+		return nil, err
+	}
+
+	return incidents, nil
+}
+
+// UpdateStatusIncident changes the title, impact, components and schedule of an incident. The state only
+// moves through AddStatusIncidentUpdate so that every change shows up in the timeline.
+func (p *DatabaseProvider) UpdateStatusIncident(ctx context.Context, incident *models.StatusIncident) error {
+	query := `
+		UPDATE status_incidents
+		SET title = $2, impact = $3, components = $4, scheduled_start = $5, scheduled_end = $6, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := p.pool.Exec(ctx, query,
+		incident.ID,
+		incident.Title,
+		string(incident.Impact),
+		incident.Components,
+		incident.ScheduledStart,
+		incident.ScheduledEnd,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update status incident: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("status incident not found")
+	}
+
+	return nil
+}
+
+// DeleteStatusIncident removes an incident and its updates.
+func (p *DatabaseProvider) DeleteStatusIncident(ctx context.Context, id int) error {
+	result, err := p.pool.Exec(ctx, `DELETE FROM status_incidents WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete status incident: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("status incident not found")
+	}
+
+	return nil
+}
+
+// attachStatusIncidentUpdates loads the updates of the given incidents in one query.
+func (p *DatabaseProvider) attachStatusIncidentUpdates(ctx context.Context, incidents []*models.StatusIncident) error {
+	if len(incidents) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.StatusIncident, len(incidents))
+	ids := make([]int, 0, len(incidents))
+	for _, incident := range incidents {
+		incident.Updates = []models.StatusIncidentUpdate{}
+		byID[incident.ID] = incident
+		ids = append(ids, incident.ID)
+	}
+
+	query := `
+		SELECT id, incident_id, state, message, created_by_iss, created_by_sub, created_at
+		FROM status_incident_updates
+		WHERE incident_id = ANY($1)
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := p.pool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get status incident updates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var update models.StatusIncidentUpdate
+		err := rows.Scan(
+			&update.ID,
+			&update.IncidentID,
+			&update.State,
+			&update.Message,
+			&update.CreatedByIss,
+			&update.CreatedBySub,
+			&update.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan status incident update: %w", err)
+		}
+		byID[update.IncidentID].Updates = append(byID[update.IncidentID].Updates, update)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate status incident updates: %w", err)
+	}
+
+	return nil
+}
+
+func scanStatusIncident(row pgx.Row) (*models.StatusIncident, error) {
+	var incident models.StatusIncident
+	err := row.Scan(
+		&incident.ID,
+		&incident.Kind,
+		&incident.Title,
+		&incident.Impact,
+		&incident.State,
+		&incident.Components,
+		&incident.ScheduledStart,
+		&incident.ScheduledEnd,
+		&incident.CreatedByIss,
+		&incident.CreatedBySub,
+		&incident.CreatedAt,
+		&incident.UpdatedAt,
+		&incident.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
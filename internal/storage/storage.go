@@ -54,23 +54,47 @@ type Provider interface {
 
 	/* Firewall Alias Queries */
 
-	AddIPToWhitelist(ctx context.Context, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description string, expiresAt *time.Time, clientIP, userAgent *string) (*models.FirewallIPWhitelistEntry, error)
+	AddIPToWhitelist(ctx context.Context, ownerIss, ownerSub, aliasName, aliasUUID, ipAddress, description string, expiresAt *time.Time, clientIP, userAgent *string, enrichment, clientEnrichment models.IPEnrichment, schedule *models.AccessSchedule) (*models.FirewallIPWhitelistEntry, error)
 	GetAllWhitelistEntries(ctx context.Context) ([]*models.FirewallIPWhitelistEntry, error)
 	GetWhitelistEntryByID(ctx context.Context, id int) (*models.FirewallIPWhitelistEntry, error)
 	GetUserWhitelistEntries(ctx context.Context, ownerIss, ownerSub string) ([]*models.FirewallIPWhitelistEntry, error)
 	RemoveIPFromWhitelist(ctx context.Context, id int, ownerIss, ownerSub string, clientIP, userAgent *string) error
+	ExtendWhitelistEntry(ctx context.Context, id, previousCount int, expiresAt time.Time, actorIss, actorSub, notes string, clientIP, userAgent *string) (bool, error)
 
 	BlacklistIP(ctx context.Context, id int, adminIss, adminSub, reason string) error
 	BlacklistIPAddress(ctx context.Context, aliasUUID, ipAddress, adminIss, adminSub, reason string) (int, error)
-	//GetBlacklistedIPs(ctx context.Context, aliasUUID string) ([]*models.FirewallIPWhitelistEntry, error)
-	IsIPBlacklisted(ctx context.Context, aliasName, ipAddress string) (bool, error)
+	RemoveAliasEntries(ctx context.Context, aliasUUID, adminIss, adminSub, reason string) (int, error)
+
+	CreateBlacklistRule(ctx context.Context, rule *models.FirewallBlacklistRule) (*models.FirewallBlacklistRule, error)
+	GetBlacklistRuleByID(ctx context.Context, id int) (*models.FirewallBlacklistRule, error)
+	GetBlacklistRules(ctx context.Context, aliasUUID string, includeInactive bool) ([]*models.FirewallBlacklistRule, error)
+	UpdateBlacklistRule(ctx context.Context, id int, reason *string, expiresAt *time.Time) error
+	RemoveBlacklistRule(ctx context.Context, id int, removerIss, removerSub string) error
+	ReplaceFeedBlacklistRules(ctx context.Context, feedName string, aliasUUID *string, rules []*models.FirewallBlacklistRule, creatorIss, creatorSub string) (int, error)
+	IsIPBlacklisted(ctx context.Context, aliasUUID, ipAddress string, asn *int64) (bool, error)
 
 	GetPendingIPs(ctx context.Context, aliasUUID string) ([]*models.FirewallIPWhitelistEntry, error)
+	GetAliasSyncEntries(ctx context.Context, aliasUUID string) ([]*models.FirewallIPWhitelistEntry, error)
 	MarkIPsAsAdded(ctx context.Context, ids []int, systemUserIss, systemUserSub string) error
+	GetScheduledWhitelistEntries(ctx context.Context) ([]*models.FirewallIPWhitelistEntry, error)
+	SetWhitelistEntryWindow(ctx context.Context, id int, open bool, systemUserIss, systemUserSub string) (bool, error)
 	ExpireOldIPs(ctx context.Context, systemUserIss, systemUserSub string) (int, error)
 
 	CountUserActiveIPs(ctx context.Context, ownerIss, ownerSub, aliasUUID string) (int, error)
 	CountTotalActiveIPs(ctx context.Context, aliasUUID string) (int, error)
+	CountUserDistinctNetworks(ctx context.Context, ownerIss, ownerSub string, window time.Duration) (int, int, error)
+
+	CreateFirewallSyncPlan(ctx context.Context, plan *models.FirewallSyncPlan) (*models.FirewallSyncPlan, error)
+	GetFirewallSyncPlans(ctx context.Context, aliasUUID string, limit int) ([]*models.FirewallSyncPlan, error)
+	NotifyFirewallSync(ctx context.Context, aliasUUID string) error
+	ListenFirewallSync(ctx context.Context, handler func(aliasUUID string)) error
+
+	GetManagedAliases(ctx context.Context) ([]*models.FirewallManagedAlias, error)
+	GetManagedAliasByID(ctx context.Context, id int) (*models.FirewallManagedAlias, error)
+	CreateManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) (*models.FirewallManagedAlias, error)
+	ImportManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) (bool, error)
+	UpdateManagedAlias(ctx context.Context, alias *models.FirewallManagedAlias) error
+	DeleteManagedAlias(ctx context.Context, id int) error
 
 	/* Audit Log Queries */
 
@@ -80,6 +104,30 @@ type Provider interface {
 
 	CreateWhitelistEvent(ctx context.Context, whitelistID int, actorIss, actorSub, eventType, notes string, clientIP, userAgent *string) error
 	GetWhitelistEventsByEntry(ctx context.Context, whitelistID int) ([]*models.FirewallIPWhitelistEvent, error)
+	SearchWhitelistEvents(ctx context.Context, filter models.FirewallEventFilter, params models.PaginationParams) (*models.PaginatedFirewallEvents, error)
+	StreamWhitelistEvents(ctx context.Context, filter models.FirewallEventFilter, fn func(*models.FirewallAuditEvent) error) error
+
+	/* Data History Queries */
+
+	RecordDataHistory(ctx context.Context, samples []models.DataHistorySample) error
+	GetDataHistory(ctx context.Context, queryName, variables string, resolution models.DataHistoryResolution, start, end time.Time) ([]models.DataHistoryPoint, error)
+	DeleteDataHistoryBefore(ctx context.Context, queryName string, resolution models.DataHistoryResolution, before time.Time) (int64, error)
+
+	/* Data Alert Queries */
+
+	CreateDataAlert(ctx context.Context, alert *models.DataAlert) (*models.DataAlert, error)
+	GetActiveDataAlerts(ctx context.Context) ([]*models.DataAlert, error)
+	UpdateDataAlert(ctx context.Context, alert *models.DataAlert) error
+	DeleteDataAlert(ctx context.Context, id int) error
+
+	/* Status Page Queries */
+
+	CreateStatusIncident(ctx context.Context, incident *models.StatusIncident, message string) (*models.StatusIncident, error)
+	AddStatusIncidentUpdate(ctx context.Context, incidentID int, update *models.StatusIncidentUpdate) (*models.StatusIncident, error)
+	GetStatusIncident(ctx context.Context, id int) (*models.StatusIncident, error)
+	GetStatusIncidents(ctx context.Context, since time.Time) ([]*models.StatusIncident, error)
+	UpdateStatusIncident(ctx context.Context, incident *models.StatusIncident) error
+	DeleteStatusIncident(ctx context.Context, id int) error
 
 	/* Encryption Validation */
 
@@ -20,11 +20,6 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
-// chiContextKey is the context key used by chi router
-type chiContextKeyType string
-
-const chiContextKey chiContextKeyType = "chi.RouteContext"
-
 // TestContext holds everything needed for testing
 type TestContext struct {
 	AppContext          *middlewares.AppContext
@@ -388,12 +383,12 @@ func (tc *TestContext) WithQueryParam(key, value string) *TestContext {
 
 // WithURLParam sets a URL parameter in the chi route context (for path parameters like /resource/{id})
 func (tc *TestContext) WithURLParam(key, value string) *TestContext {
-	rctx := tc.Request.Context().Value(chiContextKey)
+	rctx := tc.Request.Context().Value(chi.RouteCtxKey)
 	if rctx == nil {
 		// Create a new chi context if one doesn't exist
 		ctx := chi.NewRouteContext()
 		ctx.URLParams.Add(key, value)
-		tc.Request = tc.Request.WithContext(context.WithValue(tc.Request.Context(), chiContextKey, ctx))
+		tc.Request = tc.Request.WithContext(context.WithValue(tc.Request.Context(), chi.RouteCtxKey, ctx))
 		tc.AppContext.Request = tc.Request
 		tc.AppContext.Context = tc.Request.Context()
 	} else if chiCtx, ok := rctx.(*chi.Context); ok {
@@ -3,8 +3,10 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
+	"time"
 )
 
 var (
@@ -38,3 +40,8 @@ func ExtractAuthorizationHeader(r *http.Request) (string, error) {
 
 	return token, nil
 }
+
+// RetryAfterSeconds converts a wait into a Retry-After value, rounding up so clients never retry too early.
+func RetryAfterSeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
+}
@@ -1,8 +1,15 @@
 package utils
 
 import (
+	"fmt"
+	"homelab-dashboard/internal/config"
+	"os"
+	"path/filepath"
+
 	v1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 func IsCertificateReady(cert *v1.Certificate) bool {
@@ -14,3 +21,32 @@ func IsCertificateReady(cert *v1.Certificate) bool {
 
 	return false
 }
+
+// KubernetesRestConfig builds the client configuration for the cluster cfg points at: the service account
+// of the pod when running in-cluster, otherwise the kubeconfig file, ~/.kube/config when none is set.
+func KubernetesRestConfig(cfg *config.KubernetesConfig) (*rest.Config, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("kubernetes configuration is nil")
+	}
+
+	if cfg.InCluster {
+		restConfig, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create in-cluster config: %w", err)
+		}
+		return restConfig, nil
+	}
+
+	kubeconfig := cfg.Kubeconfig
+	if kubeconfig == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			kubeconfig = filepath.Join(home, ".kube", "config")
+		}
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config from kubeconfig: %w", err)
+	}
+	return restConfig, nil
+}
@@ -1,7 +1,15 @@
-import type { ResultData } from '@/types/Data.ts';
+import type {
+  ActiveAlert,
+  DataHistoryResolution,
+  DataHistoryResult,
+  DataVariable,
+  RangeResultData,
+  ResultData,
+} from '@/types/Data.ts';
 
 export const fetchMetrics = async (
-  queries?: string[]
+  queries?: string[],
+  variables?: Record<string, string>
 ): Promise<ResultData[]> => {
   const url = new URL('/api/data', window.location.origin);
 
@@ -9,6 +17,10 @@ export const fetchMetrics = async (
     url.searchParams.set('queries', queries.join(','));
   }
 
+  for (const [name, value] of Object.entries(variables ?? {})) {
+    url.searchParams.set(`var.${name}`, value);
+  }
+
   const response = await fetch(url.toString(), {
     credentials: 'include',
   });
@@ -19,3 +31,101 @@ export const fetchMetrics = async (
 
   return response.json();
 };
+
+export const fetchDataVariables = async (): Promise<DataVariable[]> => {
+  const response = await fetch('/api/data/variables', {
+    credentials: 'include',
+  });
+
+  if (!response.ok) {
+    throw new Error(`Failed to fetch variables: ${response.statusText}`);
+  }
+
+  return response.json();
+};
+
+export interface RangeWindow {
+  start: Date;
+  end: Date;
+  step?: string;
+}
+
+export const fetchMetricRange = async (
+  query: string,
+  range: RangeWindow,
+  variables?: Record<string, string>
+): Promise<RangeResultData> => {
+  const url = new URL(
+    `/api/data/${encodeURIComponent(query)}/range`,
+    window.location.origin
+  );
+
+  url.searchParams.set('start', range.start.toISOString());
+  url.searchParams.set('end', range.end.toISOString());
+  if (range.step) {
+    url.searchParams.set('step', range.step);
+  }
+
+  for (const [name, value] of Object.entries(variables ?? {})) {
+    url.searchParams.set(`var.${name}`, value);
+  }
+
+  const response = await fetch(url.toString(), {
+    credentials: 'include',
+  });
+
+  if (!response.ok) {
+    throw new Error(`Failed to fetch range: ${response.statusText}`);
+  }
+
+  return response.json();
+};
+
+export interface HistoryWindow {
+  start: Date;
+  end: Date;
+  resolution?: DataHistoryResolution;
+}
+
+export const fetchMetricHistory = async (
+  query: string,
+  range: HistoryWindow,
+  variables?: Record<string, string>
+): Promise<DataHistoryResult> => {
+  const url = new URL(
+    `/api/data/${encodeURIComponent(query)}/history`,
+    window.location.origin
+  );
+
+  url.searchParams.set('start', range.start.toISOString());
+  url.searchParams.set('end', range.end.toISOString());
+  if (range.resolution) {
+    url.searchParams.set('resolution', range.resolution);
+  }
+
+  for (const [name, value] of Object.entries(variables ?? {})) {
+    url.searchParams.set(`var.${name}`, value);
+  }
+
+  const response = await fetch(url.toString(), {
+    credentials: 'include',
+  });
+
+  if (!response.ok) {
+    throw new Error(`Failed to fetch history: ${response.statusText}`);
+  }
+
+  return response.json();
+};
+
+export const fetchActiveAlerts = async (): Promise<ActiveAlert[]> => {
+  const response = await fetch('/api/alerts', {
+    credentials: 'include',
+  });
+
+  if (!response.ok) {
+    throw new Error(`Failed to fetch alerts: ${response.statusText}`);
+  }
+
+  return response.json();
+};
@@ -106,10 +106,11 @@ async function fetchAllEntries(): Promise<FirewallIPWhitelistEntry[]> {
   return response.json();
 }
 
-export function useAvailableAliases() {
+export function useAvailableAliases(enabled = true) {
   return useQuery({
     queryKey: firewallKeys.aliases(),
     queryFn: fetchAvailableAliases,
+    enabled,
     staleTime: 1000 * 60 * 5, // 5 minutes
   });
 }
@@ -0,0 +1,102 @@
+import { useQuery } from '@tanstack/react-query';
+import type {
+  CreateStatusIncidentRequest,
+  PostStatusIncidentUpdateRequest,
+  StatusIncident,
+  StatusPage,
+} from '@/types/Status.ts';
+
+export const statusKeys = {
+  all: ['status'] as const,
+  page: () => [...statusKeys.all, 'page'] as const,
+  incident: (id: number) => [...statusKeys.all, 'incidents', id] as const,
+};
+
+export const fetchStatusPage = async (): Promise<StatusPage> => {
+  const response = await fetch('/api/status', {
+    credentials: 'include',
+  });
+
+  if (!response.ok) {
+    throw new Error(`Failed to fetch status page: ${response.statusText}`);
+  }
+
+  return response.json();
+};
+
+export const fetchStatusIncident = async (
+  id: number
+): Promise<StatusIncident> => {
+  const response = await fetch(`/api/status/incidents/${id}`, {
+    credentials: 'include',
+  });
+
+  if (!response.ok) {
+    throw new Error(`Failed to fetch incident: ${response.statusText}`);
+  }
+
+  return response.json();
+};
+
+export const createStatusIncident = async (
+  input: CreateStatusIncidentRequest
+): Promise<StatusIncident> => {
+  const response = await fetch('/api/status/incidents', {
+    method: 'POST',
+    credentials: 'include',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify(input),
+  });
+
+  if (!response.ok) {
+    const error = await response
+      .json()
+      .catch(() => ({ error: response.statusText }));
+    throw new Error(error.error || 'Failed to create incident');
+  }
+
+  return response.json();
+};
+
+export const postStatusIncidentUpdate = async (
+  id: number,
+  input: PostStatusIncidentUpdateRequest
+): Promise<StatusIncident> => {
+  const response = await fetch(`/api/status/incidents/${id}/updates`, {
+    method: 'POST',
+    credentials: 'include',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify(input),
+  });
+
+  if (!response.ok) {
+    const error = await response
+      .json()
+      .catch(() => ({ error: response.statusText }));
+    throw new Error(error.error || 'Failed to post incident update');
+  }
+
+  return response.json();
+};
+
+export function useStatusPage() {
+  return useQuery({
+    queryKey: statusKeys.page(),
+    queryFn: fetchStatusPage,
+    staleTime: 1000 * 30, // 30 seconds
+    refetchInterval: 60000, // Auto-refresh every minute
+  });
+}
+
+export function useStatusIncident(id: number) {
+  return useQuery({
+    queryKey: statusKeys.incident(id),
+    queryFn: () => fetchStatusIncident(id),
+    staleTime: 1000 * 30, // 30 seconds
+    refetchInterval: 60000, // Auto-refresh every minute
+  });
+}
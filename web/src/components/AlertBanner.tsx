@@ -0,0 +1,50 @@
+import { TriangleAlert } from 'lucide-react';
+import { Alert, AlertDescription, AlertTitle } from '@/components/ui/alert.tsx';
+import { useActiveAlerts } from '@/hooks/useMetrics.tsx';
+import type { ActiveAlert } from '@/types/Data.ts';
+
+const describeLabels = (labels: Record<string, string>) =>
+  Object.entries(labels)
+    .filter(([name]) => name !== '__name__')
+    .map(([name, value]) => `${name}=${value}`)
+    .join(', ');
+
+const describeAlert = (alert: ActiveAlert) => {
+  const labels = describeLabels(alert.labels);
+  const summary = alert.summary ?? alert.rule;
+  return labels ? `${summary} (${labels})` : summary;
+};
+
+export function AlertBanner() {
+  const { data: alerts } = useActiveAlerts();
+
+  if (!alerts || alerts.length === 0) return null;
+
+  const critical = alerts.some((alert) => alert.severity === 'critical');
+
+  return (
+    <div className="px-4 pt-4">
+      <Alert variant={critical ? 'destructive' : 'default'}>
+        <TriangleAlert />
+        <AlertTitle>
+          {alerts.length === 1
+            ? '1 alert is firing'
+            : `${alerts.length} alerts are firing`}
+        </AlertTitle>
+        <AlertDescription>
+          <ul className="list-disc pl-4">
+            {alerts.map((alert) => (
+              <li key={`${alert.rule}-${describeLabels(alert.labels)}`}>
+                <span className="font-medium capitalize">
+                  {alert.severity}
+                </span>
+                : {describeAlert(alert)} since{' '}
+                {new Date(alert.fired_at * 1000).toLocaleString()}
+              </li>
+            ))}
+          </ul>
+        </AlertDescription>
+      </Alert>
+    </div>
+  );
+}
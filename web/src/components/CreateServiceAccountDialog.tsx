@@ -19,6 +19,7 @@ import {
 } from '@/components/ui/tooltip';
 import { Copy, Check, AlertCircle } from 'lucide-react';
 import { useCreateServiceAccount, useUserScopes } from '@/api/ServiceAccounts';
+import { useAvailableAliases } from '@/api/Firewall';
 
 interface CreateServiceAccountDialogProps {
   open: boolean;
@@ -32,12 +33,20 @@ export function CreateServiceAccountDialog({
   const [name, setName] = useState('');
   const [expiryDays, setExpiryDays] = useState('365');
   const [selectedScopes, setSelectedScopes] = useState<string[]>([]);
+  const [selectedAliases, setSelectedAliases] = useState<string[]>([]);
   const [createdToken, setCreatedToken] = useState<string | null>(null);
   const [copied, setCopied] = useState(false);
 
   const { data: userScopes, isLoading: scopesLoading } = useUserScopes();
   const createMutation = useCreateServiceAccount();
 
+  const firewallEnabled =
+    userScopes?.scopes.some(
+      (scopeInfo) =>
+        scopeInfo.scope.startsWith('firewall:') && !scopeInfo.disabled
+    ) ?? false;
+  const { data: availableAliases } = useAvailableAliases(firewallEnabled);
+
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     if (!name.trim() || selectedScopes.length === 0) {
@@ -54,6 +63,7 @@ export function CreateServiceAccountDialog({
         name: name.trim(),
         token_expires_at: expiresAt.toISOString(),
         scopes: selectedScopes,
+        firewall_aliases: selectedAliases,
       });
       setCreatedToken(result.token || null);
     } catch (error) {
@@ -80,6 +90,7 @@ export function CreateServiceAccountDialog({
     setName('');
     setExpiryDays('365');
     setSelectedScopes([]);
+    setSelectedAliases([]);
     setCreatedToken(null);
     setCopied(false);
     createMutation.reset();
@@ -93,6 +104,12 @@ export function CreateServiceAccountDialog({
     );
   };
 
+  const toggleAlias = (uuid: string) => {
+    setSelectedAliases((prev) =>
+      prev.includes(uuid) ? prev.filter((a) => a !== uuid) : [...prev, uuid]
+    );
+  };
+
   // Show token display if service account was created
   if (createdToken) {
     return (
@@ -251,6 +268,38 @@ export function CreateServiceAccountDialog({
               )}
             </div>
 
+            {firewallEnabled &&
+              availableAliases &&
+              availableAliases.length > 0 && (
+                <div className="space-y-2">
+                  <Label>Firewall Aliases</Label>
+                  <p className="text-sm text-muted-foreground">
+                    Select the aliases this service account may manage its own
+                    entries in. You can only grant aliases that you have.
+                  </p>
+                  <div className="space-y-2 border rounded-md p-4 max-h-[200px] overflow-y-auto">
+                    {availableAliases.map((alias) => (
+                      <div
+                        key={alias.uuid}
+                        className="flex items-center space-x-2"
+                      >
+                        <Checkbox
+                          id={`alias-${alias.uuid}`}
+                          checked={selectedAliases.includes(alias.uuid)}
+                          onCheckedChange={() => toggleAlias(alias.uuid)}
+                        />
+                        <Label
+                          htmlFor={`alias-${alias.uuid}`}
+                          className="text-sm font-normal cursor-pointer"
+                        >
+                          {alias.name}
+                        </Label>
+                      </div>
+                    ))}
+                  </div>
+                </div>
+              )}
+
             {createMutation.isError && (
               <Alert variant="destructive">
                 <AlertCircle className="h-4 w-4" />
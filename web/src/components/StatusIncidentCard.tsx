@@ -0,0 +1,137 @@
+import { Link } from '@tanstack/react-router';
+import { Badge } from '@/components/ui/badge';
+import {
+  Card,
+  CardContent,
+  CardDescription,
+  CardHeader,
+  CardTitle,
+} from '@/components/ui/card';
+import type {
+  StatusIncident,
+  StatusIncidentImpact,
+  StatusPageStatus,
+} from '@/types/Status';
+
+type BadgeVariant =
+  | 'default'
+  | 'outline'
+  | 'secondary'
+  | 'destructive'
+  | 'warning'
+  | 'success'
+  | 'info';
+
+const statusVariants: Record<StatusPageStatus, BadgeVariant> = {
+  operational: 'success',
+  unknown: 'secondary',
+  under_maintenance: 'info',
+  degraded_performance: 'warning',
+  partial_outage: 'warning',
+  major_outage: 'destructive',
+};
+
+const impactVariants: Record<StatusIncidentImpact, BadgeVariant> = {
+  none: 'secondary',
+  minor: 'warning',
+  major: 'destructive',
+  critical: 'destructive',
+};
+
+// Turns API values such as "partial_outage" into "Partial outage"
+export const formatStatusLabel = (value: string) => {
+  const label = value.replaceAll('_', ' ');
+  return label.charAt(0).toUpperCase() + label.slice(1);
+};
+
+export const formatStatusDate = (dateString: string) =>
+  new Date(dateString).toLocaleString('en-US', {
+    year: 'numeric',
+    month: 'short',
+    day: 'numeric',
+    hour: 'numeric',
+    minute: '2-digit',
+    timeZoneName: 'short',
+  });
+
+export function StatusBadge({ status }: { status: StatusPageStatus }) {
+  return (
+    <Badge variant={statusVariants[status]}>
+      {formatStatusLabel(status)}
+    </Badge>
+  );
+}
+
+interface StatusIncidentCardProps {
+  incident: StatusIncident;
+  // Show every update instead of the latest one with a link to the incident
+  showAllUpdates?: boolean;
+}
+
+export function StatusIncidentCard({
+  incident,
+  showAllUpdates = false,
+}: StatusIncidentCardProps) {
+  const updates = showAllUpdates
+    ? incident.updates
+    : incident.updates.slice(0, 1);
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle className="flex items-center justify-between gap-4">
+          {showAllUpdates ? (
+            incident.title
+          ) : (
+            <Link
+              to="/status/incidents/$id"
+              params={{ id: incident.id.toString() }}
+              className="hover:underline"
+            >
+              {incident.title}
+            </Link>
+          )}
+          <div className="flex gap-2">
+            {incident.kind === 'incident' && (
+              <Badge variant={impactVariants[incident.impact]}>
+                {formatStatusLabel(incident.impact)}
+              </Badge>
+            )}
+            <Badge variant="outline">
+              {formatStatusLabel(incident.state)}
+            </Badge>
+          </div>
+        </CardTitle>
+        <CardDescription>
+          {incident.kind === 'maintenance' && incident.scheduled_start
+            ? `Scheduled ${formatStatusDate(incident.scheduled_start)}${
+                incident.scheduled_end
+                  ? ` – ${formatStatusDate(incident.scheduled_end)}`
+                  : ''
+              }`
+            : `Opened ${formatStatusDate(incident.created_at)}`}
+          {incident.components.length > 0 &&
+            ` • Affects ${incident.components.join(', ')}`}
+        </CardDescription>
+      </CardHeader>
+      {updates.length > 0 && (
+        <CardContent className="space-y-4">
+          {updates.map((update) => (
+            <div key={update.id}>
+              <div className="text-sm">
+                <span className="font-semibold">
+                  {formatStatusLabel(update.state)}
+                </span>
+                {' – '}
+                {update.message}
+              </div>
+              <div className="text-xs text-muted-foreground">
+                {formatStatusDate(update.created_at)}
+              </div>
+            </div>
+          ))}
+        </CardContent>
+      )}
+    </Card>
+  );
+}
@@ -31,6 +31,11 @@ export function Header() {
                 <Link to={'/blog'}>Blog</Link>
               </NavigationMenuLink>
             </NavigationMenuItem>
+            <NavigationMenuItem>
+              <NavigationMenuLink asChild>
+                <Link to={'/status'}>Status</Link>
+              </NavigationMenuLink>
+            </NavigationMenuItem>
             <NavigationMenuItem>
               <NavigationMenuLink asChild>
                 <Link to={'/about'}>About</Link>
@@ -1,6 +1,19 @@
-import { useQuery, type UseQueryOptions } from '@tanstack/react-query';
-import type { ResultData } from '@/types/Data.ts';
-import { fetchMetrics } from '@/api/Data.tsx';
+import { useEffect } from 'react';
+import {
+  useQuery,
+  useQueryClient,
+  type UseQueryOptions,
+} from '@tanstack/react-query';
+import type { DataVariable, ResultData } from '@/types/Data.ts';
+import {
+  fetchActiveAlerts,
+  fetchDataVariables,
+  fetchMetricHistory,
+  fetchMetricRange,
+  fetchMetrics,
+  type HistoryWindow,
+  type RangeWindow,
+} from '@/api/Data.tsx';
 import { processResult } from '@/utils/Data.tsx';
 
 export const useAllMetrics = (
@@ -10,7 +23,7 @@ export const useAllMetrics = (
     queryKey: ['metrics', 'all'],
     queryFn: () => fetchMetrics(),
     staleTime: 5 * 60 * 1000, // 5 minutes
-    refetchInterval: 30 * 1000, // Refetch every 30 seconds
+    refetchInterval: 5 * 60 * 1000, // Fallback only, useMetricsStream pushes updates
     ...options,
   });
 };
@@ -34,11 +47,81 @@ export const useMetrics = (
     queryFn: () => fetchMetrics(queries),
     enabled: queries.length > 0,
     staleTime: 5 * 60 * 1000,
-    refetchInterval: 30 * 1000,
+    refetchInterval: 5 * 60 * 1000,
     ...options,
   });
 };
 
+// useTemplatedMetrics fetches queries for a selection of template variables. Selections that are not
+// precomputed are run on demand by the server, so they are not refetched in the background.
+export const useTemplatedMetrics = (
+  queries: string[],
+  variables: Record<string, string>,
+  options?: Omit<UseQueryOptions<ResultData[]>, 'queryKey' | 'queryFn'>
+) => {
+  return useQuery({
+    queryKey: ['metrics', 'templated', [...queries].sort(), variables],
+    queryFn: () => fetchMetrics(queries, variables),
+    enabled: queries.length > 0,
+    staleTime: 60 * 1000,
+    ...options,
+  });
+};
+
+export const useDataVariables = () => {
+  return useQuery({
+    queryKey: ['metrics', 'variables'],
+    queryFn: fetchDataVariables,
+    staleTime: 5 * 60 * 1000,
+  });
+};
+
+// useMetricRange runs a query over a chosen window, e.g. to zoom into an outage. The server caches each
+// window briefly, so a window is not refetched while it is shown.
+export const useMetricRange = (
+  queryName: string,
+  range: RangeWindow | undefined,
+  variables?: Record<string, string>
+) => {
+  return useQuery({
+    queryKey: [
+      'metrics',
+      'range',
+      queryName,
+      range?.start.toISOString(),
+      range?.end.toISOString(),
+      range?.step,
+      variables,
+    ],
+    queryFn: () => fetchMetricRange(queryName, range!, variables),
+    enabled: !!queryName && !!range,
+    staleTime: Infinity,
+  });
+};
+
+// useMetricHistory reads the long-term history the server records for a query, e.g. for year-over-year
+// panels. Buckets fill in slowly, so the history is only refetched every few minutes.
+export const useMetricHistory = (
+  queryName: string,
+  range: HistoryWindow | undefined,
+  variables?: Record<string, string>
+) => {
+  return useQuery({
+    queryKey: [
+      'metrics',
+      'history',
+      queryName,
+      range?.start.toISOString(),
+      range?.end.toISOString(),
+      range?.resolution,
+      variables,
+    ],
+    queryFn: () => fetchMetricHistory(queryName, range!, variables),
+    enabled: !!queryName && !!range,
+    staleTime: 5 * 60 * 1000,
+  });
+};
+
 export const useMetric = (
   queryName: string,
   options?: Omit<
@@ -54,7 +137,55 @@ export const useMetric = (
     },
     enabled: !!queryName,
     staleTime: 5 * 60 * 1000,
-    refetchInterval: 30 * 1000,
+    refetchInterval: 5 * 60 * 1000,
     ...options,
   });
 };
+
+// useMetricsStream keeps every cached metrics query up to date from the /api/data/stream Server-Sent Events
+// endpoint, so components get fresh values as soon as the server has them instead of polling.
+export const useMetricsStream = () => {
+  const queryClient = useQueryClient();
+
+  useEffect(() => {
+    const source = new EventSource('/api/data/stream', {
+      withCredentials: true,
+    });
+
+    source.onmessage = (event: MessageEvent<string>) => {
+      const result = JSON.parse(event.data) as ResultData;
+
+      queryClient.setQueryData<ResultData[]>(['metrics', 'all'], (current) => {
+        if (!current) return current;
+        return current.some((item) => item.query_name === result.query_name)
+          ? current.map((item) =>
+              item.query_name === result.query_name ? result : item
+            )
+          : [...current, result];
+      });
+
+      queryClient.setQueriesData<ResultData[] | ResultData | undefined>(
+        { queryKey: ['metrics'] },
+        (current) => {
+          if (Array.isArray(current)) {
+            return current.map((item) =>
+              item.query_name === result.query_name ? result : item
+            );
+          }
+          return current?.query_name === result.query_name ? result : current;
+        }
+      );
+    };
+
+    return () => source.close();
+  }, [queryClient]);
+};
+
+// useActiveAlerts lists the alerts firing on queries the user may read, for the dashboard's banner.
+export const useActiveAlerts = () => {
+  return useQuery({
+    queryKey: ['alerts', 'active'],
+    queryFn: fetchActiveAlerts,
+    refetchInterval: 30 * 1000,
+  });
+};
@@ -14,10 +14,12 @@ import { Route as AboutRouteImport } from './routes/about'
 import { Route as SettingsRouteRouteImport } from './routes/settings/route'
 import { Route as IndexRouteImport } from './routes/index'
 import { Route as SettingsIndexRouteImport } from './routes/settings/index'
+import { Route as StatusIndexRouteImport } from './routes/status/index'
 import { Route as BlogIndexRouteImport } from './routes/blog/index'
 import { Route as SettingsServiceAccountsRouteImport } from './routes/settings/service-accounts'
 import { Route as SettingsProfileRouteImport } from './routes/settings/profile'
 import { Route as BlogSlugRouteImport } from './routes/blog/$slug'
+import { Route as StatusIncidentsIdRouteImport } from './routes/status/incidents/$id'
 import { Route as SettingsFirewallIndexRouteImport } from './routes/settings/firewall/index'
 import { Route as SettingsCertsIndexRouteImport } from './routes/settings/certs/index'
 import { Route as SettingsCertsSettingsRouteImport } from './routes/settings/certs/settings'
@@ -50,6 +52,11 @@ const SettingsIndexRoute = SettingsIndexRouteImport.update({
   path: '/',
   getParentRoute: () => SettingsRouteRoute,
 } as any)
+const StatusIndexRoute = StatusIndexRouteImport.update({
+  id: '/status/',
+  path: '/status/',
+  getParentRoute: () => rootRouteImport,
+} as any)
 const BlogIndexRoute = BlogIndexRouteImport.update({
   id: '/blog/',
   path: '/blog/',
@@ -70,6 +77,11 @@ const BlogSlugRoute = BlogSlugRouteImport.update({
   path: '/blog/$slug',
   getParentRoute: () => rootRouteImport,
 } as any)
+const StatusIncidentsIdRoute = StatusIncidentsIdRouteImport.update({
+  id: '/status/incidents/$id',
+  path: '/status/incidents/$id',
+  getParentRoute: () => rootRouteImport,
+} as any)
 const SettingsFirewallIndexRoute = SettingsFirewallIndexRouteImport.update({
   id: '/firewall/',
   path: '/firewall/',
@@ -112,11 +124,13 @@ export interface FileRoutesByFullPath {
   '/settings/profile': typeof SettingsProfileRoute
   '/settings/service-accounts': typeof SettingsServiceAccountsRoute
   '/blog/': typeof BlogIndexRoute
+  '/status/': typeof StatusIndexRoute
   '/settings/': typeof SettingsIndexRoute
   '/settings/certs/requests': typeof SettingsCertsRequestsRoute
   '/settings/certs/settings': typeof SettingsCertsSettingsRoute
   '/settings/certs/': typeof SettingsCertsIndexRoute
   '/settings/firewall/': typeof SettingsFirewallIndexRoute
+  '/status/incidents/$id': typeof StatusIncidentsIdRoute
   '/settings/certs/admin/requests': typeof SettingsCertsAdminRequestsRoute
   '/settings/firewall/admin/': typeof SettingsFirewallAdminIndexRoute
 }
@@ -128,11 +142,13 @@ export interface FileRoutesByTo {
   '/settings/profile': typeof SettingsProfileRoute
   '/settings/service-accounts': typeof SettingsServiceAccountsRoute
   '/blog': typeof BlogIndexRoute
+  '/status': typeof StatusIndexRoute
   '/settings': typeof SettingsIndexRoute
   '/settings/certs/requests': typeof SettingsCertsRequestsRoute
   '/settings/certs/settings': typeof SettingsCertsSettingsRoute
   '/settings/certs': typeof SettingsCertsIndexRoute
   '/settings/firewall': typeof SettingsFirewallIndexRoute
+  '/status/incidents/$id': typeof StatusIncidentsIdRoute
   '/settings/certs/admin/requests': typeof SettingsCertsAdminRequestsRoute
   '/settings/firewall/admin': typeof SettingsFirewallAdminIndexRoute
 }
@@ -146,11 +162,13 @@ export interface FileRoutesById {
   '/settings/profile': typeof SettingsProfileRoute
   '/settings/service-accounts': typeof SettingsServiceAccountsRoute
   '/blog/': typeof BlogIndexRoute
+  '/status/': typeof StatusIndexRoute
   '/settings/': typeof SettingsIndexRoute
   '/settings/certs/requests': typeof SettingsCertsRequestsRoute
   '/settings/certs/settings': typeof SettingsCertsSettingsRoute
   '/settings/certs/': typeof SettingsCertsIndexRoute
   '/settings/firewall/': typeof SettingsFirewallIndexRoute
+  '/status/incidents/$id': typeof StatusIncidentsIdRoute
   '/settings/certs/admin/requests': typeof SettingsCertsAdminRequestsRoute
   '/settings/firewall/admin/': typeof SettingsFirewallAdminIndexRoute
 }
@@ -165,11 +183,13 @@ export interface FileRouteTypes {
     | '/settings/profile'
     | '/settings/service-accounts'
     | '/blog/'
+    | '/status/'
     | '/settings/'
     | '/settings/certs/requests'
     | '/settings/certs/settings'
     | '/settings/certs/'
     | '/settings/firewall/'
+    | '/status/incidents/$id'
     | '/settings/certs/admin/requests'
     | '/settings/firewall/admin/'
   fileRoutesByTo: FileRoutesByTo
@@ -181,11 +201,13 @@ export interface FileRouteTypes {
     | '/settings/profile'
     | '/settings/service-accounts'
     | '/blog'
+    | '/status'
     | '/settings'
     | '/settings/certs/requests'
     | '/settings/certs/settings'
     | '/settings/certs'
     | '/settings/firewall'
+    | '/status/incidents/$id'
     | '/settings/certs/admin/requests'
     | '/settings/firewall/admin'
   id:
@@ -198,11 +220,13 @@ export interface FileRouteTypes {
     | '/settings/profile'
     | '/settings/service-accounts'
     | '/blog/'
+    | '/status/'
     | '/settings/'
     | '/settings/certs/requests'
     | '/settings/certs/settings'
     | '/settings/certs/'
     | '/settings/firewall/'
+    | '/status/incidents/$id'
     | '/settings/certs/admin/requests'
     | '/settings/firewall/admin/'
   fileRoutesById: FileRoutesById
@@ -214,6 +238,8 @@ export interface RootRouteChildren {
   ErrorRoute: typeof ErrorRoute
   BlogSlugRoute: typeof BlogSlugRoute
   BlogIndexRoute: typeof BlogIndexRoute
+  StatusIndexRoute: typeof StatusIndexRoute
+  StatusIncidentsIdRoute: typeof StatusIncidentsIdRoute
 }
 
 declare module '@tanstack/react-router' {
@@ -253,6 +279,13 @@ declare module '@tanstack/react-router' {
       preLoaderRoute: typeof SettingsIndexRouteImport
       parentRoute: typeof SettingsRouteRoute
     }
+    '/status/': {
+      id: '/status/'
+      path: '/status'
+      fullPath: '/status/'
+      preLoaderRoute: typeof StatusIndexRouteImport
+      parentRoute: typeof rootRouteImport
+    }
     '/blog/': {
       id: '/blog/'
       path: '/blog'
@@ -281,6 +314,13 @@ declare module '@tanstack/react-router' {
       preLoaderRoute: typeof BlogSlugRouteImport
       parentRoute: typeof rootRouteImport
     }
+    '/status/incidents/$id': {
+      id: '/status/incidents/$id'
+      path: '/status/incidents/$id'
+      fullPath: '/status/incidents/$id'
+      preLoaderRoute: typeof StatusIncidentsIdRouteImport
+      parentRoute: typeof rootRouteImport
+    }
     '/settings/firewall/': {
       id: '/settings/firewall/'
       path: '/firewall'
@@ -361,6 +401,8 @@ const rootRouteChildren: RootRouteChildren = {
   ErrorRoute: ErrorRoute,
   BlogSlugRoute: BlogSlugRoute,
   BlogIndexRoute: BlogIndexRoute,
+  StatusIndexRoute: StatusIndexRoute,
+  StatusIncidentsIdRoute: StatusIncidentsIdRoute,
 }
 export const routeTree = rootRouteImport
   ._addFileChildren(rootRouteChildren)
@@ -1,11 +1,14 @@
 import { createRootRoute, Outlet } from '@tanstack/react-router';
 import { TanStackRouterDevtools } from '@tanstack/react-router-devtools';
 import { Header } from '@/components/header.tsx';
+import { useMetricsStream } from '@/hooks/useMetrics.tsx';
 
 
 
-export const Route = createRootRoute({
-  component: () => (
+function RootLayout() {
+  useMetricsStream();
+
+  return (
     <>
       <Header />
       <main>
@@ -14,5 +17,9 @@ export const Route = createRootRoute({
 
       <TanStackRouterDevtools />
     </>
-  ),
+  );
+}
+
+export const Route = createRootRoute({
+  component: RootLayout,
 });
@@ -11,6 +11,7 @@ import { ClusterAvgCPUWeekCard } from '@/components/ClusterAvgCPUWeekCard.tsx';
 import { useAuth } from '@/hooks/useAuth.tsx';
 import { useEffect, useState } from 'react';
 import { LoginDialog } from '@/components/LoginDialog.tsx';
+import { AlertBanner } from '@/components/AlertBanner.tsx';
 
 export const Route = createFileRoute('/')({
   component: Index,
@@ -65,6 +66,7 @@ function Index() {
 
   return (
     <>
+      <AlertBanner />
       <div className="grid grid-cols-1 sm:grid-cols-2 lg:grid-cols-3 xl:grid-cols-4 auto-rows-96 gap-4 p-4">
         <div className="col-span-1 row-span-1 sm:col-span-1 lg:col-span-1 lg:row-span-1">
           <PodUptimeCards />
@@ -218,6 +218,18 @@ function RouteComponent() {
     })}`;
   };
 
+  // GeoIP details of an address, e.g. "DE • AS3320 Deutsche Telekom AG"
+  const formatNetwork = (
+    countryCode?: string,
+    asn?: number,
+    asOrganization?: string
+  ) => {
+    const network = [asn && `AS${asn}`, asOrganization]
+      .filter(Boolean)
+      .join(' ');
+    return [countryCode, network].filter(Boolean).join(' • ') || null;
+  };
+
   // Action handlers
   const handleRemoveIP = async (id: number) => {
     try {
@@ -263,6 +275,11 @@ function RouteComponent() {
   const renderEntry = (entry: FirewallIPWhitelistEntry) => {
     const canRemove = entry.status === 'requested' || entry.status === 'added';
     const canBlacklist = entry.status !== 'blacklisted_by_admin';
+    const network = formatNetwork(
+      entry.country_code,
+      entry.asn,
+      entry.as_organization
+    );
 
     return (
       <AccordionItem
@@ -284,6 +301,12 @@ function RouteComponent() {
                   />
                   {' • '}
                   {entry.alias_name}
+                  {network && (
+                    <>
+                      {' • '}
+                      {network}
+                    </>
+                  )}
                 </div>
               </div>
             </div>
@@ -308,6 +331,13 @@ function RouteComponent() {
                   <TableCell>IPv{entry.ip_version}</TableCell>
                 </TableRow>
 
+                {network && (
+                  <TableRow>
+                    <TableHead>Network</TableHead>
+                    <TableCell>{network}</TableCell>
+                  </TableRow>
+                )}
+
                 <TableRow>
                   <TableHead>Alias</TableHead>
                   <TableCell>{entry.alias_name}</TableCell>
@@ -391,39 +421,47 @@ function RouteComponent() {
                 <h3 className="font-semibold mb-2">Event History</h3>
                 <Table>
                   <TableBody>
-                    {entry.events.map((event) => (
-                      <TableRow key={event.id}>
-                        <TableCell className="font-medium">
-                          {event.event_type}
-                        </TableCell>
-                        <TableCell>
-                          <UserDisplay
-                            displayName={event.actor_display_name}
-                            username={event.actor_username}
-                            sub={event.actor_sub}
-                            iss={event.actor_iss}
-                          />
-                        </TableCell>
-                        <TableCell>
-                          {event.notes && (
-                            <div className="text-sm">{event.notes}</div>
-                          )}
-                          {event.client_ip && (
-                            <div className="text-xs text-muted-foreground">
-                              IP: {event.client_ip}
-                            </div>
-                          )}
-                          {event.user_agent && (
-                            <div className="text-xs text-muted-foreground">
-                              UA: {event.user_agent}
-                            </div>
-                          )}
-                        </TableCell>
-                        <TableCell className="text-sm text-muted-foreground">
-                          {formatDate(event.created_at)}
-                        </TableCell>
-                      </TableRow>
-                    ))}
+                    {entry.events.map((event) => {
+                      const clientNetwork = formatNetwork(
+                        event.client_country_code,
+                        event.client_asn,
+                        event.client_as_organization
+                      );
+                      return (
+                        <TableRow key={event.id}>
+                          <TableCell className="font-medium">
+                            {event.event_type}
+                          </TableCell>
+                          <TableCell>
+                            <UserDisplay
+                              displayName={event.actor_display_name}
+                              username={event.actor_username}
+                              sub={event.actor_sub}
+                              iss={event.actor_iss}
+                            />
+                          </TableCell>
+                          <TableCell>
+                            {event.notes && (
+                              <div className="text-sm">{event.notes}</div>
+                            )}
+                            {event.client_ip && (
+                              <div className="text-xs text-muted-foreground">
+                                IP: {event.client_ip}
+                                {clientNetwork && ` (${clientNetwork})`}
+                              </div>
+                            )}
+                            {event.user_agent && (
+                              <div className="text-xs text-muted-foreground">
+                                UA: {event.user_agent}
+                              </div>
+                            )}
+                          </TableCell>
+                          <TableCell className="text-sm text-muted-foreground">
+                            {formatDate(event.created_at)}
+                          </TableCell>
+                        </TableRow>
+                      );
+                    })}
                   </TableBody>
                 </Table>
               </div>
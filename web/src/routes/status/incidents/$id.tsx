@@ -0,0 +1,39 @@
+import { createFileRoute, Link } from '@tanstack/react-router';
+import { useStatusIncident } from '@/api/Status';
+import { StatusIncidentCard } from '@/components/StatusIncidentCard';
+
+export const Route = createFileRoute('/status/incidents/$id')({
+  component: StatusIncidentPage,
+});
+
+function StatusIncidentPage() {
+  const { id } = Route.useParams();
+  const {
+    data: incident,
+    isLoading,
+    isError,
+    error,
+  } = useStatusIncident(Number(id));
+
+  return (
+    <div className="container mx-auto p-6 max-w-4xl">
+      <div className="mb-6">
+        <Link to="/status" className="text-sm text-muted-foreground underline">
+          Back to status
+        </Link>
+      </div>
+
+      {isLoading && (
+        <div className="text-center py-12">Loading incident...</div>
+      )}
+
+      {(isError || (!isLoading && !incident)) && (
+        <div className="text-center py-12 text-destructive">
+          Error loading incident: {error?.message}
+        </div>
+      )}
+
+      {incident && <StatusIncidentCard incident={incident} showAllUpdates />}
+    </div>
+  );
+}
@@ -0,0 +1,105 @@
+import { createFileRoute } from '@tanstack/react-router';
+import { useStatusPage } from '@/api/Status';
+import {
+  StatusBadge,
+  StatusIncidentCard,
+  formatStatusDate,
+} from '@/components/StatusIncidentCard';
+import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card';
+import type { StatusIncident } from '@/types/Status';
+
+export const Route = createFileRoute('/status/')({
+  component: StatusPage,
+});
+
+function StatusPage() {
+  const { data: page, isLoading, isError, error } = useStatusPage();
+
+  if (isLoading) {
+    return (
+      <div className="container mx-auto p-6 max-w-4xl">
+        <div className="text-center py-12">Loading status...</div>
+      </div>
+    );
+  }
+
+  if (isError || !page) {
+    return (
+      <div className="container mx-auto p-6 max-w-4xl">
+        <div className="text-center py-12 text-destructive">
+          Error loading status: {error?.message}
+        </div>
+      </div>
+    );
+  }
+
+  const renderIncidents = (title: string, incidents: StatusIncident[]) =>
+    incidents.length > 0 && (
+      <div className="mb-8">
+        <h2 className="text-xl font-semibold mb-4">{title}</h2>
+        <div className="space-y-4">
+          {incidents.map((incident) => (
+            <StatusIncidentCard key={incident.id} incident={incident} />
+          ))}
+        </div>
+      </div>
+    );
+
+  return (
+    <div className="container mx-auto p-6 max-w-4xl">
+      <div className="mb-8 flex items-start justify-between">
+        <div>
+          <h1 className="text-3xl font-bold mb-2">{page.title}</h1>
+          <p className="text-muted-foreground">
+            Last updated {formatStatusDate(page.updated_at)}
+            {' • '}
+            <a href="/api/status/feed" className="underline">
+              Subscribe
+            </a>
+          </p>
+        </div>
+        <StatusBadge status={page.status} />
+      </div>
+
+      {renderIncidents('Incidents', page.incidents)}
+      {renderIncidents('Maintenance', page.maintenance)}
+
+      <div className="mb-8 space-y-4">
+        {page.services.map((service) => (
+          <Card key={service.name}>
+            <CardHeader>
+              <CardTitle className="flex items-center justify-between">
+                {service.name}
+                <StatusBadge status={service.status} />
+              </CardTitle>
+            </CardHeader>
+            {service.components.length > 0 && (
+              <CardContent className="space-y-2">
+                {service.components.map((component) => (
+                  <div
+                    key={component.name}
+                    className="flex items-center justify-between"
+                  >
+                    <div>
+                      <div className="text-sm font-medium">
+                        {component.name}
+                      </div>
+                      {component.description && (
+                        <div className="text-xs text-muted-foreground">
+                          {component.description}
+                        </div>
+                      )}
+                    </div>
+                    <StatusBadge status={component.status} />
+                  </div>
+                ))}
+              </CardContent>
+            )}
+          </Card>
+        ))}
+      </div>
+
+      {renderIncidents('Past Incidents', page.history)}
+    </div>
+  );
+}
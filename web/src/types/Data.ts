@@ -12,6 +12,7 @@ export interface Annotations {
   source: string;
   status: boolean;
   workload?: string;
+  display?: string; // set by the seconds_to_duration transform
 }
 export interface ValScalar {
   timestamp: number;
@@ -31,11 +32,69 @@ export interface ValMatrix {
   values: [number, number][];
 }
 
-export type PrometheusData = ValScalar | ValVector[] | ValMatrix[];
+// ValString is a [timestamp, value] pair, e.g. a scalar formatted by the seconds_to_duration transform.
+export type ValString = [number, string];
+
+export type PrometheusData = ValScalar | ValVector[] | ValMatrix[] | ValString;
 
 export interface ResultData {
   query_name: string;
-  type: 'scalar' | 'vector' | 'matrix';
+  type: 'scalar' | 'vector' | 'matrix' | 'string';
   data: PrometheusData;
   timestamp: number;
+  stale?: boolean;
+  age_seconds?: number;
+  variables?: Record<string, string>;
+  // Returned by the backend alongside the result, e.g. when it answered with partial data
+  warnings?: string[];
+}
+
+export interface DataVariable {
+  name: string;
+  default: string;
+  options: string[];
+  precompute: string[];
+}
+
+export interface RangeResultData extends ResultData {
+  start: number;
+  end: number;
+  step: number;
+}
+
+export type DataHistoryResolution = '5m' | '1h' | '1d';
+
+export interface DataHistoryPoint {
+  timestamp: number;
+  samples: number;
+  avg: number;
+  min: number;
+  max: number;
+  last: number;
+}
+
+export interface DataHistorySeries {
+  labels: Record<string, string>;
+  points: DataHistoryPoint[];
+}
+
+export interface DataHistoryResult {
+  query_name: string;
+  variables?: Record<string, string>;
+  resolution: DataHistoryResolution;
+  start: number;
+  end: number;
+  series: DataHistorySeries[];
+}
+
+export type AlertSeverity = 'info' | 'warning' | 'critical';
+
+export interface ActiveAlert {
+  rule: string;
+  severity: AlertSeverity;
+  summary?: string;
+  query_name: string;
+  labels: Record<string, string>;
+  value: number;
+  fired_at: number;
 }
@@ -18,6 +18,9 @@ export interface FirewallIPWhitelistEntry {
   alias_uuid: string;
   ip_address: string;
   ip_version: number; // 4 or 6
+  country_code?: string; // GeoIP details, omitted when no database covers the address
+  asn?: number;
+  as_organization?: string;
   description: string | null;
   status: FirewallIPStatus;
   requested_at: string;
@@ -30,6 +33,7 @@ export interface FirewallIPWhitelistEntry {
   removed_by_display_name: string | null;
   removal_reason: string | null;
   events: FirewallIPWhitelistEvent[];
+  estimated_apply_at?: string; // only set in the response to a change awaiting a firewall sync
 }
 
 export interface FirewallIPWhitelistEvent {
@@ -43,6 +47,9 @@ export interface FirewallIPWhitelistEvent {
   notes: string | null;
   client_ip: string | null;
   user_agent: string | null;
+  client_country_code?: string; // GeoIP details of client_ip, when known
+  client_asn?: number;
+  client_as_organization?: string;
   created_at: string;
 }
 
@@ -7,15 +7,22 @@ export interface ServiceAccount {
   is_disabled: boolean;
   deleted_at?: string | null;
   scopes: string[];
+  firewall_grants?: FirewallAliasGrant[];
   created_by_iss: string;
   created_by_sub: string;
   created_at: string;
 }
 
+export interface FirewallAliasGrant {
+  alias_uuid: string;
+  auth_group: string;
+}
+
 export interface CreateServiceAccountInput {
   name: string;
   token_expires_at: string;
   scopes: string[];
+  firewall_aliases?: string[]; // alias uuids, limited to aliases available to the creator
 }
 
 export interface ScopeInfo {
@@ -0,0 +1,80 @@
+export type StatusPageStatus =
+  | 'operational'
+  | 'unknown'
+  | 'under_maintenance'
+  | 'degraded_performance'
+  | 'partial_outage'
+  | 'major_outage';
+
+export type StatusIncidentKind = 'incident' | 'maintenance';
+
+export type StatusIncidentImpact = 'none' | 'minor' | 'major' | 'critical';
+
+export type StatusIncidentState =
+  | 'investigating'
+  | 'identified'
+  | 'monitoring'
+  | 'resolved'
+  | 'scheduled'
+  | 'in_progress'
+  | 'completed';
+
+export interface StatusIncidentUpdate {
+  id: number;
+  state: StatusIncidentState;
+  message: string;
+  created_at: string;
+}
+
+export interface StatusIncident {
+  id: number;
+  kind: StatusIncidentKind;
+  title: string;
+  impact: StatusIncidentImpact;
+  state: StatusIncidentState;
+  components: string[];
+  scheduled_start?: string; // maintenance only
+  scheduled_end?: string;
+  created_at: string;
+  updated_at: string;
+  resolved_at?: string;
+  updates: StatusIncidentUpdate[]; // newest first
+}
+
+export interface StatusPageComponent {
+  name: string;
+  description?: string;
+  status: StatusPageStatus;
+}
+
+export interface StatusPageService {
+  name: string;
+  status: StatusPageStatus;
+  components: StatusPageComponent[];
+}
+
+export interface StatusPage {
+  title: string;
+  status: StatusPageStatus;
+  services: StatusPageService[];
+  incidents: StatusIncident[]; // open incidents
+  maintenance: StatusIncident[]; // under way or upcoming
+  history: StatusIncident[]; // closed within history_days
+  updated_at: string;
+}
+
+export interface CreateStatusIncidentRequest {
+  kind?: StatusIncidentKind;
+  title: string;
+  impact?: StatusIncidentImpact;
+  state?: StatusIncidentState;
+  components: string[];
+  scheduled_start?: string;
+  scheduled_end?: string;
+  message: string;
+}
+
+export interface PostStatusIncidentUpdateRequest {
+  state?: StatusIncidentState; // defaults to the current state
+  message: string;
+}
@@ -2,6 +2,7 @@ import type {
   ResultData,
   ValMatrix,
   ValScalar,
+  ValString,
   ValVector,
 } from '@/types/Data.ts';
 
@@ -21,6 +22,11 @@ type ProcessedResult =
       processed: ValMatrix[];
       original: ResultData;
     }
+  | {
+      type: 'string';
+      processed: ValString;
+      original: ResultData;
+    }
   | {
       type: 'unknown';
       processed: unknown;
@@ -46,6 +52,10 @@ export function processResult(result?: ResultData): ProcessedResult | null {
       const matrix = result.data as ValMatrix[];
       return { type: 'matrix', processed: matrix, original: result };
 
+    case 'string':
+      const formatted = result.data as ValString;
+      return { type: 'string', processed: formatted, original: result };
+
     default:
       console.log('Unknown result type:', result.type);
       return { type: 'unknown', processed: result.data, original: result };